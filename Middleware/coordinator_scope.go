@@ -0,0 +1,40 @@
+package middleware
+
+import (
+    "errors"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    repo "StudenAchievementReportingSystem/app/repository/postgresql"
+    "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
+)
+
+// UserID returns the id of the authenticated caller, stored by AuthRequired
+// as a uuid.UUID or, by older code, as a string
+func UserID(c *fiber.Ctx) (uuid.UUID, error) {
+    switch v := c.Locals("user_id").(type) {
+    case uuid.UUID:
+        return v, nil
+    case string:
+        return uuid.Parse(v)
+    case nil:
+        return uuid.Nil, errors.New("unauthorized: user_id missing in context")
+    }
+    return uuid.Nil, errors.New("server error: user_id format invalid (expected string or uuid)")
+}
+
+// CoordinatorScope returns the scope of a caller holding oversight:scoped, or
+// nil when the caller's access is not limited to a scope
+func CoordinatorScope(c *fiber.Ctx, org repo.OrganizationRepository) (*models.CoordinatorScope, error) {
+    if !HasPermission(c, "oversight:scoped") {
+        return nil, nil
+    }
+    userID, err := UserID(c)
+    if err != nil {
+        return nil, err
+    }
+    if org == nil {
+        return &models.CoordinatorScope{UserID: userID}, nil
+    }
+    return org.GetCoordinatorScope(c.Context(), userID)
+}
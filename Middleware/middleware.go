@@ -4,6 +4,7 @@ import (
     "strings"
     "StudenAchievementReportingSystem/utils"
     "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
 )
 
 func AuthRequired() fiber.Handler {
@@ -28,10 +29,36 @@ func AuthRequired() fiber.Handler {
         c.Locals("role_name", claims.RoleName) 
         c.Locals("permissions", claims.Permissions) 
 
+        // super admins work across tenants unless they pick one with X-Tenant-ID;
+        // everyone else is held to the tenant of their token
+        if hasClaim(claims.Permissions, "tenant:all") {
+            c.Locals(utils.TenantKey, utils.AllTenants)
+            if header := c.Get("X-Tenant-ID"); header != "" {
+                tenantID, err := uuid.Parse(header)
+                if err != nil || tenantID == uuid.Nil {
+                    return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid X-Tenant-ID header"})
+                }
+                c.Locals(utils.TenantKey, tenantID)
+            }
+        } else if claims.TenantID == uuid.Nil {
+            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token has no tenant"})
+        } else {
+            c.Locals(utils.TenantKey, claims.TenantID)
+        }
+
         return c.Next()
     }
 }
 
+func hasClaim(perms []string, needed string) bool {
+    for _, p := range perms {
+        if p == needed {
+            return true
+        }
+    }
+    return false
+}
+
 func RoleAllowed(allowedRoles ...string) fiber.Handler {
     return func(c *fiber.Ctx) error {
         role := c.Locals("role_name")
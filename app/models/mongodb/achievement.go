@@ -38,15 +38,77 @@ type AchievementDetails struct {
 }
 
 type Attachment struct {
-	FileName   string    `bson:"fileName" json:"fileName"`
-	FileURL    string    `bson:"fileUrl" json:"fileUrl"`
-	FileType   string    `bson:"fileType" json:"fileType"`
-	UploadedAt time.Time `bson:"uploadedAt" json:"uploadedAt"`
+	ID         string             `bson:"id" json:"id"`
+	FileName   string             `bson:"fileName" json:"fileName"`
+	Caption    string             `bson:"caption,omitempty" json:"caption,omitempty"`
+	Kind       string             `bson:"kind,omitempty" json:"kind,omitempty"` // certificate, photo, assignment_letter or other
+	StorageKey string             `bson:"storageKey" json:"-"` // key of the file in the attachment storage
+	FileURL    string             `bson:"-" json:"fileUrl,omitempty"` // resolved from StorageKey when read
+	FileType   string             `bson:"fileType" json:"fileType"`
+	FileSize   int64              `bson:"fileSize,omitempty" json:"fileSize,omitempty"` // bytes; counts towards the storage quotas
+	Checksum   string             `bson:"checksum,omitempty" json:"checksum,omitempty"` // SHA-256, hex
+	UploadedAt time.Time          `bson:"uploadedAt" json:"uploadedAt"`
+	Scan       *AttachmentScan    `bson:"scan,omitempty" json:"scan,omitempty"` // nil for files uploaded before scanning
+	Preview    *AttachmentPreview `bson:"preview,omitempty" json:"preview,omitempty"` // nil for types without previews
+	PreviewURL string             `bson:"-" json:"previewUrl,omitempty"` // resolved when the preview is ready
+	MissingAt  *time.Time         `bson:"missingAt,omitempty" json:"missingAt,omitempty"` // set when the storage check did not find the file
+}
+
+const (
+	ScanPending  = "pending"
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+)
+
+// AttachmentScan is the malware scan verdict of an attachment. Uploads stay
+// in quarantine (pending) until scanned; infected files are deleted.
+type AttachmentScan struct {
+	Status    string     `bson:"status" json:"status"`
+	Signature string     `bson:"signature,omitempty" json:"signature,omitempty"`
+	ScannedAt *time.Time `bson:"scannedAt,omitempty" json:"scannedAt,omitempty"`
+}
+
+const (
+	PreviewPending     = "pending"
+	PreviewReady       = "ready"
+	PreviewUnavailable = "unavailable"
+)
+
+// AttachmentPreview tracks the thumbnail of an image or the first page of a
+// PDF, stored next to the file. Previews are made after the malware scan.
+type AttachmentPreview struct {
+	Status      string     `bson:"status" json:"status"`
+	GeneratedAt *time.Time `bson:"generatedAt,omitempty" json:"generatedAt,omitempty"`
+}
+
+// Downloadable reports whether the file passed the scan, or predates it, and
+// was not found missing from storage
+func (a Attachment) Downloadable() bool {
+	return a.MissingAt == nil && (a.Scan == nil || a.Scan.Status == ScanClean)
+}
+
+// DuplicateWarning flags another achievement that looks like the same claim
+type DuplicateWarning struct {
+	AchievementID string    `bson:"achievementId" json:"achievementId"` // achievement_references.id
+	MongoID       string    `bson:"mongoId" json:"mongoId"`
+	StudentID     string    `bson:"studentId" json:"studentId"`
+	Title         string    `bson:"title" json:"title"`
+	Reasons       []string  `bson:"reasons" json:"reasons"`
+	DetectedAt    time.Time `bson:"detectedAt" json:"detectedAt"`
+}
+
+// DuplicateKeys holds normalized copies of the fields duplicate detection
+// matches on, so candidates are found with indexed equality lookups
+type DuplicateKeys struct {
+	Title               string `bson:"title,omitempty"`
+	CertificationNumber string `bson:"certificationNumber,omitempty"`
+	CompetitionName     string `bson:"competitionName,omitempty"`
 }
 
 type Achievement struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	StudentID       string             `bson:"studentId" json:"studentId"` 
+	TenantID        string             `bson:"tenantId,omitempty" json:"-"` // tenants.id of the student
 	AchievementType string             `bson:"achievementType" json:"achievementType"` 
 	Title           string             `bson:"title" json:"title"`
 	Description     string             `bson:"description" json:"description"`
@@ -55,6 +117,23 @@ type Achievement struct {
 	Attachments     []Attachment       `bson:"attachments" json:"attachments"`
 	Tags            []string           `bson:"tags" json:"tags"`
 	Points          int                `bson:"points" json:"points"`
+	PeriodID        string             `bson:"periodId,omitempty" json:"periodId,omitempty"` // academic period of the event date
+	DuplicateWarnings []DuplicateWarning `bson:"duplicateWarnings,omitempty" json:"duplicateWarnings,omitempty"`
+	DuplicateKeys   *DuplicateKeys     `bson:"duplicateKeys,omitempty" json:"-"` // set by the repository on every write
+	Version         int                `bson:"version" json:"version"`
+	VerifiedVersion int                `bson:"verifiedVersion,omitempty" json:"verifiedVersion,omitempty"`
+	VerifiedHash    string             `bson:"verifiedHash,omitempty" json:"verifiedHash,omitempty"`
+	VerifiedHashFormat int             `bson:"verifiedHashFormat,omitempty" json:"verifiedHashFormat,omitempty"` // format VerifiedHash was computed in
+	DeletedAt       *time.Time         `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"` // set while in the trash
 	CreatedAt       time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt       time.Time          `bson:"updatedAt" json:"updatedAt"`
-}
\ No newline at end of file
+}
+
+// EventDate is the day an achievement happened: the event date, or the start
+// date for organization activities. It is zero when neither is set.
+func (a Achievement) EventDate() time.Time {
+	if !a.Details.EventDate.IsZero() {
+		return a.Details.EventDate
+	}
+	return a.Details.StartDate
+}
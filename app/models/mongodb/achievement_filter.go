@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// AchievementFilter holds list filters that apply to the MongoDB document
+type AchievementFilter struct {
+	Search    string     // $text search over title, description and tags
+	Types     []string   // achievementType in
+	Level     string     // details.competitionLevel
+	DateFrom  *time.Time // eventDate or startDate >= DateFrom
+	DateTo    *time.Time // eventDate or startDate < DateTo
+	MinPoints *int
+	MaxPoints *int
+}
+
+// IsEmpty reports whether no filter is set, in which case the list can be paged in Postgres alone
+func (f AchievementFilter) IsEmpty() bool {
+	return f.Search == "" && len(f.Types) == 0 && f.Level == "" &&
+		f.DateFrom == nil && f.DateTo == nil && f.MinPoints == nil && f.MaxPoints == nil
+}
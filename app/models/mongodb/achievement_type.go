@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JSONSchema is the subset of JSON Schema supported for custom fields.
+// It is stored as a typed document so keys such as "$schema" never reach MongoDB.
+type JSONSchema struct {
+	Type        string                 `bson:"type,omitempty" json:"type,omitempty"` // object, string, number, integer, boolean, array
+	Title       string                 `bson:"title,omitempty" json:"title,omitempty"`
+	Description string                 `bson:"description,omitempty" json:"description,omitempty"`
+	Properties  map[string]*JSONSchema `bson:"properties,omitempty" json:"properties,omitempty"`
+	Required    []string               `bson:"required,omitempty" json:"required,omitempty"`
+	Items       *JSONSchema            `bson:"items,omitempty" json:"items,omitempty"`
+	Enum        []interface{}          `bson:"enum,omitempty" json:"enum,omitempty"`
+	Format      string                 `bson:"format,omitempty" json:"format,omitempty"` // date, date-time, email, uri
+	Pattern     string                 `bson:"pattern,omitempty" json:"pattern,omitempty"`
+	MinLength   *int                   `bson:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength   *int                   `bson:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Minimum     *float64               `bson:"minimum,omitempty" json:"minimum,omitempty"`
+	Maximum     *float64               `bson:"maximum,omitempty" json:"maximum,omitempty"`
+	MinItems    *int                   `bson:"minItems,omitempty" json:"minItems,omitempty"`
+	MaxItems    *int                   `bson:"maxItems,omitempty" json:"maxItems,omitempty"`
+
+	AdditionalProperties *bool `bson:"additionalProperties,omitempty" json:"additionalProperties,omitempty"`
+}
+
+type FieldUIHint struct {
+	Label       string `bson:"label,omitempty" json:"label,omitempty"`
+	Widget      string `bson:"widget,omitempty" json:"widget,omitempty"` // text, textarea, date, select, number
+	Placeholder string `bson:"placeholder,omitempty" json:"placeholder,omitempty"`
+	HelpText    string `bson:"helpText,omitempty" json:"helpText,omitempty"`
+	Order       int    `bson:"order,omitempty" json:"order,omitempty"`
+}
+
+type PointRule struct {
+	DefaultPoints int            `bson:"defaultPoints" json:"defaultPoints"`
+	ByLevel       map[string]int `bson:"byLevel,omitempty" json:"byLevel,omitempty"` // keyed on details.competitionLevel
+}
+
+type AchievementTypeDefinition struct {
+	ID                      primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Code                    string                 `bson:"code" json:"code"`
+	TenantID                string                 `bson:"tenantId,omitempty" json:"tenantId,omitempty"` // empty for types shared by all tenants
+	Name                    string                 `bson:"name" json:"name"`
+	Description             string                 `bson:"description" json:"description"`
+	BuiltIn                 bool                   `bson:"builtIn" json:"builtIn"`
+	CustomFieldSchema       *JSONSchema            `bson:"customFieldSchema,omitempty" json:"customFieldSchema,omitempty"`
+	UIHints                 map[string]FieldUIHint `bson:"uiHints,omitempty" json:"uiHints,omitempty"`
+	PointRule               PointRule              `bson:"pointRule" json:"pointRule"`
+	RequiredAttachmentKinds []string               `bson:"requiredAttachmentKinds" json:"requiredAttachmentKinds"`
+	IsActive                bool                   `bson:"isActive" json:"isActive"`
+	CreatedAt               time.Time              `bson:"createdAt" json:"createdAt"`
+	UpdatedAt               time.Time              `bson:"updatedAt" json:"updatedAt"`
+}
+
+// PointsFor returns the suggested points for an achievement of this type
+func (t *AchievementTypeDefinition) PointsFor(a Achievement) int {
+	if p, ok := t.PointRule.ByLevel[a.Details.CompetitionLevel]; ok {
+		return p
+	}
+	return t.PointRule.DefaultPoints
+}
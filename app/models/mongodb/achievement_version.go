@@ -0,0 +1,133 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AchievementVersion is an immutable copy of an achievement document as it was
+// before an update (or at verification time)
+type AchievementVersion struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	AchievementID string             `bson:"achievementId" json:"achievementId"` // achievements._id, hex
+	Version       int                `bson:"version" json:"version"`
+	Hash          string             `bson:"hash" json:"hash"`
+	HashFormat    int                `bson:"hashFormat,omitempty" json:"hashFormat"` // how Hash was computed, see ContentHashFormat
+	Snapshot      Achievement        `bson:"snapshot" json:"snapshot"`
+	ArchivedAt    time.Time          `bson:"archivedAt" json:"archivedAt"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// CurrentVersion returns the version number of the document; documents created
+// before versioning existed count as version 1
+func (a Achievement) CurrentVersion() int {
+	if a.Version < 1 {
+		return 1
+	}
+	return a.Version
+}
+
+// Content hash formats. Stored hashes carry the format they were computed in,
+// so changing how content is hashed never flags verified achievements as edited.
+const (
+	// HashFormatLegacy is the format of hashes stored without a format: it
+	// still pretends files live below /uploads/
+	HashFormatLegacy = 0
+	// HashFormatCanonical hashes attachments by identity and content only,
+	// leaving out where and how the file is stored
+	HashFormatCanonical = 1
+
+	CurrentHashFormat = HashFormatCanonical
+)
+
+// legacyAttachment is the layout attachments had when content hashes were first
+// stored; fields added later are omitted when empty for the same reason
+type legacyAttachment struct {
+	FileName   string    `json:"fileName"`
+	FileURL    string    `json:"fileUrl"`
+	FileType   string    `json:"fileType"`
+	Checksum   string    `json:"checksum,omitempty"`
+	UploadedAt time.Time `json:"uploadedAt"`
+	Caption    string    `json:"caption,omitempty"`
+	Kind       string    `json:"kind,omitempty"`
+}
+
+// canonicalAttachment is an attachment in HashFormatCanonical. The storage
+// key, size, scan and preview are left out: they change when files are moved
+// or checked, not when the student changes the claim.
+type canonicalAttachment struct {
+	ID         string    `json:"id"`
+	FileName   string    `json:"fileName"`
+	FileType   string    `json:"fileType"`
+	Checksum   string    `json:"checksum"`
+	UploadedAt time.Time `json:"uploadedAt"`
+	Caption    string    `json:"caption"`
+	Kind       string    `json:"kind"`
+}
+
+// ContentHash is a SHA-256 over the student-supplied content of an achievement
+// in CurrentHashFormat. Review data (points, duplicate warnings) and bookkeeping
+// fields are excluded so the hash only changes when the claim itself changes.
+func (a Achievement) ContentHash() string {
+	return a.ContentHashFormat(CurrentHashFormat)
+}
+
+// ContentHashFormat is ContentHash in the given format, for comparing with
+// hashes stored in an older one
+func (a Achievement) ContentHashFormat(format int) string {
+	var attachments interface{}
+	if a.Attachments != nil {
+		if format == HashFormatLegacy {
+			list := make([]legacyAttachment, len(a.Attachments))
+			for i, att := range a.Attachments {
+				list[i] = legacyAttachment{
+					FileName:   att.FileName,
+					FileURL:    "/uploads/" + att.StorageKey,
+					FileType:   att.FileType,
+					Checksum:   att.Checksum,
+					UploadedAt: att.UploadedAt.UTC().Truncate(time.Millisecond),
+					Caption:    att.Caption,
+					Kind:       att.Kind,
+				}
+			}
+			attachments = list
+		} else {
+			list := make([]canonicalAttachment, len(a.Attachments))
+			for i, att := range a.Attachments {
+				list[i] = canonicalAttachment{
+					ID:         att.ID,
+					FileName:   att.FileName,
+					FileType:   att.FileType,
+					Checksum:   att.Checksum,
+					UploadedAt: att.UploadedAt.UTC().Truncate(time.Millisecond),
+					Caption:    att.Caption,
+					Kind:       att.Kind,
+				}
+			}
+			attachments = list
+		}
+	}
+
+	content := struct {
+		StudentID       string                 `json:"studentId"`
+		AchievementType string                 `json:"achievementType"`
+		Title           string                 `json:"title"`
+		Description     string                 `json:"description"`
+		Details         AchievementDetails     `json:"details"`
+		CustomFields    map[string]interface{} `json:"customFields"`
+		Attachments     interface{}            `json:"attachments"`
+		Tags            []string               `json:"tags"`
+	}{a.StudentID, a.AchievementType, a.Title, a.Description, a.Details, a.CustomFields, attachments, a.Tags}
+
+	b, _ := json.Marshal(content)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,5 +1,11 @@
 package models
 
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 // Struktur Output Statistik Global
 type GlobalStatistics struct {
     TotalAchievements int                    `json:"totalAchievements"`
@@ -7,6 +13,31 @@ type GlobalStatistics struct {
     TypeDistribution   map[string]int        `json:"typeDistribution"`
     LevelDistribution  map[string]int        `json:"levelDistribution"` // Nasional, Internasional, dll
     TrendByYear        map[string]int        `json:"trendByYear"`
+    PeriodDistribution map[string]int        `json:"periodDistribution"` // keyed by academic period name
+}
+
+// StatsFilter narrows the report aggregations; empty fields do not filter
+type StatsFilter struct {
+    PeriodID   string   // academic_periods.id
+    StudentIDs []string // limits to these students when not nil, e.g. a coordinator scope
+}
+
+// StudentTotal is the number of achievements and points of one student
+type StudentTotal struct {
+    StudentID    string `bson:"_id"`
+    Achievements int    `bson:"achievements"`
+    TotalPoints  int    `bson:"totalPoints"`
+}
+
+// UnitStatistics is one row of the report grouped by faculty, department or
+// program study. Students without a program study are reported with an empty id.
+type UnitStatistics struct {
+    ID           string `json:"id,omitempty"`
+    Code         string `json:"code,omitempty"`
+    Name         string `json:"name"`
+    Students     int    `json:"students"`
+    Achievements int    `json:"achievements"`
+    TotalPoints  int    `json:"totalPoints"`
 }
 
 type TopStudent struct {
@@ -22,4 +53,88 @@ type StudentStatistics struct {
     TotalPoints      int            `json:"totalPoints"`
     TotalAchievements int           `json:"totalAchievements"`
     ByType           map[string]int `json:"byType"`
-}
\ No newline at end of file
+}
+
+// Struktur Output Laporan Dugaan Duplikat
+type DuplicateReportItem struct {
+    AchievementID string             `json:"achievementId"`
+    Status        string             `json:"status"`
+    StudentID     string             `json:"studentId"`
+    StudentName   string             `json:"studentName"`
+    Title         string             `json:"title"`
+    Type          string             `json:"type"`
+    Warnings      []DuplicateWarning `json:"warnings"`
+}
+
+// StorageUsage is the space taken by attachment files. As a quota, a zero
+// field is not limited.
+type StorageUsage struct {
+    Bytes int64 `bson:"bytes" json:"bytes"`
+    Files int   `bson:"files" json:"files"`
+}
+
+// AchievementStorage is the usage of one achievement's attachments
+type AchievementStorage struct {
+    MongoID       primitive.ObjectID `bson:"_id" json:"-"`
+    AchievementID string             `bson:"-" json:"achievementId,omitempty"`
+    Title         string             `bson:"title" json:"title"`
+    InTrash       bool               `bson:"-" json:"inTrash,omitempty"`
+    StorageUsage  `bson:",inline"`
+}
+
+// StudentStorage is a student's attachment usage against the quotas
+type StudentStorage struct {
+    Used             StorageUsage         `json:"used"`
+    Quota            StorageUsage         `json:"quota"`
+    AchievementQuota StorageUsage         `json:"achievementQuota"`
+    Achievements     []AchievementStorage `json:"achievements"`
+}
+
+// StorageReportItem is one student of the storage report
+type StorageReportItem struct {
+    StudentID    string `bson:"_id" json:"studentId"`
+    StudentName  string `bson:"-" json:"studentName"`
+    StorageUsage `bson:",inline"`
+    Achievements int    `bson:"achievements" json:"achievements"`
+    OverQuota    bool   `bson:"-" json:"overQuota"`
+}
+
+// StorageCheckReport is the outcome of checking the attachment storage
+// against the attachments in the database
+type StorageCheckReport struct {
+    CheckedAt   time.Time           `json:"checkedAt"`
+    Files       int                 `json:"files"`       // files in storage, previews included
+    Attachments int                 `json:"attachments"` // attachments that should have a file
+    Orphans     []OrphanFile        `json:"orphans"`
+    Missing     []MissingAttachment `json:"missing"`
+    BlobDrift   []BlobDrift         `json:"blobDrift"`
+    Repaired    bool                `json:"repaired"`
+    Deleted     int                 `json:"deleted"`  // orphans deleted by the repair
+    Marked      int                 `json:"marked"`   // attachments newly marked missing
+    Restored    int                 `json:"restored"` // attachments whose file is back
+}
+
+// OrphanFile is a stored file no attachment refers to
+type OrphanFile struct {
+    Key     string    `json:"key"`
+    Size    int64     `json:"size"`
+    ModTime time.Time `json:"modTime"`
+}
+
+// MissingAttachment is an attachment whose file is not in storage
+type MissingAttachment struct {
+    MongoID      string     `json:"mongoId"`
+    AttachmentID string     `json:"attachmentId"`
+    FileName     string     `json:"fileName"`
+    StorageKey   string     `json:"storageKey"`
+    MissingAt    *time.Time `json:"missingAt,omitempty"` // when it was first marked
+}
+
+// BlobDrift is a shared file whose reference count disagrees with the number
+// of attachments using it
+type BlobDrift struct {
+    Checksum    string `json:"checksum"`
+    StorageKey  string `json:"storageKey"`
+    RefCount    int    `json:"refCount"`    // 0 when the blob is not registered
+    Attachments int    `json:"attachments"`
+}
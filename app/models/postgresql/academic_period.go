@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+const (
+	SemesterGanjil = "ganjil"
+	SemesterGenap  = "genap"
+	SemesterPendek = "pendek"
+)
+
+// AcademicPeriod is a semester of an academic year. Achievements belong to the
+// period their event date falls in; new submissions for a period are refused
+// after its submission deadline.
+type AcademicPeriod struct {
+	ID                 uuid.UUID  `json:"id" db:"id"`
+	Name               string     `json:"name" db:"name"`                   // e.g. "Ganjil 2024/2025"
+	AcademicYear       string     `json:"academicYear" db:"academic_year"` // e.g. "2024/2025"
+	Semester           string     `json:"semester" db:"semester"`           // ganjil, genap or pendek
+	StartDate          time.Time  `json:"startDate" db:"start_date"`
+	EndDate            time.Time  `json:"endDate" db:"end_date"`
+	SubmissionDeadline *time.Time `json:"submissionDeadline" db:"submission_deadline"`
+	IsActive           bool       `json:"isActive" db:"is_active"`
+	CreatedAt          time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// Contains reports whether t falls on a day between the start and end date, inclusive
+func (p AcademicPeriod) Contains(t time.Time) bool {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return !day.Before(p.StartDate.UTC()) && !day.After(p.EndDate.UTC())
+}
+
+// SubmissionClosed reports whether the submission deadline has passed at now
+func (p AcademicPeriod) SubmissionClosed(now time.Time) bool {
+	return p.SubmissionDeadline != nil && now.After(*p.SubmissionDeadline)
+}
@@ -22,6 +22,9 @@ type AchievementReference struct {
 	VerifiedAt         *time.Time `json:"verifiedAt" db:"verified_at"`
 	VerifiedBy         *uuid.UUID `json:"verifiedBy" db:"verified_by"`
 	RejectionNote      *string    `json:"rejectionNote" db:"rejection_note"`
+	Version            int        `json:"version" db:"version"`
+	PeriodID           *uuid.UUID `json:"periodId" db:"period_id"`
+	DeletedAt          *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
 	CreatedAt          time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt          time.Time  `json:"updatedAt" db:"updated_at"`
 }
\ No newline at end of file
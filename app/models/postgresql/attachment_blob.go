@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// AttachmentBlob is a stored file shared by every attachment with the same
+// content
+type AttachmentBlob struct {
+	Checksum    string    `json:"checksum" db:"checksum"` // SHA-256, hex
+	StorageKey  string    `json:"storageKey" db:"storage_key"`
+	SizeBytes   int64     `json:"sizeBytes" db:"size_bytes"`
+	ContentType string    `json:"contentType" db:"content_type"`
+	RefCount    int       `json:"refCount" db:"ref_count"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// AttachmentType allows uploads of a detected file type up to a size
+type AttachmentType struct {
+	Code         string    `json:"code" db:"code"` // utils.FileTypes code, e.g. "pdf"
+	MaxSizeBytes int64     `json:"maxSizeBytes" db:"max_size_bytes"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
@@ -10,6 +10,7 @@ type JWTClaims struct {
 	UserID      uuid.UUID `json:"userId"`
 	RoleID      uuid.UUID `json:"roleId"`
 	RoleName    string    `json:"roleName"`
+	TenantID    uuid.UUID `json:"tenantId"`
 	Permissions []string  `json:"permissions,omitempty"` 
 	jwt.RegisteredClaims
 }
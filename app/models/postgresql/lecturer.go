@@ -9,7 +9,8 @@ type Lecturer struct {
     ID          uuid.UUID `json:"id" db:"id"`
     UserID      uuid.UUID `json:"user_id" db:"user_id"`
     LecturerID  string    `json:"lecturer_id" db:"lecturer_id"` 
-    Department  string    `json:"department" db:"department"`
+    Department  string    `json:"department" db:"department"` // name of the department
+    DepartmentID *uuid.UUID `json:"department_id" db:"department_id"`
     CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -18,4 +19,14 @@ type LecturerResp struct {
     LecturerID string    `json:"lecturerId"`
     FullName   string    `json:"fullName,omitempty"`
     Department string    `json:"department"`
-}
\ No newline at end of file
+}
+
+// LecturerSummary is a lecturer with the name of its user account
+type LecturerSummary struct {
+    ID         uuid.UUID `json:"id"`
+    UserID     uuid.UUID `json:"userId"`
+    LecturerID string    `json:"lecturerId"`
+    FullName   string    `json:"fullName"`
+    Department string    `json:"department"`
+    DepartmentID *uuid.UUID `json:"departmentId,omitempty"`
+}
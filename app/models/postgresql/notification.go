@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+const NotificationAttachmentInfected = "attachment_infected"
+
+// Notification is a message for one user
+type Notification struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"-" db:"user_id"`
+	Kind          string     `json:"kind" db:"kind"`
+	Message       string     `json:"message" db:"message"`
+	AchievementID *uuid.UUID `json:"achievementId,omitempty" db:"achievement_id"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	ReadAt        *time.Time `json:"readAt" db:"read_at"`
+}
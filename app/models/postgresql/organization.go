@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// Faculty is the top level of the organization: faculty > department > program study
+type Faculty struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"` // e.g. "FT"
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Department belongs to a faculty; lecturers belong to a department
+type Department struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	FacultyID uuid.UUID `json:"facultyId" db:"faculty_id"`
+	Code      string    `json:"code" db:"code"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// ProgramStudy belongs to a department; students belong to a program study
+type ProgramStudy struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	DepartmentID uuid.UUID `json:"departmentId" db:"department_id"`
+	Code         string    `json:"code" db:"code"`
+	Name         string    `json:"name" db:"name"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// OrgUnit is a faculty, department or program study as shown in grouped reports
+type OrgUnit struct {
+	ID   uuid.UUID `json:"id"`
+	Code string    `json:"code"`
+	Name string    `json:"name"`
+}
+
+// StudentUnits places a student in the organization. Units are nil while the
+// student has no program study.
+type StudentUnits struct {
+	StudentID    uuid.UUID
+	ProgramStudy *OrgUnit
+	Department   *OrgUnit
+	Faculty      *OrgUnit
+}
+
+// CoordinatorScope lists the departments and program studies a coordinator
+// oversees. Covered holds every program study in scope, including those of the
+// listed departments.
+type CoordinatorScope struct {
+	UserID          uuid.UUID   `json:"userId"`
+	DepartmentIDs   []uuid.UUID `json:"departmentIds"`
+	ProgramStudyIDs []uuid.UUID `json:"programStudyIds"`
+	Covered         []uuid.UUID `json:"coveredProgramStudyIds"`
+}
+
+// Covers reports whether a student of the given program study is in scope
+func (s CoordinatorScope) Covers(programStudyID *uuid.UUID) bool {
+	if programStudyID == nil {
+		return false
+	}
+	for _, id := range s.Covered {
+		if id == *programStudyID {
+			return true
+		}
+	}
+	return false
+}
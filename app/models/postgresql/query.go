@@ -1,11 +1,33 @@
 package models
 
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
 type PaginationQuery struct {
-	Page   int    `query:"page"`
-	Limit  int    `query:"limit"`
-	Sort   string `query:"sort"`   // created_at_desc, created_at_asc
-	Search string `query:"search"` // Optional: Search by title (butuh join mongo, kompleks)
-	Status string `query:"status"` // Filter status
+	Page       int    `query:"page"`
+	Limit      int    `query:"limit"`
+	Sort       string `query:"sort"`       // created_at_desc, created_at_asc
+	Search     string `query:"search"`     // Full-text search over title, description and tags (MongoDB)
+	Status     string `query:"status"`     // Filter status
+	Cursor     string `query:"cursor"`     // Opaque keyset cursor from meta.nextCursor / meta.prevCursor
+	Pagination string `query:"pagination"` // "cursor" to start keyset pagination without a cursor
+	Total      string `query:"total"`      // "approx" adds an estimated total in cursor mode, "exact" counts every search match
+
+	// Filters on the MongoDB document
+	Type      string `query:"type"`     // achievement type, comma separated
+	Level     string `query:"level"`    // details.competitionLevel
+	DateFrom  string `query:"dateFrom"` // YYYY-MM-DD, event or start date
+	DateTo    string `query:"dateTo"`   // YYYY-MM-DD, inclusive
+	MinPoints *int   `query:"minPoints"`
+	MaxPoints *int   `query:"maxPoints"`
+
+	// Filters on the student
+	ProgramStudy string `query:"programStudy"`
+	AcademicYear string `query:"academicYear"`
+
+	Period string `query:"period"` // academic period ID, or "active"
 }
 
 type PaginationMeta struct {
@@ -13,9 +35,25 @@ type PaginationMeta struct {
 	TotalPage   int `json:"totalPage"`
 	TotalData   int `json:"totalData"`
 	Limit       int `json:"limit"`
+
+	// Searches and document filters only: whether matches follow this page
+	HasMore *bool `json:"hasMore,omitempty"`
+
+	// Cursor mode only
+	NextCursor  string `json:"nextCursor,omitempty"`
+	PrevCursor  string `json:"prevCursor,omitempty"`
+	ApproxTotal *int64 `json:"approxTotal,omitempty"`
+}
+
+// Cursor is a position in a listing ordered by (created_at, id).
+// Backward cursors fetch the page before the position.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
 }
 
 type PaginatedResponse struct {
 	Data []interface{}  `json:"data"`
 	Meta PaginationMeta `json:"meta"`
-}
\ No newline at end of file
+}
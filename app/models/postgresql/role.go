@@ -9,5 +9,6 @@ type Roles struct {
 	ID          uuid.UUID `json:"id" db:"id"`
 	Name        string    `json:"name" db:"name"`
 	Description string    `json:"description" db:"description"`
+	TenantID    *uuid.UUID `json:"tenantId,omitempty" db:"tenant_id"` // nil for roles shared by all tenants
 	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
 }
\ No newline at end of file
@@ -10,7 +10,8 @@ type Student struct {
     ID            uuid.UUID  `json:"id" db:"id"`
     UserID        uuid.UUID  `json:"user_id" db:"user_id"`
     StudentID     string     `json:"student_id" db:"student_id"`
-    ProgramStudy  string     `json:"program_study" db:"program_study"`
+    ProgramStudy  string     `json:"program_study" db:"program_study"` // name of the program study
+    ProgramStudyID *uuid.UUID `json:"program_study_id" db:"program_study_id"`
     AcademicYear  string     `json:"academic_year" db:"academic_year"`
     AdvisorID     *uuid.UUID `json:"advisor_id" db:"advisor_id"` 
     CreatedAt     time.Time  `json:"created_at" db:"created_at"`
@@ -27,7 +28,37 @@ type StudentResp struct {
 }
 
 type StudentWithUser struct {
-    ID           uuid.UUID
-    FullName     string
-    ProgramStudy string
+    ID           uuid.UUID `json:"id"`
+    StudentID    string    `json:"studentId"`
+    FullName     string    `json:"fullName"`
+    ProgramStudy string    `json:"programStudy"`
+    ProgramStudyID *uuid.UUID `json:"programStudyId,omitempty"`
+    AcademicYear string    `json:"academicYear"`
+}
+
+// AdvisorAssignment is a period in which a lecturer advised a student.
+// EffectiveTo is nil for the current advisor.
+type AdvisorAssignment struct {
+    ID            uuid.UUID  `json:"id"`
+    StudentID     uuid.UUID  `json:"studentId"`
+    LecturerID    uuid.UUID  `json:"lecturerId"`
+    LecturerName  string     `json:"lecturerName"`
+    AssignedBy    *uuid.UUID `json:"assignedBy,omitempty"`
+    EffectiveFrom time.Time  `json:"effectiveFrom"`
+    EffectiveTo   *time.Time `json:"effectiveTo,omitempty"`
+}
+
+// AdvisorChange moves a student to a lecturer. Both are given by UUID or by
+// student and lecturer number, as in CSV imports.
+type AdvisorChange struct {
+    Student  string `json:"student"`
+    Lecturer string `json:"lecturer"`
+}
+
+// ReassignResult sums up a reassignment. PendingSubmissions counts the submitted
+// achievements of the moved students, which are now verified by the new advisor.
+type ReassignResult struct {
+    Reassigned         int `json:"reassigned"`
+    Unchanged          int `json:"unchanged"`
+    PendingSubmissions int `json:"pendingSubmissions"`
 }
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// DefaultTenantID is the tenant migration 008 moves all existing data into
+var DefaultTenantID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Tenant is a faculty or campus running on the shared deployment with its own
+// users, roles, organization units and achievement types
+type Tenant struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Code      string    `json:"code" db:"code"` // e.g. "FT"
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
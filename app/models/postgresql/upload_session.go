@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// UploadSession is a resumable upload of one attachment that has not been
+// completed yet
+type UploadSession struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	AchievementID uuid.UUID `json:"achievementId" db:"achievement_id"`
+	UserID        uuid.UUID `json:"-" db:"user_id"`
+	FileName      string    `json:"fileName" db:"file_name"`
+	Caption       string    `json:"caption,omitempty" db:"caption"`
+	Kind          string    `json:"kind,omitempty" db:"kind"`
+	Length        int64     `json:"length" db:"upload_length"`
+	Offset        int64     `json:"offset" db:"upload_offset"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+	ExpiresAt     time.Time `json:"expiresAt" db:"expires_at"`
+}
+
+// Complete reports whether every byte of the file has been received
+func (u UploadSession) Complete() bool {
+	return u.Offset >= u.Length
+}
@@ -12,6 +12,7 @@ type User struct {
 	PasswordHash string    `json:"-" db:"password_hash"` 
 	FullName     string    `json:"full_name" db:"full_name"`
 	RoleID       uuid.UUID `json:"role_id" db:"role_id"`
+	TenantID     uuid.UUID `json:"tenant_id" db:"tenant_id"`
 	IsActive     bool      `json:"is_active" db:"is_active"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
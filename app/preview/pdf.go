@@ -0,0 +1,108 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os/exec"
+	"strconv"
+)
+
+type embeddedPDF struct{}
+
+// NewEmbeddedPDF previews a PDF with the first JPEG image stored in it, on a
+// best-effort basis: the page tree is not read, so this is the first page only
+// when the file stores its pages in order, as scanned certificates and letters
+// of one JPEG per page usually do. PDFs without such an image, like documents
+// exported from a word processor, are unsupported. Use pdftoppm to render the
+// actual first page.
+func NewEmbeddedPDF() PDFRenderer {
+	return embeddedPDF{}
+}
+
+func (embeddedPDF) RenderFirstPage(ctx context.Context, pdf []byte) (image.Image, error) {
+	for rest := pdf; ; {
+		i := bytes.Index(rest, []byte("/DCTDecode"))
+		if i < 0 {
+			return nil, fmt.Errorf("%w: no JPEG page image in PDF", ErrUnsupported)
+		}
+		data, ok := imageStream(rest, i)
+		rest = rest[i+len("/DCTDecode"):]
+		if !ok {
+			continue
+		}
+		// the declared size is checked before anything is decoded
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		if cfg.Width*cfg.Height > maxPixels {
+			return nil, fmt.Errorf("%w: page image of %dx%d", ErrUnsupported, cfg.Width, cfg.Height)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		return img, nil
+	}
+}
+
+// imageStream returns the data of the stream whose dictionary contains the
+// filter name at i, if that dictionary describes an image compressed with
+// DCTDecode alone
+func imageStream(pdf []byte, i int) ([]byte, bool) {
+	start := bytes.LastIndex(pdf[:i], []byte("obj"))
+	end := bytes.Index(pdf[i:], []byte("stream"))
+	if start < 0 || end < 0 {
+		return nil, false
+	}
+	dict := pdf[start : i+end]
+	compact := bytes.Join(bytes.Fields(dict), nil)
+	if !bytes.Contains(compact, []byte("/Subtype/Image")) {
+		return nil, false
+	}
+	// other filters applied before DCTDecode would need decoding first
+	for _, filter := range []string{"/FlateDecode", "/LZWDecode", "/ASCII85Decode", "/ASCIIHexDecode"} {
+		if bytes.Contains(compact, []byte(filter)) {
+			return nil, false
+		}
+	}
+
+	data := pdf[i+end+len("stream"):]
+	data = bytes.TrimPrefix(data, []byte("\r"))
+	data = bytes.TrimPrefix(data, []byte("\n"))
+	stop := bytes.Index(data, []byte("endstream"))
+	if stop < 0 {
+		return nil, false
+	}
+	return data[:stop], true
+}
+
+type pdftoppm struct {
+	path string
+}
+
+// NewPdftoppm renders the first page with poppler's pdftoppm, run locally
+func NewPdftoppm(path string) PDFRenderer {
+	return &pdftoppm{path: path}
+}
+
+func (p *pdftoppm) RenderFirstPage(ctx context.Context, pdf []byte) (image.Image, error) {
+	// twice the preview size so the thumbnail is averaged from more pixels
+	cmd := exec.CommandContext(ctx, p.path, "-f", "1", "-l", "1", "-singlefile",
+		"-png", "-scale-to", strconv.Itoa(2*Size), "-")
+	cmd.Stdin = bytes.NewReader(pdf)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			// poppler gave up on the file
+			return nil, fmt.Errorf("%w: pdftoppm: %s", ErrUnsupported, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return nil, fmt.Errorf("pdftoppm: %w", err)
+	}
+	return decodeImage(out.Bytes())
+}
@@ -0,0 +1,167 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os/exec"
+
+	// decoders for image.Decode
+	_ "image/gif"
+	_ "image/png"
+
+	"StudenAchievementReportingSystem/config"
+)
+
+// Size is the longest side of a preview in pixels
+const Size = 320
+
+// MaxInputSize is the largest file a preview is made of; larger files get none
+const MaxInputSize = 64 * 1024 * 1024
+
+// maxPixels bounds decoded images, so a small file declaring a huge canvas
+// cannot exhaust memory
+const maxPixels = 50_000_000
+
+// ErrUnsupported means no preview can be made of a file, which will not
+// change when trying again
+var ErrUnsupported = errors.New("preview: unsupported file")
+
+// Generator makes JPEG previews of attachments
+type Generator interface {
+	Generate(ctx context.Context, contentType string, r io.Reader) ([]byte, error)
+}
+
+// PDFRenderer draws the first page of a PDF
+type PDFRenderer interface {
+	RenderFirstPage(ctx context.Context, pdf []byte) (image.Image, error)
+}
+
+// imageTypes are the formats the standard library decodes
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// Supports reports whether previews are made for a content type
+func Supports(contentType string) bool {
+	return imageTypes[contentType] || contentType == "application/pdf"
+}
+
+type generator struct {
+	pdf PDFRenderer
+}
+
+// New makes previews of images itself and of PDFs with pdf
+func New(pdf PDFRenderer) Generator {
+	return &generator{pdf: pdf}
+}
+
+// FromConfig builds the generator with the PDF renderer selected by
+// PREVIEW_PDF_RENDERER. Previews are made in process unless pdftoppm is asked
+// for explicitly; it is never picked up just because it is on the PATH.
+func FromConfig(cfg config.PreviewConfig) (Generator, error) {
+	switch cfg.PDFRenderer {
+	case "", "embedded":
+		return New(NewEmbeddedPDF()), nil
+	case "pdftoppm":
+		if _, err := exec.LookPath(cfg.PdftoppmPath); err != nil {
+			return nil, fmt.Errorf("preview: %w", err)
+		}
+		return New(NewPdftoppm(cfg.PdftoppmPath)), nil
+	}
+	return nil, fmt.Errorf("preview: unknown PDF renderer %q", cfg.PDFRenderer)
+}
+
+func (g *generator) Generate(ctx context.Context, contentType string, r io.Reader) ([]byte, error) {
+	if !Supports(contentType) {
+		return nil, ErrUnsupported
+	}
+	data, err := io.ReadAll(io.LimitReader(r, MaxInputSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxInputSize {
+		return nil, ErrUnsupported
+	}
+
+	var img image.Image
+	if contentType == "application/pdf" {
+		img, err = g.pdf.RenderFirstPage(ctx, data)
+	} else {
+		img, err = decodeImage(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, Thumbnail(img, Size), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// decodeImage decodes an image after checking its declared dimensions.
+// Undecodable images are unsupported rather than failures.
+func decodeImage(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: image of %dx%d", ErrUnsupported, cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	return img, nil
+}
+
+// Thumbnail scales img down to fit in size x size pixels, averaging the pixels
+// that make up each thumbnail pixel, onto a white background. Images that
+// already fit keep their size.
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, max(1, h*size/w)
+		} else {
+			tw, th = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			// colors are premultiplied, so the missing alpha is white
+			white := 0xffff*n - a
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r + white) / n >> 8),
+				G: uint8((g + white) / n >> 8),
+				B: uint8((bl + white) / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,71 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+)
+
+type MockAcademicPeriodRepo struct {
+	mock.Mock
+}
+
+// Compile-time check implementation
+var _ repo.AcademicPeriodRepository = (*MockAcademicPeriodRepo)(nil)
+
+func (m *MockAcademicPeriodRepo) GetAll(ctx context.Context) ([]models.AcademicPeriod, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AcademicPeriod), args.Error(1)
+}
+
+func (m *MockAcademicPeriodRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.AcademicPeriod, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AcademicPeriod), args.Error(1)
+}
+
+func (m *MockAcademicPeriodRepo) GetActive(ctx context.Context) (*models.AcademicPeriod, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AcademicPeriod), args.Error(1)
+}
+
+func (m *MockAcademicPeriodRepo) FindByDate(ctx context.Context, date time.Time) (*models.AcademicPeriod, error) {
+	args := m.Called(ctx, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AcademicPeriod), args.Error(1)
+}
+
+func (m *MockAcademicPeriodRepo) HasOverlap(ctx context.Context, start, end time.Time, excludeID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, start, end, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAcademicPeriodRepo) Create(ctx context.Context, p models.AcademicPeriod) (uuid.UUID, error) {
+	args := m.Called(ctx, p)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockAcademicPeriodRepo) Update(ctx context.Context, p models.AcademicPeriod) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockAcademicPeriodRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	repoMongo "StudenAchievementReportingSystem/app/repository/mongodb"
+)
+
+type MockAchievementTypeRepo struct {
+	mock.Mock
+}
+
+// Compile-time check implementation
+var _ repoMongo.AchievementTypeRepository = (*MockAchievementTypeRepo)(nil)
+
+func (m *MockAchievementTypeRepo) FindAll(ctx context.Context, activeOnly bool) ([]modelMongo.AchievementTypeDefinition, error) {
+	args := m.Called(ctx, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.AchievementTypeDefinition), args.Error(1)
+}
+
+func (m *MockAchievementTypeRepo) FindByCode(ctx context.Context, code string) (*modelMongo.AchievementTypeDefinition, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*modelMongo.AchievementTypeDefinition), args.Error(1)
+}
+
+func (m *MockAchievementTypeRepo) Insert(ctx context.Context, def modelMongo.AchievementTypeDefinition) (string, error) {
+	args := m.Called(ctx, def)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAchievementTypeRepo) Update(ctx context.Context, code string, def modelMongo.AchievementTypeDefinition) error {
+	args := m.Called(ctx, code, def)
+	return args.Error(0)
+}
+
+func (m *MockAchievementTypeRepo) SetActive(ctx context.Context, code string, active bool) error {
+	args := m.Called(ctx, code, active)
+	return args.Error(0)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -28,8 +29,8 @@ type MockAchievementMongoRepo struct {
 // Compile-time check implementation
 var _ repoMongo.AchievementRepository = (*MockAchievementMongoRepo)(nil)
 
-func (m *MockAchievementMongoRepo) GetStudentAchievements(studentId uuid.UUID) ([]modelMongo.Achievement, error) {
-	args := m.Called(studentId)
+func (m *MockAchievementMongoRepo) GetStudentAchievements(ctx context.Context, studentId uuid.UUID) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx, studentId)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -67,27 +68,55 @@ func (m *MockAchievementMongoRepo) UpdateOne(ctx context.Context, mongoID string
 	return args.Error(0)
 }
 
-func (m *MockAchievementMongoRepo) AddAttachment(ctx context.Context, mongoID string, attachment modelMongo.Attachment) error {
+func (m *MockAchievementMongoRepo) AddAttachments(ctx context.Context, mongoID string, attachments []modelMongo.Attachment) error {
+	args := m.Called(ctx, mongoID, attachments)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) ReplaceAttachment(ctx context.Context, mongoID string, attachment modelMongo.Attachment) error {
 	args := m.Called(ctx, mongoID, attachment)
 	return args.Error(0)
 }
 
-func (m *MockAchievementMongoRepo) GetGlobalStats(ctx context.Context) (*modelMongo.GlobalStatistics, error) {
-	args := m.Called(ctx)
+func (m *MockAchievementMongoRepo) UpdateAttachmentInfo(ctx context.Context, mongoID, attachmentID, caption, kind string) error {
+	args := m.Called(ctx, mongoID, attachmentID, caption, kind)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) RemoveAttachment(ctx context.Context, mongoID, attachmentID string) error {
+	args := m.Called(ctx, mongoID, attachmentID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) ReorderAttachments(ctx context.Context, mongoID string, attachmentIDs []string) error {
+	args := m.Called(ctx, mongoID, attachmentIDs)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) GetGlobalStats(ctx context.Context, f modelMongo.StatsFilter) (*modelMongo.GlobalStatistics, error) {
+	args := m.Called(ctx, f)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*modelMongo.GlobalStatistics), args.Error(1)
 }
 
-func (m *MockAchievementMongoRepo) GetStudentStats(ctx context.Context, studentID string) (*modelMongo.StudentStatistics, error) {
-	args := m.Called(ctx, studentID)
+func (m *MockAchievementMongoRepo) GetStudentStats(ctx context.Context, studentID string, f modelMongo.StatsFilter) (*modelMongo.StudentStatistics, error) {
+	args := m.Called(ctx, studentID, f)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*modelMongo.StudentStatistics), args.Error(1)
 }
 
+func (m *MockAchievementMongoRepo) GetStudentTotals(ctx context.Context, f modelMongo.StatsFilter) ([]modelMongo.StudentTotal, error) {
+	args := m.Called(ctx, f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.StudentTotal), args.Error(1)
+}
+
 // =========================================================
 // MOCK ACHIEVEMENT REPOSITORY (PostgreSQL)
 // =========================================================
@@ -117,6 +146,19 @@ func (m *MockAchievementPgRepo) GetAllReferences(ctx context.Context, filter map
 	return args.Get(0).([]modelPg.AchievementReference), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockAchievementPgRepo) GetReferencesByCursor(ctx context.Context, filter map[string]interface{}, limit int, cursor *modelPg.Cursor, sort string) ([]modelPg.AchievementReference, bool, error) {
+	args := m.Called(ctx, filter, limit, cursor, sort)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).([]modelPg.AchievementReference), args.Bool(1), args.Error(2)
+}
+
+func (m *MockAchievementPgRepo) EstimateReferences(ctx context.Context, filter map[string]interface{}) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockAchievementPgRepo) GetReferenceByID(ctx context.Context, id uuid.UUID) (modelPg.AchievementReference, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(modelPg.AchievementReference), args.Error(1)
@@ -137,7 +179,197 @@ func (m *MockAchievementPgRepo) SubmitReference(ctx context.Context, id uuid.UUI
 	return args.Error(0)
 }
 
-func (m *MockAchievementMongoRepo) UpdatePoints( ctx context.Context, mongoID string, points int) error {
-    args := m.Called(ctx, mongoID, points)
-    return args.Error(0)
-}
\ No newline at end of file
+func (m *MockAchievementPgRepo) GetReferencesByMongoIDs(ctx context.Context, mongoIDs []string) ([]modelPg.AchievementReference, error) {
+	args := m.Called(ctx, mongoIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelPg.AchievementReference), args.Error(1)
+}
+
+func (m *MockAchievementPgRepo) BumpVersion(ctx context.Context, id uuid.UUID, expected int) error {
+	args := m.Called(ctx, id, expected)
+	return args.Error(0)
+}
+
+func (m *MockAchievementPgRepo) ReleaseVersion(ctx context.Context, id uuid.UUID, claimed int) error {
+	args := m.Called(ctx, id, claimed)
+	return args.Error(0)
+}
+
+func (m *MockAchievementPgRepo) GetTrash(ctx context.Context, studentID uuid.UUID, deletedAfter time.Time) ([]modelPg.AchievementReference, error) {
+	args := m.Called(ctx, studentID, deletedAfter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelPg.AchievementReference), args.Error(1)
+}
+
+func (m *MockAchievementPgRepo) GetDeletedReferenceByID(ctx context.Context, id uuid.UUID) (modelPg.AchievementReference, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(modelPg.AchievementReference), args.Error(1)
+}
+
+func (m *MockAchievementPgRepo) RestoreReference(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAchievementPgRepo) GetPurgeableReferences(ctx context.Context, deletedBefore time.Time) ([]modelPg.AchievementReference, error) {
+	args := m.Called(ctx, deletedBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelPg.AchievementReference), args.Error(1)
+}
+
+func (m *MockAchievementPgRepo) PurgeReference(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAchievementPgRepo) SetPeriod(ctx context.Context, id uuid.UUID, periodID *uuid.UUID) error {
+	args := m.Called(ctx, id, periodID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementPgRepo) GetReferencesWithoutPeriod(ctx context.Context, after *modelPg.Cursor, limit int) ([]modelPg.AchievementReference, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelPg.AchievementReference), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) FindDuplicateCandidates(ctx context.Context, achievement modelMongo.Achievement) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx, achievement)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) SetDuplicateWarnings(ctx context.Context, mongoID string, warnings []modelMongo.DuplicateWarning) error {
+	args := m.Called(ctx, mongoID, warnings)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) FindFlaggedDuplicates(ctx context.Context) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) GetVersions(ctx context.Context, mongoID string) ([]modelMongo.AchievementVersion, error) {
+	args := m.Called(ctx, mongoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.AchievementVersion), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) GetVersion(ctx context.Context, mongoID string, version int) (*modelMongo.AchievementVersion, error) {
+	args := m.Called(ctx, mongoID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*modelMongo.AchievementVersion), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) PinVerifiedVersion(ctx context.Context, mongoID string, points int) error {
+	args := m.Called(ctx, mongoID, points)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) MoveToTrash(ctx context.Context, mongoID string) error {
+	args := m.Called(ctx, mongoID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) RestoreFromTrash(ctx context.Context, mongoID string) error {
+	args := m.Called(ctx, mongoID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) SetPeriod(ctx context.Context, mongoID string, periodID string) error {
+	args := m.Called(ctx, mongoID, periodID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) MatchIDs(ctx context.Context, mongoIDs []string, f modelMongo.AchievementFilter) ([]string, error) {
+	args := m.Called(ctx, mongoIDs, f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) FindPendingScans(ctx context.Context, limit int) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) SetAttachmentScan(ctx context.Context, mongoID, attachmentID string, scan modelMongo.AttachmentScan) error {
+	args := m.Called(ctx, mongoID, attachmentID, scan)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) FindPendingPreviews(ctx context.Context, limit int) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) SetAttachmentPreview(ctx context.Context, mongoID, attachmentID, storageKey string, preview modelMongo.AttachmentPreview) error {
+	args := m.Called(ctx, mongoID, attachmentID, storageKey, preview)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) StorageByAchievement(ctx context.Context, studentID string) ([]modelMongo.AchievementStorage, error) {
+	args := m.Called(ctx, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.AchievementStorage), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) TopStorageUsers(ctx context.Context, limit int) ([]modelMongo.StorageReportItem, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.StorageReportItem), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) FindUnsizedAttachments(ctx context.Context) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) SetAttachmentSize(ctx context.Context, mongoID, attachmentID, storageKey string, size int64) error {
+	args := m.Called(ctx, mongoID, attachmentID, storageKey, size)
+	return args.Error(0)
+}
+
+func (m *MockAchievementMongoRepo) FindStoredAttachments(ctx context.Context) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementMongoRepo) MarkAttachmentMissing(ctx context.Context, mongoID, attachmentID, storageKey string, missingAt *time.Time) error {
+	args := m.Called(ctx, mongoID, attachmentID, storageKey, missingAt)
+	return args.Error(0)
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 
@@ -23,23 +25,23 @@ type MockAdminRepo struct {
 // Pastikan struct ini mengimplementasikan repo.AdminRepository
 var _ repo.AdminRepository = (*MockAdminRepo)(nil)
 
-func (m *MockAdminRepo) CreateUser(user *models.User) error {
-	args := m.Called(user)
+func (m *MockAdminRepo) CreateUser(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
-func (m *MockAdminRepo) UpdateUser(user *models.User) error {
-	args := m.Called(user)
+func (m *MockAdminRepo) UpdateUser(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
-func (m *MockAdminRepo) DeleteUser(id uuid.UUID) error {
-	args := m.Called(id)
+func (m *MockAdminRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockAdminRepo) GetUserByID(id uuid.UUID) (*models.User, error) {
-	args := m.Called(id)
+func (m *MockAdminRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, id)
 	// Safety check agar tidak panic jika return nil
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -47,31 +49,31 @@ func (m *MockAdminRepo) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockAdminRepo) GetAllUsers() ([]models.User, error) {
-	args := m.Called()
+func (m *MockAdminRepo) GetAllUsers(ctx context.Context) ([]models.User, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]models.User), args.Error(1)
 }
 
-func (m *MockAdminRepo) AssignRole(userID, roleID uuid.UUID) error {
-	args := m.Called(userID, roleID)
+func (m *MockAdminRepo) AssignRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	args := m.Called(ctx, userID, roleID)
 	return args.Error(0)
 }
 
-func (m *MockAdminRepo) SetStudentProfile(profile *models.Student) error {
-	args := m.Called(profile)
+func (m *MockAdminRepo) SetStudentProfile(ctx context.Context, profile *models.Student) error {
+	args := m.Called(ctx, profile)
 	return args.Error(0)
 }
 
-func (m *MockAdminRepo) SetLecturerProfile(profile *models.Lecturer) error {
-	args := m.Called(profile)
+func (m *MockAdminRepo) SetLecturerProfile(ctx context.Context, profile *models.Lecturer) error {
+	args := m.Called(ctx, profile)
 	return args.Error(0)
 }
 
-func (m *MockAdminRepo) SetAdvisor(studentID, lecturerID uuid.UUID) error {
-	args := m.Called(studentID, lecturerID)
+func (m *MockAdminRepo) SetAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID) error {
+	args := m.Called(ctx, studentID, lecturerID)
 	return args.Error(0)
 }
 
@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+)
+
+type MockAttachmentBlobRepo struct {
+	mock.Mock
+}
+
+// Compile-time check implementation
+var _ repo.AttachmentBlobRepository = (*MockAttachmentBlobRepo)(nil)
+
+// Acquire calls store with the blob's storage key when a test returns true as
+// the second value, as if the content was new
+func (m *MockAttachmentBlobRepo) Acquire(ctx context.Context, b models.AttachmentBlob, store func(storageKey string) error) (*models.AttachmentBlob, error) {
+	args := m.Called(ctx, b)
+	if err := args.Error(2); err != nil {
+		return nil, err
+	}
+	blob, _ := args.Get(0).(*models.AttachmentBlob)
+	if fn, ok := args.Get(0).(func(models.AttachmentBlob) *models.AttachmentBlob); ok {
+		blob = fn(b)
+	}
+	if args.Bool(1) {
+		if err := store(blob.StorageKey); err != nil {
+			return nil, err
+		}
+	}
+	return blob, nil
+}
+
+// Release calls remove with the storage key a test returns as the second
+// value, as if the last reference was dropped
+func (m *MockAttachmentBlobRepo) Release(ctx context.Context, checksum string, remove func(storageKey string) error) error {
+	args := m.Called(ctx, checksum)
+	if key, _ := args.Get(1).(string); key != "" {
+		remove(key)
+	}
+	return args.Error(0)
+}
+
+func (m *MockAttachmentBlobRepo) List(ctx context.Context) ([]models.AttachmentBlob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AttachmentBlob), args.Error(1)
+}
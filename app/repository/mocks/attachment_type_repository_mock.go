@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+)
+
+type MockAttachmentTypeRepo struct {
+	mock.Mock
+}
+
+// Compile-time check implementation
+var _ repo.AttachmentTypeRepository = (*MockAttachmentTypeRepo)(nil)
+
+func (m *MockAttachmentTypeRepo) GetAll(ctx context.Context) ([]models.AttachmentType, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AttachmentType), args.Error(1)
+}
+
+func (m *MockAttachmentTypeRepo) GetByCode(ctx context.Context, code string) (*models.AttachmentType, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AttachmentType), args.Error(1)
+}
+
+func (m *MockAttachmentTypeRepo) Save(ctx context.Context, t models.AttachmentType) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentTypeRepo) Delete(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
@@ -26,8 +26,8 @@ func (m *MockLecturerRepo) GetLecturerByUserID(ctx context.Context, userID uuid.
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (m *MockLecturerRepo) GetAdvisees(lecturerID uuid.UUID) ([]models.Student, error) {
-	args := m.Called(lecturerID)
+func (m *MockLecturerRepo) GetAdvisees(ctx context.Context, lecturerID uuid.UUID) ([]models.Student, error) {
+	args := m.Called(ctx, lecturerID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -36,18 +36,26 @@ func (m *MockLecturerRepo) GetAdvisees(lecturerID uuid.UUID) ([]models.Student,
 
 // --- Method BARU yang WAJIB DITAMBAHKAN (Penyebab Error) ---
 
-func (m *MockLecturerRepo) GetLecturerByID(id uuid.UUID) (*models.Lecturer, error) {
-	args := m.Called(id)
+func (m *MockLecturerRepo) GetLecturerByID(ctx context.Context, id uuid.UUID) (*models.Lecturer, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Lecturer), args.Error(1)
 }
 
-func (m *MockLecturerRepo) GetAllLecturers() ([]models.Lecturer, error) {
-	args := m.Called()
+func (m *MockLecturerRepo) GetAllLecturers(ctx context.Context) ([]models.Lecturer, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]models.Lecturer), args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *MockLecturerRepo) GetLecturersByUserIDs(ctx context.Context, ids []uuid.UUID) ([]models.LecturerSummary, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LecturerSummary), args.Error(1)
+}
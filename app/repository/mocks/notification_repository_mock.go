@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+)
+
+type MockNotificationRepo struct {
+	mock.Mock
+}
+
+// Compile-time check implementation
+var _ repo.NotificationRepository = (*MockNotificationRepo)(nil)
+
+func (m *MockNotificationRepo) Create(ctx context.Context, n models.Notification) error {
+	args := m.Called(ctx, n)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepo) GetByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool) ([]models.Notification, error) {
+	args := m.Called(ctx, userID, unreadOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepo) MarkRead(ctx context.Context, id, userID uuid.UUID) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
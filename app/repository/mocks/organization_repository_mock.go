@@ -0,0 +1,142 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+)
+
+type MockOrganizationRepo struct {
+	mock.Mock
+}
+
+// Compile-time check implementation
+var _ repo.OrganizationRepository = (*MockOrganizationRepo)(nil)
+
+func (m *MockOrganizationRepo) GetFaculties(ctx context.Context) ([]models.Faculty, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Faculty), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) GetFacultyByID(ctx context.Context, id uuid.UUID) (*models.Faculty, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Faculty), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) CreateFaculty(ctx context.Context, v models.Faculty) (uuid.UUID, error) {
+	args := m.Called(ctx, v)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) UpdateFaculty(ctx context.Context, v models.Faculty) error {
+	args := m.Called(ctx, v)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) DeleteFaculty(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) GetDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error) {
+	args := m.Called(ctx, facultyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Department), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) GetDepartmentByID(ctx context.Context, id uuid.UUID) (*models.Department, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Department), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) CreateDepartment(ctx context.Context, v models.Department) (uuid.UUID, error) {
+	args := m.Called(ctx, v)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) UpdateDepartment(ctx context.Context, v models.Department) error {
+	args := m.Called(ctx, v)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) DeleteDepartment(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) MergeDepartments(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error {
+	args := m.Called(ctx, targetID, sourceIDs)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) GetProgramStudies(ctx context.Context, departmentID *uuid.UUID) ([]models.ProgramStudy, error) {
+	args := m.Called(ctx, departmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ProgramStudy), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) GetProgramStudyByID(ctx context.Context, id uuid.UUID) (*models.ProgramStudy, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProgramStudy), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) CreateProgramStudy(ctx context.Context, v models.ProgramStudy) (uuid.UUID, error) {
+	args := m.Called(ctx, v)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) UpdateProgramStudy(ctx context.Context, v models.ProgramStudy) error {
+	args := m.Called(ctx, v)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) DeleteProgramStudy(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) MergeProgramStudies(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error {
+	args := m.Called(ctx, targetID, sourceIDs)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) GetStudentUnits(ctx context.Context) ([]models.StudentUnits, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StudentUnits), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) GetCoordinatorScope(ctx context.Context, userID uuid.UUID) (*models.CoordinatorScope, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CoordinatorScope), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) SetCoordinatorScope(ctx context.Context, scope models.CoordinatorScope) error {
+	args := m.Called(ctx, scope)
+	return args.Error(0)
+}
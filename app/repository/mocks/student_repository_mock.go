@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -42,9 +43,36 @@ func (m *MockStudentRepo) GetStudentByID(ctx context.Context, id uuid.UUID) (*mo
 	return args.Get(0).(*models.Student), args.Error(1)
 }
 
-func (m *MockStudentRepo) UpdateAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID) error {
-	args := m.Called(ctx, studentID, lecturerID)
-	return args.Error(0)
+func (m *MockStudentRepo) UpdateAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID, assignedBy *uuid.UUID) (*models.ReassignResult, error) {
+	args := m.Called(ctx, studentID, lecturerID, assignedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReassignResult), args.Error(1)
+}
+
+func (m *MockStudentRepo) ReassignAdvisors(ctx context.Context, changes []models.AdvisorChange, assignedBy *uuid.UUID, scope *models.CoordinatorScope) (*models.ReassignResult, error) {
+	args := m.Called(ctx, changes, assignedBy, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReassignResult), args.Error(1)
+}
+
+func (m *MockStudentRepo) TransferAdvisees(ctx context.Context, fromLecturerID, toLecturerID uuid.UUID, assignedBy *uuid.UUID, scope *models.CoordinatorScope) (*models.ReassignResult, error) {
+	args := m.Called(ctx, fromLecturerID, toLecturerID, assignedBy, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReassignResult), args.Error(1)
+}
+
+func (m *MockStudentRepo) GetAdvisorHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorAssignment, error) {
+	args := m.Called(ctx, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AdvisorAssignment), args.Error(1)
 }
 
 func (m *MockStudentRepo) GetStudentsByIDs(ctx context.Context, ids []string) ([]models.StudentWithUser, error) {
@@ -69,8 +97,8 @@ type MockAchievementRepo struct {
 var _ repoMongo.AchievementRepository = (*MockAchievementRepo)(nil)
 
 // 1. Method yang dipakai di StudentService
-func (m *MockAchievementRepo) GetStudentAchievements(studentId uuid.UUID) ([]modelMongo.Achievement, error) {
-	args := m.Called(studentId)
+func (m *MockAchievementRepo) GetStudentAchievements(ctx context.Context, studentId uuid.UUID) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx, studentId)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -110,28 +138,184 @@ func (m *MockAchievementRepo) UpdateOne(ctx context.Context, mongoID string, dat
 }
 
 // INI METHOD PENYEBAB ERROR (Sudah ditambahkan)
-func (m *MockAchievementRepo) AddAttachment(ctx context.Context, mongoID string, attachment modelMongo.Attachment) error {
+func (m *MockAchievementRepo) AddAttachments(ctx context.Context, mongoID string, attachments []modelMongo.Attachment) error {
+	args := m.Called(ctx, mongoID, attachments)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) ReplaceAttachment(ctx context.Context, mongoID string, attachment modelMongo.Attachment) error {
 	args := m.Called(ctx, mongoID, attachment)
 	return args.Error(0)
 }
 
-func (m *MockAchievementRepo) GetGlobalStats(ctx context.Context) (*modelMongo.GlobalStatistics, error) {
-	args := m.Called(ctx)
+func (m *MockAchievementRepo) UpdateAttachmentInfo(ctx context.Context, mongoID, attachmentID, caption, kind string) error {
+	args := m.Called(ctx, mongoID, attachmentID, caption, kind)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) RemoveAttachment(ctx context.Context, mongoID, attachmentID string) error {
+	args := m.Called(ctx, mongoID, attachmentID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) ReorderAttachments(ctx context.Context, mongoID string, attachmentIDs []string) error {
+	args := m.Called(ctx, mongoID, attachmentIDs)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) GetGlobalStats(ctx context.Context, f modelMongo.StatsFilter) (*modelMongo.GlobalStatistics, error) {
+	args := m.Called(ctx, f)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*modelMongo.GlobalStatistics), args.Error(1)
 }
 
-func (m *MockAchievementRepo) GetStudentStats(ctx context.Context, studentID string) (*modelMongo.StudentStatistics, error) {
-	args := m.Called(ctx, studentID)
+func (m *MockAchievementRepo) GetStudentStats(ctx context.Context, studentID string, f modelMongo.StatsFilter) (*modelMongo.StudentStatistics, error) {
+	args := m.Called(ctx, studentID, f)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*modelMongo.StudentStatistics), args.Error(1)
 }
 
-func (m *MockAchievementRepo) UpdatePoints(ctx context.Context,mongoID string,points int) error {
+func (m *MockAchievementRepo) GetStudentTotals(ctx context.Context, f modelMongo.StatsFilter) ([]modelMongo.StudentTotal, error) {
+	args := m.Called(ctx, f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.StudentTotal), args.Error(1)
+}
+
+func (m *MockAchievementRepo) FindDuplicateCandidates(ctx context.Context, achievement modelMongo.Achievement) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx, achievement)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementRepo) SetDuplicateWarnings(ctx context.Context, mongoID string, warnings []modelMongo.DuplicateWarning) error {
+	args := m.Called(ctx, mongoID, warnings)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) FindFlaggedDuplicates(ctx context.Context) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementRepo) GetVersions(ctx context.Context, mongoID string) ([]modelMongo.AchievementVersion, error) {
+	args := m.Called(ctx, mongoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.AchievementVersion), args.Error(1)
+}
+
+func (m *MockAchievementRepo) GetVersion(ctx context.Context, mongoID string, version int) (*modelMongo.AchievementVersion, error) {
+	args := m.Called(ctx, mongoID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*modelMongo.AchievementVersion), args.Error(1)
+}
+
+func (m *MockAchievementRepo) PinVerifiedVersion(ctx context.Context, mongoID string, points int) error {
 	args := m.Called(ctx, mongoID, points)
 	return args.Error(0)
-}
\ No newline at end of file
+}
+
+func (m *MockAchievementRepo) MoveToTrash(ctx context.Context, mongoID string) error {
+	args := m.Called(ctx, mongoID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) RestoreFromTrash(ctx context.Context, mongoID string) error {
+	args := m.Called(ctx, mongoID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) SetPeriod(ctx context.Context, mongoID string, periodID string) error {
+	args := m.Called(ctx, mongoID, periodID)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) MatchIDs(ctx context.Context, mongoIDs []string, f modelMongo.AchievementFilter) ([]string, error) {
+	args := m.Called(ctx, mongoIDs, f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAchievementRepo) FindPendingScans(ctx context.Context, limit int) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementRepo) SetAttachmentScan(ctx context.Context, mongoID, attachmentID string, scan modelMongo.AttachmentScan) error {
+	args := m.Called(ctx, mongoID, attachmentID, scan)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) FindPendingPreviews(ctx context.Context, limit int) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementRepo) SetAttachmentPreview(ctx context.Context, mongoID, attachmentID, storageKey string, preview modelMongo.AttachmentPreview) error {
+	args := m.Called(ctx, mongoID, attachmentID, storageKey, preview)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) StorageByAchievement(ctx context.Context, studentID string) ([]modelMongo.AchievementStorage, error) {
+	args := m.Called(ctx, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.AchievementStorage), args.Error(1)
+}
+
+func (m *MockAchievementRepo) TopStorageUsers(ctx context.Context, limit int) ([]modelMongo.StorageReportItem, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.StorageReportItem), args.Error(1)
+}
+
+func (m *MockAchievementRepo) FindUnsizedAttachments(ctx context.Context) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementRepo) SetAttachmentSize(ctx context.Context, mongoID, attachmentID, storageKey string, size int64) error {
+	args := m.Called(ctx, mongoID, attachmentID, storageKey, size)
+	return args.Error(0)
+}
+
+func (m *MockAchievementRepo) FindStoredAttachments(ctx context.Context) ([]modelMongo.Achievement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]modelMongo.Achievement), args.Error(1)
+}
+
+func (m *MockAchievementRepo) MarkAttachmentMissing(ctx context.Context, mongoID, attachmentID, storageKey string, missingAt *time.Time) error {
+	args := m.Called(ctx, mongoID, attachmentID, storageKey, missingAt)
+	return args.Error(0)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+)
+
+type MockTenantRepo struct {
+	mock.Mock
+}
+
+// Compile-time check implementation
+var _ repo.TenantRepository = (*MockTenantRepo)(nil)
+
+func (m *MockTenantRepo) GetAll(ctx context.Context) ([]models.Tenant, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepo) Create(ctx context.Context, t models.Tenant) (uuid.UUID, error) {
+	args := m.Called(ctx, t)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
@@ -0,0 +1,59 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+)
+
+type MockUploadSessionRepo struct {
+	mock.Mock
+}
+
+// Compile-time check implementation
+var _ repo.UploadSessionRepository = (*MockUploadSessionRepo)(nil)
+
+func (m *MockUploadSessionRepo) Create(ctx context.Context, u *models.UploadSession) error {
+	args := m.Called(ctx, u)
+	return args.Error(0)
+}
+
+func (m *MockUploadSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.UploadSession, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UploadSession), args.Error(1)
+}
+
+func (m *MockUploadSessionRepo) CountByUser(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	args := m.Called(ctx, userID, now)
+	return args.Int(0), args.Error(1)
+}
+
+// Advance calls write unless a test returns an error
+func (m *MockUploadSessionRepo) Advance(ctx context.Context, id uuid.UUID, from, to int64, expiresAt time.Time, write func() error) error {
+	args := m.Called(ctx, id, from, to, expiresAt)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return write()
+}
+
+func (m *MockUploadSessionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUploadSessionRepo) DeleteExpired(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
@@ -2,42 +2,292 @@ package repository
 
 import (
     "context"
+    "errors"
+    "regexp"
 	"time"
     models "StudenAchievementReportingSystem/app/models/mongodb"
+    "StudenAchievementReportingSystem/utils"
     "github.com/google/uuid"
     "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AchievementRepository interface {
-    GetStudentAchievements(studentId uuid.UUID) ([]models.Achievement, error)
+    GetStudentAchievements(ctx context.Context, studentId uuid.UUID) ([]models.Achievement, error)
     InsertOne(ctx context.Context, achievement models.Achievement) (string, error)
     FindAllDetails(ctx context.Context, mongoIDs []string) ([]models.Achievement, error)
 	FindOne(ctx context.Context, mongoID string) (*models.Achievement, error)
 	DeleteAchievement(ctx context.Context, mongoID string) error
 	UpdateOne(ctx context.Context, mongoID string, data models.Achievement) error
-	AddAttachment(ctx context.Context, mongoID string, attachment models.Attachment) error
-    GetGlobalStats(ctx context.Context) (*models.GlobalStatistics, error) 
-    GetStudentStats(ctx context.Context, studentID string) (*models.StudentStatistics, error) 
-    UpdatePoints(ctx context.Context, mongoID string, points int) error
+	AddAttachments(ctx context.Context, mongoID string, attachments []models.Attachment) error
+    ReplaceAttachment(ctx context.Context, mongoID string, attachment models.Attachment) error
+    UpdateAttachmentInfo(ctx context.Context, mongoID, attachmentID, caption, kind string) error
+    RemoveAttachment(ctx context.Context, mongoID, attachmentID string) error
+    ReorderAttachments(ctx context.Context, mongoID string, attachmentIDs []string) error
+    GetGlobalStats(ctx context.Context, f models.StatsFilter) (*models.GlobalStatistics, error) 
+    GetStudentStats(ctx context.Context, studentID string, f models.StatsFilter) (*models.StudentStatistics, error) 
+    GetStudentTotals(ctx context.Context, f models.StatsFilter) ([]models.StudentTotal, error)
+    FindDuplicateCandidates(ctx context.Context, achievement models.Achievement) ([]models.Achievement, error)
+    SetDuplicateWarnings(ctx context.Context, mongoID string, warnings []models.DuplicateWarning) error
+    FindFlaggedDuplicates(ctx context.Context) ([]models.Achievement, error)
+    GetVersions(ctx context.Context, mongoID string) ([]models.AchievementVersion, error)
+    GetVersion(ctx context.Context, mongoID string, version int) (*models.AchievementVersion, error)
+    PinVerifiedVersion(ctx context.Context, mongoID string, points int) error
+    MoveToTrash(ctx context.Context, mongoID string) error
+    RestoreFromTrash(ctx context.Context, mongoID string) error
+    SetPeriod(ctx context.Context, mongoID string, periodID string) error
+    FindPendingScans(ctx context.Context, limit int) ([]models.Achievement, error)
+    SetAttachmentScan(ctx context.Context, mongoID, attachmentID string, scan models.AttachmentScan) error
+    FindPendingPreviews(ctx context.Context, limit int) ([]models.Achievement, error)
+    SetAttachmentPreview(ctx context.Context, mongoID, attachmentID, storageKey string, preview models.AttachmentPreview) error
+    StorageByAchievement(ctx context.Context, studentID string) ([]models.AchievementStorage, error)
+    TopStorageUsers(ctx context.Context, limit int) ([]models.StorageReportItem, error)
+    FindUnsizedAttachments(ctx context.Context) ([]models.Achievement, error)
+    SetAttachmentSize(ctx context.Context, mongoID, attachmentID, storageKey string, size int64) error
+    FindStoredAttachments(ctx context.Context) ([]models.Achievement, error)
+    MarkAttachmentMissing(ctx context.Context, mongoID, attachmentID, storageKey string, missingAt *time.Time) error
+    MatchIDs(ctx context.Context, mongoIDs []string, f models.AchievementFilter) ([]string, error)
+}
+
+// ErrAttachmentNotFound is returned for attachment IDs the document does not have
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// ErrVersionConflict is returned when other changes kept taking the version
+// number an update was about to use
+var ErrVersionConflict = errors.New("achievement was modified concurrently")
+
+// versionRetries bounds how often a versioned update starts over
+const versionRetries = 5
+
+// duplicateCandidateLimit bounds how many candidates one submission is compared with
+const duplicateCandidateLimit = 50
+
+// duplicateProjection holds the fields utils.DuplicateReasons compares and the
+// warning needs
+var duplicateProjection = bson.M{
+    "studentId":                   1,
+    "title":                       1,
+    "details.certificationNumber": 1,
+    "details.eventDate":           1,
+    "details.organizer":           1,
+    "details.competitionName":     1,
+    "details.rank":                1,
+    "attachments.checksum":        1,
+}
+
+// notDeleted excludes documents that are in the trash
+var notDeleted = bson.M{"deletedAt": bson.M{"$exists": false}}
+
+// scoped limits a filter to the tenant of ctx; callers working across tenants
+// get it unchanged
+func scoped(ctx context.Context, filter bson.M) bson.M {
+    return scopedBy(ctx, "tenantId", filter)
+}
+
+// scopedBy is scoped for collections keeping the tenant under another field
+func scopedBy(ctx context.Context, field string, filter bson.M) bson.M {
+    if id, ok := utils.TenantFromContext(ctx); ok {
+        filter[field] = id.String()
+    }
+    return filter
+}
+
+// statsMatch is the $match stage shared by the report aggregations
+func statsMatch(ctx context.Context, f models.StatsFilter, extra bson.M) bson.M {
+    match := scoped(ctx, bson.M{"deletedAt": bson.M{"$exists": false}})
+    if f.PeriodID != "" {
+        match["periodId"] = f.PeriodID
+    }
+    if f.StudentIDs != nil {
+        match["studentId"] = bson.M{"$in": f.StudentIDs}
+    }
+    for k, v := range extra {
+        match[k] = v
+    }
+    return bson.M{"$match": match}
 }
 
 type achievementRepository struct {
     collection *mongo.Collection
+    versions   *mongo.Collection
+}
+
+// EnsureAchievementIndexes creates the indexes the achievement queries rely on.
+// It is safe to call on every start.
+func EnsureAchievementIndexes(ctx context.Context, mongodb *mongo.Database) error {
+    _, err := mongodb.Collection("achievements").Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}, {Key: "tags", Value: "text"}},
+            Options: options.Index().
+                SetName("achievement_text").
+                SetWeights(bson.D{{Key: "title", Value: 10}, {Key: "tags", Value: 5}, {Key: "description", Value: 1}}).
+                SetDefaultLanguage("none"),
+        },
+        {Keys: bson.D{{Key: "achievementType", Value: 1}, {Key: "createdAt", Value: -1}}},
+        {Keys: bson.D{{Key: "studentId", Value: 1}}},
+        {Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "createdAt", Value: -1}}},
+        {Keys: bson.D{{Key: "attachments.scan.status", Value: 1}}, Options: options.Index().SetSparse(true)},
+        {Keys: bson.D{{Key: "attachments.checksum", Value: 1}}, Options: options.Index().SetSparse(true)},
+        {Keys: bson.D{{Key: "attachments.preview.status", Value: 1}}, Options: options.Index().SetSparse(true)},
+        // duplicate candidate lookups
+        {Keys: bson.D{{Key: "duplicateKeys.title", Value: 1}}, Options: options.Index().SetSparse(true)},
+        {Keys: bson.D{{Key: "duplicateKeys.certificationNumber", Value: 1}}, Options: options.Index().SetSparse(true)},
+        {Keys: bson.D{{Key: "duplicateKeys.competitionName", Value: 1}, {Key: "details.rank", Value: 1}}, Options: options.Index().SetSparse(true)},
+    })
+    if err != nil {
+        return err
+    }
+
+    _, err = mongodb.Collection("achievement_versions").Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "achievementId", Value: 1}, {Key: "version", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    return err
+}
+
+// BackfillTenant moves documents stored before tenants existed into the given
+// tenant. It is safe to call on every start.
+func BackfillTenant(ctx context.Context, mongodb *mongo.Database, tenantID string) error {
+    _, err := mongodb.Collection("achievements").UpdateMany(ctx,
+        bson.M{"tenantId": bson.M{"$exists": false}},
+        bson.M{"$set": bson.M{"tenantId": tenantID}},
+    )
+    if err != nil {
+        return err
+    }
+    _, err = mongodb.Collection("achievement_versions").UpdateMany(ctx,
+        bson.M{"snapshot.tenantId": bson.M{"$exists": false}},
+        bson.M{"$set": bson.M{"snapshot.tenantId": tenantID}},
+    )
+    return err
+}
+
+// BackfillStorageKeys turns the /uploads/ URLs stored by older versions into
+// storage keys and gives attachments without an ID one taken from their key,
+// in documents and in version snapshots. It is safe to call on every start.
+func BackfillStorageKeys(ctx context.Context, mongodb *mongo.Database) error {
+    for _, target := range []struct{ collection, field string }{
+        {"achievements", "attachments"},
+        {"achievement_versions", "snapshot.attachments"},
+    } {
+        collection := mongodb.Collection(target.collection)
+        if err := backfillStorageKeys(ctx, collection, target.field); err != nil {
+            return err
+        }
+        if err := backfillAttachmentIDs(ctx, collection, target.field); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func backfillStorageKeys(ctx context.Context, collection *mongo.Collection, field string) error {
+    _, err := collection.UpdateMany(ctx,
+        bson.M{field + ".fileUrl": bson.M{"$exists": true}},
+        bson.A{
+            bson.M{"$set": bson.M{field: bson.M{"$map": bson.M{
+                "input": "$" + field,
+                "as":    "a",
+                "in": bson.M{"$mergeObjects": bson.A{"$$a", bson.M{
+                    "storageKey": bson.M{"$ifNull": bson.A{"$$a.storageKey", bson.M{"$replaceOne": bson.M{
+                        "input": "$$a.fileUrl", "find": "/uploads/", "replacement": "",
+                    }}}},
+                }}},
+            }}}},
+            bson.M{"$unset": field + ".fileUrl"},
+        },
+    )
+    return err
+}
+
+// BackfillPreviews queues previews for attachments of the given content
+// types uploaded before previews existed. It is safe to call on every start.
+func BackfillPreviews(ctx context.Context, mongodb *mongo.Database, contentTypes []string) error {
+    _, err := mongodb.Collection("achievements").UpdateMany(ctx,
+        bson.M{"attachments": bson.M{"$elemMatch": bson.M{
+            "preview":  bson.M{"$exists": false},
+            "fileType": bson.M{"$in": contentTypes},
+        }}},
+        bson.M{"$set": bson.M{"attachments.$[a].preview": models.AttachmentPreview{Status: models.PreviewPending}}},
+        options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{
+            "a.preview":  bson.M{"$exists": false},
+            "a.fileType": bson.M{"$in": contentTypes},
+        }}}),
+    )
+    return err
+}
+
+// BackfillDuplicateKeys fills in the normalized duplicate detection fields of
+// documents stored before they existed. It is safe to call on every start.
+func BackfillDuplicateKeys(ctx context.Context, mongodb *mongo.Database) error {
+    collection := mongodb.Collection("achievements")
+    cursor, err := collection.Find(ctx,
+        bson.M{"duplicateKeys": bson.M{"$exists": false}},
+        options.Find().SetProjection(bson.M{"title": 1, "details": 1}),
+    )
+    if err != nil {
+        return err
+    }
+    defer cursor.Close(ctx)
+
+    for cursor.Next(ctx) {
+        var a models.Achievement
+        if err := cursor.Decode(&a); err != nil {
+            return err
+        }
+        _, err := collection.UpdateOne(ctx,
+            bson.M{"_id": a.ID, "duplicateKeys": bson.M{"$exists": false}},
+            bson.M{"$set": bson.M{"duplicateKeys": duplicateKeys(a)}},
+        )
+        if err != nil {
+            return err
+        }
+    }
+    return cursor.Err()
+}
+
+// duplicateKeys normalizes the fields FindDuplicateCandidates matches on the
+// way utils.DuplicateReasons compares them
+func duplicateKeys(a models.Achievement) *models.DuplicateKeys {
+    return &models.DuplicateKeys{
+        Title:               utils.NormalizeText(a.Title),
+        CertificationNumber: utils.NormalizeText(a.Details.CertificationNumber),
+        CompetitionName:     utils.NormalizeText(a.Details.CompetitionName),
+    }
+}
+
+// backfillAttachmentIDs uses the key without its extension, which is the
+// random name the file got on upload
+func backfillAttachmentIDs(ctx context.Context, collection *mongo.Collection, field string) error {
+    _, err := collection.UpdateMany(ctx,
+        bson.M{field: bson.M{"$elemMatch": bson.M{"id": bson.M{"$exists": false}}}},
+        bson.A{
+            bson.M{"$set": bson.M{field: bson.M{"$map": bson.M{
+                "input": "$" + field,
+                "as":    "a",
+                "in": bson.M{"$mergeObjects": bson.A{"$$a", bson.M{
+                    "id": bson.M{"$ifNull": bson.A{"$$a.id", bson.M{"$arrayElemAt": bson.A{
+                        bson.M{"$split": bson.A{"$$a.storageKey", "."}}, 0,
+                    }}}},
+                }}},
+            }}}},
+        },
+    )
+    return err
 }
 
 func NewAchievementRepository(mongodb *mongo.Database) AchievementRepository {
     return &achievementRepository{
         collection: mongodb.Collection("achievements"),
+        versions:   mongodb.Collection("achievement_versions"),
     }
 }
 
-func (r *achievementRepository) GetStudentAchievements(studentId uuid.UUID) ([]models.Achievement, error) {
-    ctx := context.Background()
-
-    filter := bson.M{"studentId": studentId.String()}
-    cursor, err := r.collection.Find(ctx, filter)
+func (r *achievementRepository) GetStudentAchievements(ctx context.Context, studentId uuid.UUID) ([]models.Achievement, error) {
+    filter := bson.M{"studentId": studentId.String(), "deletedAt": bson.M{"$exists": false}}
+    cursor, err := r.collection.Find(ctx, scoped(ctx, filter))
     if err != nil {
         return nil, err
     }
@@ -47,7 +297,13 @@ func (r *achievementRepository) GetStudentAchievements(studentId uuid.UUID) ([]m
     return results, err
 }
 
+// InsertOne stores a new document in the tenant of ctx
 func (r *achievementRepository) InsertOne(ctx context.Context, achievement models.Achievement) (string, error) {
+	achievement.Version = 1
+	achievement.DuplicateKeys = duplicateKeys(achievement)
+	if id, ok := utils.TenantFromContext(ctx); ok {
+		achievement.TenantID = id.String()
+	}
 	collection := r.collection
 	result, err := collection.InsertOne(ctx, achievement)
 	if err != nil {
@@ -66,7 +322,7 @@ func (r *achievementRepository) FindAllDetails(ctx context.Context, mongoIDs []s
 	collection := r.collection
 	filter := bson.M{"_id": bson.M{"$in": objectIDs}}
 	
-	cursor, err := collection.Find(ctx, filter)
+	cursor, err := collection.Find(ctx, scoped(ctx, filter))
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +344,7 @@ func (r *achievementRepository) FindOne(ctx context.Context, mongoID string) (*m
     var result models.Achievement
     filter := bson.M{"_id": oid}
     
-    err = r.collection.FindOne(ctx, filter).Decode(&result)
+    err = r.collection.FindOne(ctx, scoped(ctx, filter)).Decode(&result)
     if err != nil {
         return nil, err
     }
@@ -103,55 +359,373 @@ func (r *achievementRepository) DeleteAchievement(ctx context.Context, mongoID s
     }
 
     filter := bson.M{"_id": oid}
-    _, err = r.collection.DeleteOne(ctx, filter)
+    if _, err = r.collection.DeleteOne(ctx, scoped(ctx, filter)); err != nil {
+        return err
+    }
+
+    _, err = r.versions.DeleteMany(ctx, scopedBy(ctx, "snapshot.tenantId", bson.M{"achievementId": mongoID}))
     return err
 }
 
 func (r *achievementRepository) UpdateOne(ctx context.Context, mongoID string, data models.Achievement) error {
+    return r.versioned(ctx, mongoID, bson.M{}, func(current *models.Achievement) interface{} {
+        return bson.M{
+            "$set": bson.M{
+                "title":           data.Title,
+                "description":     data.Description,
+                "achievementType": data.AchievementType,
+                "details":         data.Details,
+                "customFields":    data.CustomFields,
+                "tags":            data.Tags,
+                "periodId":        data.PeriodID,
+                "duplicateKeys":   duplicateKeys(data),
+                "version":         current.CurrentVersion() + 1,
+                "updatedAt":       time.Now(),
+            },
+        }
+    })
+}
+
+// AddAttachments appends files in the given order
+func (r *achievementRepository) AddAttachments(ctx context.Context, mongoID string, attachments []models.Attachment) error {
+    return r.versioned(ctx, mongoID, bson.M{}, func(current *models.Achievement) interface{} {
+        return bson.M{
+            "$push": bson.M{"attachments": bson.M{"$each": attachments}},
+            "$set":  bson.M{"version": current.CurrentVersion() + 1, "updatedAt": time.Now()},
+        }
+    })
+}
+
+// versioned archives the current document and applies the update built from
+// it, guarded by the version archive saw. When another change took the next
+// version number first it starts over, so two changes never share a number.
+// filter is added to the document filter; ErrAttachmentNotFound means it did
+// not match.
+func (r *achievementRepository) versioned(ctx context.Context, mongoID string, filter bson.M, update func(current *models.Achievement) interface{}, opts ...*options.UpdateOptions) error {
+    for attempt := 0; ; attempt++ {
+        current, err := r.archive(ctx, mongoID)
+        if err != nil {
+            return err
+        }
+
+        guarded := bson.M{"_id": current.ID, "version": current.Version}
+        if current.Version < 1 {
+            // documents from before versioning have no number yet
+            guarded["version"] = bson.M{"$in": bson.A{nil, 0}}
+        }
+        for k, v := range filter {
+            guarded[k] = v
+        }
+        res, err := r.collection.UpdateOne(ctx, scoped(ctx, guarded), update(current), opts...)
+        if err != nil {
+            return err
+        }
+        if res.MatchedCount > 0 {
+            return nil
+        }
+
+        latest, err := r.FindOne(ctx, mongoID)
+        if err != nil {
+            return err
+        }
+        if latest.CurrentVersion() == current.CurrentVersion() {
+            return ErrAttachmentNotFound
+        }
+        if attempt == versionRetries {
+            return ErrVersionConflict
+        }
+    }
+}
+
+// updateAttachments applies an attachment change as a new version, built by
+// update from the version number. filter is added to the document filter;
+// ErrAttachmentNotFound means it did not match.
+func (r *achievementRepository) updateAttachments(ctx context.Context, mongoID string, filter bson.M, update func(version int) interface{}, opts ...*options.UpdateOptions) error {
+    return r.versioned(ctx, mongoID, filter, func(current *models.Achievement) interface{} {
+        return update(current.CurrentVersion() + 1)
+    }, opts...)
+}
+
+// ReplaceAttachment swaps the attachment with the same ID, keeping its place
+func (r *achievementRepository) ReplaceAttachment(ctx context.Context, mongoID string, attachment models.Attachment) error {
+    return r.updateAttachments(ctx, mongoID,
+        bson.M{"attachments.id": attachment.ID},
+        func(version int) interface{} {
+            return bson.M{"$set": bson.M{"attachments.$[a]": attachment, "version": version, "updatedAt": time.Now()}}
+        },
+        options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"a.id": attachment.ID}}}),
+    )
+}
+
+func (r *achievementRepository) UpdateAttachmentInfo(ctx context.Context, mongoID, attachmentID, caption, kind string) error {
+    return r.updateAttachments(ctx, mongoID,
+        bson.M{"attachments.id": attachmentID},
+        func(version int) interface{} {
+            return bson.M{"$set": bson.M{"attachments.$[a].caption": caption, "attachments.$[a].kind": kind, "version": version, "updatedAt": time.Now()}}
+        },
+        options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"a.id": attachmentID}}}),
+    )
+}
+
+func (r *achievementRepository) RemoveAttachment(ctx context.Context, mongoID, attachmentID string) error {
+    return r.updateAttachments(ctx, mongoID,
+        bson.M{"attachments.id": attachmentID},
+        func(version int) interface{} {
+            return bson.M{"$pull": bson.M{"attachments": bson.M{"id": attachmentID}}, "$set": bson.M{"version": version, "updatedAt": time.Now()}}
+        },
+    )
+}
+
+// ReorderAttachments puts the attachments in the order of attachmentIDs, which
+// must list each of them once. ErrAttachmentNotFound means the list does not
+// match the stored attachments.
+func (r *achievementRepository) ReorderAttachments(ctx context.Context, mongoID string, attachmentIDs []string) error {
+    return r.updateAttachments(ctx, mongoID,
+        bson.M{"attachments": bson.M{"$size": len(attachmentIDs)}, "attachments.id": bson.M{"$all": attachmentIDs}},
+        func(version int) interface{} {
+            return bson.A{bson.M{"$set": bson.M{
+                "attachments": bson.M{"$map": bson.M{
+                    "input": attachmentIDs,
+                    "as":    "id",
+                    "in": bson.M{"$arrayElemAt": bson.A{bson.M{"$filter": bson.M{
+                        "input": "$attachments",
+                        "cond":  bson.M{"$eq": bson.A{"$$this.id", "$$id"}},
+                    }}, 0}},
+                }},
+                "version":   version,
+                "updatedAt": time.Now(),
+            }}}
+        },
+    )
+}
+
+// FindPendingScans returns documents with quarantined attachments in every
+// tenant, including those in the trash. Files missing from storage wait until
+// they are back.
+func (r *achievementRepository) FindPendingScans(ctx context.Context, limit int) ([]models.Achievement, error) {
+    filter := bson.M{"attachments": bson.M{"$elemMatch": bson.M{
+        "scan.status": models.ScanPending,
+        "missingAt":   bson.M{"$exists": false},
+    }}}
+    cursor, err := r.collection.Find(ctx, filter, options.Find().SetLimit(int64(limit)))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []models.Achievement
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+// SetAttachmentScan records a scan verdict. It is not a change by the student,
+// so no version is archived.
+func (r *achievementRepository) SetAttachmentScan(ctx context.Context, mongoID, attachmentID string, scan models.AttachmentScan) error {
     oid, err := primitive.ObjectIDFromHex(mongoID)
     if err != nil {
-         return err 
+        return err
     }
 
-    update := bson.M{
-        "$set": bson.M{
-            "title":           data.Title,
-            "description":     data.Description,
-            "achievementType": data.AchievementType,
-            "details":         data.Details,
-            "tags":            data.Tags,
-            "points":          data.Points,
-            "updatedAt":       time.Now(),
-        },
+    _, err = r.collection.UpdateOne(ctx,
+        bson.M{"_id": oid},
+        bson.M{"$set": bson.M{"attachments.$[a].scan": scan}},
+        options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"a.id": attachmentID}}}),
+    )
+    return err
+}
+
+// FindPendingPreviews returns documents with attachments that passed the
+// malware scan, or predate it, and still need a preview
+func (r *achievementRepository) FindPendingPreviews(ctx context.Context, limit int) ([]models.Achievement, error) {
+    filter := bson.M{"attachments": bson.M{"$elemMatch": bson.M{
+        "preview.status": models.PreviewPending,
+        "scan.status":    bson.M{"$nin": bson.A{models.ScanPending, models.ScanInfected}},
+        "missingAt":      bson.M{"$exists": false},
+    }}}
+    cursor, err := r.collection.Find(ctx, filter, options.Find().SetLimit(int64(limit)))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []models.Achievement
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+// SetAttachmentPreview records the preview of an attachment's file. It does
+// nothing when the file was replaced in the meantime, and archives no version.
+func (r *achievementRepository) SetAttachmentPreview(ctx context.Context, mongoID, attachmentID, storageKey string, preview models.AttachmentPreview) error {
+    oid, err := primitive.ObjectIDFromHex(mongoID)
+    if err != nil {
+        return err
     }
 
-    _, err = r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+    _, err = r.collection.UpdateOne(ctx,
+        bson.M{"_id": oid},
+        bson.M{"$set": bson.M{"attachments.$[a].preview": preview}},
+        options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"a.id": attachmentID, "a.storageKey": storageKey}}}),
+    )
     return err
 }
 
-func (r *achievementRepository) AddAttachment(ctx context.Context, mongoID string, attachment models.Attachment) error {
+// storedAttachments keeps the attachments whose file is in storage; the files
+// of infected ones were deleted by the scan, and missing ones were lost
+var storedAttachments = bson.M{"$filter": bson.M{
+    "input": bson.M{"$ifNull": bson.A{"$attachments", bson.A{}}},
+    "as":    "a",
+    "cond":  bson.M{"$and": bson.A{
+        bson.M{"$ne": bson.A{"$$a.scan.status", models.ScanInfected}},
+        bson.M{"$not": bson.A{"$$a.missingAt"}},
+    }},
+}}
+
+// StorageByAchievement returns the attachment usage of each achievement of a
+// student that has files, largest first. Achievements in the trash count
+// until they are purged, as their files are kept until then.
+func (r *achievementRepository) StorageByAchievement(ctx context.Context, studentID string) ([]models.AchievementStorage, error) {
+    pipeline := bson.A{
+        bson.M{"$match": scoped(ctx, bson.M{"studentId": studentID})},
+        bson.M{"$project": bson.M{"title": 1, "attachments": storedAttachments}},
+        bson.M{"$project": bson.M{
+            "title": 1,
+            "bytes": bson.M{"$sum": "$attachments.fileSize"},
+            "files": bson.M{"$size": "$attachments"},
+        }},
+        bson.M{"$match": bson.M{"files": bson.M{"$gt": 0}}},
+        bson.M{"$sort": bson.D{{Key: "bytes", Value: -1}, {Key: "_id", Value: 1}}},
+    }
+    cursor, err := r.collection.Aggregate(ctx, pipeline)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []models.AchievementStorage
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+// TopStorageUsers returns the students whose attachments take the most space
+func (r *achievementRepository) TopStorageUsers(ctx context.Context, limit int) ([]models.StorageReportItem, error) {
+    pipeline := bson.A{
+        bson.M{"$match": scoped(ctx, bson.M{"attachments.0": bson.M{"$exists": true}})},
+        bson.M{"$project": bson.M{"studentId": 1, "attachments": storedAttachments}},
+        bson.M{"$match": bson.M{"attachments.0": bson.M{"$exists": true}}},
+        bson.M{"$group": bson.M{
+            "_id":          "$studentId",
+            "bytes":        bson.M{"$sum": bson.M{"$sum": "$attachments.fileSize"}},
+            "files":        bson.M{"$sum": bson.M{"$size": "$attachments"}},
+            "achievements": bson.M{"$sum": 1},
+        }},
+        bson.M{"$sort": bson.D{{Key: "bytes", Value: -1}, {Key: "files", Value: -1}, {Key: "_id", Value: 1}}},
+        bson.M{"$limit": limit},
+    }
+    cursor, err := r.collection.Aggregate(ctx, pipeline)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []models.StorageReportItem
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+// FindUnsizedAttachments returns the documents with stored files uploaded
+// before sizes were recorded, in every tenant
+func (r *achievementRepository) FindUnsizedAttachments(ctx context.Context) ([]models.Achievement, error) {
+    filter := bson.M{"attachments": bson.M{"$elemMatch": bson.M{
+        "fileSize":    bson.M{"$exists": false},
+        "storageKey":  bson.M{"$nin": bson.A{nil, ""}},
+        "scan.status": bson.M{"$ne": models.ScanInfected},
+        "missingAt":   bson.M{"$exists": false},
+    }}}
+    cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"attachments": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []models.Achievement
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+// SetAttachmentSize records the size of an attachment's file. It does nothing
+// when the file was replaced in the meantime, and archives no version.
+func (r *achievementRepository) SetAttachmentSize(ctx context.Context, mongoID, attachmentID, storageKey string, size int64) error {
     oid, err := primitive.ObjectIDFromHex(mongoID)
     if err != nil {
-         return err 
+        return err
+    }
+
+    _, err = r.collection.UpdateOne(ctx,
+        bson.M{"_id": oid},
+        bson.M{"$set": bson.M{"attachments.$[a].fileSize": size}},
+        options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"a.id": attachmentID, "a.storageKey": storageKey}}}),
+    )
+    return err
+}
+
+// FindStoredAttachments returns every document with attachments, in every
+// tenant and including the trash, with only its attachments loaded
+func (r *achievementRepository) FindStoredAttachments(ctx context.Context) ([]models.Achievement, error) {
+    filter := bson.M{"attachments.0": bson.M{"$exists": true}}
+    cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"attachments": 1}))
+    if err != nil {
+        return nil, err
     }
+    defer cursor.Close(ctx)
 
-    update := bson.M{
-        "$push": bson.M{"attachments": attachment},
-        "$set":  bson.M{"updatedAt": time.Now()},
+    var results []models.Achievement
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
     }
+    return results, nil
+}
 
-    _, err = r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+// MarkAttachmentMissing records when an attachment's file was found missing
+// from storage, or clears the mark when missingAt is nil. It does nothing when
+// the file was replaced in the meantime, and archives no version.
+func (r *achievementRepository) MarkAttachmentMissing(ctx context.Context, mongoID, attachmentID, storageKey string, missingAt *time.Time) error {
+    oid, err := primitive.ObjectIDFromHex(mongoID)
+    if err != nil {
+        return err
+    }
+
+    update := bson.M{"$unset": bson.M{"attachments.$[a].missingAt": ""}}
+    if missingAt != nil {
+        update = bson.M{"$set": bson.M{"attachments.$[a].missingAt": *missingAt}}
+    }
+    _, err = r.collection.UpdateOne(ctx,
+        bson.M{"_id": oid},
+        update,
+        options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"a.id": attachmentID, "a.storageKey": storageKey}}}),
+    )
     return err
 }
 
-func (r *achievementRepository) GetGlobalStats(ctx context.Context) (*models.GlobalStatistics, error) {
+func (r *achievementRepository) GetGlobalStats(ctx context.Context, f models.StatsFilter) (*models.GlobalStatistics, error) {
     stats := &models.GlobalStatistics{
-        TypeDistribution:  make(map[string]int),
-        LevelDistribution: make(map[string]int),
-        TrendByYear:       make(map[string]int),
+        TypeDistribution:   make(map[string]int),
+        LevelDistribution:  make(map[string]int),
+        TrendByYear:        make(map[string]int),
+        PeriodDistribution: make(map[string]int),
     }
 
     pipelineType := bson.A{
+        statsMatch(ctx, f, nil),
         bson.M{"$group": bson.M{"_id": "$achievementType", "count": bson.M{"$sum": 1}}},
     }
     cursor, _ := r.collection.Aggregate(ctx, pipelineType)
@@ -162,7 +736,7 @@ func (r *achievementRepository) GetGlobalStats(ctx context.Context) (*models.Glo
     }
 
     pipelineLevel := bson.A{
-        bson.M{"$match": bson.M{"details.competitionLevel": bson.M{"$exists": true}}},
+        statsMatch(ctx, f, bson.M{"details.competitionLevel": bson.M{"$exists": true}}),
         bson.M{"$group": bson.M{"_id": "$details.competitionLevel", "count": bson.M{"$sum": 1}}},
     }
     cursor, _ = r.collection.Aggregate(ctx, pipelineLevel)
@@ -173,6 +747,7 @@ func (r *achievementRepository) GetGlobalStats(ctx context.Context) (*models.Glo
     }
 
     pipelineTop := bson.A{
+        statsMatch(ctx, f, nil),
         bson.M{"$group": bson.M{"_id": "$studentId", "totalPoints": bson.M{"$sum": "$points"}}},
         bson.M{"$sort": bson.M{"totalPoints": -1}},
         bson.M{"$limit": 5},
@@ -188,13 +763,27 @@ func (r *achievementRepository) GetGlobalStats(ctx context.Context) (*models.Glo
         })
     }
 
+    // keyed by period id here, the service replaces the ids with period names
+    pipelinePeriod := bson.A{
+        statsMatch(ctx, f, nil),
+        bson.M{"$group": bson.M{"_id": "$periodId", "count": bson.M{"$sum": 1}}},
+    }
+    cursor, _ = r.collection.Aggregate(ctx, pipelinePeriod)
+    var periodResults []struct { Id string `bson:"_id"`; Count int `bson:"count"` }
+    cursor.All(ctx, &periodResults)
+    for _, res := range periodResults {
+        if res.Id != "" {
+            stats.PeriodDistribution[res.Id] = res.Count
+        }
+    }
+
     return stats, nil
 }
 
-func (r *achievementRepository) GetStudentStats(ctx context.Context, studentID string) (*models.StudentStatistics, error) {
+func (r *achievementRepository) GetStudentStats(ctx context.Context, studentID string, f models.StatsFilter) (*models.StudentStatistics, error) {
     stats := &models.StudentStatistics{ByType: make(map[string]int)}
     pipeline := bson.A{
-        bson.M{"$match": bson.M{"studentId": studentID}},
+        statsMatch(ctx, f, bson.M{"studentId": studentID}),
         bson.M{"$group": bson.M{
             "_id": "$achievementType",
             "count": bson.M{"$sum": 1},
@@ -225,22 +814,292 @@ func (r *achievementRepository) GetStudentStats(ctx context.Context, studentID s
     return stats, nil
 }
 
-func (r *achievementRepository) UpdatePoints(ctx context.Context,mongoID string, points int,) error {
+// GetStudentTotals counts achievements and sums points per student
+func (r *achievementRepository) GetStudentTotals(ctx context.Context, f models.StatsFilter) ([]models.StudentTotal, error) {
+    pipeline := bson.A{
+        statsMatch(ctx, f, nil),
+        bson.M{"$group": bson.M{
+            "_id":          "$studentId",
+            "achievements": bson.M{"$sum": 1},
+            "totalPoints":  bson.M{"$sum": "$points"},
+        }},
+    }
+
+    cursor, err := r.collection.Aggregate(ctx, pipeline)
+    if err != nil {
+        return nil, err
+    }
+
+    totals := make([]models.StudentTotal, 0)
+    if err = cursor.All(ctx, &totals); err != nil {
+        return nil, err
+    }
+    return totals, nil
+}
+
+// FindDuplicateCandidates returns other achievements sharing a certification number,
+// an attachment checksum, a title or a competition name and rank with the given one.
+// An event date alone is too common to look up; it only counts together with the
+// title or the competition. The caller decides which candidates are real duplicates.
+func (r *achievementRepository) FindDuplicateCandidates(ctx context.Context, a models.Achievement) ([]models.Achievement, error) {
+    var or bson.A
+
+    keys := duplicateKeys(a)
+    if keys.CertificationNumber != "" {
+        or = append(or, bson.M{"duplicateKeys.certificationNumber": keys.CertificationNumber})
+    }
+
+    var checksums []string
+    for _, att := range a.Attachments {
+        if att.Checksum != "" {
+            checksums = append(checksums, att.Checksum)
+        }
+    }
+    if len(checksums) > 0 {
+        or = append(or, bson.M{"attachments.checksum": bson.M{"$in": checksums}})
+    }
+
+    // titles match word by word, ignoring case and punctuation
+    if keys.Title != "" {
+        or = append(or, bson.M{"duplicateKeys.title": keys.Title})
+    }
+
+    if keys.CompetitionName != "" && a.Details.Rank > 0 {
+        or = append(or, bson.M{"duplicateKeys.competitionName": keys.CompetitionName, "details.rank": a.Details.Rank})
+    }
+
+    if len(or) == 0 {
+        return []models.Achievement{}, nil
+    }
+
+    filter := bson.M{"_id": bson.M{"$ne": a.ID}, "deletedAt": bson.M{"$exists": false}, "$or": or}
+    opts := options.Find().SetProjection(duplicateProjection).SetLimit(duplicateCandidateLimit)
+    cursor, err := r.collection.Find(ctx, scoped(ctx, filter), opts)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []models.Achievement
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+func (r *achievementRepository) SetDuplicateWarnings(ctx context.Context, mongoID string, warnings []models.DuplicateWarning) error {
     oid, err := primitive.ObjectIDFromHex(mongoID)
     if err != nil {
         return err
     }
 
-    _, err = r.collection.UpdateOne(
-        ctx,
-        bson.M{"_id": oid},
-        bson.M{
+    update := bson.M{"$set": bson.M{"duplicateWarnings": warnings}}
+    if len(warnings) == 0 {
+        update = bson.M{"$unset": bson.M{"duplicateWarnings": ""}}
+    }
+
+    _, err = r.collection.UpdateOne(ctx, scoped(ctx, bson.M{"_id": oid}), update)
+    return err
+}
+
+func (r *achievementRepository) FindFlaggedDuplicates(ctx context.Context) ([]models.Achievement, error) {
+    filter := bson.M{"duplicateWarnings.0": bson.M{"$exists": true}, "deletedAt": bson.M{"$exists": false}}
+    cursor, err := r.collection.Find(ctx, scoped(ctx, filter))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []models.Achievement
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+// archive stores a snapshot of the current document in achievement_versions
+// and returns it. Snapshots are keyed by (achievementId, version), so archiving
+// the same version twice keeps the first copy.
+func (r *achievementRepository) archive(ctx context.Context, mongoID string) (*models.Achievement, error) {
+    current, err := r.FindOne(ctx, mongoID)
+    if err != nil {
+        return nil, err
+    }
+
+    version := current.CurrentVersion()
+    snapshot := models.AchievementVersion{
+        AchievementID: mongoID,
+        Version:       version,
+        Hash:          current.ContentHash(),
+        HashFormat:    models.CurrentHashFormat,
+        Snapshot:      *current,
+        ArchivedAt:    time.Now(),
+    }
+    snapshot.Snapshot.Version = version
+
+    _, err = r.versions.UpdateOne(ctx,
+        bson.M{"achievementId": mongoID, "version": version},
+        bson.M{"$setOnInsert": snapshot},
+        options.Update().SetUpsert(true),
+    )
+    if err != nil {
+        return nil, err
+    }
+    return current, nil
+}
+
+func (r *achievementRepository) GetVersions(ctx context.Context, mongoID string) ([]models.AchievementVersion, error) {
+    cursor, err := r.versions.Find(ctx,
+        scopedBy(ctx, "snapshot.tenantId", bson.M{"achievementId": mongoID}),
+        options.Find().SetSort(bson.M{"version": 1}),
+    )
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []models.AchievementVersion
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+func (r *achievementRepository) GetVersion(ctx context.Context, mongoID string, version int) (*models.AchievementVersion, error) {
+    var result models.AchievementVersion
+    err := r.versions.FindOne(ctx, scopedBy(ctx, "snapshot.tenantId", bson.M{"achievementId": mongoID, "version": version})).Decode(&result)
+    if err != nil {
+        return nil, err
+    }
+    return &result, nil
+}
+
+// PinVerifiedVersion records the current version as the one a lecturer verified,
+// together with its content hash and the awarded points, and archives it so it
+// can always be retrieved. Points and pin are written in one update, so a
+// document never carries points without the version they were awarded for.
+func (r *achievementRepository) PinVerifiedVersion(ctx context.Context, mongoID string, points int) error {
+    return r.versioned(ctx, mongoID, bson.M{}, func(current *models.Achievement) interface{} {
+        return bson.M{
             "$set": bson.M{
-                "points":     points,
-                "updatedAt": time.Now(),
+                "points":             points,
+                "updatedAt":          time.Now(),
+                "version":            current.CurrentVersion(),
+                "verifiedVersion":    current.CurrentVersion(),
+                "verifiedHash":       current.ContentHash(),
+                "verifiedHashFormat": models.CurrentHashFormat,
             },
-        },
-    )
+        }
+    })
+}
+
+// MoveToTrash marks a document as deleted without removing it, so it can be restored
+func (r *achievementRepository) MoveToTrash(ctx context.Context, mongoID string) error {
+    oid, err := primitive.ObjectIDFromHex(mongoID)
+    if err != nil {
+        return err
+    }
+
+    _, err = r.collection.UpdateOne(ctx, scoped(ctx, bson.M{"_id": oid}), bson.M{"$set": bson.M{"deletedAt": time.Now()}})
+    return err
+}
+
+func (r *achievementRepository) RestoreFromTrash(ctx context.Context, mongoID string) error {
+    oid, err := primitive.ObjectIDFromHex(mongoID)
+    if err != nil {
+        return err
+    }
+
+    res, err := r.collection.UpdateOne(ctx, scoped(ctx, bson.M{"_id": oid}), bson.M{"$unset": bson.M{"deletedAt": ""}})
+    if err != nil {
+        return err
+    }
+    if res.MatchedCount == 0 {
+        return mongo.ErrNoDocuments
+    }
+    return nil
+}
+
+// SetPeriod records the academic period of a document without creating a new
+// version, the period is derived from the event date and not edited by students
+func (r *achievementRepository) SetPeriod(ctx context.Context, mongoID string, periodID string) error {
+    oid, err := primitive.ObjectIDFromHex(mongoID)
+    if err != nil {
+        return err
+    }
 
+    update := bson.M{"$set": bson.M{"periodId": periodID}}
+    if periodID == "" {
+        update = bson.M{"$unset": bson.M{"periodId": ""}}
+    }
+    _, err = r.collection.UpdateOne(ctx, scoped(ctx, bson.M{"_id": oid}), update)
     return err
-}
\ No newline at end of file
+}
+
+// MatchIDs returns those of the given achievements that match f, in no
+// particular order. Callers hand it one batch of references at a time and keep
+// their own order.
+func (r *achievementRepository) MatchIDs(ctx context.Context, mongoIDs []string, f models.AchievementFilter) ([]string, error) {
+    objectIDs := make([]primitive.ObjectID, 0, len(mongoIDs))
+    for _, id := range mongoIDs {
+        if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+            objectIDs = append(objectIDs, oid)
+        }
+    }
+
+    filter := scoped(ctx, bson.M{
+        "_id":       bson.M{"$in": objectIDs},
+        "deletedAt": bson.M{"$exists": false},
+    })
+    if f.Search != "" {
+        filter["$text"] = bson.M{"$search": f.Search}
+    }
+    if len(f.Types) > 0 {
+        filter["achievementType"] = bson.M{"$in": f.Types}
+    }
+    if f.Level != "" {
+        filter["details.competitionLevel"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.Level) + "$", "$options": "i"}
+    }
+    if f.DateFrom != nil || f.DateTo != nil {
+        dateRange := bson.M{}
+        if f.DateFrom != nil {
+            dateRange["$gte"] = *f.DateFrom
+        }
+        if f.DateTo != nil {
+            dateRange["$lt"] = *f.DateTo
+        }
+        filter["$or"] = bson.A{
+            bson.M{"details.eventDate": dateRange},
+            bson.M{"details.startDate": dateRange},
+        }
+    }
+    if f.MinPoints != nil || f.MaxPoints != nil {
+        points := bson.M{}
+        if f.MinPoints != nil {
+            points["$gte"] = *f.MinPoints
+        }
+        if f.MaxPoints != nil {
+            points["$lte"] = *f.MaxPoints
+        }
+        filter["points"] = points
+    }
+
+    opts := options.Find().SetProjection(bson.M{"_id": 1})
+    cursor, err := r.collection.Find(ctx, filter, opts)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []struct {
+        ID primitive.ObjectID `bson:"_id"`
+    }
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    ids := make([]string, 0, len(results))
+    for _, res := range results {
+        ids = append(ids, res.ID.Hex())
+    }
+    return ids, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+    models "StudenAchievementReportingSystem/app/models/mongodb"
+    "StudenAchievementReportingSystem/utils"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrTypeCodeExists = errors.New("achievement type code already exists")
+
+// AchievementTypeRepository stores type definitions. Types without a tenant are
+// shared by every tenant; a tenant sees them together with its own types and
+// may replace a shared type by defining one with the same code.
+type AchievementTypeRepository interface {
+    FindAll(ctx context.Context, activeOnly bool) ([]models.AchievementTypeDefinition, error)
+    FindByCode(ctx context.Context, code string) (*models.AchievementTypeDefinition, error)
+    Insert(ctx context.Context, def models.AchievementTypeDefinition) (string, error)
+    Update(ctx context.Context, code string, def models.AchievementTypeDefinition) error
+    SetActive(ctx context.Context, code string, active bool) error
+}
+
+type achievementTypeRepository struct {
+    collection *mongo.Collection
+}
+
+func NewAchievementTypeRepository(mongodb *mongo.Database) AchievementTypeRepository {
+    return &achievementTypeRepository{
+        collection: mongodb.Collection("achievement_types"),
+    }
+}
+
+// visible matches the types the tenant of ctx may use
+func visible(ctx context.Context, filter bson.M) bson.M {
+    if id, ok := utils.TenantFromContext(ctx); ok {
+        filter["$or"] = bson.A{
+            bson.M{"tenantId": id.String()},
+            bson.M{"tenantId": bson.M{"$exists": false}},
+        }
+    }
+    return filter
+}
+
+// owned matches the types the tenant of ctx may change: its own, or the shared
+// ones when ctx carries no tenant
+func owned(ctx context.Context, code string) bson.M {
+    if id, ok := utils.TenantFromContext(ctx); ok {
+        return bson.M{"code": code, "tenantId": id.String()}
+    }
+    return bson.M{"code": code, "tenantId": bson.M{"$exists": false}}
+}
+
+func (r *achievementTypeRepository) FindAll(ctx context.Context, activeOnly bool) ([]models.AchievementTypeDefinition, error) {
+    filter := visible(ctx, bson.M{})
+    if activeOnly {
+        filter["isActive"] = true
+    }
+
+    cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"code": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var all []models.AchievementTypeDefinition
+    if err := cursor.All(ctx, &all); err != nil {
+        return nil, err
+    }
+
+    overridden := make(map[string]bool)
+    for _, def := range all {
+        if def.TenantID != "" {
+            overridden[def.Code] = true
+        }
+    }
+    var results []models.AchievementTypeDefinition
+    for _, def := range all {
+        if def.TenantID == "" && overridden[def.Code] {
+            continue
+        }
+        results = append(results, def)
+    }
+    return results, nil
+}
+
+func (r *achievementTypeRepository) FindByCode(ctx context.Context, code string) (*models.AchievementTypeDefinition, error) {
+    var result models.AchievementTypeDefinition
+    // a tenant's own definition sorts before the shared one it overrides
+    err := r.collection.FindOne(ctx,
+        visible(ctx, bson.M{"code": code}),
+        options.FindOne().SetSort(bson.M{"tenantId": -1}),
+    ).Decode(&result)
+    if err != nil {
+        return nil, err
+    }
+    return &result, nil
+}
+
+func (r *achievementTypeRepository) Insert(ctx context.Context, def models.AchievementTypeDefinition) (string, error) {
+    def.CreatedAt = time.Now()
+    def.UpdatedAt = time.Now()
+    def.TenantID = ""
+    if id, ok := utils.TenantFromContext(ctx); ok {
+        def.TenantID = id.String()
+    }
+
+    res, err := r.collection.UpdateOne(ctx,
+        owned(ctx, def.Code),
+        bson.M{"$setOnInsert": def},
+        options.Update().SetUpsert(true),
+    )
+    if err != nil {
+        return "", err
+    }
+    if res.UpsertedID == nil {
+        return "", ErrTypeCodeExists
+    }
+    return res.UpsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (r *achievementTypeRepository) Update(ctx context.Context, code string, def models.AchievementTypeDefinition) error {
+    update := bson.M{
+        "$set": bson.M{
+            "name":                    def.Name,
+            "description":             def.Description,
+            "customFieldSchema":       def.CustomFieldSchema,
+            "uiHints":                 def.UIHints,
+            "pointRule":               def.PointRule,
+            "requiredAttachmentKinds": def.RequiredAttachmentKinds,
+            "isActive":                def.IsActive,
+            "updatedAt":               time.Now(),
+        },
+    }
+
+    res, err := r.collection.UpdateOne(ctx, owned(ctx, code), update)
+    if err != nil {
+        return err
+    }
+    if res.MatchedCount == 0 {
+        return mongo.ErrNoDocuments
+    }
+    return nil
+}
+
+func (r *achievementTypeRepository) SetActive(ctx context.Context, code string, active bool) error {
+    res, err := r.collection.UpdateOne(ctx,
+        owned(ctx, code),
+        bson.M{"$set": bson.M{"isActive": active, "updatedAt": time.Now()}},
+    )
+    if err != nil {
+        return err
+    }
+    if res.MatchedCount == 0 {
+        return mongo.ErrNoDocuments
+    }
+    return nil
+}
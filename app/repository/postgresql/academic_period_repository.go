@@ -0,0 +1,185 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "time"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    "github.com/google/uuid"
+)
+
+// ErrPeriodInUse is returned when deleting a period that achievements are assigned to
+var ErrPeriodInUse = errors.New("academic period has achievements assigned")
+
+// AcademicPeriodRepository manages the academic calendar. The calendar is
+// university wide, so periods are shared by all tenants.
+type AcademicPeriodRepository interface {
+    GetAll(ctx context.Context) ([]models.AcademicPeriod, error)
+    GetByID(ctx context.Context, id uuid.UUID) (*models.AcademicPeriod, error)
+    GetActive(ctx context.Context) (*models.AcademicPeriod, error)
+    FindByDate(ctx context.Context, date time.Time) (*models.AcademicPeriod, error)
+    HasOverlap(ctx context.Context, start, end time.Time, excludeID uuid.UUID) (bool, error)
+    Create(ctx context.Context, p models.AcademicPeriod) (uuid.UUID, error)
+    Update(ctx context.Context, p models.AcademicPeriod) error
+    Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type academicPeriodRepository struct {
+    db *sql.DB
+}
+
+func NewAcademicPeriodRepository(db *sql.DB) AcademicPeriodRepository {
+    return &academicPeriodRepository{db: db}
+}
+
+const periodColumns = `id, name, academic_year, semester, start_date, end_date, submission_deadline, is_active, created_at, updated_at`
+
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+func scanPeriod(row rowScanner) (*models.AcademicPeriod, error) {
+    var p models.AcademicPeriod
+    err := row.Scan(
+        &p.ID,
+        &p.Name,
+        &p.AcademicYear,
+        &p.Semester,
+        &p.StartDate,
+        &p.EndDate,
+        &p.SubmissionDeadline,
+        &p.IsActive,
+        &p.CreatedAt,
+        &p.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
+
+func (r *academicPeriodRepository) GetAll(ctx context.Context) ([]models.AcademicPeriod, error) {
+    rows, err := r.db.QueryContext(ctx, `SELECT `+periodColumns+` FROM academic_periods ORDER BY start_date DESC`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.AcademicPeriod, 0)
+    for rows.Next() {
+        p, err := scanPeriod(rows)
+        if err != nil {
+            return nil, err
+        }
+        list = append(list, *p)
+    }
+    return list, rows.Err()
+}
+
+func (r *academicPeriodRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AcademicPeriod, error) {
+    return scanPeriod(r.db.QueryRowContext(ctx, `SELECT `+periodColumns+` FROM academic_periods WHERE id = $1`, id))
+}
+
+func (r *academicPeriodRepository) GetActive(ctx context.Context) (*models.AcademicPeriod, error) {
+    return scanPeriod(r.db.QueryRowContext(ctx, `SELECT `+periodColumns+` FROM academic_periods WHERE is_active`))
+}
+
+// FindByDate returns the period whose start and end date contain the given day
+func (r *academicPeriodRepository) FindByDate(ctx context.Context, date time.Time) (*models.AcademicPeriod, error) {
+    query := `SELECT ` + periodColumns + `
+        FROM academic_periods
+        WHERE $1::date BETWEEN start_date AND end_date
+        ORDER BY start_date DESC
+        LIMIT 1`
+    return scanPeriod(r.db.QueryRowContext(ctx, query, date.Format("2006-01-02")))
+}
+
+func (r *academicPeriodRepository) HasOverlap(ctx context.Context, start, end time.Time, excludeID uuid.UUID) (bool, error) {
+    var exists bool
+    err := r.db.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM academic_periods
+            WHERE id != $3 AND start_date <= $2::date AND end_date >= $1::date
+        )`, start.Format("2006-01-02"), end.Format("2006-01-02"), excludeID).Scan(&exists)
+    return exists, err
+}
+
+func (r *academicPeriodRepository) Create(ctx context.Context, p models.AcademicPeriod) (uuid.UUID, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return uuid.Nil, err
+    }
+    defer tx.Rollback()
+
+    if p.IsActive {
+        if _, err := tx.ExecContext(ctx, `UPDATE academic_periods SET is_active = FALSE, updated_at = NOW() WHERE is_active`); err != nil {
+            return uuid.Nil, err
+        }
+    }
+
+    var id uuid.UUID
+    err = tx.QueryRowContext(ctx, `
+        INSERT INTO academic_periods (name, academic_year, semester, start_date, end_date, submission_deadline, is_active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+        RETURNING id`,
+        p.Name, p.AcademicYear, p.Semester, p.StartDate, p.EndDate, p.SubmissionDeadline, p.IsActive,
+    ).Scan(&id)
+    if err != nil {
+        return uuid.Nil, err
+    }
+
+    return id, tx.Commit()
+}
+
+// Update saves a period; marking it active clears the flag on every other period
+func (r *academicPeriodRepository) Update(ctx context.Context, p models.AcademicPeriod) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if p.IsActive {
+        if _, err := tx.ExecContext(ctx, `UPDATE academic_periods SET is_active = FALSE, updated_at = NOW() WHERE is_active AND id != $1`, p.ID); err != nil {
+            return err
+        }
+    }
+
+    res, err := tx.ExecContext(ctx, `
+        UPDATE academic_periods
+        SET name = $1, academic_year = $2, semester = $3, start_date = $4, end_date = $5,
+            submission_deadline = $6, is_active = $7, updated_at = NOW()
+        WHERE id = $8`,
+        p.Name, p.AcademicYear, p.Semester, p.StartDate, p.EndDate, p.SubmissionDeadline, p.IsActive, p.ID,
+    )
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return sql.ErrNoRows
+    }
+
+    return tx.Commit()
+}
+
+func (r *academicPeriodRepository) Delete(ctx context.Context, id uuid.UUID) error {
+    var inUse bool
+    err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM achievement_references WHERE period_id = $1)`, id).Scan(&inUse)
+    if err != nil {
+        return err
+    }
+    if inUse {
+        return ErrPeriodInUse
+    }
+
+    res, err := r.db.ExecContext(ctx, `DELETE FROM academic_periods WHERE id = $1`, id)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return sql.ErrNoRows
+    }
+    return nil
+}
@@ -3,6 +3,8 @@ package repository
 import (
     "context"
     "database/sql"
+    "encoding/json"
+    "errors"
     "fmt"
     "time"
     models "StudenAchievementReportingSystem/app/models/postgresql"
@@ -10,14 +12,29 @@ import (
     "github.com/lib/pq"
 )
 
+// ErrVersionConflict is returned when the reference was changed since the caller read it
+var ErrVersionConflict = errors.New("achievement was modified concurrently")
+
 type AchievementRepoPostgres interface {
     Create(ctx context.Context, ref models.AchievementReference) (uuid.UUID, error)
     GetStudentByUserID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error)
     GetAllReferences(ctx context.Context, filter map[string]interface{}, limit, offset int, sort string) ([]models.AchievementReference, int64, error)
+    GetReferencesByCursor(ctx context.Context, filter map[string]interface{}, limit int, cursor *models.Cursor, sort string) ([]models.AchievementReference, bool, error)
+    EstimateReferences(ctx context.Context, filter map[string]interface{}) (int64, error)
     GetReferenceByID(ctx context.Context, id uuid.UUID) (models.AchievementReference, error)
     DeleteReference(ctx context.Context, id uuid.UUID) error
     UpdateStatus(ctx context.Context, id uuid.UUID, status string, verifiedBy *uuid.UUID, note string) error
     SubmitReference(ctx context.Context, id uuid.UUID) error
+    GetReferencesByMongoIDs(ctx context.Context, mongoIDs []string) ([]models.AchievementReference, error)
+    BumpVersion(ctx context.Context, id uuid.UUID, expected int) error
+    ReleaseVersion(ctx context.Context, id uuid.UUID, claimed int) error
+    GetTrash(ctx context.Context, studentID uuid.UUID, deletedAfter time.Time) ([]models.AchievementReference, error)
+    GetDeletedReferenceByID(ctx context.Context, id uuid.UUID) (models.AchievementReference, error)
+    RestoreReference(ctx context.Context, id uuid.UUID) error
+    GetPurgeableReferences(ctx context.Context, deletedBefore time.Time) ([]models.AchievementReference, error)
+    PurgeReference(ctx context.Context, id uuid.UUID) error
+    SetPeriod(ctx context.Context, id uuid.UUID, periodID *uuid.UUID) error
+    GetReferencesWithoutPeriod(ctx context.Context, after *models.Cursor, limit int) ([]models.AchievementReference, error)
 }
 
 type achievementRepoPostgres struct {
@@ -32,18 +49,19 @@ func (r *achievementRepoPostgres) GetStudentByUserID(ctx context.Context, userID
     query := `
                 SELECT id 
                 FROM students
-                WHERE user_id = $1
+                WHERE user_id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
     `
     var studentID uuid.UUID
-    err := r.db.QueryRowContext(ctx, query, userID).Scan(&studentID)
+    err := r.db.QueryRowContext(ctx, query, userID, tenantArg(ctx)).Scan(&studentID)
     return studentID, err
 }
 
+// Create stores a reference in the tenant of its student
 func (r *achievementRepoPostgres) Create(ctx context.Context, ref models.AchievementReference) (uuid.UUID, error) {
     query := `
         INSERT INTO achievement_references (
-            student_id, mongo_achievement_id, status, created_at, updated_at
-        ) VALUES ($1, $2, $3, NOW(), NOW())
+            student_id, mongo_achievement_id, status, period_id, tenant_id, created_at, updated_at
+        ) VALUES ($1, $2, $3, $4, (SELECT tenant_id FROM students WHERE id = $1), NOW(), NOW())
         RETURNING id
     `
     var newID uuid.UUID
@@ -51,15 +69,17 @@ func (r *achievementRepoPostgres) Create(ctx context.Context, ref models.Achieve
         ref.StudentID, 
         ref.MongoAchievementID, 
         ref.Status, 
+        ref.PeriodID,
     ).Scan(&newID)
 
     return newID, err
 }
 
-func (r *achievementRepoPostgres) GetAllReferences(ctx context.Context, filter map[string]interface{}, limit, offset int, sort string) ([]models.AchievementReference, int64, error) {
-    whereClause := " WHERE status != 'deleted'"
-    var args []interface{}
-    argCount := 1
+// referenceWhere builds the WHERE clause shared by the reference listings
+func referenceWhere(ctx context.Context, filter map[string]interface{}) (string, []interface{}) {
+    whereClause := " WHERE status != 'deleted' AND ($1::uuid IS NULL OR tenant_id = $1)"
+    args := []interface{}{tenantArg(ctx)}
+    argCount := 2
 
     if val, ok := filter["student_id"]; ok {
         whereClause += fmt.Sprintf(" AND student_id = $%d", argCount)
@@ -84,6 +104,40 @@ func (r *achievementRepoPostgres) GetAllReferences(ctx context.Context, filter m
         argCount++
     }
 
+    if val, ok := filter["program_study"]; ok {
+        // matches a program study id, code or name; the text column covers unmapped students
+        whereClause += fmt.Sprintf(` AND student_id IN (
+            SELECT s.id FROM students s LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+            WHERE ps.id::text = $%[1]d OR ps.code = $%[1]d OR COALESCE(ps.name, s.program_study) = $%[1]d)`, argCount)
+        args = append(args, val)
+        argCount++
+    }
+
+    if val, ok := filter["program_study_ids"]; ok {
+        whereClause += fmt.Sprintf(" AND student_id IN (SELECT id FROM students WHERE program_study_id = ANY($%d))", argCount)
+        args = append(args, pq.Array(val))
+        argCount++
+    }
+
+    if val, ok := filter["academic_year"]; ok {
+        whereClause += fmt.Sprintf(" AND student_id IN (SELECT id FROM students WHERE academic_year = $%d)", argCount)
+        args = append(args, val)
+        argCount++
+    }
+
+    if val, ok := filter["period_id"]; ok {
+        whereClause += fmt.Sprintf(" AND period_id = $%d", argCount)
+        args = append(args, val)
+        argCount++
+    }
+
+    return whereClause, args
+}
+
+func (r *achievementRepoPostgres) GetAllReferences(ctx context.Context, filter map[string]interface{}, limit, offset int, sort string) ([]models.AchievementReference, int64, error) {
+    whereClause, args := referenceWhere(ctx, filter)
+    argCount := len(args) + 1
+
     var totalCount int64
     countQuery := `
                     SELECT COUNT(*) 
@@ -96,14 +150,15 @@ func (r *achievementRepoPostgres) GetAllReferences(ctx context.Context, filter m
     }
 
     query := `
-        SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, created_at 
+        SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, verified_by, period_id, created_at 
         FROM achievement_references 
     ` + whereClause
 
+    // id breaks ties so rows with the same timestamp keep their place between pages
     if sort == "oldest" {
-        query += ` ORDER BY created_at ASC`
+        query += ` ORDER BY created_at ASC, id ASC`
     } else {
-        query += ` ORDER BY created_at DESC`
+        query += ` ORDER BY created_at DESC, id DESC`
     }
 
     if limit > 0 {
@@ -127,6 +182,8 @@ func (r *achievementRepoPostgres) GetAllReferences(ctx context.Context, filter m
             &ref.Status, 
             &ref.SubmittedAt, 
             &ref.VerifiedAt,
+            &ref.VerifiedBy,
+            &ref.PeriodID,
             &ref.CreatedAt,
         )
         if err != nil {
@@ -142,15 +199,15 @@ func (r *achievementRepoPostgres) GetReferenceByID(ctx context.Context, id uuid.
     query := `
         SELECT 
             id, student_id, mongo_achievement_id, status, rejection_note, 
-            created_at, submitted_at, verified_at, verified_by 
+            created_at, submitted_at, verified_at, verified_by, version, period_id 
         FROM achievement_references 
-        WHERE status != 'deleted' AND id = $1
+        WHERE status != 'deleted' AND id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
     `
     
     var ref models.AchievementReference
     var rejectionNote sql.NullString
 
-    err := r.db.QueryRowContext(ctx, query, id).Scan(
+    err := r.db.QueryRowContext(ctx, query, id, tenantArg(ctx)).Scan(
         &ref.ID, 
         &ref.StudentID, 
         &ref.MongoAchievementID, 
@@ -160,6 +217,8 @@ func (r *achievementRepoPostgres) GetReferenceByID(ctx context.Context, id uuid.
         &ref.SubmittedAt, 
         &ref.VerifiedAt,  
         &ref.VerifiedBy,  
+        &ref.Version,
+        &ref.PeriodID,
     )
 
     if rejectionNote.Valid {
@@ -173,10 +232,10 @@ func (r *achievementRepoPostgres) GetReferenceByID(ctx context.Context, id uuid.
 func (r *achievementRepoPostgres) DeleteReference(ctx context.Context, id uuid.UUID) error {
     query := `
         UPDATE achievement_references 
-        SET status = 'deleted', updated_at = NOW() 
-        WHERE id = $1
+        SET status = 'deleted', deleted_at = NOW(), updated_at = NOW() 
+        WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
     `
-    _, err := r.db.ExecContext(ctx, query, id)
+    _, err := r.db.ExecContext(ctx, query, id, tenantArg(ctx))
     return err
 }
 
@@ -184,9 +243,9 @@ func (r *achievementRepoPostgres) UpdateStatus(ctx context.Context, id uuid.UUID
     query := `
         UPDATE achievement_references 
         SET status = $1, verified_by = $2, verified_at = $3, rejection_note = $4, updated_at = NOW()
-        WHERE id = $5
+        WHERE id = $5 AND ($6::uuid IS NULL OR tenant_id = $6)
     `
-    _, err := r.db.ExecContext(ctx, query, status, verifiedBy, time.Now(), note, id)
+    _, err := r.db.ExecContext(ctx, query, status, verifiedBy, time.Now(), note, id, tenantArg(ctx))
     return err
 }
 
@@ -196,8 +255,320 @@ func (r *achievementRepoPostgres) SubmitReference(ctx context.Context, id uuid.U
         SET status = 'submitted', 
             submitted_at = NOW(), 
             updated_at = NOW()
-        WHERE id = $1
+        WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
+    `
+    _, err := r.db.ExecContext(ctx, query, id, tenantArg(ctx))
+    return err
+}
+
+func (r *achievementRepoPostgres) GetReferencesByMongoIDs(ctx context.Context, mongoIDs []string) ([]models.AchievementReference, error) {
+    if len(mongoIDs) == 0 {
+        return []models.AchievementReference{}, nil
+    }
+
+    query := `
+        SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, created_at
+        FROM achievement_references
+        WHERE status != 'deleted' AND mongo_achievement_id = ANY($1) AND ($2::uuid IS NULL OR tenant_id = $2)
+    `
+    rows, err := r.db.QueryContext(ctx, query, pq.Array(mongoIDs), tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var results []models.AchievementReference
+    for rows.Next() {
+        var ref models.AchievementReference
+        if err := rows.Scan(
+            &ref.ID,
+            &ref.StudentID,
+            &ref.MongoAchievementID,
+            &ref.Status,
+            &ref.SubmittedAt,
+            &ref.VerifiedAt,
+            &ref.CreatedAt,
+        ); err != nil {
+            return nil, err
+        }
+        results = append(results, ref)
+    }
+    return results, nil
+}
+
+// BumpVersion increments the version counter only if it still equals expected.
+// Callers claim the version before writing, so of two requests holding the same
+// ETag only the first one gets through.
+func (r *achievementRepoPostgres) BumpVersion(ctx context.Context, id uuid.UUID, expected int) error {
+    query := `
+        UPDATE achievement_references 
+        SET version = version + 1, updated_at = NOW()
+        WHERE id = $1 AND version = $2 AND status != 'deleted' AND ($3::uuid IS NULL OR tenant_id = $3)
     `
-    _, err := r.db.ExecContext(ctx, query, id)
+    res, err := r.db.ExecContext(ctx, query, id, expected, tenantArg(ctx))
+    if err != nil {
+        return err
+    }
+
+    affected, err := res.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if affected == 0 {
+        return ErrVersionConflict
+    }
+    return nil
+}
+
+// ReleaseVersion undoes a BumpVersion whose write failed. It only steps back
+// while the counter still holds the claimed version, so a bump made by another
+// request in the meantime is kept.
+func (r *achievementRepoPostgres) ReleaseVersion(ctx context.Context, id uuid.UUID, claimed int) error {
+    query := `
+        UPDATE achievement_references 
+        SET version = version - 1, updated_at = NOW()
+        WHERE id = $1 AND version = $2 AND ($3::uuid IS NULL OR tenant_id = $3)
+    `
+    _, err := r.db.ExecContext(ctx, query, id, claimed, tenantArg(ctx))
     return err
-}
\ No newline at end of file
+}
+
+func (r *achievementRepoPostgres) scanDeleted(rows *sql.Rows) ([]models.AchievementReference, error) {
+    defer rows.Close()
+
+    var results []models.AchievementReference
+    for rows.Next() {
+        var ref models.AchievementReference
+        if err := rows.Scan(
+            &ref.ID,
+            &ref.StudentID,
+            &ref.MongoAchievementID,
+            &ref.Status,
+            &ref.CreatedAt,
+            &ref.DeletedAt,
+        ); err != nil {
+            return nil, err
+        }
+        results = append(results, ref)
+    }
+    return results, rows.Err()
+}
+
+// GetTrash returns the deleted achievements of a student that are still restorable
+func (r *achievementRepoPostgres) GetTrash(ctx context.Context, studentID uuid.UUID, deletedAfter time.Time) ([]models.AchievementReference, error) {
+    query := `
+        SELECT id, student_id, mongo_achievement_id, status, created_at, deleted_at
+        FROM achievement_references
+        WHERE status = 'deleted' AND student_id = $1 AND deleted_at > $2 AND ($3::uuid IS NULL OR tenant_id = $3)
+        ORDER BY deleted_at DESC
+    `
+    rows, err := r.db.QueryContext(ctx, query, studentID, deletedAfter, tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    return r.scanDeleted(rows)
+}
+
+func (r *achievementRepoPostgres) GetDeletedReferenceByID(ctx context.Context, id uuid.UUID) (models.AchievementReference, error) {
+    query := `
+        SELECT id, student_id, mongo_achievement_id, status, created_at, deleted_at, version
+        FROM achievement_references
+        WHERE status = 'deleted' AND id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
+    `
+    var ref models.AchievementReference
+    err := r.db.QueryRowContext(ctx, query, id, tenantArg(ctx)).Scan(
+        &ref.ID,
+        &ref.StudentID,
+        &ref.MongoAchievementID,
+        &ref.Status,
+        &ref.CreatedAt,
+        &ref.DeletedAt,
+        &ref.Version,
+    )
+    return ref, err
+}
+
+// RestoreReference moves a deleted reference back to draft, the only status that can be deleted
+func (r *achievementRepoPostgres) RestoreReference(ctx context.Context, id uuid.UUID) error {
+    query := `
+        UPDATE achievement_references 
+        SET status = 'draft', deleted_at = NULL, version = version + 1, updated_at = NOW()
+        WHERE id = $1 AND status = 'deleted' AND ($2::uuid IS NULL OR tenant_id = $2)
+    `
+    res, err := r.db.ExecContext(ctx, query, id, tenantArg(ctx))
+    if err != nil {
+        return err
+    }
+    if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+        return sql.ErrNoRows
+    }
+    return err
+}
+
+func (r *achievementRepoPostgres) GetPurgeableReferences(ctx context.Context, deletedBefore time.Time) ([]models.AchievementReference, error) {
+    query := `
+        SELECT id, student_id, mongo_achievement_id, status, created_at, deleted_at
+        FROM achievement_references
+        WHERE status = 'deleted' AND deleted_at < $1 AND ($2::uuid IS NULL OR tenant_id = $2)
+    `
+    rows, err := r.db.QueryContext(ctx, query, deletedBefore, tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    return r.scanDeleted(rows)
+}
+
+// PurgeReference permanently removes a deleted reference
+func (r *achievementRepoPostgres) PurgeReference(ctx context.Context, id uuid.UUID) error {
+    query := `
+        DELETE FROM achievement_references 
+        WHERE id = $1 AND status = 'deleted' AND ($2::uuid IS NULL OR tenant_id = $2)
+    `
+    _, err := r.db.ExecContext(ctx, query, id, tenantArg(ctx))
+    return err
+}
+
+// GetReferencesByCursor returns up to limit references after (or, for a backward
+// cursor, before) the cursor position using keyset pagination on (created_at, id).
+// The second result reports whether more rows exist in the direction of travel.
+// Results are always in display order.
+func (r *achievementRepoPostgres) GetReferencesByCursor(ctx context.Context, filter map[string]interface{}, limit int, cursor *models.Cursor, sort string) ([]models.AchievementReference, bool, error) {
+    whereClause, args := referenceWhere(ctx, filter)
+    argCount := len(args) + 1
+
+    ascending := sort == "oldest"
+    backward := cursor != nil && cursor.Backward
+    if backward {
+        ascending = !ascending
+    }
+
+    if cursor != nil {
+        op := "<"
+        if ascending {
+            op = ">"
+        }
+        whereClause += fmt.Sprintf(" AND (created_at, id) %s ($%d, $%d)", op, argCount, argCount+1)
+        args = append(args, cursor.CreatedAt, cursor.ID)
+        argCount += 2
+    }
+
+    order := " ORDER BY created_at DESC, id DESC"
+    if ascending {
+        order = " ORDER BY created_at ASC, id ASC"
+    }
+
+    query := `
+        SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, verified_by, period_id, created_at 
+        FROM achievement_references 
+    ` + whereClause + order + fmt.Sprintf(" LIMIT $%d", argCount)
+    args = append(args, limit+1)
+
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, false, err
+    }
+    defer rows.Close()
+
+    var results []models.AchievementReference
+    for rows.Next() {
+        var ref models.AchievementReference
+        if err := rows.Scan(
+            &ref.ID,
+            &ref.StudentID,
+            &ref.MongoAchievementID,
+            &ref.Status,
+            &ref.SubmittedAt,
+            &ref.VerifiedAt,
+            &ref.VerifiedBy,
+            &ref.PeriodID,
+            &ref.CreatedAt,
+        ); err != nil {
+            return nil, false, err
+        }
+        results = append(results, ref)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, false, err
+    }
+
+    hasMore := len(results) > limit
+    if hasMore {
+        results = results[:limit]
+    }
+    if backward {
+        for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+            results[i], results[j] = results[j], results[i]
+        }
+    }
+    return results, hasMore, nil
+}
+
+// EstimateReferences returns the planner's row estimate for a listing, which is
+// far cheaper than COUNT(*) on large tables but only approximate
+func (r *achievementRepoPostgres) EstimateReferences(ctx context.Context, filter map[string]interface{}) (int64, error) {
+    whereClause, args := referenceWhere(ctx, filter)
+
+    var plan []byte
+    err := r.db.QueryRowContext(ctx, `EXPLAIN (FORMAT JSON) SELECT 1 FROM achievement_references`+whereClause, args...).Scan(&plan)
+    if err != nil {
+        return 0, err
+    }
+
+    var parsed []struct {
+        Plan struct {
+            Rows float64 `json:"Plan Rows"`
+        } `json:"Plan"`
+    }
+    if err := json.Unmarshal(plan, &parsed); err != nil || len(parsed) == 0 {
+        return 0, err
+    }
+    return int64(parsed[0].Plan.Rows), nil
+}
+
+// SetPeriod assigns a reference to an academic period, or clears it with nil
+func (r *achievementRepoPostgres) SetPeriod(ctx context.Context, id uuid.UUID, periodID *uuid.UUID) error {
+    _, err := r.db.ExecContext(ctx, `
+        UPDATE achievement_references
+        SET period_id = $1, updated_at = NOW()
+        WHERE id = $2 AND ($3::uuid IS NULL OR tenant_id = $3)
+    `, periodID, id, tenantArg(ctx))
+    return err
+}
+
+// GetReferencesWithoutPeriod pages through achievements not yet assigned to an
+// academic period in (created_at, id) order, starting after the given cursor
+func (r *achievementRepoPostgres) GetReferencesWithoutPeriod(ctx context.Context, after *models.Cursor, limit int) ([]models.AchievementReference, error) {
+    query := `
+        SELECT id, student_id, mongo_achievement_id, status, created_at
+        FROM achievement_references
+        WHERE status != 'deleted' AND period_id IS NULL AND ($2::uuid IS NULL OR tenant_id = $2)
+    `
+    args := []interface{}{limit, tenantArg(ctx)}
+    if after != nil {
+        query += ` AND (created_at, id) > ($3, $4)`
+        args = append(args, after.CreatedAt, after.ID)
+    }
+    query += ` ORDER BY created_at, id LIMIT $1`
+
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var results []models.AchievementReference
+    for rows.Next() {
+        var ref models.AchievementReference
+        if err := rows.Scan(
+            &ref.ID,
+            &ref.StudentID,
+            &ref.MongoAchievementID,
+            &ref.Status,
+            &ref.CreatedAt,
+        ); err != nil {
+            return nil, err
+        }
+        results = append(results, ref)
+    }
+    return results, rows.Err()
+}
@@ -1,22 +1,27 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	models "StudenAchievementReportingSystem/app/models/postgresql"
 	"github.com/google/uuid"
 )
 
+// ErrUserOrRoleNotFound is returned when the user is not in the caller's tenant
+// or the role is neither shared nor one of the user's tenant
+var ErrUserOrRoleNotFound = errors.New("user not found or role not available in its tenant")
+
 type AdminRepository interface {
-	CreateUser(user *models.User) error
-	UpdateUser(user *models.User) error
-	DeleteUser(id uuid.UUID) error
-	GetUserByID(id uuid.UUID) (*models.User, error)
-	GetAllUsers() ([]models.User, error)
-	AssignRole(userID uuid.UUID, roleID uuid.UUID) error
-	SetStudentProfile(profile *models.Student) error
-	SetLecturerProfile(profile *models.Lecturer) error
-	SetAdvisor(studentID, lecturerID uuid.UUID) error
+	CreateUser(ctx context.Context, user *models.User) error
+	UpdateUser(ctx context.Context, user *models.User) error
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetAllUsers(ctx context.Context) ([]models.User, error)
+	AssignRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error
+	SetStudentProfile(ctx context.Context, profile *models.Student) error
+	SetLecturerProfile(ctx context.Context, profile *models.Lecturer) error
+	SetAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID) error
 }
 
 type adminRepository struct {
@@ -27,12 +32,16 @@ func NewAdminRepository(db *sql.DB) AdminRepository {
 	return &adminRepository{db: db}
 }
 
-func (r *adminRepository) CreateUser(user *models.User) error {
+// CreateUser adds the user to the caller's tenant
+func (r *adminRepository) CreateUser(ctx context.Context, user *models.User) error {
+	user.TenantID = newRowTenant(ctx)
+
 	query := `
-		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7, NOW(), NOW())
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active, tenant_id, created_at, updated_at)
+		SELECT $1,$2,$3,$4,$5,$6,$7,$8, NOW(), NOW()
+		WHERE EXISTS (SELECT 1 FROM roles WHERE id = $6 AND (tenant_id IS NULL OR tenant_id = $8))
 	`
-	_, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		user.ID,
 		user.Username,
 		user.Email,
@@ -40,37 +49,55 @@ func (r *adminRepository) CreateUser(user *models.User) error {
 		user.FullName,
 		user.RoleID,
 		user.IsActive,
+		user.TenantID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrUserOrRoleNotFound
+	}
+	return nil
 }
 
-func (r *adminRepository) UpdateUser(user *models.User) error {
+func (r *adminRepository) UpdateUser(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users SET
 			username=$1, email=$2, full_name=$3,
 			role_id=$4, is_active=$5, updated_at=NOW()
 		WHERE id=$6
+		  AND ($7::uuid IS NULL OR tenant_id = $7)
+		  AND EXISTS (SELECT 1 FROM roles r WHERE r.id = $4 AND (r.tenant_id IS NULL OR r.tenant_id = users.tenant_id))
 	`
 
-	_, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		user.Username,
 		user.Email,
 		user.FullName,
 		user.RoleID,
 		user.IsActive,
 		user.ID,
+		tenantArg(ctx),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrUserOrRoleNotFound
+	}
+	return nil
 }
 
-func (r *adminRepository) DeleteUser(id uuid.UUID) error {
+func (r *adminRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	query := `
         UPDATE users
         SET is_active = FALSE,
             updated_at = NOW()
-        WHERE id = $1 AND is_active = TRUE
+        WHERE id = $1 AND is_active = TRUE AND ($2::uuid IS NULL OR tenant_id = $2)
     `
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id, tenantArg(ctx))
 	if err != nil {
 		return err
 	}
@@ -83,17 +110,17 @@ func (r *adminRepository) DeleteUser(id uuid.UUID) error {
 	return nil
 }
 
-func (r *adminRepository) GetUserByID(id uuid.UUID) (*models.User, error) {
+func (r *adminRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
 
 	query := `
-		SELECT id, username, email, password_hash, full_name, role_id, is_active,
+		SELECT id, username, email, password_hash, full_name, role_id, tenant_id, is_active,
 		       created_at, updated_at
 		FROM users
-		WHERE id=$1
+		WHERE id=$1 AND ($2::uuid IS NULL OR tenant_id = $2)
 	`
 
-	row := r.db.QueryRow(query, id)
+	row := r.db.QueryRowContext(ctx, query, id, tenantArg(ctx))
 	err := row.Scan(
 		&user.ID,
 		&user.Username,
@@ -101,6 +128,7 @@ func (r *adminRepository) GetUserByID(id uuid.UUID) (*models.User, error) {
 		&user.PasswordHash,
 		&user.FullName,
 		&user.RoleID,
+		&user.TenantID,
 		&user.IsActive,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -116,12 +144,14 @@ func (r *adminRepository) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
-func (r *adminRepository) GetAllUsers() ([]models.User, error) {
+func (r *adminRepository) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	query := `
-		SELECT id, username, email, full_name, role_id, is_active, created_at
-		FROM users ORDER BY created_at DESC
+		SELECT id, username, email, full_name, role_id, tenant_id, is_active, created_at
+		FROM users
+		WHERE $1::uuid IS NULL OR tenant_id = $1
+		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query, tenantArg(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +167,7 @@ func (r *adminRepository) GetAllUsers() ([]models.User, error) {
 			&u.Email,
 			&u.FullName,
 			&u.RoleID,
+			&u.TenantID,
 			&u.IsActive,
 			&u.CreatedAt,
 		)
@@ -149,47 +180,80 @@ func (r *adminRepository) GetAllUsers() ([]models.User, error) {
 	return list, nil
 }
 
-func (r *adminRepository) AssignRole(userID uuid.UUID, roleID uuid.UUID) error {
-	_, err := r.db.Exec(`UPDATE users SET role_id=$1 WHERE id=$2`, roleID, userID)
-	return err
+func (r *adminRepository) AssignRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET role_id=$1
+		WHERE id=$2
+		  AND ($3::uuid IS NULL OR tenant_id = $3)
+		  AND EXISTS (SELECT 1 FROM roles r WHERE r.id = $1 AND (r.tenant_id IS NULL OR r.tenant_id = users.tenant_id))`,
+		roleID, userID, tenantArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrUserOrRoleNotFound
+	}
+	return nil
 }
 
-func (r *adminRepository) SetStudentProfile(s *models.Student) error {
+// SetStudentProfile creates or updates the student profile of a user; the
+// profile takes the tenant of the user
+func (r *adminRepository) SetStudentProfile(ctx context.Context, s *models.Student) error {
 	query := `
-		INSERT INTO students (id, user_id, student_id, program_study, academic_year, advisor_id, created_at)
-		VALUES ($1,$2,$3,$4,$5,$6, NOW())
+		INSERT INTO students (id, user_id, student_id, program_study, academic_year, advisor_id, program_study_id, tenant_id, created_at)
+		SELECT $1, u.id, $3, COALESCE((SELECT name FROM program_studies WHERE id=$7), $4), $5, $6, $7, u.tenant_id, NOW()
+		FROM users u
+		WHERE u.id = $2 AND ($8::uuid IS NULL OR u.tenant_id = $8)
 		ON CONFLICT (user_id) DO UPDATE SET
-			student_id=$3, program_study=$4, academic_year=$5, advisor_id=$6
+			student_id=$3, program_study=EXCLUDED.program_study, academic_year=$5, advisor_id=$6, program_study_id=$7
 	`
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		s.ID,
 		s.UserID,
 		s.StudentID,
 		s.ProgramStudy,
 		s.AcademicYear,
 		s.AdvisorID,
+		s.ProgramStudyID,
+		tenantArg(ctx),
 	)
 	return err
 }
 
-func (r *adminRepository) SetLecturerProfile(l *models.Lecturer) error {
+// SetLecturerProfile creates or updates the lecturer profile of a user; the
+// profile takes the tenant of the user
+func (r *adminRepository) SetLecturerProfile(ctx context.Context, l *models.Lecturer) error {
 	query := `
-		INSERT INTO lecturers (id, user_id, lecturer_id, department, created_at)
-		VALUES ($1,$2,$3,$4, NOW())
+		INSERT INTO lecturers (id, user_id, lecturer_id, department, department_id, tenant_id, created_at)
+		SELECT $1, u.id, $3, COALESCE((SELECT name FROM departments WHERE id=$5), $4), $5, u.tenant_id, NOW()
+		FROM users u
+		WHERE u.id = $2 AND ($6::uuid IS NULL OR u.tenant_id = $6)
 		ON CONFLICT (user_id) DO UPDATE SET
-			lecturer_id=$3, department=$4
+			lecturer_id=$3, department=EXCLUDED.department, department_id=$5
 	`
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		l.ID,
 		l.UserID,
 		l.LecturerID,
 		l.Department,
+		l.DepartmentID,
+		tenantArg(ctx),
 	)
 	return err
 }
 
-func (r *adminRepository) SetAdvisor(studentID, lecturerID uuid.UUID) error {
-	query := `UPDATE students SET advisor_id=$1 WHERE user_id=$2`
-	_, err := r.db.Exec(query, lecturerID, studentID)
-	return err
+// SetAdvisor takes the student ID, not its user ID, and records the change in
+// the advisor history like StudentRepository.UpdateAdvisor
+func (r *adminRepository) SetAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, _, err := moveAdvisor(ctx, tx, studentID, lecturerID, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,110 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+)
+
+type AttachmentBlobRepository interface {
+    Acquire(ctx context.Context, b models.AttachmentBlob, store func(storageKey string) error) (*models.AttachmentBlob, error)
+    Release(ctx context.Context, checksum string, remove func(storageKey string) error) error
+    List(ctx context.Context) ([]models.AttachmentBlob, error)
+}
+
+type attachmentBlobRepository struct {
+    db *sql.DB
+}
+
+func NewAttachmentBlobRepository(db *sql.DB) AttachmentBlobRepository {
+    return &attachmentBlobRepository{db: db}
+}
+
+// Acquire adds a reference to the blob with b's checksum. When there is none
+// yet, b is registered and store is called with its storage key before the
+// row is committed, so the blob only becomes visible once its file is stored:
+// an upload of the same content waits on the row meanwhile, and when store
+// fails nothing is registered.
+func (r *attachmentBlobRepository) Acquire(ctx context.Context, b models.AttachmentBlob, store func(storageKey string) error) (*models.AttachmentBlob, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    var blob models.AttachmentBlob
+    err = tx.QueryRowContext(ctx, `
+        INSERT INTO attachment_blobs (checksum, storage_key, size_bytes, content_type, ref_count, created_at)
+        VALUES ($1, $2, $3, $4, 1, NOW())
+        ON CONFLICT (checksum) DO UPDATE SET ref_count = attachment_blobs.ref_count + 1
+        RETURNING checksum, storage_key, size_bytes, content_type, ref_count, created_at`,
+        b.Checksum, b.StorageKey, b.SizeBytes, b.ContentType).
+        Scan(&blob.Checksum, &blob.StorageKey, &blob.SizeBytes, &blob.ContentType, &blob.RefCount, &blob.CreatedAt)
+    if err != nil {
+        return nil, err
+    }
+
+    if blob.RefCount == 1 {
+        if err := store(blob.StorageKey); err != nil {
+            return nil, err
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return nil, err
+    }
+    return &blob, nil
+}
+
+// Release drops a reference to a blob. When it was the last one, remove is
+// called with the storage key while the row is still locked, so an upload of
+// the same content waits and stores the file again instead of reusing one
+// that is being deleted. The row is deleted even if remove fails; a file left
+// behind is only wasted space. Unknown checksums return sql.ErrNoRows.
+func (r *attachmentBlobRepository) Release(ctx context.Context, checksum string, remove func(storageKey string) error) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var key string
+    var refs int
+    err = tx.QueryRowContext(ctx, `
+        UPDATE attachment_blobs SET ref_count = GREATEST(ref_count - 1, 0)
+        WHERE checksum = $1
+        RETURNING storage_key, ref_count`, checksum).Scan(&key, &refs)
+    if err != nil {
+        return err
+    }
+
+    if refs == 0 {
+        remove(key)
+        if _, err := tx.ExecContext(ctx, `DELETE FROM attachment_blobs WHERE checksum = $1`, checksum); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+// List returns every registered blob
+func (r *attachmentBlobRepository) List(ctx context.Context) ([]models.AttachmentBlob, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT checksum, storage_key, size_bytes, content_type, ref_count, created_at
+        FROM attachment_blobs
+        ORDER BY checksum`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var blobs []models.AttachmentBlob
+    for rows.Next() {
+        var b models.AttachmentBlob
+        if err := rows.Scan(&b.Checksum, &b.StorageKey, &b.SizeBytes, &b.ContentType, &b.RefCount, &b.CreatedAt); err != nil {
+            return nil, err
+        }
+        blobs = append(blobs, b)
+    }
+    return blobs, rows.Err()
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+)
+
+type AttachmentTypeRepository interface {
+    GetAll(ctx context.Context) ([]models.AttachmentType, error)
+    GetByCode(ctx context.Context, code string) (*models.AttachmentType, error)
+    Save(ctx context.Context, t models.AttachmentType) error
+    Delete(ctx context.Context, code string) error
+}
+
+type attachmentTypeRepository struct {
+    db *sql.DB
+}
+
+func NewAttachmentTypeRepository(db *sql.DB) AttachmentTypeRepository {
+    return &attachmentTypeRepository{db: db}
+}
+
+func (r *attachmentTypeRepository) GetAll(ctx context.Context) ([]models.AttachmentType, error) {
+    rows, err := r.db.QueryContext(ctx, `SELECT code, max_size_bytes, updated_at FROM attachment_types ORDER BY code`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.AttachmentType, 0)
+    for rows.Next() {
+        var t models.AttachmentType
+        if err := rows.Scan(&t.Code, &t.MaxSizeBytes, &t.UpdatedAt); err != nil {
+            return nil, err
+        }
+        list = append(list, t)
+    }
+    return list, rows.Err()
+}
+
+// GetByCode returns sql.ErrNoRows for types that are not allowed
+func (r *attachmentTypeRepository) GetByCode(ctx context.Context, code string) (*models.AttachmentType, error) {
+    var t models.AttachmentType
+    err := r.db.QueryRowContext(ctx, `SELECT code, max_size_bytes, updated_at FROM attachment_types WHERE code = $1`, code).
+        Scan(&t.Code, &t.MaxSizeBytes, &t.UpdatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &t, nil
+}
+
+// Save allows a type or changes its limit
+func (r *attachmentTypeRepository) Save(ctx context.Context, t models.AttachmentType) error {
+    _, err := r.db.ExecContext(ctx, `
+        INSERT INTO attachment_types (code, max_size_bytes, updated_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (code) DO UPDATE SET max_size_bytes = EXCLUDED.max_size_bytes, updated_at = NOW()`,
+        t.Code, t.MaxSizeBytes)
+    return err
+}
+
+func (r *attachmentTypeRepository) Delete(ctx context.Context, code string) error {
+    return affectedOne(r.db.ExecContext(ctx, `DELETE FROM attachment_types WHERE code = $1`, code))
+}
@@ -6,13 +6,15 @@ import (
 	"errors"
 	models "StudenAchievementReportingSystem/app/models/postgresql"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type LecturerRepository interface {
-	GetAllLecturers() ([]models.Lecturer, error)
-	GetLecturerByID(id uuid.UUID) (*models.Lecturer, error)
-	GetAdvisees(lecturerID uuid.UUID) ([]models.Student, error)
+	GetAllLecturers(ctx context.Context) ([]models.Lecturer, error)
+	GetLecturerByID(ctx context.Context, id uuid.UUID) (*models.Lecturer, error)
+	GetAdvisees(ctx context.Context, lecturerID uuid.UUID) ([]models.Student, error)
 	GetLecturerByUserID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error)
+	GetLecturersByUserIDs(ctx context.Context, ids []uuid.UUID) ([]models.LecturerSummary, error)
 }
 
 type lecturerRepository struct {
@@ -23,10 +25,12 @@ func NewLecturerRepository(db *sql.DB) LecturerRepository {
 	return &lecturerRepository{db: db}
 }
 
-func (r *lecturerRepository) GetAllLecturers() ([]models.Lecturer, error) {
-	rows, err := r.db.Query(`
-		SELECT id, user_id, lecturer_id, department, created_at 
-		FROM lecturers`)
+func (r *lecturerRepository) GetAllLecturers(ctx context.Context) ([]models.Lecturer, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.id, l.user_id, l.lecturer_id, COALESCE(d.name, l.department), l.department_id, l.created_at
+		FROM lecturers l
+		LEFT JOIN departments d ON d.id = l.department_id
+		WHERE $1::uuid IS NULL OR l.tenant_id = $1`, tenantArg(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -35,19 +39,21 @@ func (r *lecturerRepository) GetAllLecturers() ([]models.Lecturer, error) {
 	var list []models.Lecturer
 	for rows.Next() {
 		var l models.Lecturer
-		rows.Scan(&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.CreatedAt)
+		rows.Scan(&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.DepartmentID, &l.CreatedAt)
 		list = append(list, l)
 	}
 	return list, nil
 }
 
 
-func (r *lecturerRepository) GetLecturerByID(id uuid.UUID) (*models.Lecturer, error) {
+func (r *lecturerRepository) GetLecturerByID(ctx context.Context, id uuid.UUID) (*models.Lecturer, error) {
 	var l models.Lecturer
-	err := r.db.QueryRow(`
-		SELECT id, user_id, lecturer_id, department, created_at
-		FROM lecturers WHERE id=$1
-	`, id).Scan(&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.CreatedAt)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT l.id, l.user_id, l.lecturer_id, COALESCE(d.name, l.department), l.department_id, l.created_at
+		FROM lecturers l
+		LEFT JOIN departments d ON d.id = l.department_id
+		WHERE l.id=$1 AND ($2::uuid IS NULL OR l.tenant_id = $2)
+	`, id, tenantArg(ctx)).Scan(&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.DepartmentID, &l.CreatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, err
@@ -56,11 +62,13 @@ func (r *lecturerRepository) GetLecturerByID(id uuid.UUID) (*models.Lecturer, er
 }
 
 
-func (r *lecturerRepository) GetAdvisees(lecturerID uuid.UUID) ([]models.Student, error) {
-	rows, err := r.db.Query(`
-		SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at
-		FROM students WHERE advisor_id=$1
-	`, lecturerID)
+func (r *lecturerRepository) GetAdvisees(ctx context.Context, lecturerID uuid.UUID) ([]models.Student, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.id, s.user_id, s.student_id, COALESCE(ps.name, s.program_study), s.program_study_id, s.academic_year, s.advisor_id, s.created_at
+		FROM students s
+		LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+		WHERE s.advisor_id=$1 AND ($2::uuid IS NULL OR s.tenant_id = $2)
+	`, lecturerID, tenantArg(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +78,7 @@ func (r *lecturerRepository) GetAdvisees(lecturerID uuid.UUID) ([]models.Student
 	for rows.Next() {
 		var s models.Student
 		rows.Scan(&s.ID, &s.UserID, &s.StudentID,
-			&s.ProgramStudy, &s.AcademicYear,
+			&s.ProgramStudy, &s.ProgramStudyID, &s.AcademicYear,
 			&s.AdvisorID, &s.CreatedAt,
 		)
 		list = append(list, s)
@@ -79,12 +87,40 @@ func (r *lecturerRepository) GetAdvisees(lecturerID uuid.UUID) ([]models.Student
 }
 
 func (r *lecturerRepository) GetLecturerByUserID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
-    query := `SELECT id FROM lecturers WHERE user_id = $1`
+    query := `SELECT id FROM lecturers WHERE user_id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`
     var lecturerID uuid.UUID
-    err := r.db.QueryRowContext(ctx, query, userID).Scan(&lecturerID)
+    err := r.db.QueryRowContext(ctx, query, userID, tenantArg(ctx)).Scan(&lecturerID)
     if err != nil {
         return uuid.Nil, errors.New("lecturer profile not found")
     }
     return lecturerID, nil
 }
 
+// GetLecturersByUserIDs looks up lecturers by their user id, as stored in
+// achievement_references.verified_by
+func (r *lecturerRepository) GetLecturersByUserIDs(ctx context.Context, ids []uuid.UUID) ([]models.LecturerSummary, error) {
+	if len(ids) == 0 {
+		return []models.LecturerSummary{}, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.id, l.user_id, l.lecturer_id, u.full_name, COALESCE(d.name, l.department), l.department_id
+		FROM lecturers l
+		JOIN users u ON u.id = l.user_id
+		LEFT JOIN departments d ON d.id = l.department_id
+		WHERE l.user_id = ANY($1) AND ($2::uuid IS NULL OR l.tenant_id = $2)`, pq.Array(ids), tenantArg(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.LecturerSummary
+	for rows.Next() {
+		var l models.LecturerSummary
+		if err := rows.Scan(&l.ID, &l.UserID, &l.LecturerID, &l.FullName, &l.Department, &l.DepartmentID); err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    "github.com/google/uuid"
+)
+
+type NotificationRepository interface {
+    Create(ctx context.Context, n models.Notification) error
+    GetByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool) ([]models.Notification, error)
+    MarkRead(ctx context.Context, id, userID uuid.UUID) error
+}
+
+type notificationRepository struct {
+    db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+    return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, n models.Notification) error {
+    _, err := r.db.ExecContext(ctx, `
+        INSERT INTO notifications (user_id, kind, message, achievement_id, created_at)
+        VALUES ($1, $2, $3, $4, NOW())`,
+        n.UserID, n.Kind, n.Message, n.AchievementID)
+    return err
+}
+
+// GetByUser returns the latest 100 notifications of a user, newest first
+func (r *notificationRepository) GetByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool) ([]models.Notification, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT id, user_id, kind, message, achievement_id, created_at, read_at
+        FROM notifications
+        WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+        ORDER BY created_at DESC
+        LIMIT 100`, userID, unreadOnly)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.Notification, 0)
+    for rows.Next() {
+        var n models.Notification
+        if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Message, &n.AchievementID, &n.CreatedAt, &n.ReadAt); err != nil {
+            return nil, err
+        }
+        list = append(list, n)
+    }
+    return list, rows.Err()
+}
+
+// MarkRead returns sql.ErrNoRows when the notification belongs to someone else
+func (r *notificationRepository) MarkRead(ctx context.Context, id, userID uuid.UUID) error {
+    return affectedOne(r.db.ExecContext(ctx, `
+        UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+        WHERE id = $1 AND user_id = $2`, id, userID))
+}
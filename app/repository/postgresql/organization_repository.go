@@ -0,0 +1,588 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    "github.com/google/uuid"
+    "github.com/lib/pq"
+)
+
+var (
+    // ErrUnitInUse is returned when deleting a faculty, department or program
+    // study that still has children, lecturers or students
+    ErrUnitInUse = errors.New("organization unit is still in use")
+    // ErrDuplicateCode is returned when a code is already taken
+    ErrDuplicateCode = errors.New("code is already in use")
+    // ErrParentNotFound is returned when the faculty or department to attach to does not exist
+    ErrParentNotFound = errors.New("parent organization unit not found")
+)
+
+type OrganizationRepository interface {
+    GetFaculties(ctx context.Context) ([]models.Faculty, error)
+    GetFacultyByID(ctx context.Context, id uuid.UUID) (*models.Faculty, error)
+    CreateFaculty(ctx context.Context, f models.Faculty) (uuid.UUID, error)
+    UpdateFaculty(ctx context.Context, f models.Faculty) error
+    DeleteFaculty(ctx context.Context, id uuid.UUID) error
+
+    GetDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error)
+    GetDepartmentByID(ctx context.Context, id uuid.UUID) (*models.Department, error)
+    CreateDepartment(ctx context.Context, d models.Department) (uuid.UUID, error)
+    UpdateDepartment(ctx context.Context, d models.Department) error
+    DeleteDepartment(ctx context.Context, id uuid.UUID) error
+    MergeDepartments(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error
+
+    GetProgramStudies(ctx context.Context, departmentID *uuid.UUID) ([]models.ProgramStudy, error)
+    GetProgramStudyByID(ctx context.Context, id uuid.UUID) (*models.ProgramStudy, error)
+    CreateProgramStudy(ctx context.Context, p models.ProgramStudy) (uuid.UUID, error)
+    UpdateProgramStudy(ctx context.Context, p models.ProgramStudy) error
+    DeleteProgramStudy(ctx context.Context, id uuid.UUID) error
+    MergeProgramStudies(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error
+
+    GetStudentUnits(ctx context.Context) ([]models.StudentUnits, error)
+
+    GetCoordinatorScope(ctx context.Context, userID uuid.UUID) (*models.CoordinatorScope, error)
+    SetCoordinatorScope(ctx context.Context, scope models.CoordinatorScope) error
+}
+
+type organizationRepository struct {
+    db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) OrganizationRepository {
+    return &organizationRepository{db: db}
+}
+
+// orgError maps constraint violations to the errors the service reports
+func orgError(err error) error {
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) {
+        switch pqErr.Code {
+        case "23505":
+            return ErrDuplicateCode
+        case "23503":
+            return ErrParentNotFound
+        }
+    }
+    return err
+}
+
+func affectedOne(res sql.Result, err error) error {
+    if err != nil {
+        return orgError(err)
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return sql.ErrNoRows
+    }
+    return nil
+}
+
+func (r *organizationRepository) inUse(ctx context.Context, query string, id uuid.UUID) error {
+    var used bool
+    if err := r.db.QueryRowContext(ctx, query, id).Scan(&used); err != nil {
+        return err
+    }
+    if used {
+        return ErrUnitInUse
+    }
+    return nil
+}
+
+// parentInTenant returns ErrParentNotFound unless the faculty or department to
+// attach to exists in the caller's tenant
+func (r *organizationRepository) parentInTenant(ctx context.Context, table string, id uuid.UUID) error {
+    var found bool
+    err := r.db.QueryRowContext(ctx,
+        `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2))`,
+        id, tenantArg(ctx)).Scan(&found)
+    if err != nil {
+        return err
+    }
+    if !found {
+        return ErrParentNotFound
+    }
+    return nil
+}
+
+// mergeSources narrows merge sources to the units of the target's tenant. It
+// returns sql.ErrNoRows when the target is not in the caller's tenant.
+func mergeSources(ctx context.Context, tx *sql.Tx, table string, targetID uuid.UUID, sourceIDs []uuid.UUID) (string, []uuid.UUID, error) {
+    var name string
+    var tenantID uuid.UUID
+    err := tx.QueryRowContext(ctx,
+        `SELECT name, tenant_id FROM `+table+` WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`,
+        targetID, tenantArg(ctx)).Scan(&name, &tenantID)
+    if err != nil {
+        return "", nil, err
+    }
+
+    rows, err := tx.QueryContext(ctx, `SELECT id FROM `+table+` WHERE id = ANY($1) AND tenant_id = $2`, pq.Array(sourceIDs), tenantID)
+    if err != nil {
+        return "", nil, err
+    }
+    defer rows.Close()
+
+    ids := make([]uuid.UUID, 0, len(sourceIDs))
+    for rows.Next() {
+        var id uuid.UUID
+        if err := rows.Scan(&id); err != nil {
+            return "", nil, err
+        }
+        ids = append(ids, id)
+    }
+    return name, ids, rows.Err()
+}
+
+func (r *organizationRepository) GetFaculties(ctx context.Context) ([]models.Faculty, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT id, code, name, created_at, updated_at FROM faculties
+        WHERE $1::uuid IS NULL OR tenant_id = $1
+        ORDER BY code`, tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.Faculty, 0)
+    for rows.Next() {
+        var f models.Faculty
+        if err := rows.Scan(&f.ID, &f.Code, &f.Name, &f.CreatedAt, &f.UpdatedAt); err != nil {
+            return nil, err
+        }
+        list = append(list, f)
+    }
+    return list, rows.Err()
+}
+
+func (r *organizationRepository) GetFacultyByID(ctx context.Context, id uuid.UUID) (*models.Faculty, error) {
+    var f models.Faculty
+    err := r.db.QueryRowContext(ctx, `
+        SELECT id, code, name, created_at, updated_at FROM faculties
+        WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`, id, tenantArg(ctx)).
+        Scan(&f.ID, &f.Code, &f.Name, &f.CreatedAt, &f.UpdatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &f, nil
+}
+
+func (r *organizationRepository) CreateFaculty(ctx context.Context, f models.Faculty) (uuid.UUID, error) {
+    var id uuid.UUID
+    err := r.db.QueryRowContext(ctx, `
+        INSERT INTO faculties (code, name, tenant_id, created_at, updated_at)
+        VALUES ($1, $2, $3, NOW(), NOW())
+        RETURNING id`, f.Code, f.Name, newRowTenant(ctx)).Scan(&id)
+    return id, orgError(err)
+}
+
+func (r *organizationRepository) UpdateFaculty(ctx context.Context, f models.Faculty) error {
+    return affectedOne(r.db.ExecContext(ctx, `
+        UPDATE faculties SET code = $1, name = $2, updated_at = NOW()
+        WHERE id = $3 AND ($4::uuid IS NULL OR tenant_id = $4)`,
+        f.Code, f.Name, f.ID, tenantArg(ctx)))
+}
+
+func (r *organizationRepository) DeleteFaculty(ctx context.Context, id uuid.UUID) error {
+    if err := r.inUse(ctx, `SELECT EXISTS (SELECT 1 FROM departments WHERE faculty_id = $1)`, id); err != nil {
+        return err
+    }
+    return affectedOne(r.db.ExecContext(ctx, `DELETE FROM faculties WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`, id, tenantArg(ctx)))
+}
+
+func (r *organizationRepository) GetDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT id, faculty_id, code, name, created_at, updated_at
+        FROM departments
+        WHERE ($1::uuid IS NULL OR faculty_id = $1) AND ($2::uuid IS NULL OR tenant_id = $2)
+        ORDER BY code`, facultyID, tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.Department, 0)
+    for rows.Next() {
+        var d models.Department
+        if err := rows.Scan(&d.ID, &d.FacultyID, &d.Code, &d.Name, &d.CreatedAt, &d.UpdatedAt); err != nil {
+            return nil, err
+        }
+        list = append(list, d)
+    }
+    return list, rows.Err()
+}
+
+func (r *organizationRepository) GetDepartmentByID(ctx context.Context, id uuid.UUID) (*models.Department, error) {
+    var d models.Department
+    err := r.db.QueryRowContext(ctx, `
+        SELECT id, faculty_id, code, name, created_at, updated_at FROM departments
+        WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`, id, tenantArg(ctx)).
+        Scan(&d.ID, &d.FacultyID, &d.Code, &d.Name, &d.CreatedAt, &d.UpdatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &d, nil
+}
+
+// CreateDepartment adds a department to a faculty of the caller's tenant
+func (r *organizationRepository) CreateDepartment(ctx context.Context, d models.Department) (uuid.UUID, error) {
+    if err := r.parentInTenant(ctx, "faculties", d.FacultyID); err != nil {
+        return uuid.Nil, err
+    }
+
+    var id uuid.UUID
+    err := r.db.QueryRowContext(ctx, `
+        INSERT INTO departments (faculty_id, code, name, tenant_id, created_at, updated_at)
+        VALUES ($1, $2, $3, (SELECT tenant_id FROM faculties WHERE id = $1), NOW(), NOW())
+        RETURNING id`, d.FacultyID, d.Code, d.Name).Scan(&id)
+    return id, orgError(err)
+}
+
+// UpdateDepartment also refreshes the legacy department text of its lecturers
+func (r *organizationRepository) UpdateDepartment(ctx context.Context, d models.Department) error {
+    if err := r.parentInTenant(ctx, "faculties", d.FacultyID); err != nil {
+        return err
+    }
+
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    err = affectedOne(tx.ExecContext(ctx, `
+        UPDATE departments SET faculty_id = $1, code = $2, name = $3, updated_at = NOW()
+        WHERE id = $4 AND ($5::uuid IS NULL OR tenant_id = $5)`,
+        d.FacultyID, d.Code, d.Name, d.ID, tenantArg(ctx)))
+    if err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `UPDATE lecturers SET department = $1 WHERE department_id = $2`, d.Name, d.ID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *organizationRepository) DeleteDepartment(ctx context.Context, id uuid.UUID) error {
+    err := r.inUse(ctx, `
+        SELECT EXISTS (SELECT 1 FROM program_studies WHERE department_id = $1)
+            OR EXISTS (SELECT 1 FROM lecturers WHERE department_id = $1)`, id)
+    if err != nil {
+        return err
+    }
+    return affectedOne(r.db.ExecContext(ctx, `DELETE FROM departments WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`, id, tenantArg(ctx)))
+}
+
+// MergeDepartments moves the lecturers and program studies of the source
+// departments to the target and deletes the sources, in one transaction.
+// Sources of another tenant than the target's are left alone.
+func (r *organizationRepository) MergeDepartments(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    name, sourceIDs, err := mergeSources(ctx, tx, "departments", targetID, sourceIDs)
+    if err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `
+        UPDATE lecturers SET department_id = $1, department = $2 WHERE department_id = ANY($3)`,
+        targetID, name, pq.Array(sourceIDs)); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `
+        UPDATE program_studies SET department_id = $1, updated_at = NOW() WHERE department_id = ANY($2)`,
+        targetID, pq.Array(sourceIDs)); err != nil {
+        return err
+    }
+    // coordinators of a source now oversee the target; their old rows go with the source
+    if _, err := tx.ExecContext(ctx, `
+        INSERT INTO coordinator_scopes (user_id, department_id)
+        SELECT DISTINCT user_id, $1::uuid FROM coordinator_scopes WHERE department_id = ANY($2)
+        ON CONFLICT DO NOTHING`, targetID, pq.Array(sourceIDs)); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `DELETE FROM departments WHERE id = ANY($1) AND id != $2`, pq.Array(sourceIDs), targetID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *organizationRepository) GetProgramStudies(ctx context.Context, departmentID *uuid.UUID) ([]models.ProgramStudy, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT id, department_id, code, name, created_at, updated_at
+        FROM program_studies
+        WHERE ($1::uuid IS NULL OR department_id = $1) AND ($2::uuid IS NULL OR tenant_id = $2)
+        ORDER BY code`, departmentID, tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.ProgramStudy, 0)
+    for rows.Next() {
+        var p models.ProgramStudy
+        if err := rows.Scan(&p.ID, &p.DepartmentID, &p.Code, &p.Name, &p.CreatedAt, &p.UpdatedAt); err != nil {
+            return nil, err
+        }
+        list = append(list, p)
+    }
+    return list, rows.Err()
+}
+
+func (r *organizationRepository) GetProgramStudyByID(ctx context.Context, id uuid.UUID) (*models.ProgramStudy, error) {
+    var p models.ProgramStudy
+    err := r.db.QueryRowContext(ctx, `
+        SELECT id, department_id, code, name, created_at, updated_at FROM program_studies
+        WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`, id, tenantArg(ctx)).
+        Scan(&p.ID, &p.DepartmentID, &p.Code, &p.Name, &p.CreatedAt, &p.UpdatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
+
+// CreateProgramStudy adds a program study to a department of the caller's tenant
+func (r *organizationRepository) CreateProgramStudy(ctx context.Context, p models.ProgramStudy) (uuid.UUID, error) {
+    if err := r.parentInTenant(ctx, "departments", p.DepartmentID); err != nil {
+        return uuid.Nil, err
+    }
+
+    var id uuid.UUID
+    err := r.db.QueryRowContext(ctx, `
+        INSERT INTO program_studies (department_id, code, name, tenant_id, created_at, updated_at)
+        VALUES ($1, $2, $3, (SELECT tenant_id FROM departments WHERE id = $1), NOW(), NOW())
+        RETURNING id`, p.DepartmentID, p.Code, p.Name).Scan(&id)
+    return id, orgError(err)
+}
+
+// UpdateProgramStudy also refreshes the legacy program_study text of its students
+func (r *organizationRepository) UpdateProgramStudy(ctx context.Context, p models.ProgramStudy) error {
+    if err := r.parentInTenant(ctx, "departments", p.DepartmentID); err != nil {
+        return err
+    }
+
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    err = affectedOne(tx.ExecContext(ctx, `
+        UPDATE program_studies SET department_id = $1, code = $2, name = $3, updated_at = NOW()
+        WHERE id = $4 AND ($5::uuid IS NULL OR tenant_id = $5)`,
+        p.DepartmentID, p.Code, p.Name, p.ID, tenantArg(ctx)))
+    if err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `UPDATE students SET program_study = $1 WHERE program_study_id = $2`, p.Name, p.ID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+func (r *organizationRepository) DeleteProgramStudy(ctx context.Context, id uuid.UUID) error {
+    if err := r.inUse(ctx, `SELECT EXISTS (SELECT 1 FROM students WHERE program_study_id = $1)`, id); err != nil {
+        return err
+    }
+    return affectedOne(r.db.ExecContext(ctx, `DELETE FROM program_studies WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`, id, tenantArg(ctx)))
+}
+
+// MergeProgramStudies moves the students of the source program studies to the
+// target and deletes the sources, in one transaction. Sources of another tenant
+// than the target's are left alone.
+func (r *organizationRepository) MergeProgramStudies(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    name, sourceIDs, err := mergeSources(ctx, tx, "program_studies", targetID, sourceIDs)
+    if err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `
+        UPDATE students SET program_study_id = $1, program_study = $2 WHERE program_study_id = ANY($3)`,
+        targetID, name, pq.Array(sourceIDs)); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `
+        INSERT INTO coordinator_scopes (user_id, program_study_id)
+        SELECT DISTINCT user_id, $1::uuid FROM coordinator_scopes WHERE program_study_id = ANY($2)
+        ON CONFLICT DO NOTHING`, targetID, pq.Array(sourceIDs)); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `DELETE FROM program_studies WHERE id = ANY($1) AND id != $2`, pq.Array(sourceIDs), targetID); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// GetStudentUnits returns the program study, department and faculty of every student
+func (r *organizationRepository) GetStudentUnits(ctx context.Context) ([]models.StudentUnits, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT s.id,
+               ps.id, ps.code, ps.name,
+               d.id, d.code, d.name,
+               f.id, f.code, f.name
+        FROM students s
+        LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+        LEFT JOIN departments d ON d.id = ps.department_id
+        LEFT JOIN faculties f ON f.id = d.faculty_id
+        WHERE $1::uuid IS NULL OR s.tenant_id = $1`, tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.StudentUnits, 0)
+    for rows.Next() {
+        var su models.StudentUnits
+        var ps, d, f nullUnit
+        if err := rows.Scan(
+            &su.StudentID,
+            &ps.id, &ps.code, &ps.name,
+            &d.id, &d.code, &d.name,
+            &f.id, &f.code, &f.name,
+        ); err != nil {
+            return nil, err
+        }
+        su.ProgramStudy, su.Department, su.Faculty = ps.unit(), d.unit(), f.unit()
+        list = append(list, su)
+    }
+    return list, rows.Err()
+}
+
+// GetCoordinatorScope returns what a user oversees. A user without scope rows
+// gets an empty scope, which covers nothing.
+func (r *organizationRepository) GetCoordinatorScope(ctx context.Context, userID uuid.UUID) (*models.CoordinatorScope, error) {
+    scope := &models.CoordinatorScope{
+        UserID:          userID,
+        DepartmentIDs:   []uuid.UUID{},
+        ProgramStudyIDs: []uuid.UUID{},
+        Covered:         []uuid.UUID{},
+    }
+
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT cs.department_id, cs.program_study_id
+        FROM coordinator_scopes cs
+        JOIN users u ON u.id = cs.user_id
+        WHERE cs.user_id = $1 AND ($2::uuid IS NULL OR u.tenant_id = $2)`, userID, tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var dept, prodi uuid.NullUUID
+        if err := rows.Scan(&dept, &prodi); err != nil {
+            return nil, err
+        }
+        if dept.Valid {
+            scope.DepartmentIDs = append(scope.DepartmentIDs, dept.UUID)
+        }
+        if prodi.Valid {
+            scope.ProgramStudyIDs = append(scope.ProgramStudyIDs, prodi.UUID)
+        }
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    covered, err := r.db.QueryContext(ctx, `
+        SELECT id FROM program_studies
+        WHERE id = ANY($1) OR department_id = ANY($2)`,
+        pq.Array(scope.ProgramStudyIDs), pq.Array(scope.DepartmentIDs))
+    if err != nil {
+        return nil, err
+    }
+    defer covered.Close()
+    for covered.Next() {
+        var id uuid.UUID
+        if err := covered.Scan(&id); err != nil {
+            return nil, err
+        }
+        scope.Covered = append(scope.Covered, id)
+    }
+    return scope, covered.Err()
+}
+
+// unitsInTenant returns ErrParentNotFound unless every id is a unit of the tenant
+func unitsInTenant(ctx context.Context, tx *sql.Tx, table string, ids []uuid.UUID, tenantID uuid.UUID) error {
+    distinct := make(map[uuid.UUID]bool, len(ids))
+    for _, id := range ids {
+        distinct[id] = true
+    }
+
+    var found int
+    err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table+` WHERE id = ANY($1) AND tenant_id = $2`,
+        pq.Array(ids), tenantID).Scan(&found)
+    if err != nil {
+        return err
+    }
+    if found != len(distinct) {
+        return ErrParentNotFound
+    }
+    return nil
+}
+
+// SetCoordinatorScope replaces the departments and program studies of a
+// coordinator. Units have to belong to the coordinator's tenant.
+func (r *organizationRepository) SetCoordinatorScope(ctx context.Context, scope models.CoordinatorScope) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var tenantID uuid.UUID
+    err = tx.QueryRowContext(ctx, `SELECT tenant_id FROM users WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)`,
+        scope.UserID, tenantArg(ctx)).Scan(&tenantID)
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrParentNotFound
+    } else if err != nil {
+        return err
+    }
+    if err := unitsInTenant(ctx, tx, "departments", scope.DepartmentIDs, tenantID); err != nil {
+        return err
+    }
+    if err := unitsInTenant(ctx, tx, "program_studies", scope.ProgramStudyIDs, tenantID); err != nil {
+        return err
+    }
+
+    if _, err := tx.ExecContext(ctx, `DELETE FROM coordinator_scopes WHERE user_id = $1`, scope.UserID); err != nil {
+        return err
+    }
+    for _, id := range scope.DepartmentIDs {
+        if _, err := tx.ExecContext(ctx, `
+            INSERT INTO coordinator_scopes (user_id, department_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+            scope.UserID, id); err != nil {
+            return orgError(err)
+        }
+    }
+    for _, id := range scope.ProgramStudyIDs {
+        if _, err := tx.ExecContext(ctx, `
+            INSERT INTO coordinator_scopes (user_id, program_study_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+            scope.UserID, id); err != nil {
+            return orgError(err)
+        }
+    }
+    return tx.Commit()
+}
+
+// nullUnit scans a unit from a LEFT JOIN that may not have matched
+type nullUnit struct {
+    id   uuid.NullUUID
+    code sql.NullString
+    name sql.NullString
+}
+
+func (n nullUnit) unit() *models.OrgUnit {
+    if !n.id.Valid {
+        return nil
+    }
+    return &models.OrgUnit{ID: n.id.UUID, Code: n.code.String, Name: n.name.String}
+}
@@ -4,15 +4,27 @@ import (
     "context"
     "database/sql"
     "errors"
+    "fmt"
     models "StudenAchievementReportingSystem/app/models/postgresql"
     "github.com/google/uuid"
     "github.com/lib/pq"
 )
 
+var (
+    ErrStudentNotFound   = errors.New("student not found")
+    ErrLecturerNotActive = errors.New("lecturer not found or not active")
+    ErrStudentOutOfScope = errors.New("student is outside the coordinator scope")
+)
+
+// StudentRepository reads students. Advisor changes are recorded in
+// advisor_assignments; a nil scope means the caller is not a coordinator.
 type StudentRepository interface {
     GetAllStudents(ctx context.Context) ([]models.Student, error)
     GetStudentByID(ctx context.Context, id uuid.UUID) (*models.Student, error)
-    UpdateAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID) error
+    UpdateAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID, assignedBy *uuid.UUID) (*models.ReassignResult, error)
+    ReassignAdvisors(ctx context.Context, changes []models.AdvisorChange, assignedBy *uuid.UUID, scope *models.CoordinatorScope) (*models.ReassignResult, error)
+    TransferAdvisees(ctx context.Context, fromLecturerID, toLecturerID uuid.UUID, assignedBy *uuid.UUID, scope *models.CoordinatorScope) (*models.ReassignResult, error)
+    GetAdvisorHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorAssignment, error)
     GetStudentsByIDs(ctx context.Context, ids []string) ([]models.StudentWithUser, error)
 }
 
@@ -26,12 +38,14 @@ func NewStudentRepository(pg *sql.DB) StudentRepository {
 
 func (r *studentRepository) GetAllStudents(ctx context.Context) ([]models.Student, error) {
     query := `
-        SELECT s.id, s.user_id, s.student_id, u.full_name, s.program_study, s.academic_year, s.advisor_id, s.created_at
+        SELECT s.id, s.user_id, s.student_id, u.full_name, COALESCE(ps.name, s.program_study), s.program_study_id, s.academic_year, s.advisor_id, s.created_at
         FROM students s
         JOIN users u ON s.user_id = u.id
+        LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+        WHERE $1::uuid IS NULL OR s.tenant_id = $1
         ORDER BY s.created_at DESC
     `
-    rows, err := r.pg.QueryContext(ctx, query)
+    rows, err := r.pg.QueryContext(ctx, query, tenantArg(ctx))
     if err != nil {
         return nil, err
     }
@@ -46,6 +60,7 @@ func (r *studentRepository) GetAllStudents(ctx context.Context) ([]models.Studen
             &s.StudentID, 
             &s.FullName, 
             &s.ProgramStudy,
+            &s.ProgramStudyID,
             &s.AcademicYear, 
             &s.AdvisorID, 
             &s.CreatedAt,
@@ -61,18 +76,20 @@ func (r *studentRepository) GetAllStudents(ctx context.Context) ([]models.Studen
 func (r *studentRepository) GetStudentByID(ctx context.Context, id uuid.UUID) (*models.Student, error) {
     var s models.Student
     query := `
-        SELECT s.id, s.user_id, s.student_id, s.program_study, s.academic_year, s.advisor_id, s.created_at, u.full_name
+        SELECT s.id, s.user_id, s.student_id, COALESCE(ps.name, s.program_study), s.program_study_id, s.academic_year, s.advisor_id, s.created_at, u.full_name
         FROM students s
         JOIN users u ON s.user_id = u.id
-        WHERE s.id = $1
+        LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+        WHERE s.id = $1 AND ($2::uuid IS NULL OR s.tenant_id = $2)
     `
 
     var advisorID sql.NullString 
 
-    err := r.pg.QueryRowContext(ctx, query, id).Scan(
+    err := r.pg.QueryRowContext(ctx, query, id, tenantArg(ctx)).Scan(
         &s.ID, &s.UserID,
         &s.StudentID,
         &s.ProgramStudy,
+        &s.ProgramStudyID,
         &s.AcademicYear,
         &advisorID, 
         &s.CreatedAt,
@@ -80,7 +97,7 @@ func (r *studentRepository) GetStudentByID(ctx context.Context, id uuid.UUID) (*
     )
 
     if err == sql.ErrNoRows {
-        return nil, errors.New("student not found")
+        return nil, ErrStudentNotFound
     } else if err != nil {
         return nil, err
     }
@@ -96,10 +113,203 @@ func (r *studentRepository) GetStudentByID(ctx context.Context, id uuid.UUID) (*
     return &s, nil
 }
 
-func (r *studentRepository) UpdateAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID) error {
-    query := `UPDATE students SET advisor_id=$1 WHERE id=$2`
-    _, err := r.pg.ExecContext(ctx, query, lecturerID, studentID)
-    return err
+// moveAdvisor makes lecturerID the advisor of the student, closing the open
+// assignment and opening a new one. It reports whether the advisor changed and
+// how many submitted achievements of the student wait for verification.
+func moveAdvisor(ctx context.Context, tx *sql.Tx, studentID, lecturerID uuid.UUID, assignedBy *uuid.UUID) (bool, int, error) {
+    var current uuid.NullUUID
+    var tenantID uuid.UUID
+    err := tx.QueryRowContext(ctx, `
+        SELECT advisor_id, tenant_id FROM students
+        WHERE id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
+        FOR UPDATE`, studentID, tenantArg(ctx)).Scan(&current, &tenantID)
+    if err == sql.ErrNoRows {
+        return false, 0, ErrStudentNotFound
+    }
+    if err != nil {
+        return false, 0, err
+    }
+
+    // the advisor has to be an active lecturer of the student's tenant
+    var active bool
+    err = tx.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM lecturers l JOIN users u ON u.id = l.user_id
+            WHERE l.id = $1 AND l.tenant_id = $2 AND u.is_active
+        )`, lecturerID, tenantID).Scan(&active)
+    if err != nil {
+        return false, 0, err
+    }
+    if !active {
+        return false, 0, ErrLecturerNotActive
+    }
+
+    if current.Valid && current.UUID == lecturerID {
+        return false, 0, nil
+    }
+
+    if _, err = tx.ExecContext(ctx, `
+        UPDATE advisor_assignments SET effective_to = NOW()
+        WHERE student_id = $1 AND effective_to IS NULL`, studentID); err != nil {
+        return false, 0, err
+    }
+    if _, err = tx.ExecContext(ctx, `
+        INSERT INTO advisor_assignments (student_id, lecturer_id, tenant_id, assigned_by, effective_from)
+        VALUES ($1, $2, $3, $4, NOW())`, studentID, lecturerID, tenantID, assignedBy); err != nil {
+        return false, 0, err
+    }
+    // verifiers are looked up through advisor_id, so pending submissions follow
+    if _, err = tx.ExecContext(ctx, `UPDATE students SET advisor_id = $1 WHERE id = $2`, lecturerID, studentID); err != nil {
+        return false, 0, err
+    }
+
+    var pending int
+    err = tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM achievement_references
+        WHERE student_id = $1 AND status = 'submitted'`, studentID).Scan(&pending)
+    return true, pending, err
+}
+
+type moveFunc func(studentID, lecturerID uuid.UUID) error
+
+// reassign runs fn in one transaction, handing it a move that records each
+// advisor change, and tallies the moves
+func (r *studentRepository) reassign(ctx context.Context, assignedBy *uuid.UUID, fn func(tx *sql.Tx, move moveFunc) error) (*models.ReassignResult, error) {
+    tx, err := r.pg.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    result := &models.ReassignResult{}
+    move := func(studentID, lecturerID uuid.UUID) error {
+        changed, pending, err := moveAdvisor(ctx, tx, studentID, lecturerID, assignedBy)
+        if err != nil {
+            return err
+        }
+        if !changed {
+            result.Unchanged++
+            return nil
+        }
+        result.Reassigned++
+        result.PendingSubmissions += pending
+        return nil
+    }
+    if err := fn(tx, move); err != nil {
+        return nil, err
+    }
+    if err := tx.Commit(); err != nil {
+        return nil, err
+    }
+    return result, nil
+}
+
+func (r *studentRepository) UpdateAdvisor(ctx context.Context, studentID, lecturerID uuid.UUID, assignedBy *uuid.UUID) (*models.ReassignResult, error) {
+    return r.reassign(ctx, assignedBy, func(tx *sql.Tx, move moveFunc) error {
+        return move(studentID, lecturerID)
+    })
+}
+
+// ReassignAdvisors applies all changes or none; errors name the student of the
+// change that failed
+func (r *studentRepository) ReassignAdvisors(ctx context.Context, changes []models.AdvisorChange, assignedBy *uuid.UUID, scope *models.CoordinatorScope) (*models.ReassignResult, error) {
+    return r.reassign(ctx, assignedBy, func(tx *sql.Tx, move moveFunc) error {
+        for _, ch := range changes {
+            var studentID uuid.UUID
+            var programStudyID *uuid.UUID
+            err := tx.QueryRowContext(ctx, `
+                SELECT id, program_study_id FROM students
+                WHERE (id::text = $1 OR student_id = $1) AND ($2::uuid IS NULL OR tenant_id = $2)`,
+                ch.Student, tenantArg(ctx)).Scan(&studentID, &programStudyID)
+            if err == sql.ErrNoRows {
+                err = ErrStudentNotFound
+            }
+            if err == nil && scope != nil && !scope.Covers(programStudyID) {
+                err = ErrStudentOutOfScope
+            }
+            if err != nil {
+                return fmt.Errorf("%s: %w", ch.Student, err)
+            }
+
+            var lecturerID uuid.UUID
+            err = tx.QueryRowContext(ctx, `
+                SELECT id FROM lecturers
+                WHERE (id::text = $1 OR lecturer_id = $1) AND ($2::uuid IS NULL OR tenant_id = $2)`,
+                ch.Lecturer, tenantArg(ctx)).Scan(&lecturerID)
+            if err == sql.ErrNoRows {
+                err = ErrLecturerNotActive
+            }
+            if err == nil {
+                err = move(studentID, lecturerID)
+            }
+            if err != nil {
+                return fmt.Errorf("%s: %w", ch.Student, err)
+            }
+        }
+        return nil
+    })
+}
+
+// TransferAdvisees moves every advisee of one lecturer to another. Coordinators
+// only move the advisees in their scope.
+func (r *studentRepository) TransferAdvisees(ctx context.Context, fromLecturerID, toLecturerID uuid.UUID, assignedBy *uuid.UUID, scope *models.CoordinatorScope) (*models.ReassignResult, error) {
+    return r.reassign(ctx, assignedBy, func(tx *sql.Tx, move moveFunc) error {
+        rows, err := tx.QueryContext(ctx, `
+            SELECT id, program_study_id FROM students
+            WHERE advisor_id = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
+            ORDER BY id`, fromLecturerID, tenantArg(ctx))
+        if err != nil {
+            return err
+        }
+        var ids []uuid.UUID
+        for rows.Next() {
+            var id uuid.UUID
+            var programStudyID *uuid.UUID
+            if err := rows.Scan(&id, &programStudyID); err != nil {
+                rows.Close()
+                return err
+            }
+            if scope == nil || scope.Covers(programStudyID) {
+                ids = append(ids, id)
+            }
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return err
+        }
+
+        for _, id := range ids {
+            if err := move(id, toLecturerID); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// GetAdvisorHistory lists the advisors of a student, the current one first
+func (r *studentRepository) GetAdvisorHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorAssignment, error) {
+    rows, err := r.pg.QueryContext(ctx, `
+        SELECT a.id, a.student_id, a.lecturer_id, u.full_name, a.assigned_by, a.effective_from, a.effective_to
+        FROM advisor_assignments a
+        JOIN lecturers l ON l.id = a.lecturer_id
+        JOIN users u ON u.id = l.user_id
+        WHERE a.student_id = $1 AND ($2::uuid IS NULL OR a.tenant_id = $2)
+        ORDER BY a.effective_from DESC`, studentID, tenantArg(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.AdvisorAssignment, 0)
+    for rows.Next() {
+        var a models.AdvisorAssignment
+        if err := rows.Scan(&a.ID, &a.StudentID, &a.LecturerID, &a.LecturerName, &a.AssignedBy, &a.EffectiveFrom, &a.EffectiveTo); err != nil {
+            return nil, err
+        }
+        list = append(list, a)
+    }
+    return list, rows.Err()
 }
 
 func (r *studentRepository) GetStudentsByIDs(ctx context.Context, ids []string) ([]models.StudentWithUser, error) {
@@ -108,13 +318,14 @@ func (r *studentRepository) GetStudentsByIDs(ctx context.Context, ids []string)
     }
 
     query := `
-        SELECT s.id, u.full_name, s.program_study
+        SELECT s.id, s.student_id, u.full_name, COALESCE(ps.name, s.program_study), s.program_study_id, s.academic_year
         FROM students s
         JOIN users u ON s.user_id = u.id
-        WHERE s.id::text = ANY($1)
+        LEFT JOIN program_studies ps ON ps.id = s.program_study_id
+        WHERE s.id::text = ANY($1) AND ($2::uuid IS NULL OR s.tenant_id = $2)
     `
 
-    rows, err := r.pg.QueryContext(ctx, query, pq.Array(ids))
+    rows, err := r.pg.QueryContext(ctx, query, pq.Array(ids), tenantArg(ctx))
     if err != nil {
         return nil, err
     }
@@ -125,8 +336,11 @@ func (r *studentRepository) GetStudentsByIDs(ctx context.Context, ids []string)
         var data models.StudentWithUser
         if err := rows.Scan(
             &data.ID, 
+            &data.StudentID,
             &data.FullName, 
             &data.ProgramStudy,
+            &data.ProgramStudyID,
+            &data.AcademicYear,
             ); err != nil {
             return nil, err
         }
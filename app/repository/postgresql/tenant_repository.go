@@ -0,0 +1,66 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    "StudenAchievementReportingSystem/utils"
+    "github.com/google/uuid"
+)
+
+// tenantArg is the tenant ctx is scoped to as a query argument, NULL for callers
+// working across tenants. Queries guard with ($n::uuid IS NULL OR tenant_id = $n).
+func tenantArg(ctx context.Context) uuid.NullUUID {
+    id, ok := utils.TenantFromContext(ctx)
+    return uuid.NullUUID{UUID: id, Valid: ok}
+}
+
+// newRowTenant is the tenant new top-level rows (users, faculties) are created
+// in: the caller's, or the default tenant for super admins that did not pick one
+func newRowTenant(ctx context.Context) uuid.UUID {
+    if id, ok := utils.TenantFromContext(ctx); ok {
+        return id
+    }
+    return models.DefaultTenantID
+}
+
+type TenantRepository interface {
+    GetAll(ctx context.Context) ([]models.Tenant, error)
+    Create(ctx context.Context, t models.Tenant) (uuid.UUID, error)
+}
+
+type tenantRepository struct {
+    db *sql.DB
+}
+
+func NewTenantRepository(db *sql.DB) TenantRepository {
+    return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) GetAll(ctx context.Context) ([]models.Tenant, error) {
+    rows, err := r.db.QueryContext(ctx, `SELECT id, code, name, created_at, updated_at FROM tenants ORDER BY code`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    list := make([]models.Tenant, 0)
+    for rows.Next() {
+        var t models.Tenant
+        if err := rows.Scan(&t.ID, &t.Code, &t.Name, &t.CreatedAt, &t.UpdatedAt); err != nil {
+            return nil, err
+        }
+        list = append(list, t)
+    }
+    return list, rows.Err()
+}
+
+func (r *tenantRepository) Create(ctx context.Context, t models.Tenant) (uuid.UUID, error) {
+    var id uuid.UUID
+    err := r.db.QueryRowContext(ctx, `
+        INSERT INTO tenants (code, name, created_at, updated_at)
+        VALUES ($1, $2, NOW(), NOW())
+        RETURNING id`, t.Code, t.Name).Scan(&id)
+    return id, orgError(err)
+}
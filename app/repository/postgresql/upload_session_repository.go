@@ -0,0 +1,116 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "time"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    "github.com/google/uuid"
+)
+
+type UploadSessionRepository interface {
+    Create(ctx context.Context, u *models.UploadSession) error
+    GetByID(ctx context.Context, id uuid.UUID) (*models.UploadSession, error)
+    CountByUser(ctx context.Context, userID uuid.UUID, now time.Time) (int, error)
+    Advance(ctx context.Context, id uuid.UUID, from, to int64, expiresAt time.Time, write func() error) error
+    Delete(ctx context.Context, id uuid.UUID) error
+    DeleteExpired(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+}
+
+type uploadSessionRepository struct {
+    db *sql.DB
+}
+
+func NewUploadSessionRepository(db *sql.DB) UploadSessionRepository {
+    return &uploadSessionRepository{db: db}
+}
+
+// Create fills in the generated ID and creation time
+func (r *uploadSessionRepository) Create(ctx context.Context, u *models.UploadSession) error {
+    return r.db.QueryRowContext(ctx, `
+        INSERT INTO upload_sessions (achievement_id, user_id, file_name, caption, kind, upload_length, upload_offset, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, 0, $7)
+        RETURNING id, created_at`,
+        u.AchievementID, u.UserID, u.FileName, u.Caption, u.Kind, u.Length, u.ExpiresAt).
+        Scan(&u.ID, &u.CreatedAt)
+}
+
+func (r *uploadSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.UploadSession, error) {
+    var u models.UploadSession
+    err := r.db.QueryRowContext(ctx, `
+        SELECT id, achievement_id, user_id, file_name, caption, kind, upload_length, upload_offset, created_at, expires_at
+        FROM upload_sessions WHERE id = $1`, id).
+        Scan(&u.ID, &u.AchievementID, &u.UserID, &u.FileName, &u.Caption, &u.Kind, &u.Length, &u.Offset, &u.CreatedAt, &u.ExpiresAt)
+    if err != nil {
+        return nil, err
+    }
+    return &u, nil
+}
+
+// CountByUser counts the uploads of a user that have not expired yet
+func (r *uploadSessionRepository) CountByUser(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+    var n int
+    err := r.db.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM upload_sessions WHERE user_id = $1 AND expires_at > $2`, userID, now).Scan(&n)
+    return n, err
+}
+
+// Advance calls write to store the chunk from..to, then moves the offset and
+// extends the expiry. The session row stays locked meanwhile, so a second
+// client sending the same chunk waits and then gets sql.ErrNoRows instead of
+// writing over the staged bytes. Nothing is updated when write fails.
+func (r *uploadSessionRepository) Advance(ctx context.Context, id uuid.UUID, from, to int64, expiresAt time.Time, write func() error) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var offset int64
+    err = tx.QueryRowContext(ctx, `
+        SELECT upload_offset FROM upload_sessions WHERE id = $1 FOR UPDATE`, id).Scan(&offset)
+    if err != nil {
+        return err
+    }
+    if offset != from {
+        return sql.ErrNoRows
+    }
+
+    if err := write(); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `
+        UPDATE upload_sessions SET upload_offset = $2, expires_at = $3
+        WHERE id = $1`, id, to, expiresAt); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// Delete succeeds when the session is already gone
+func (r *uploadSessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+    _, err := r.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE id = $1`, id)
+    return err
+}
+
+// DeleteExpired removes abandoned uploads and returns their IDs so the staged
+// bytes can be removed as well
+func (r *uploadSessionRepository) DeleteExpired(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        DELETE FROM upload_sessions WHERE expires_at <= $1 RETURNING id`, now)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    ids := make([]uuid.UUID, 0)
+    for rows.Next() {
+        var id uuid.UUID
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
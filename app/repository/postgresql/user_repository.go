@@ -7,6 +7,8 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserRepository resolves the user behind a login or token, before the tenant
+// of the request is known, so its lookups are not scoped by tenant
 type UserRepository interface {
     GetByUsername(username string) (*models.User, string, error)
     GetPermissionsByRoleID(roleID uuid.UUID) ([]string, error)
@@ -28,7 +30,7 @@ func (r *userRepository) GetByUsername(username string) (*models.User, string, e
 	query := `
 		SELECT 
 			u.id, u.username, u.email, u.password_hash, 
-			u.full_name, u.role_id, u.is_active, 
+			u.full_name, u.role_id, u.tenant_id, u.is_active, 
 			r.name
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
@@ -44,6 +46,7 @@ func (r *userRepository) GetByUsername(username string) (*models.User, string, e
 		&user.PasswordHash,
 		&user.FullName,
 		&user.RoleID,
+		&user.TenantID,
 		&user.IsActive,
 		&roleName,    
 	)
@@ -88,7 +91,7 @@ func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	var user models.User
 
 	query := `
-		SELECT id, username, email, full_name, role_id, is_active
+		SELECT id, username, email, full_name, role_id, tenant_id, is_active
 		FROM users
 		WHERE id = $1
 	`
@@ -101,6 +104,7 @@ func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
 		&user.Email,
 		&user.FullName,
 		&user.RoleID,
+		&user.TenantID,
 		&user.IsActive,
 	)
 
@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is the largest chunk sent per INSTREAM frame
+const clamdChunkSize = 64 * 1024
+
+type clamAV struct {
+	network string
+	addr    string
+	timeout time.Duration
+}
+
+// NewClamAV talks to a clamd daemon at addr, "host:port" for TCP or
+// "unix:/path/to/clamd.sock", streaming files with the INSTREAM command. A
+// scan that takes longer than timeout fails.
+func NewClamAV(addr string, timeout time.Duration) Scanner {
+	s := &clamAV{network: "tcp", addr: addr, timeout: timeout}
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		s.network, s.addr = "unix", path
+	}
+	return s
+}
+
+func (s *clamAV) Scan(ctx context.Context, r io.Reader) (Verdict, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.addr)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	// the z prefix makes clamd read and answer NUL-terminated lines
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return Verdict{}, err
+	}
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return Verdict{}, err
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return Verdict{}, err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return Verdict{}, readErr
+		}
+	}
+	// a zero-length chunk ends the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return Verdict{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return Verdict{}, err
+	}
+	return parseClamdReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamdReply reads "stream: OK", "stream: <signature> FOUND" or an
+// error such as "INSTREAM size limit exceeded. ERROR"
+func parseClamdReply(reply string) (Verdict, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return Verdict{Clean: true}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return Verdict{Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	}
+	return Verdict{}, fmt.Errorf("clamd: %s", reply)
+}
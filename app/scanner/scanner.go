@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"StudenAchievementReportingSystem/config"
+)
+
+// Verdict is the outcome of scanning one file
+type Verdict struct {
+	Clean     bool
+	Signature string // name of the detected malware when not clean
+}
+
+// Scanner checks file contents for malware. An error means the file could not
+// be scanned and should be tried again later, not that it is infected.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Verdict, error)
+}
+
+// Func adapts a function to Scanner
+type Func func(ctx context.Context, r io.Reader) (Verdict, error)
+
+func (f Func) Scan(ctx context.Context, r io.Reader) (Verdict, error) {
+	return f(ctx, r)
+}
+
+// NewNoop reports every file as clean, for deployments without a scanner
+func NewNoop() Scanner {
+	return Func(func(ctx context.Context, r io.Reader) (Verdict, error) {
+		return Verdict{Clean: true}, nil
+	})
+}
+
+// FromConfig builds the scanner selected by SCANNER_DRIVER
+func FromConfig(cfg config.ScannerConfig) (Scanner, error) {
+	switch cfg.Driver {
+	case "", "none":
+		return NewNoop(), nil
+	case "clamav":
+		return NewClamAV(cfg.ClamAVAddr, cfg.Timeout), nil
+	}
+	return nil, fmt.Errorf("scanner: unknown driver %q", cfg.Driver)
+}
@@ -0,0 +1,836 @@
+package service
+
+import (
+    "context"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/base64"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "mime"
+    "mime/multipart"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+    repoMongo "StudenAchievementReportingSystem/app/repository/mongodb"
+    repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/app/preview"
+    "StudenAchievementReportingSystem/app/storage"
+    "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
+    "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
+)
+
+// signedAttachmentPath is the public route of signed attachment links
+const signedAttachmentPath = "/api/v1/files/achievements/%s/attachments/%s"
+
+// resolveAttachments fills in signed download URLs for files that passed the
+// malware scan, and for their previews once made. They are not stored because
+// they expire, and are only handed to callers that passed the read check of
+// the achievement.
+func (s *AchievementService) resolveAttachments(achievementID uuid.UUID, attachments []modelMongo.Attachment) []modelMongo.Attachment {
+    now := time.Now()
+    resolved := make([]modelMongo.Attachment, len(attachments))
+    for i, att := range attachments {
+        resolved[i] = att
+        if att.ID == "" || !att.Downloadable() {
+            continue
+        }
+        path := fmt.Sprintf(signedAttachmentPath, achievementID, url.PathEscape(att.ID))
+        resolved[i].FileURL = utils.SignURL(path, now)
+        if att.Preview != nil && att.Preview.Status == modelMongo.PreviewReady {
+            resolved[i].PreviewURL = utils.SignURL(path+"/preview", now)
+        }
+    }
+    return resolved
+}
+
+// removeStoredFiles releases the files of attachments that were removed or
+// never made it into a document. Infected files were released by the scan.
+func (s *AchievementService) removeStoredFiles(ctx context.Context, attachments []modelMongo.Attachment) {
+    for _, att := range attachments {
+        if att.StorageKey == "" || (att.Scan != nil && att.Scan.Status == modelMongo.ScanInfected) {
+            continue
+        }
+        s.releaseFile(ctx, att)
+    }
+}
+
+// releaseFile drops an attachment's reference to its stored file, which is
+// deleted with the last reference. Files stored before deduplication belong
+// to a single attachment and are deleted right away.
+func (s *AchievementService) releaseFile(ctx context.Context, att modelMongo.Attachment) {
+    remove := func(key string) error {
+        err := s.files.Delete(ctx, key)
+        if err != nil {
+            log.Printf("attachments: failed to remove %s: %v", key, err)
+        }
+        if err := s.files.Delete(ctx, previewKey(key)); err != nil {
+            log.Printf("attachments: failed to remove %s: %v", previewKey(key), err)
+        }
+        return err
+    }
+    if !strings.HasPrefix(att.StorageKey, blobKeyPrefix) || att.Checksum == "" {
+        remove(att.StorageKey)
+        return
+    }
+    if err := s.blobs.Release(ctx, att.Checksum, remove); err != nil {
+        log.Printf("attachments: failed to release %s: %v", att.StorageKey, err)
+    }
+}
+
+// blobKeyPrefix marks storage keys derived from the content of the file
+const blobKeyPrefix = "sha256/"
+
+// blobKey names a stored file after its SHA-256, so identical uploads share
+// one file. Keys carry the extension of the detected type so downloads keep
+// their type.
+func blobKey(checksum string, fileType *utils.FileType) string {
+    return blobKeyPrefix + checksum[:2] + "/" + checksum + fileType.Extensions[0]
+}
+
+// errChecksumMismatch means a stored file no longer has the content that was
+// uploaded
+var errChecksumMismatch = errors.New("stored file does not match its checksum")
+
+// openVerified opens a stored file for sending. A file with a checksum is
+// read once, into a temporary file while it is hashed, and only handed out
+// when it matches, so the bytes sent are the bytes checked. Closing the
+// result removes the temporary file.
+func (s *AchievementService) openVerified(ctx context.Context, att *modelMongo.Attachment) (io.ReadCloser, error) {
+    body, err := s.files.Open(ctx, att.StorageKey)
+    if err != nil || att.Checksum == "" {
+        return body, err
+    }
+    defer body.Close()
+
+    spool, err := os.CreateTemp("", "attachment-*")
+    if err != nil {
+        return nil, err
+    }
+    spooled := &spooledFile{spool}
+
+    hash := sha256.New()
+    if _, err := io.Copy(io.MultiWriter(spool, hash), body); err != nil {
+        spooled.Close()
+        return nil, err
+    }
+    if hex.EncodeToString(hash.Sum(nil)) != att.Checksum {
+        spooled.Close()
+        return nil, errChecksumMismatch
+    }
+    if _, err := spool.Seek(0, io.SeekStart); err != nil {
+        spooled.Close()
+        return nil, err
+    }
+    return spooled, nil
+}
+
+// spooledFile is a temporary file that is deleted when closed
+type spooledFile struct {
+    *os.File
+}
+
+func (f *spooledFile) Close() error {
+    err := f.File.Close()
+    os.Remove(f.Name())
+    return err
+}
+
+// inlineTypes may be shown in the browser; anything else is served as a
+// download so uploaded HTML or SVG cannot run in the API's origin
+var inlineTypes = map[string]bool{
+    "application/pdf": true,
+    "image/png":       true,
+    "image/jpeg":      true,
+    "image/gif":       true,
+    "image/webp":      true,
+}
+
+// findAttachment loads the attachment of an achievement by its ID
+func (s *AchievementService) findAttachment(ctx context.Context, ref modelPg.AchievementReference, attachmentID string) (*modelMongo.Attachment, error) {
+    doc, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID)
+    if err != nil {
+        return nil, err
+    }
+    for i := range doc.Attachments {
+        if doc.Attachments[i].ID == attachmentID {
+            return &doc.Attachments[i], nil
+        }
+    }
+    return nil, storage.ErrNotFound
+}
+
+// sendAttachment sends a file after checking it against its checksum. Files
+// without one are redirected to the backend when it offers direct links. Files
+// are held back until they are scanned.
+func (s *AchievementService) sendAttachment(c *fiber.Ctx, att *modelMongo.Attachment) error {
+    ctx := c.Context()
+    if !att.Downloadable() {
+        if att.MissingAt != nil {
+            return c.Status(410).JSON(fiber.Map{"error": "File is missing from storage"})
+        }
+        if att.Scan.Status == modelMongo.ScanInfected {
+            return c.Status(410).JSON(fiber.Map{"error": "File was removed by the malware scan"})
+        }
+        return c.Status(409).JSON(fiber.Map{"error": "File is still being scanned for malware"})
+    }
+
+    // files from before checksums were taken cannot be verified, so only they
+    // are handed to a direct link; the rest are checked and sent from here
+    if att.Checksum == "" {
+        direct, err := s.files.URL(ctx, att.StorageKey)
+        if err == nil {
+            return c.Redirect(direct, fiber.StatusFound)
+        }
+        if !errors.Is(err, storage.ErrNoDirectURL) {
+            log.Printf("attachments: failed to link %s: %v", att.StorageKey, err)
+            return c.Status(500).JSON(fiber.Map{"error": "Failed to read file"})
+        }
+    }
+
+    body, err := s.openVerified(ctx, att)
+    if errors.Is(err, storage.ErrNotFound) {
+        return c.Status(404).JSON(fiber.Map{"error": "File not found"})
+    }
+    if errors.Is(err, errChecksumMismatch) {
+        log.Printf("attachments: integrity check of %s failed: %v", att.StorageKey, err)
+        return c.Status(500).JSON(fiber.Map{"error": "File failed its integrity check"})
+    }
+    if err != nil {
+        log.Printf("attachments: failed to open %s: %v", att.StorageKey, err)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to read file"})
+    }
+    if att.Checksum != "" {
+        sum, _ := hex.DecodeString(att.Checksum)
+        c.Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum)+":")
+    }
+
+    contentType := att.FileType
+    if contentType == "" {
+        contentType = fiber.MIMEOctetStream
+    }
+    disposition := "attachment"
+    if inlineTypes[contentType] {
+        disposition = "inline"
+    }
+    c.Set(fiber.HeaderContentType, contentType)
+    c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": att.FileName}))
+    c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+    c.Set(fiber.HeaderCacheControl, "private, no-store")
+    return c.SendStream(body)
+}
+
+// readableAttachment loads the attachment addressed by the request after
+// applying the same access rules as the achievement detail. A non-zero status
+// is the response to send instead.
+func (s *AchievementService) readableAttachment(c *fiber.Ctx) (*modelMongo.Attachment, int, string) {
+    ctx := c.Context()
+    achievementID, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return nil, 400, "Invalid achievement ID"
+    }
+
+    userID, err := getUserIDFromToken(c)
+    if err != nil {
+        return nil, 401, "Unauthorized"
+    }
+
+    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
+    if err != nil {
+        return nil, 404, "Achievement not found"
+    }
+
+    if _, status, msg := s.checkReadAccess(c, userID, ref); status != 0 {
+        return nil, status, msg
+    }
+
+    att, err := s.findAttachment(ctx, ref, c.Params("attachmentId"))
+    if err != nil {
+        return nil, 404, "Attachment not found"
+    }
+    return att, 0, ""
+}
+
+// signedAttachment loads the attachment addressed by a signed link, which
+// authorizes the request by itself. A non-zero status is the response to send
+// instead.
+func (s *AchievementService) signedAttachment(c *fiber.Ctx) (*modelMongo.Attachment, int, string) {
+    ctx := c.Context()
+    if !utils.VerifySignedURL(c.Path(), c.Query("expires"), c.Query("signature"), time.Now()) {
+        return nil, 403, "Invalid or expired link"
+    }
+    // the signature stands in for the caller, whose tenant the link does not carry
+    c.Locals(utils.TenantKey, utils.AllTenants)
+
+    achievementID, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return nil, 400, "Invalid achievement ID"
+    }
+
+    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
+    if err != nil {
+        return nil, 404, "Achievement not found"
+    }
+
+    att, err := s.findAttachment(ctx, ref, c.Params("attachmentId"))
+    if err != nil {
+        return nil, 404, "Attachment not found"
+    }
+    return att, 0, ""
+}
+
+// DownloadAttachment godoc
+// @Summary Download Attachment
+// @Description Download an attachment, with the same access rules as the achievement detail
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path string true "Achievement ID (UUID)"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a short-lived storage URL, for files without a checksum"
+// @Failure 400,401,403,404,409,410,500 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId} [get]
+func (s *AchievementService) DownloadAttachment(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+    return fiber.ErrForbidden
+    }
+
+    att, status, msg := s.readableAttachment(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    return s.sendAttachment(c, att)
+}
+
+// DownloadSignedAttachment godoc
+// @Summary Download Attachment by Signed Link
+// @Description Download an attachment with the fileUrl returned by the API. The link needs no token and expires after a few minutes.
+// @Tags Achievements
+// @Produce octet-stream
+// @Param id path string true "Achievement ID (UUID)"
+// @Param attachmentId path string true "Attachment ID"
+// @Param expires query int true "Expiry (Unix time)"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a short-lived storage URL, for files without a checksum"
+// @Failure 400,403,404,409,410,500 {object} map[string]interface{}
+// @Router /files/achievements/{id}/attachments/{attachmentId} [get]
+func (s *AchievementService) DownloadSignedAttachment(c *fiber.Ctx) error {
+    att, status, msg := s.signedAttachment(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    return s.sendAttachment(c, att)
+}
+
+// checkUpload detects the type of an upload from its leading bytes, which it
+// returns so they can be stored with the rest, and applies the allowlist and
+// size limit of that type. The name and Content-Type sent by the client are
+// not trusted; the extension only has to agree with the content.
+func (s *AchievementService) checkUpload(c *fiber.Ctx, filename string, size int64, content io.Reader) (*utils.FileType, []byte, int, string) {
+    head := make([]byte, utils.SniffLength)
+    n, err := io.ReadFull(content, head)
+    if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+        return nil, nil, 400, "Failed to read uploaded file"
+    }
+    head = head[:n]
+
+    fileType := utils.DetectFileType(head)
+    if fileType == nil {
+        return nil, nil, 415, "Unsupported file type"
+    }
+    if !fileType.HasExtension(filepath.Ext(filename)) {
+        return nil, nil, 400, fmt.Sprintf("File extension does not match its content (%s)", fileType.MimeType)
+    }
+
+    allowed, err := s.uploadTypes.GetByCode(c.Context(), fileType.Code)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, nil, 415, fmt.Sprintf("Uploading %s files is not allowed", fileType.MimeType)
+    }
+    if err != nil {
+        return nil, nil, 500, "Failed to check attachment type"
+    }
+    if size > allowed.MaxSizeBytes {
+        return nil, nil, 413, fmt.Sprintf("File exceeds the limit of %d bytes for %s", allowed.MaxSizeBytes, fileType.MimeType)
+    }
+    return fileType, head, 0, ""
+}
+
+// maxFilesPerUpload bounds the files of one upload request
+const maxFilesPerUpload = 10
+
+// draftForAttachments loads the achievement whose attachments the current
+// student wants to change; that is only allowed for their own drafts. A
+// non-zero status is the response to send instead.
+func (s *AchievementService) draftForAttachments(c *fiber.Ctx) (modelPg.AchievementReference, int, string) {
+    ctx := c.Context()
+    achievementID, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return modelPg.AchievementReference{}, 400, "Invalid ID"
+    }
+
+    userID, err := getUserIDFromToken(c)
+    if err != nil {
+        return modelPg.AchievementReference{}, 401, "Unauthorized"
+    }
+
+    studentID, err := s.pgRepo.GetStudentByUserID(ctx, userID)
+    if err != nil {
+        return modelPg.AchievementReference{}, 404, "Student profile not found"
+    }
+
+    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
+    if err != nil {
+        return modelPg.AchievementReference{}, 404, "Achievement not found"
+    }
+
+    if ref.StudentID != studentID {
+        return modelPg.AchievementReference{}, 403, "Forbidden"
+    }
+    if ref.Status != "draft" {
+        return modelPg.AchievementReference{}, 400, "Cannot change files of submitted/verified achievements"
+    }
+    return ref, 0, ""
+}
+
+// storeUpload checks an uploaded file and stores it, or adds a reference to
+// the stored file with the same content. The returned attachment still needs
+// an ID and is quarantined until the malware scan has run.
+func (s *AchievementService) storeUpload(c *fiber.Ctx, file *multipart.FileHeader) (modelMongo.Attachment, int, string) {
+    content, err := file.Open()
+    if err != nil {
+        return modelMongo.Attachment{}, 400, "Failed to read uploaded file"
+    }
+    defer content.Close()
+
+    return s.storeFile(c, file.Filename, file.Size, content)
+}
+
+// storeFile is storeUpload for a file read from content. The content is read
+// twice: once to check and hash it, then to store it under its hash.
+func (s *AchievementService) storeFile(c *fiber.Ctx, filename string, size int64, content io.ReadSeeker) (modelMongo.Attachment, int, string) {
+    ctx := c.Context()
+    hash := sha256.New()
+    fileType, _, status, msg := s.checkUpload(c, filename, size, io.TeeReader(content, hash))
+    if status != 0 {
+        return modelMongo.Attachment{}, status, filename + ": " + msg
+    }
+    if _, err := io.Copy(hash, content); err != nil {
+        return modelMongo.Attachment{}, 400, "Failed to read uploaded file"
+    }
+    if _, err := content.Seek(0, io.SeekStart); err != nil {
+        return modelMongo.Attachment{}, 500, "Failed to read uploaded file"
+    }
+    checksum := hex.EncodeToString(hash.Sum(nil))
+
+    // new content is stored before its blob is registered
+    var putErr error
+    blob, err := s.blobs.Acquire(ctx, modelPg.AttachmentBlob{
+        Checksum:    checksum,
+        StorageKey:  blobKey(checksum, fileType),
+        SizeBytes:   size,
+        ContentType: fileType.MimeType,
+    }, func(storageKey string) error {
+        putErr = s.files.Put(ctx, storageKey, content, size, fileType.MimeType)
+        return putErr
+    })
+    if putErr != nil {
+        log.Printf("attachments: failed to store %s: %v", blobKey(checksum, fileType), putErr)
+        return modelMongo.Attachment{}, 500, "Failed to store file"
+    }
+    if err != nil {
+        return modelMongo.Attachment{}, 500, "Failed to register file"
+    }
+
+    attachment := modelMongo.Attachment{
+        FileName:   filename,
+        StorageKey: blob.StorageKey,
+        FileType:   fileType.MimeType,
+        FileSize:   size,
+        Checksum:   checksum,
+        UploadedAt: time.Now(),
+        Scan:       &modelMongo.AttachmentScan{Status: modelMongo.ScanPending},
+    }
+    if preview.Supports(fileType.MimeType) {
+        attachment.Preview = &modelMongo.AttachmentPreview{Status: modelMongo.PreviewPending}
+    }
+    return attachment, 0, ""
+}
+
+// bumpForAttachments makes the ETag held by other clients stale after an
+// attachment change. Attachment changes do not require If-Match; a conflict
+// means someone else already bumped the version.
+func (s *AchievementService) bumpForAttachments(c *fiber.Ctx, ref modelPg.AchievementReference) error {
+    err := s.pgRepo.BumpVersion(c.Context(), ref.ID, ref.Version)
+    if err == nil {
+        c.Set(fiber.HeaderETag, utils.ETag(ref.Version+1))
+        return nil
+    }
+    if errors.Is(err, repoPg.ErrVersionConflict) {
+        return nil
+    }
+    return err
+}
+
+// formValue returns the i-th value of a repeated multipart field, or ""
+func formValue(form *multipart.Form, name string, i int) string {
+    if values := form.Value[name]; i < len(values) {
+        return strings.TrimSpace(values[i])
+    }
+    return ""
+}
+
+// UploadAttachments godoc
+// @Summary Upload Attachments
+// @Description Upload up to 10 files, together at most 10 MB, for an achievement (Draft only), appended after the existing ones. Larger requests are refused with 413; send bigger files as resumable uploads. Repeat `caption` and `kind` in the order of the files to describe them. The type is detected from the content and must be allowed in /attachment-types. Files stay in quarantine until the malware scan finds them clean. Uploads that would exceed the student's or the achievement's storage quota are refused with 413 (see /me/storage).
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Param file formData file true "Files to upload (repeat the field for several files)"
+// @Param caption formData string false "Caption of each file"
+// @Param kind formData string false "Kind of each file: certificate, photo, assignment_letter or other"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,403,404,413,415,500 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments [post]
+func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+
+    ref, status, msg := s.draftForAttachments(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    form, err := c.MultipartForm()
+    if err != nil || len(form.File["file"]) == 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "No file uploaded"})
+    }
+    files := form.File["file"]
+    if len(files) > maxFilesPerUpload {
+        return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("At most %d files can be uploaded at once", maxFilesPerUpload)})
+    }
+
+    var errs []utils.FieldError
+    for i := range files {
+        errs = append(errs, utils.ValidateAttachmentInfo(fmt.Sprintf("files[%d].", i), formValue(form, "caption", i), formValue(form, "kind", i))...)
+    }
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    var total int64
+    for _, file := range files {
+        total += file.Size
+    }
+    if status, msg := s.checkQuota(ctx, ref, total, len(files), 0); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    attachments := make([]modelMongo.Attachment, 0, len(files))
+    for i, file := range files {
+        attachment, status, msg := s.storeUpload(c, file)
+        if status != 0 {
+            // all files are added or none
+            s.removeStoredFiles(ctx, attachments)
+            return c.Status(status).JSON(fiber.Map{"error": msg})
+        }
+        attachment.ID = uuid.New().String()
+        attachment.Caption = formValue(form, "caption", i)
+        attachment.Kind = strings.ToLower(formValue(form, "kind", i))
+        attachments = append(attachments, attachment)
+    }
+
+    err = s.mongoRepo.AddAttachments(ctx, ref.MongoAchievementID, attachments)
+    if err != nil {
+        // the document does not reference the files, so they must not stay behind
+        s.removeStoredFiles(ctx, attachments)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update database info", "details": err.Error()})
+    }
+
+    if err := s.bumpForAttachments(c, ref); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement version"})
+    }
+
+    return c.JSON(fiber.Map{
+        "message": "Files uploaded successfully",
+        "data": s.resolveAttachments(ref.ID, attachments),
+    })
+}
+
+// ReplaceAttachment godoc
+// @Summary Replace Attachment
+// @Description Replace the file of an attachment (Draft only). The attachment keeps its ID and place; caption and kind are kept unless sent. The old file is deleted.
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Param attachmentId path string true "Attachment ID"
+// @Param file formData file true "New file"
+// @Param caption formData string false "Caption"
+// @Param kind formData string false "Kind: certificate, photo, assignment_letter or other"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,403,404,413,415,500 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId} [put]
+func (s *AchievementService) ReplaceAttachment(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+
+    ref, status, msg := s.draftForAttachments(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    old, err := s.findAttachment(ctx, ref, c.Params("attachmentId"))
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+    }
+
+    form, err := c.MultipartForm()
+    if err != nil || len(form.File["file"]) != 1 {
+        return c.Status(400).JSON(fiber.Map{"error": "Exactly one file must be uploaded"})
+    }
+
+    caption, kind := old.Caption, old.Kind
+    if _, ok := form.Value["caption"]; ok {
+        caption = formValue(form, "caption", 0)
+    }
+    if _, ok := form.Value["kind"]; ok {
+        kind = strings.ToLower(formValue(form, "kind", 0))
+    }
+    if errs := utils.ValidateAttachmentInfo("", caption, kind); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if status, msg := s.checkQuota(ctx, ref, form.File["file"][0].Size, 0, old.FileSize); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    attachment, status, msg := s.storeUpload(c, form.File["file"][0])
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    attachment.ID = old.ID
+    attachment.Caption = caption
+    attachment.Kind = kind
+
+    err = s.mongoRepo.ReplaceAttachment(ctx, ref.MongoAchievementID, attachment)
+    if err != nil {
+        s.removeStoredFiles(ctx, []modelMongo.Attachment{attachment})
+        if errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+            return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+        }
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update database info"})
+    }
+    s.removeStoredFiles(ctx, []modelMongo.Attachment{*old})
+
+    if err := s.bumpForAttachments(c, ref); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement version"})
+    }
+
+    return c.JSON(fiber.Map{
+        "message": "File replaced successfully",
+        "data": s.resolveAttachments(ref.ID, []modelMongo.Attachment{attachment})[0],
+    })
+}
+
+// AttachmentInfoRequest changes the description of an attachment; omitted
+// fields are kept
+type AttachmentInfoRequest struct {
+    Caption *string `json:"caption" example:"Sertifikat juara 1"`
+    Kind    *string `json:"kind" example:"certificate"`
+}
+
+// UpdateAttachment godoc
+// @Summary Update Attachment
+// @Description Change the caption or kind of an attachment (Draft only)
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Param attachmentId path string true "Attachment ID"
+// @Param request body AttachmentInfoRequest true "Caption and kind"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId} [patch]
+func (s *AchievementService) UpdateAttachment(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+
+    ref, status, msg := s.draftForAttachments(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    var req AttachmentInfoRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+
+    att, err := s.findAttachment(ctx, ref, c.Params("attachmentId"))
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+    }
+    if req.Caption != nil {
+        att.Caption = strings.TrimSpace(*req.Caption)
+    }
+    if req.Kind != nil {
+        att.Kind = strings.ToLower(strings.TrimSpace(*req.Kind))
+    }
+    if errs := utils.ValidateAttachmentInfo("", att.Caption, att.Kind); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    err = s.mongoRepo.UpdateAttachmentInfo(ctx, ref.MongoAchievementID, att.ID, att.Caption, att.Kind)
+    if errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+        return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update database info"})
+    }
+
+    if err := s.bumpForAttachments(c, ref); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement version"})
+    }
+
+    return c.JSON(fiber.Map{
+        "message": "Attachment updated successfully",
+        "data": s.resolveAttachments(ref.ID, []modelMongo.Attachment{*att})[0],
+    })
+}
+
+// DeleteAttachment godoc
+// @Summary Delete Attachment
+// @Description Remove an attachment and its file (Draft only)
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {object} map[string]string
+// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId} [delete]
+func (s *AchievementService) DeleteAttachment(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+
+    ref, status, msg := s.draftForAttachments(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    att, err := s.findAttachment(ctx, ref, c.Params("attachmentId"))
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+    }
+
+    err = s.mongoRepo.RemoveAttachment(ctx, ref.MongoAchievementID, att.ID)
+    if errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+        return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update database info"})
+    }
+    s.removeStoredFiles(ctx, []modelMongo.Attachment{*att})
+
+    if err := s.bumpForAttachments(c, ref); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement version"})
+    }
+    return c.JSON(fiber.Map{"message": "Attachment deleted successfully"})
+}
+
+// AttachmentOrderRequest lists every attachment ID in the new order
+type AttachmentOrderRequest struct {
+    Order []string `json:"order"`
+}
+
+// ReorderAttachments godoc
+// @Summary Reorder Attachments
+// @Description Set the order of the attachments (Draft only). The list must contain every attachment ID once.
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Param request body AttachmentOrderRequest true "Attachment IDs in order"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,403,404,409,500 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/order [put]
+func (s *AchievementService) ReorderAttachments(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+
+    ref, status, msg := s.draftForAttachments(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    var req AttachmentOrderRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+
+    doc, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement details"})
+    }
+
+    byID := make(map[string]modelMongo.Attachment, len(doc.Attachments))
+    for _, att := range doc.Attachments {
+        byID[att.ID] = att
+    }
+    ordered := make([]modelMongo.Attachment, 0, len(req.Order))
+    for _, id := range req.Order {
+        att, ok := byID[id]
+        if !ok {
+            break
+        }
+        delete(byID, id)
+        ordered = append(ordered, att)
+    }
+    if len(ordered) != len(req.Order) || len(byID) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": []utils.FieldError{
+            {Field: "order", Message: "must list every attachment ID once"},
+        }})
+    }
+
+    err = s.mongoRepo.ReorderAttachments(ctx, ref.MongoAchievementID, req.Order)
+    if errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+        return c.Status(409).JSON(fiber.Map{"error": "Attachments were changed meanwhile; reload and try again"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update database info"})
+    }
+
+    if err := s.bumpForAttachments(c, ref); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement version"})
+    }
+
+    return c.JSON(fiber.Map{
+        "message": "Attachments reordered successfully",
+        "data": s.resolveAttachments(ref.ID, ordered),
+    })
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+    "context"
+
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+    "StudenAchievementReportingSystem/utils"
+
+    "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
+)
+
+// related entities that can be inlined with ?expand=
+var achievementExpansions = []string{"student", "verifier", "attachments"}
+
+var listFields = []string{"id", "status", "submittedAt", "title", "type", "points", "createdAt", "studentId", "periodId", "possibleDuplicate"}
+
+var detailFields = []string{"id", "status", "version", "periodId", "rejectionNote", "details", "createdAt", "modifiedSinceVerification"}
+
+// responseShape is what the caller asked for with ?fields= and ?expand=
+type responseShape struct {
+    fields []string
+    expand map[string]bool
+}
+
+func parseResponseShape(c *fiber.Ctx, allowed []string) (responseShape, []utils.FieldError) {
+    shape := responseShape{
+        fields: utils.SplitList(c.Query("fields")),
+        expand: make(map[string]bool),
+    }
+
+    expand := utils.SplitList(c.Query("expand"))
+    errs := utils.CheckListValues("expand", expand, achievementExpansions)
+    errs = append(errs, utils.CheckListValues("fields", shape.fields, append(append([]string{}, allowed...), achievementExpansions...))...)
+
+    for _, e := range expand {
+        shape.expand[e] = true
+    }
+    return shape, errs
+}
+
+// expandItems inlines the requested related entities into rendered achievements.
+// Students and verifiers are fetched with one query each for the whole page.
+func (s *AchievementService) expandItems(ctx context.Context, items []map[string]interface{}, refs []modelPg.AchievementReference, docs []modelMongo.Achievement, shape responseShape) {
+    var students map[string]modelPg.StudentWithUser
+    if shape.expand["student"] {
+        students = s.loadStudents(ctx, refs)
+    }
+    var verifiers map[uuid.UUID]modelPg.LecturerSummary
+    if shape.expand["verifier"] {
+        verifiers = s.loadVerifiers(ctx, refs)
+    }
+
+    for i, item := range items {
+        ref := refs[i]
+        if shape.expand["student"] {
+            if st, ok := students[ref.StudentID.String()]; ok {
+                item["student"] = st
+            } else {
+                item["student"] = nil
+            }
+        }
+        if shape.expand["verifier"] {
+            item["verifier"] = nil
+            if ref.VerifiedBy != nil {
+                if v, ok := verifiers[*ref.VerifiedBy]; ok {
+                    item["verifier"] = v
+                }
+            }
+        }
+        if shape.expand["attachments"] {
+            attachments := docs[i].Attachments
+            if attachments == nil {
+                attachments = []modelMongo.Attachment{}
+            }
+            item["attachments"] = s.resolveAttachments(ref.ID, attachments)
+        }
+    }
+}
+
+func (s *AchievementService) loadStudents(ctx context.Context, refs []modelPg.AchievementReference) map[string]modelPg.StudentWithUser {
+    result := make(map[string]modelPg.StudentWithUser)
+    if s.student == nil {
+        return result
+    }
+
+    seen := make(map[string]bool)
+    var ids []string
+    for _, r := range refs {
+        id := r.StudentID.String()
+        if !seen[id] {
+            seen[id] = true
+            ids = append(ids, id)
+        }
+    }
+    if len(ids) == 0 {
+        return result
+    }
+
+    students, _ := s.student.GetStudentsByIDs(ctx, ids)
+    for _, st := range students {
+        result[st.ID.String()] = st
+    }
+    return result
+}
+
+// loadVerifiers resolves verified_by, the id of the user who verified or
+// rejected an achievement, to lecturer summaries
+func (s *AchievementService) loadVerifiers(ctx context.Context, refs []modelPg.AchievementReference) map[uuid.UUID]modelPg.LecturerSummary {
+    result := make(map[uuid.UUID]modelPg.LecturerSummary)
+
+    seen := make(map[uuid.UUID]bool)
+    var ids []uuid.UUID
+    for _, r := range refs {
+        if r.VerifiedBy != nil && !seen[*r.VerifiedBy] {
+            seen[*r.VerifiedBy] = true
+            ids = append(ids, *r.VerifiedBy)
+        }
+    }
+    if len(ids) == 0 {
+        return result
+    }
+
+    lecturers, _ := s.lecturer.GetLecturersByUserIDs(ctx, ids)
+    for _, l := range lecturers {
+        result[l.UserID] = l
+    }
+    return result
+}
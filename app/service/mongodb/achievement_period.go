@@ -0,0 +1,182 @@
+package service
+
+import (
+    "context"
+    "log"
+    "time"
+
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+    repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/config"
+
+    "github.com/google/uuid"
+)
+
+// page size of the period assignment job
+const periodAssignBatch = 500
+
+// periodFor returns the academic period of an achievement's event date. Without
+// an event date the fallback (usually the creation time) decides. nil means no
+// period covers that day.
+func (s *AchievementService) periodFor(ctx context.Context, a modelMongo.Achievement, fallback time.Time) *modelPg.AcademicPeriod {
+    if s.periods == nil {
+        return nil
+    }
+    day := a.EventDate()
+    if day.IsZero() {
+        day = fallback
+    }
+    period, err := s.periods.FindByDate(ctx, day)
+    if err != nil {
+        return nil
+    }
+    return period
+}
+
+// assignPeriod stores the period on the reference when it changed after an edit
+func (s *AchievementService) assignPeriod(ctx context.Context, ref modelPg.AchievementReference, period *modelPg.AcademicPeriod) error {
+    var id *uuid.UUID
+    if period != nil {
+        id = &period.ID
+    }
+    if samePeriod(ref.PeriodID, id) {
+        return nil
+    }
+    return s.pgRepo.SetPeriod(ctx, ref.ID, id)
+}
+
+func samePeriod(a, b *uuid.UUID) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return *a == *b
+}
+
+func periodIDString(p *modelPg.AcademicPeriod) string {
+    if p == nil {
+        return ""
+    }
+    return p.ID.String()
+}
+
+// submissionClosed reports whether the period of an achievement stopped taking
+// submissions, together with the period for the error message
+func (s *AchievementService) submissionClosed(ctx context.Context, ref modelPg.AchievementReference) (bool, *modelPg.AcademicPeriod) {
+    if s.periods == nil || ref.PeriodID == nil {
+        return false, nil
+    }
+    period, err := s.periods.GetByID(ctx, *ref.PeriodID)
+    if err != nil {
+        return false, nil
+    }
+    return period.SubmissionClosed(time.Now()), period
+}
+
+// resolvePeriod turns a ?period= value (a period UUID or "active") into the period
+func resolvePeriod(ctx context.Context, periods repoPg.AcademicPeriodRepository, value string) (*modelPg.AcademicPeriod, int, string) {
+    if periods == nil {
+        return nil, 400, "Academic periods are not configured"
+    }
+    if value == "active" {
+        period, err := periods.GetActive(ctx)
+        if err != nil {
+            return nil, 404, "No active academic period"
+        }
+        return period, 0, ""
+    }
+
+    id, err := uuid.Parse(value)
+    if err != nil {
+        return nil, 400, "period must be an academic period ID or active"
+    }
+    period, err := periods.GetByID(ctx, id)
+    if err != nil {
+        return nil, 404, "Academic period not found"
+    }
+    return period, 0, ""
+}
+
+// AssignPeriods gives achievements created before their period existed a
+// period, matching event dates against all configured periods
+func (s *AchievementService) AssignPeriods(ctx context.Context) (int, error) {
+    if s.periods == nil {
+        return 0, nil
+    }
+    periods, err := s.periods.GetAll(ctx)
+    if err != nil || len(periods) == 0 {
+        return 0, err
+    }
+
+    assigned := 0
+    var after *modelPg.Cursor
+    for {
+        refs, err := s.pgRepo.GetReferencesWithoutPeriod(ctx, after, periodAssignBatch)
+        if err != nil {
+            return assigned, err
+        }
+        if len(refs) == 0 {
+            return assigned, nil
+        }
+        last := refs[len(refs)-1]
+        after = &modelPg.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+
+        mongoIDs := make([]string, 0, len(refs))
+        for _, r := range refs {
+            mongoIDs = append(mongoIDs, r.MongoAchievementID)
+        }
+        docs, err := s.mongoRepo.FindAllDetails(ctx, mongoIDs)
+        if err != nil {
+            return assigned, err
+        }
+        byMongo := make(map[string]modelMongo.Achievement, len(docs))
+        for _, d := range docs {
+            byMongo[d.ID.Hex()] = d
+        }
+
+        for _, ref := range refs {
+            day := byMongo[ref.MongoAchievementID].EventDate()
+            if day.IsZero() {
+                day = ref.CreatedAt
+            }
+            for _, p := range periods {
+                if !p.Contains(day) {
+                    continue
+                }
+                if err := s.pgRepo.SetPeriod(ctx, ref.ID, &p.ID); err != nil {
+                    return assigned, err
+                }
+                if err := s.mongoRepo.SetPeriod(ctx, ref.MongoAchievementID, p.ID.String()); err != nil {
+                    return assigned, err
+                }
+                assigned++
+                break
+            }
+        }
+
+        if len(refs) < periodAssignBatch {
+            return assigned, nil
+        }
+    }
+}
+
+// RunPeriodAssignment calls AssignPeriods on start and then on every interval until ctx is done
+func (s *AchievementService) RunPeriodAssignment(ctx context.Context, cfg config.PeriodConfig) {
+    ticker := time.NewTicker(cfg.AssignInterval)
+    defer ticker.Stop()
+
+    for {
+        n, err := s.AssignPeriods(ctx)
+        if err != nil {
+            log.Printf("period assignment failed: %v", err)
+        } else if n > 0 {
+            log.Printf("period assignment: assigned %d achievements", n)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
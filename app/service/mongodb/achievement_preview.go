@@ -0,0 +1,182 @@
+package service
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "log"
+    "time"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    "StudenAchievementReportingSystem/app/preview"
+    "StudenAchievementReportingSystem/app/storage"
+    "StudenAchievementReportingSystem/config"
+    "StudenAchievementReportingSystem/middleware"
+    "github.com/gofiber/fiber/v2"
+)
+
+// previewBatch bounds the documents loaded per preview round
+const previewBatch = 20
+
+// previewKey stores the preview of a file next to it. Files shared by several
+// attachments share their preview as well.
+func previewKey(storageKey string) string {
+    return storageKey + ".preview.jpg"
+}
+
+// GeneratePendingPreviews makes the previews of attachments that passed the
+// malware scan. Files no preview can be made of are marked unavailable;
+// storage failures leave the preview pending for the next round. It returns
+// the number of previews settled.
+func (s *AchievementService) GeneratePendingPreviews(ctx context.Context) (int, error) {
+    docs, err := s.mongoRepo.FindPendingPreviews(ctx, previewBatch)
+    if err != nil {
+        return 0, err
+    }
+
+    settled := 0
+    for _, doc := range docs {
+        for _, att := range doc.Attachments {
+            if att.Preview == nil || att.Preview.Status != modelMongo.PreviewPending || !att.Downloadable() {
+                continue
+            }
+
+            status, err := s.renderPreview(ctx, att)
+            if err != nil {
+                log.Printf("previews: failed to make preview of %s: %v", att.StorageKey, err)
+                continue
+            }
+
+            now := time.Now()
+            result := modelMongo.AttachmentPreview{Status: status, GeneratedAt: &now}
+            if err := s.mongoRepo.SetAttachmentPreview(ctx, doc.ID.Hex(), att.ID, att.StorageKey, result); err != nil {
+                return settled, err
+            }
+            settled++
+        }
+    }
+    return settled, nil
+}
+
+// renderPreview stores the preview of a file and returns the status to record
+func (s *AchievementService) renderPreview(ctx context.Context, att modelMongo.Attachment) (string, error) {
+    body, err := s.files.Open(ctx, att.StorageKey)
+    if err != nil {
+        return "", err
+    }
+    defer body.Close()
+
+    thumb, err := s.previews.Generate(ctx, att.FileType, body)
+    if errors.Is(err, preview.ErrUnsupported) {
+        log.Printf("previews: no preview of %s: %v", att.StorageKey, err)
+        return modelMongo.PreviewUnavailable, nil
+    }
+    if err != nil {
+        return "", err
+    }
+
+    if err := s.files.Put(ctx, previewKey(att.StorageKey), bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg"); err != nil {
+        return "", err
+    }
+    return modelMongo.PreviewReady, nil
+}
+
+// RunPreviewGeneration makes pending previews every cfg.Interval until ctx is
+// cancelled
+func (s *AchievementService) RunPreviewGeneration(ctx context.Context, cfg config.PreviewConfig) {
+    ticker := time.NewTicker(cfg.Interval)
+    defer ticker.Stop()
+
+    for {
+        n, err := s.GeneratePendingPreviews(ctx)
+        if err != nil {
+            log.Printf("preview generation failed: %v", err)
+        } else if n > 0 {
+            log.Printf("preview generation: settled %d previews", n)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// sendPreview streams the preview of an attachment, or redirects to it when
+// the storage offers direct links
+func (s *AchievementService) sendPreview(c *fiber.Ctx, att *modelMongo.Attachment) error {
+    ctx := c.Context()
+    if !att.Downloadable() || att.Preview == nil || att.Preview.Status != modelMongo.PreviewReady {
+        return c.Status(404).JSON(fiber.Map{"error": "No preview available"})
+    }
+    key := previewKey(att.StorageKey)
+
+    direct, err := s.files.URL(ctx, key)
+    if err == nil {
+        return c.Redirect(direct, fiber.StatusFound)
+    }
+    if !errors.Is(err, storage.ErrNoDirectURL) {
+        log.Printf("previews: failed to link %s: %v", key, err)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to read preview"})
+    }
+
+    body, err := s.files.Open(ctx, key)
+    if errors.Is(err, storage.ErrNotFound) {
+        return c.Status(404).JSON(fiber.Map{"error": "No preview available"})
+    }
+    if err != nil {
+        log.Printf("previews: failed to open %s: %v", key, err)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to read preview"})
+    }
+
+    c.Set(fiber.HeaderContentType, "image/jpeg")
+    c.Set(fiber.HeaderContentDisposition, "inline")
+    c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+    c.Set(fiber.HeaderCacheControl, "private, no-store")
+    return c.SendStream(body)
+}
+
+// DownloadPreview godoc
+// @Summary Attachment Preview
+// @Description JPEG thumbnail of an image attachment or of the first page of a PDF, with the same access rules as the achievement detail
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce jpeg
+// @Param id path string true "Achievement ID (UUID)"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a short-lived storage URL"
+// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Router /achievements/{id}/attachments/{attachmentId}/preview [get]
+func (s *AchievementService) DownloadPreview(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+    return fiber.ErrForbidden
+    }
+
+    att, status, msg := s.readableAttachment(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    return s.sendPreview(c, att)
+}
+
+// DownloadSignedPreview godoc
+// @Summary Attachment Preview by Signed Link
+// @Description Load a preview with the previewUrl returned by the API. The link needs no token and expires after a few minutes.
+// @Tags Achievements
+// @Produce jpeg
+// @Param id path string true "Achievement ID (UUID)"
+// @Param attachmentId path string true "Attachment ID"
+// @Param expires query int true "Expiry (Unix time)"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Success 302 "Redirect to a short-lived storage URL"
+// @Failure 400,403,404,500 {object} map[string]interface{}
+// @Router /files/achievements/{id}/attachments/{attachmentId}/preview [get]
+func (s *AchievementService) DownloadSignedPreview(c *fiber.Ctx) error {
+    att, status, msg := s.signedAttachment(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    return s.sendPreview(c, att)
+}
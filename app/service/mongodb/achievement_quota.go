@@ -0,0 +1,163 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+    "StudenAchievementReportingSystem/app/storage"
+    "StudenAchievementReportingSystem/config"
+    "StudenAchievementReportingSystem/middleware"
+    "github.com/gofiber/fiber/v2"
+)
+
+// quotaUsage adds up the usage of a student's achievements and picks out the
+// achievement with the given document
+func quotaUsage(usage []modelMongo.AchievementStorage, mongoID string) (student, achievement modelMongo.StorageUsage) {
+    for _, a := range usage {
+        student.Bytes += a.Bytes
+        student.Files += a.Files
+        if a.MongoID.Hex() == mongoID {
+            achievement = a.StorageUsage
+        }
+    }
+    return student, achievement
+}
+
+// exceededLimit returns the limit that adding bytes and files to used would
+// break, or "". Changes that free space pass even over the quota.
+func exceededLimit(used, quota modelMongo.StorageUsage, bytes int64, files int) string {
+    if quota.Files > 0 && files > 0 && used.Files+files > quota.Files {
+        return fmt.Sprintf("%d files", quota.Files)
+    }
+    if quota.Bytes > 0 && bytes > 0 && used.Bytes+bytes > quota.Bytes {
+        return fmt.Sprintf("%d MB", quota.Bytes/(1024*1024))
+    }
+    return ""
+}
+
+// checkQuota refuses new files that would take the student or the achievement
+// over its storage quota. freed is the size of a file the new one replaces.
+// Uploads running side by side are checked against the same usage, so they
+// can overshoot a quota by their own size.
+func (s *AchievementService) checkQuota(ctx context.Context, ref modelPg.AchievementReference, bytes int64, files int, freed int64) (int, string) {
+    quotas := config.LoadStorageQuotas()
+    usage, err := s.mongoRepo.StorageByAchievement(ctx, ref.StudentID.String())
+    if err != nil {
+        return 500, "Failed to check storage quota"
+    }
+    student, achievement := quotaUsage(usage, ref.MongoAchievementID)
+
+    studentQuota := modelMongo.StorageUsage{Bytes: quotas.StudentBytes, Files: quotas.StudentFiles}
+    if limit := exceededLimit(student, studentQuota, bytes-freed, files); limit != "" {
+        return 413, "Storage quota exceeded: your attachments are limited to " + limit
+    }
+    achievementQuota := modelMongo.StorageUsage{Bytes: quotas.AchievementBytes, Files: quotas.AchievementFiles}
+    if limit := exceededLimit(achievement, achievementQuota, bytes-freed, files); limit != "" {
+        return 413, "Storage quota exceeded: the attachments of an achievement are limited to " + limit
+    }
+    return 0, ""
+}
+
+// GetMyStorage godoc
+// @Summary My Storage Usage
+// @Description Space taken by the attachments of the logged-in student, in total and per achievement, with the quotas. Achievements in the trash count until purged. A quota of 0 is not limited.
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} modelMongo.StudentStorage
+// @Failure 401,403,404,500 {object} map[string]interface{}
+// @Router /me/storage [get]
+func (s *AchievementService) GetMyStorage(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+
+    userID, err := getUserIDFromToken(c)
+    if err != nil {
+        return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+    }
+    studentID, err := s.pgRepo.GetStudentByUserID(ctx, userID)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Student profile not found"})
+    }
+
+    usage, err := s.mongoRepo.StorageByAchievement(ctx, studentID.String())
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to compute storage usage"})
+    }
+
+    mongoIDs := make([]string, 0, len(usage))
+    for _, a := range usage {
+        mongoIDs = append(mongoIDs, a.MongoID.Hex())
+    }
+    refs, err := s.pgRepo.GetReferencesByMongoIDs(ctx, mongoIDs)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement references"})
+    }
+    refByMongo := make(map[string]string, len(refs))
+    for _, r := range refs {
+        refByMongo[r.MongoAchievementID] = r.ID.String()
+    }
+
+    result := modelMongo.StudentStorage{Achievements: make([]modelMongo.AchievementStorage, 0, len(usage))}
+    result.Used, _ = quotaUsage(usage, "")
+    for _, a := range usage {
+        // achievements in the trash have no active reference
+        a.AchievementID = refByMongo[a.MongoID.Hex()]
+        a.InTrash = a.AchievementID == ""
+        result.Achievements = append(result.Achievements, a)
+    }
+
+    quotas := config.LoadStorageQuotas()
+    result.Quota = modelMongo.StorageUsage{Bytes: quotas.StudentBytes, Files: quotas.StudentFiles}
+    result.AchievementQuota = modelMongo.StorageUsage{Bytes: quotas.AchievementBytes, Files: quotas.AchievementFiles}
+    return c.JSON(result)
+}
+
+// BackfillAttachmentSizes records the size of files uploaded before sizes
+// were kept, by reading them from storage. Files that cannot be read are
+// skipped and tried again on the next start. It returns the number of sizes
+// recorded.
+func (s *AchievementService) BackfillAttachmentSizes(ctx context.Context) (int, error) {
+    docs, err := s.mongoRepo.FindUnsizedAttachments(ctx)
+    if err != nil {
+        return 0, err
+    }
+
+    sized := 0
+    for _, doc := range docs {
+        for _, att := range doc.Attachments {
+            if att.FileSize > 0 || att.StorageKey == "" || att.MissingAt != nil || (att.Scan != nil && att.Scan.Status == modelMongo.ScanInfected) {
+                continue
+            }
+
+            size, err := s.measureFile(ctx, att.StorageKey)
+            if err != nil {
+                if !errors.Is(err, storage.ErrNotFound) {
+                    log.Printf("attachments: failed to measure %s: %v", att.StorageKey, err)
+                }
+                continue
+            }
+            if err := s.mongoRepo.SetAttachmentSize(ctx, doc.ID.Hex(), att.ID, att.StorageKey, size); err != nil {
+                return sized, err
+            }
+            sized++
+        }
+    }
+    return sized, nil
+}
+
+// measureFile counts the bytes of a stored file
+func (s *AchievementService) measureFile(ctx context.Context, key string) (int64, error) {
+    body, err := s.files.Open(ctx, key)
+    if err != nil {
+        return 0, err
+    }
+    defer body.Close()
+    return io.Copy(io.Discard, body)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+    "StudenAchievementReportingSystem/app/scanner"
+    "StudenAchievementReportingSystem/config"
+    "github.com/google/uuid"
+)
+
+// scanBatch bounds how many documents one scan round loads
+const scanBatch = 50
+
+// ScanPendingAttachments scans quarantined uploads and records the verdicts.
+// Infected files are deleted and the student is notified. Files that cannot be
+// scanned, for example because clamd is down, stay pending for the next round.
+// It returns the number of attachments scanned.
+func (s *AchievementService) ScanPendingAttachments(ctx context.Context) (int, error) {
+    docs, err := s.mongoRepo.FindPendingScans(ctx, scanBatch)
+    if err != nil {
+        return 0, err
+    }
+
+    scanned := 0
+    for _, doc := range docs {
+        for _, att := range doc.Attachments {
+            if att.Scan == nil || att.Scan.Status != modelMongo.ScanPending || att.MissingAt != nil {
+                continue
+            }
+
+            verdict, err := s.scanStoredFile(ctx, att.StorageKey)
+            if err != nil {
+                log.Printf("malware scan: failed to scan %s: %v", att.StorageKey, err)
+                continue
+            }
+
+            now := time.Now()
+            result := modelMongo.AttachmentScan{Status: modelMongo.ScanClean, ScannedAt: &now}
+            if !verdict.Clean {
+                result.Status = modelMongo.ScanInfected
+                result.Signature = verdict.Signature
+                s.releaseFile(ctx, att)
+            }
+
+            if err := s.mongoRepo.SetAttachmentScan(ctx, doc.ID.Hex(), att.ID, result); err != nil {
+                return scanned, err
+            }
+            scanned++
+
+            if !verdict.Clean {
+                s.notifyInfected(ctx, doc, att, verdict.Signature)
+            }
+        }
+    }
+    return scanned, nil
+}
+
+func (s *AchievementService) scanStoredFile(ctx context.Context, key string) (scanner.Verdict, error) {
+    body, err := s.files.Open(ctx, key)
+    if err != nil {
+        return scanner.Verdict{}, err
+    }
+    defer body.Close()
+    return s.scanner.Scan(ctx, body)
+}
+
+// notifyInfected tells the student which file was removed. Failures are only
+// logged; the verdict is already stored on the attachment.
+func (s *AchievementService) notifyInfected(ctx context.Context, doc modelMongo.Achievement, att modelMongo.Attachment, signature string) {
+    studentID, err := uuid.Parse(doc.StudentID)
+    if err != nil {
+        return
+    }
+    student, err := s.student.GetStudentByID(ctx, studentID)
+    if err != nil {
+        log.Printf("malware scan: no student to notify for %s: %v", doc.ID.Hex(), err)
+        return
+    }
+
+    n := modelPg.Notification{
+        UserID:  student.UserID,
+        Kind:    modelPg.NotificationAttachmentInfected,
+        Message: fmt.Sprintf("The file %q attached to %q was removed because the malware scan detected %s. Please upload a clean copy.", att.FileName, doc.Title, signature),
+    }
+    if refs, err := s.pgRepo.GetReferencesByMongoIDs(ctx, []string{doc.ID.Hex()}); err == nil && len(refs) > 0 {
+        n.AchievementID = &refs[0].ID
+    }
+    if err := s.notifications.Create(ctx, n); err != nil {
+        log.Printf("malware scan: failed to notify student %s: %v", doc.StudentID, err)
+    }
+}
+
+// RunAttachmentScan calls ScanPendingAttachments on start and then every
+// cfg.ScanInterval until ctx is done
+func (s *AchievementService) RunAttachmentScan(ctx context.Context, cfg config.ScannerConfig) {
+    ticker := time.NewTicker(cfg.ScanInterval)
+    defer ticker.Stop()
+
+    for {
+        n, err := s.ScanPendingAttachments(ctx)
+        if err != nil {
+            log.Printf("malware scan failed: %v", err)
+        } else if n > 0 {
+            log.Printf("malware scan: scanned %d attachments", n)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
@@ -1,49 +1,175 @@
 package service
 
 import (
+    "context"
+    "encoding/json"
+    "strings"
     "time"
     "errors"
-    "os"
     "fmt"
-    "path/filepath"
+    "log"
     "math"
     modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
     modelPg "StudenAchievementReportingSystem/app/models/postgresql"
     repoMongo "StudenAchievementReportingSystem/app/repository/mongodb"
     repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/app/preview"
+    "StudenAchievementReportingSystem/app/scanner"
+    "StudenAchievementReportingSystem/app/storage"
     "github.com/gofiber/fiber/v2"
     "github.com/google/uuid"
+    "go.mongodb.org/mongo-driver/mongo"
     "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
 )
 
 type AchievementService struct {
     mongoRepo repoMongo.AchievementRepository
     pgRepo    repoPg.AchievementRepoPostgres
     lecturer   repoPg.LecturerRepository
+    typeRepo  repoMongo.AchievementTypeRepository
+    student   repoPg.StudentRepository
+    periods   repoPg.AcademicPeriodRepository
+    org       repoPg.OrganizationRepository
+    files     storage.Storage
+    uploadTypes repoPg.AttachmentTypeRepository
+    scanner   scanner.Scanner
+    notifications repoPg.NotificationRepository
+    blobs     repoPg.AttachmentBlobRepository
+    previews  preview.Generator
 }
 
-func NewAchievementService(m repoMongo.AchievementRepository, p repoPg.AchievementRepoPostgres, l repoPg.LecturerRepository) *AchievementService {
-    return &AchievementService{mongoRepo: m, pgRepo: p, lecturer: l}
+// AchievementDeps are the repositories and backends an AchievementService
+// works with
+type AchievementDeps struct {
+    Achievements    repoMongo.AchievementRepository
+    References      repoPg.AchievementRepoPostgres
+    Lecturers       repoPg.LecturerRepository
+    Types           repoMongo.AchievementTypeRepository
+    Students        repoPg.StudentRepository
+    Periods         repoPg.AcademicPeriodRepository
+    Organization    repoPg.OrganizationRepository
+    Files           storage.Storage
+    AttachmentTypes repoPg.AttachmentTypeRepository
+    Scanner         scanner.Scanner
+    Notifications   repoPg.NotificationRepository
+    Blobs           repoPg.AttachmentBlobRepository
+    Previews        preview.Generator
+}
+
+func NewAchievementService(d AchievementDeps) *AchievementService {
+    return &AchievementService{mongoRepo: d.Achievements, pgRepo: d.References, lecturer: d.Lecturers, typeRepo: d.Types, student: d.Students, periods: d.Periods, org: d.Organization, files: d.Files, uploadTypes: d.AttachmentTypes, scanner: d.Scanner, notifications: d.Notifications, blobs: d.Blobs, previews: d.Previews}
+}
+
+// findType returns the registry entry for an achievement type, or nil if there
+// is none. Other errors mean the registry could not be read.
+func (s *AchievementService) findType(ctx context.Context, code string) (*modelMongo.AchievementTypeDefinition, error) {
+    if code == "" {
+        return nil, nil
+    }
+    def, err := s.typeRepo.FindByCode(ctx, code)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return def, nil
 }
 
 func getUserIDFromToken(c *fiber.Ctx) (uuid.UUID, error) {
-    userIDRaw := c.Locals("user_id")
-    
-    if userIDRaw == nil {
-        return uuid.Nil, errors.New("unauthorized: user_id missing in context")
+    return middleware.UserID(c)
+}
+
+
+// checkReadAccess applies the read policy for a single achievement: students only
+// see their own, lecturers only see non-draft achievements of their advisees and
+// coordinators only non-draft achievements of students in their scope.
+// A non-zero status is the response to send when access is denied.
+func (s *AchievementService) checkReadAccess(c *fiber.Ctx, userID uuid.UUID, ref modelPg.AchievementReference) (isStudent bool, status int, msg string) {
+    ctx := c.Context()
+    scope, err := middleware.CoordinatorScope(c, s.org)
+    if err != nil {
+        return false, 500, "Failed to load coordinator scope"
+    }
+    if scope != nil {
+        if ref.Status == "draft" || !studentInScope(ctx, s.student, scope, ref.StudentID.String()) {
+            return false, 403, "Forbidden: This student is outside your coordinator scope"
+        }
+        return false, 0, ""
     }
 
-    if uid, ok := userIDRaw.(uuid.UUID); ok {
-        return uid, nil
+    currentStudentID, err := s.pgRepo.GetStudentByUserID(ctx, userID)
+    isStudent = err == nil
+    if isStudent {
+        if ref.StudentID != currentStudentID {
+            return isStudent, 403, "Forbidden: You cannot view this achievement"
+        }
     }
 
-    if uidStr, ok := userIDRaw.(string); ok {
-        return uuid.Parse(uidStr)
+    lecturerID, err := s.lecturer.GetLecturerByUserID(ctx, userID)
+    if err == nil {
+        advisees, err := s.lecturer.GetAdvisees(ctx, lecturerID)
+        if err != nil {
+            return isStudent, 500, "Failed to check advisee relationship"
+        }
+
+        isAdvisee := false
+        for _, mhs := range advisees {
+            if mhs.ID == ref.StudentID {
+                isAdvisee = true
+                break
+            }
+        }
+
+        if !isAdvisee {
+            return isStudent, 403, "Forbidden: This student is not your advisee"
+        }
+
+        if ref.Status == "draft" {
+            return isStudent, 403, "Forbidden: You cannot view draft achievements of your advisees"
+        }
     }
 
-    return uuid.Nil, errors.New("server error: user_id format invalid (expected string or uuid)")
+    return isStudent, 0, ""
 }
 
+// claimVersion enforces If-Match against the version of the reference and bumps
+// it before the caller writes. A non-zero status is the response to send when
+// the precondition fails. Callers whose write then fails give the version back
+// with releaseVersion.
+func (s *AchievementService) claimVersion(c *fiber.Ctx, ref modelPg.AchievementReference) (status int, msg string) {
+    header := c.Get(fiber.HeaderIfMatch)
+    if header == "" {
+        return fiber.StatusPreconditionRequired, "If-Match header is required"
+    }
+    if strings.TrimSpace(header) == "*" {
+        return fiber.StatusPreconditionRequired, "If-Match must name the ETag of the achievement, not *"
+    }
+    if !utils.IfMatch(header, ref.Version) {
+        return fiber.StatusPreconditionFailed, "Achievement has been modified, reload it and try again"
+    }
+
+    err := s.pgRepo.BumpVersion(c.Context(), ref.ID, ref.Version)
+    if errors.Is(err, repoPg.ErrVersionConflict) {
+        return fiber.StatusPreconditionFailed, "Achievement has been modified, reload it and try again"
+    }
+    if err != nil {
+        return 500, "Failed to update achievement version"
+    }
+
+    c.Set(fiber.HeaderETag, utils.ETag(ref.Version+1))
+    return 0, ""
+}
+
+// releaseVersion steps the version claimed by claimVersion back after the write
+// failed, so the ETag the client holds stays valid for a retry.
+func (s *AchievementService) releaseVersion(c *fiber.Ctx, ref modelPg.AchievementReference) {
+    c.Response().Header.Del(fiber.HeaderETag)
+    if err := s.pgRepo.ReleaseVersion(c.Context(), ref.ID, ref.Version+1); err != nil {
+        log.Printf("achievements: failed to release version %d of %s: %v", ref.Version+1, ref.ID, err)
+    }
+}
 
 // CreateAchievement godoc
 // @Summary Create New Achievement Draft
@@ -77,11 +203,22 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
     }
 
+    def, err := s.findType(ctx, req.AchievementType)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load achievement type"})
+    }
+    if errs := utils.ValidateAchievement(req, def); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
     req.Attachments = make([]modelMongo.Attachment, 0)
+    req.DuplicateWarnings = nil
     req.StudentID = studentID.String()
     req.Points = 0 
     req.CreatedAt = time.Now()
     req.UpdatedAt = time.Now()
+    period := s.periodFor(ctx, req, req.CreatedAt)
+    req.PeriodID = periodIDString(period)
     mongoID, err := s.mongoRepo.InsertOne(ctx, req)
 
     if err != nil {
@@ -94,6 +231,9 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
         Status:             "draft", 
         CreatedAt:          time.Now(),
     }
+    if period != nil {
+        ref.PeriodID = &period.ID
+    }
     
     newID, err := s.pgRepo.Create(ctx, ref)
     if err != nil {
@@ -111,7 +251,7 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 
 // GetAllAchievements godoc
 // @Summary Get List of Achievements
-// @Description Get paginated list of achievements. Filter logic depends on role (Student: own data, Lecturer: advisees data).
+// @Description Get paginated list of achievements. Filter logic depends on role (Student: own data, Lecturer: advisees data, Coordinator: non-draft achievements within their scope).
 // @Tags Achievements
 // @Security BearerAuth
 // @Produce json
@@ -119,8 +259,23 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 // @Param limit query int false "Items per page (default 10)"
 // @Param status query string false "Filter by status (draft, submitted, verified, rejected)"
 // @Param sort query string false "Sort direction"
+// @Param search query string false "Full-text search over title, description and tags"
+// @Param type query string false "Achievement type, comma separated"
+// @Param level query string false "Competition level"
+// @Param dateFrom query string false "Event or start date from (YYYY-MM-DD)"
+// @Param dateTo query string false "Event or start date to, inclusive (YYYY-MM-DD)"
+// @Param minPoints query int false "Minimum points"
+// @Param maxPoints query int false "Maximum points"
+// @Param programStudy query string false "Student program study"
+// @Param academicYear query string false "Student academic year"
+// @Param period query string false "Academic period ID, or active"
+// @Param cursor query string false "Keyset cursor from meta.nextCursor or meta.prevCursor (switches to cursor mode)"
+// @Param pagination query string false "Set to cursor to start cursor mode without a cursor"
+// @Param total query string false "approx includes an estimated total in cursor mode; exact counts every match of a search or document filter"
+// @Param fields query string false "Comma separated response fields (id is always included)"
+// @Param expand query string false "Related entities to inline: student, verifier, attachments"
 // @Success 200 {object} modelPg.PaginatedResponse
-// @Failure 401,500 {object} map[string]interface{}
+// @Failure 400,401,500 {object} map[string]interface{}
 // @Router /achievements [get]
 func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
     ctx := c.Context()
@@ -146,31 +301,35 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 
     offset := (query.Page - 1) * query.Limit
 
-    filters := make(map[string]interface{})
-
-    if studentID, err := s.pgRepo.GetStudentByUserID(ctx, userID); err == nil {
-        filters["student_id"] = studentID
-        if query.Status != "" {
-            filters["status"] = query.Status
-        }
+    docFilter, errs := parseAchievementFilter(query)
+    shape, shapeErrs := parseResponseShape(c, listFields)
+    errs = append(errs, shapeErrs...)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
     }
-    
-    lecturerID, err := s.lecturer.GetLecturerByUserID(ctx, userID)
-    if  err == nil { 
 
-        advisees, err:= s.lecturer.GetAdvisees(lecturerID)
-        if err != nil {
-        return c.Status(500).JSON(fiber.Map{
-            "error": "failed to fetch advisees",
-        })
+    filters := make(map[string]interface{})
+    if query.ProgramStudy != "" {
+        filters["program_study"] = query.ProgramStudy
+    }
+    if query.AcademicYear != "" {
+        filters["academic_year"] = query.AcademicYear
+    }
+    if query.Period != "" {
+        period, status, msg := resolvePeriod(ctx, s.periods, query.Period)
+        if status != 0 {
+            return c.Status(status).JSON(fiber.Map{"error": msg})
         }
+        filters["period_id"] = period.ID
+    }
+    isStudent := false
 
-        var studentIDs []uuid.UUID
-        for _, mhs := range advisees {
-            studentIDs = append(studentIDs, mhs.ID)
-        }
-        
-        if len(studentIDs) == 0 {
+    scope, err := middleware.CoordinatorScope(c, s.org)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load coordinator scope"})
+    }
+    if scope != nil {
+        if len(scope.Covered) == 0 {
             return c.JSON(modelPg.PaginatedResponse{
                 Data: []interface{}{},
                 Meta: modelPg.PaginationMeta{
@@ -178,54 +337,93 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
                 },
             })
         }
-
-        filters["student_ids"] = studentIDs
-
-        if query.Status != "" {
+        // coordinators see every non-draft achievement of the program studies they oversee
+        filters["program_study_ids"] = scope.Covered
+        if query.Status != "" && query.Status != "draft" {
             filters["status"] = query.Status
         } else {
-            filters["status"] = []string{"submitted", "verified"} 
+            filters["status"] = []string{"submitted", "verified", "rejected"}
         }
-    }
+    } else {
+        if studentID, err := s.pgRepo.GetStudentByUserID(ctx, userID); err == nil {
+            isStudent = true
+            filters["student_id"] = studentID
+            if query.Status != "" {
+                filters["status"] = query.Status
+            }
+        }
+    
+        lecturerID, err := s.lecturer.GetLecturerByUserID(ctx, userID)
+        if  err == nil { 
 
-    refs, totalData, err := s.pgRepo.GetAllReferences(ctx, filters, query.Limit, offset, query.Sort)
-    if err != nil {
-        return c.Status(500).JSON(fiber.Map{"error": "Database error: " + err.Error()})
-    }
+            advisees, err:= s.lecturer.GetAdvisees(ctx, lecturerID)
+            if err != nil {
+            return c.Status(500).JSON(fiber.Map{
+                "error": "failed to fetch advisees",
+            })
+            }
 
-    if len(refs) == 0 {
-        return c.JSON(modelPg.PaginatedResponse{
-            Data: []interface{}{},
-            Meta: modelPg.PaginationMeta{
-                CurrentPage: query.Page, Limit: query.Limit, TotalData: 0, TotalPage: 0,
-            },
-        })
+            var studentIDs []uuid.UUID
+            for _, mhs := range advisees {
+                studentIDs = append(studentIDs, mhs.ID)
+            }
+        
+            if len(studentIDs) == 0 {
+                return c.JSON(modelPg.PaginatedResponse{
+                    Data: []interface{}{},
+                    Meta: modelPg.PaginationMeta{
+                        CurrentPage: query.Page, Limit: query.Limit, TotalData: 0, TotalPage: 0,
+                    },
+                })
+            }
+
+            filters["student_ids"] = studentIDs
+
+            if query.Status != "" {
+                filters["status"] = query.Status
+            } else {
+                filters["status"] = []string{"submitted", "verified"} 
+            }
+        }
     }
 
-    var mongoIDs []string
-    refMap := make(map[string]modelPg.AchievementReference)
-    for _, r := range refs {
-        mongoIDs = append(mongoIDs, r.MongoAchievementID)
-        refMap[r.MongoAchievementID] = r
+    if _, ok := filters["status"]; !ok && query.Status != "" {
+        filters["status"] = query.Status
     }
 
-    details, _ := s.mongoRepo.FindAllDetails(ctx, mongoIDs)
+    if query.Cursor != "" || query.Pagination == "cursor" {
+        if !docFilter.IsEmpty() {
+            return c.Status(400).JSON(fiber.Map{"error": "Cursor pagination cannot be combined with search or document filters"})
+        }
+        return s.listByCursor(c, filters, query, isStudent, shape)
+    }
 
     var data []interface{}
-    for _, d := range details {
-        mongoIDHex := d.ID.Hex()
-        if ref, exists := refMap[mongoIDHex]; exists {
-            data = append(data, map[string]interface{}{
-                "id":             ref.ID,
-                "status":         ref.Status,
-                "submittedAt":    ref.SubmittedAt,
-                "title":          d.Title,
-                "type":           d.AchievementType,
-                "points":         d.Points,
-                "createdAt":      ref.CreatedAt,
-                "studentId":      ref.StudentID,
-            })
+    var totalData int64
+    var hasMore *bool
+
+    if docFilter.IsEmpty() {
+        // every filter lives in Postgres, page there and attach the documents
+        refs, total, err := s.pgRepo.GetAllReferences(ctx, filters, query.Limit, offset, query.Sort)
+        if err != nil {
+            return c.Status(500).JSON(fiber.Map{"error": "Database error: " + err.Error()})
         }
+        totalData = total
+        data = s.listItems(ctx, refs, isStudent, shape)
+    } else {
+        // walk the references the caller may see in batches and keep those whose
+        // document matches, so the page keeps the Postgres order
+        refs, total, more, err := s.searchReferences(ctx, filters, docFilter, query.Limit, offset, query.Sort, query.Total == "exact")
+        if err != nil {
+            return c.Status(500).JSON(fiber.Map{"error": "Failed to search achievements"})
+        }
+        totalData = total
+        hasMore = &more
+        data = s.listItems(ctx, refs, isStudent, shape)
+    }
+
+    if data == nil {
+        data = []interface{}{}
     }
 
     totalPages := int(math.Ceil(float64(totalData) / float64(query.Limit)))
@@ -237,10 +435,211 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
             TotalPage:   totalPages,
             TotalData:   int(totalData),
             Limit:       query.Limit,
+            HasMore:     hasMore,
         },
     })
 }
 
+// listByCursor serves GET /achievements in keyset mode: no COUNT(*), stable
+// pages while rows are inserted, and next/prev cursors in the meta
+func (s *AchievementService) listByCursor(c *fiber.Ctx, filters map[string]interface{}, query modelPg.PaginationQuery, isStudent bool, shape responseShape) error {
+    ctx := c.Context()
+
+    var cursor *modelPg.Cursor
+    if query.Cursor != "" {
+        decoded, err := utils.DecodeCursor(query.Cursor)
+        if err != nil {
+            return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+        }
+        cursor = decoded
+    }
+
+    refs, hasMore, err := s.pgRepo.GetReferencesByCursor(ctx, filters, query.Limit, cursor, query.Sort)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Database error: " + err.Error()})
+    }
+
+    meta := modelPg.PaginationMeta{Limit: query.Limit}
+    if len(refs) > 0 {
+        backward := cursor != nil && cursor.Backward
+        first, last := refs[0], refs[len(refs)-1]
+
+        if hasMore || backward {
+            meta.NextCursor = utils.EncodeCursor(modelPg.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+        }
+        if (backward && hasMore) || (!backward && cursor != nil) {
+            meta.PrevCursor = utils.EncodeCursor(modelPg.Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true})
+        }
+    }
+
+    if query.Total == "approx" {
+        if n, err := s.pgRepo.EstimateReferences(ctx, filters); err == nil {
+            meta.ApproxTotal = &n
+        }
+    }
+
+    return c.JSON(modelPg.PaginatedResponse{
+        Data: s.listItems(ctx, refs, isStudent, shape),
+        Meta: meta,
+    })
+}
+
+// searchBatchSize is how many references a document search sends to MongoDB
+// at once
+const searchBatchSize = 500
+
+// searchReferences pages through the references matching filters by keyset
+// on (created_at, id) in batches and keeps those whose document matches f. It
+// returns the references at offset, the number of matches counted and whether
+// more follow. Unless exact is set it stops at the first match after the
+// page, so the count only covers the matches up to the page.
+func (s *AchievementService) searchReferences(ctx context.Context, filters map[string]interface{}, f modelMongo.AchievementFilter, limit, offset int, sort string, exact bool) ([]modelPg.AchievementReference, int64, bool, error) {
+    var page []modelPg.AchievementReference
+    var total int64
+    var cursor *modelPg.Cursor
+    for {
+        refs, more, err := s.pgRepo.GetReferencesByCursor(ctx, filters, searchBatchSize, cursor, sort)
+        if err != nil {
+            return nil, 0, false, err
+        }
+        if len(refs) == 0 {
+            break
+        }
+
+        mongoIDs := make([]string, 0, len(refs))
+        for _, r := range refs {
+            mongoIDs = append(mongoIDs, r.MongoAchievementID)
+        }
+        ids, err := s.mongoRepo.MatchIDs(ctx, mongoIDs, f)
+        if err != nil {
+            return nil, 0, false, err
+        }
+        matched := make(map[string]bool, len(ids))
+        for _, id := range ids {
+            matched[id] = true
+        }
+
+        for _, r := range refs {
+            if !matched[r.MongoAchievementID] {
+                continue
+            }
+            if total >= int64(offset+limit) && !exact {
+                return page, total, true, nil
+            }
+            if total >= int64(offset) && len(page) < limit {
+                page = append(page, r)
+            }
+            total++
+        }
+        if !more {
+            break
+        }
+        last := refs[len(refs)-1]
+        cursor = &modelPg.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+    }
+    return page, total, total > int64(offset+len(page)), nil
+}
+
+// listItems attaches the MongoDB documents to references, keeping the reference order.
+// References whose document is missing are still listed.
+func (s *AchievementService) listItems(ctx context.Context, refs []modelPg.AchievementReference, isStudent bool, shape responseShape) []interface{} {
+    mongoIDs := make([]string, 0, len(refs))
+    for _, r := range refs {
+        mongoIDs = append(mongoIDs, r.MongoAchievementID)
+    }
+
+    details := make(map[string]modelMongo.Achievement)
+    if len(mongoIDs) > 0 {
+        docs, _ := s.mongoRepo.FindAllDetails(ctx, mongoIDs)
+        for _, d := range docs {
+            details[d.ID.Hex()] = d
+        }
+    }
+
+    docs := make([]modelMongo.Achievement, len(refs))
+    for i, ref := range refs {
+        docs[i] = details[ref.MongoAchievementID]
+    }
+    return s.renderItems(ctx, refs, docs, isStudent, shape)
+}
+
+// renderItems builds the list entries for references and their documents
+// (same index), then applies ?expand= and ?fields=
+func (s *AchievementService) renderItems(ctx context.Context, refs []modelPg.AchievementReference, docs []modelMongo.Achievement, isStudent bool, shape responseShape) []interface{} {
+    items := make([]map[string]interface{}, len(refs))
+    for i, ref := range refs {
+        items[i] = achievementListItem(ref, docs[i], isStudent)
+    }
+    s.expandItems(ctx, items, refs, docs, shape)
+
+    data := make([]interface{}, 0, len(items))
+    for _, item := range items {
+        data = append(data, utils.SelectFields(item, shape.fields))
+    }
+    return data
+}
+
+func achievementListItem(ref modelPg.AchievementReference, d modelMongo.Achievement, isStudent bool) map[string]interface{} {
+    item := map[string]interface{}{
+        "id":             ref.ID,
+        "status":         ref.Status,
+        "submittedAt":    ref.SubmittedAt,
+        "title":          d.Title,
+        "type":           d.AchievementType,
+        "points":         d.Points,
+        "createdAt":      ref.CreatedAt,
+        "studentId":      ref.StudentID,
+        "periodId":       ref.PeriodID,
+    }
+    if !isStudent {
+        item["possibleDuplicate"] = len(d.DuplicateWarnings) > 0
+    }
+    return item
+}
+
+// parseAchievementFilter turns the document filters of a list query into a MongoDB filter
+func parseAchievementFilter(q modelPg.PaginationQuery) (modelMongo.AchievementFilter, []utils.FieldError) {
+    var errs []utils.FieldError
+    f := modelMongo.AchievementFilter{
+        Search: strings.TrimSpace(q.Search),
+        Level:  strings.TrimSpace(q.Level),
+        MinPoints: q.MinPoints,
+        MaxPoints: q.MaxPoints,
+    }
+
+    for _, t := range strings.Split(q.Type, ",") {
+        if t = strings.TrimSpace(t); t != "" {
+            f.Types = append(f.Types, t)
+        }
+    }
+
+    if q.DateFrom != "" {
+        from, err := time.Parse("2006-01-02", q.DateFrom)
+        if err != nil {
+            errs = append(errs, utils.FieldError{Field: "dateFrom", Message: "must be a date (YYYY-MM-DD)"})
+        } else {
+            f.DateFrom = &from
+        }
+    }
+    if q.DateTo != "" {
+        to, err := time.Parse("2006-01-02", q.DateTo)
+        if err != nil {
+            errs = append(errs, utils.FieldError{Field: "dateTo", Message: "must be a date (YYYY-MM-DD)"})
+        } else {
+            end := to.AddDate(0, 0, 1)
+            f.DateTo = &end
+        }
+    }
+    if f.DateFrom != nil && f.DateTo != nil && !f.DateFrom.Before(*f.DateTo) {
+        errs = append(errs, utils.FieldError{Field: "dateTo", Message: "must not be before dateFrom"})
+    }
+    if f.MinPoints != nil && f.MaxPoints != nil && *f.MinPoints > *f.MaxPoints {
+        errs = append(errs, utils.FieldError{Field: "maxPoints", Message: "must not be less than minPoints"})
+    }
+
+    return f, errs
+}
+
 // GetAchievementDetail godoc
 // @Summary Get Achievement Detail
 // @Description Get full details of a specific achievement including MongoDB data
@@ -248,7 +647,10 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Achievement ID (UUID)"
+// @Param fields query string false "Comma separated response fields (id is always included)"
+// @Param expand query string false "Related entities to inline: student, verifier, attachments"
 // @Success 200 {object} map[string]interface{}
+// @Header 200 {string} ETag "Current version of the achievement"
 // @Failure 400,401,403,404,500 {object} map[string]interface{}
 // @Router /achievements/{id} [get]
 func (s *AchievementService) GetAchievementDetail(c *fiber.Ctx) error {
@@ -272,34 +674,14 @@ func (s *AchievementService) GetAchievementDetail(c *fiber.Ctx) error {
         return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
     }
 
-    currentStudentID, err := s.pgRepo.GetStudentByUserID(ctx, userID)
-    if err == nil {
-        if ref.StudentID != currentStudentID {
-            return c.Status(403).JSON(fiber.Map{"error": "Forbidden: You cannot view this achievement"})
-        }
-    } 
-    lecturerID, err := s.lecturer.GetLecturerByUserID(ctx, userID)
-    if err ==nil{
-        advisees, err := s.lecturer.GetAdvisees(lecturerID)
-        if err != nil {
-            return c.Status(500).JSON(fiber.Map{"error": "Failed to check advisee relationship"})
-        }
-
-        isAdvisee := false
-        for _, mhs := range advisees {
-            if mhs.ID == ref.StudentID {
-                isAdvisee = true
-                break
-            }
-        }
-
-        if !isAdvisee {
-            return c.Status(403).JSON(fiber.Map{"error": "Forbidden: This student is not your advisee"})
-        }
+    isStudent, status, msg := s.checkReadAccess(c, userID, ref)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
 
-        if ref.Status == "draft" {
-            return c.Status(403).JSON(fiber.Map{"error": "Forbidden: You cannot view draft achievements of your advisees"})
-        }
+    shape, errs := parseResponseShape(c, detailFields)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
     }
 
     detail, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID)
@@ -307,26 +689,40 @@ func (s *AchievementService) GetAchievementDetail(c *fiber.Ctx) error {
         return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement details"})
     }
 
+    // duplicate warnings are meant for reviewers only
+    if isStudent {
+        detail.DuplicateWarnings = nil
+    }
+
+    c.Set(fiber.HeaderETag, utils.ETag(ref.Version))
+
+    details := *detail
+    details.Attachments = s.resolveAttachments(ref.ID, detail.Attachments)
     response := map[string]interface{}{
         "id":            ref.ID,
         "status":        ref.Status,
+        "version":       ref.Version,
+        "periodId":      ref.PeriodID,
         "rejectionNote": ref.RejectionNote,
-        "details":       detail, 
+        "details":       details,
         "createdAt":     ref.CreatedAt,
+        "modifiedSinceVerification": modifiedSinceVerification(*detail),
     }
+    s.expandItems(ctx, []map[string]interface{}{response}, []modelPg.AchievementReference{ref}, []modelMongo.Achievement{*detail}, shape)
 
-    return c.JSON(response)
+    return c.JSON(utils.SelectFields(response, shape.fields))
 }
 
 // SubmitAchievement godoc
 // @Summary Submit Achievement
-// @Description Submit a draft achievement for verification (Student only)
+// @Description Submit a draft achievement for verification (Student only). It needs an attachment of every kind its achievement type requires.
 // @Tags Achievements
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Achievement ID (UUID)"
+// @Param If-Match header string true "ETag from GET /achievements/{id}"
 // @Success 200 {object} map[string]string
-// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Failure 400,401,403,404,412,428,500 {object} map[string]interface{}
 // @Router /achievements/{id}/submit [post]
 func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
     ctx := c.Context()
@@ -362,23 +758,47 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "Only draft achievements can be submitted"})
     }
 
+    if closed, period := s.submissionClosed(ctx, ref); closed {
+        return c.Status(400).JSON(fiber.Map{"error": "Submissions for " + period.Name + " closed on " + period.SubmissionDeadline.Format("2006-01-02 15:04")})
+    }
+
+    detail, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load achievement"})
+    }
+    def, err := s.findType(ctx, detail.AchievementType)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load achievement type"})
+    }
+    if errs := utils.ValidateRequiredAttachments(*detail, def); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if status, msg := s.claimVersion(c, ref); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
     err = s.pgRepo.SubmitReference(ctx, achievementID) 
     if err != nil {
+        s.releaseVersion(c, ref)
         return c.Status(500).JSON(fiber.Map{"error": "Failed to submit achievement"+ err.Error(),})
     }
 
+    s.flagDuplicates(ctx, ref)
+
     return c.JSON(fiber.Map{"status": "success", "message": "Achievement submitted for verification"})
 }
 
 // DeleteAchievement godoc
 // @Summary Delete Achievement
-// @Description Delete an achievement (Only allowed if status is draft)
+// @Description Move a draft achievement to the trash. It can be restored until the retention window ends, then it is purged.
 // @Tags Achievements
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Achievement ID (UUID)"
+// @Param If-Match header string true "ETag from GET /achievements/{id}"
 // @Success 200 {object} map[string]string
-// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Failure 400,401,403,404,412,428,500 {object} map[string]interface{}
 // @Router /achievements/{id} [delete]
 func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
     ctx := c.Context()
@@ -414,12 +834,21 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "Only draft achievements can be deleted"})
     }
 
+    if status, msg := s.claimVersion(c, ref); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    if err := s.mongoRepo.MoveToTrash(ctx, ref.MongoAchievementID); err != nil {
+        s.releaseVersion(c, ref)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to delete achievement details"})
+    }
+
     if err := s.pgRepo.DeleteReference(ctx, achievementID); err != nil {
+        _ = s.mongoRepo.RestoreFromTrash(ctx, ref.MongoAchievementID)
+        s.releaseVersion(c, ref)
         return c.Status(500).JSON(fiber.Map{"error": "Failed to delete reference"})
     }
 
-    _ = s.mongoRepo.DeleteAchievement(ctx, ref.MongoAchievementID)
-
     return c.JSON(fiber.Map{"message": "Achievement deleted successfully"})
 }
 
@@ -430,8 +859,9 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Achievement ID (UUID)"
+// @Param If-Match header string true "ETag from GET /achievements/{id}"
 // @Success 200 {object} map[string]string
-// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Failure 400,401,403,404,412,428,500 {object} map[string]interface{}
 // @Router /achievements/{id}/verify [post]
 func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
     ctx := c.Context()
@@ -450,7 +880,7 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
         return c.Status(401).JSON(fiber.Map{"error": err.Error()})
     }
 
-    lecturerID, err := s.lecturer.GetLecturerByUserID(ctx, userID)
+    _, err = s.lecturer.GetLecturerByUserID(ctx, userID)
     if err != nil {
         return c.Status(403).JSON(fiber.Map{"error": "User is not a lecturer"})
     }
@@ -463,34 +893,54 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
     }
 
+    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+    }
+
+    if req.Points <= 0 {
+        // fall back to the point rule of the achievement type, if any
+        if detail, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID); err == nil {
+            def, err := s.findType(ctx, detail.AchievementType)
+            if err != nil {
+                return c.Status(500).JSON(fiber.Map{"error": "Failed to load achievement type"})
+            }
+            if def != nil {
+                req.Points = def.PointsFor(*detail)
+            }
+        }
+    }
+
     if req.Points <= 0 {
         return c.Status(400).JSON(fiber.Map{
             "error": "Points must be greater than 0",
         })
     }
 
-    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
-    if err != nil {
-        return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
-    }
-
     if ref.Status != "submitted" {
         return c.Status(400).JSON(fiber.Map{
             "error": "Achievement must be in 'submitted' status to be verified",
         })
     }
 
-    
-    err = s.mongoRepo.UpdatePoints(ctx, ref.MongoAchievementID, req.Points)
+    if status, msg := s.claimVersion(c, ref); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    // award the points and pin the verified content in one update, before the
+    // status changes, so an achievement is never verified without its pin
+    err = s.mongoRepo.PinVerifiedVersion(ctx, ref.MongoAchievementID, req.Points)
     if err != nil {
+        s.releaseVersion(c, ref)
         return c.Status(500).JSON(fiber.Map{
-            "error": "Failed to update achievement points",
+            "error": "Failed to pin verified version",
         })
     }
 
     // ✅ update status di Postgres
-    err = s.pgRepo.UpdateStatus(ctx, achievementID, "verified", &lecturerID, "")
+    err = s.pgRepo.UpdateStatus(ctx, achievementID, "verified", &userID, "")
     if err != nil {
+        s.releaseVersion(c, ref)
         return c.Status(500).JSON(fiber.Map{
             "error": "Failed to verify achievement",
         })
@@ -512,9 +962,10 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Achievement ID (UUID)"
+// @Param If-Match header string true "ETag from GET /achievements/{id}"
 // @Param request body object{note=string} true "Rejection Note"
 // @Success 200 {object} map[string]string
-// @Failure 400,401,403,500 {object} map[string]interface{}
+// @Failure 400,401,403,404,412,428,500 {object} map[string]interface{}
 // @Router /achievements/{id}/reject [post]
 func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
     ctx := c.Context()
@@ -539,8 +990,18 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "Rejection note is required"})
     }
 
+    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+    }
+
+    if status, msg := s.claimVersion(c, ref); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
     err = s.pgRepo.UpdateStatus(ctx, achievementID, "rejected", &userID, req.Note)
     if err != nil {
+        s.releaseVersion(c, ref)
         return c.Status(500).JSON(fiber.Map{"error": "Failed to reject"}) 
     }
 
@@ -555,9 +1016,10 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Achievement ID (UUID)"
+// @Param If-Match header string true "ETag from GET /achievements/{id}"
 // @Param request body modelMongo.Achievement true "Updated Data"
 // @Success 200 {object} map[string]string
-// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Failure 400,401,403,404,409,412,428,500 {object} map[string]interface{}
 // @Router /achievements/{id} [put]
 func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
     ctx := c.Context()
@@ -598,14 +1060,158 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "Invalid body","details": err.Error(),})
     }
 
+    def, err := s.findType(ctx, req.AchievementType)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load achievement type"})
+    }
+    if errs := utils.ValidateAchievement(req, def); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if status, msg := s.claimVersion(c, ref); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    period := s.periodFor(ctx, req, ref.CreatedAt)
+    req.PeriodID = periodIDString(period)
+
     err = s.mongoRepo.UpdateOne(ctx, ref.MongoAchievementID, req)
+    if err != nil {
+        s.releaseVersion(c, ref)
+    }
+    if errors.Is(err, repoMongo.ErrVersionConflict) {
+        return c.Status(409).JSON(fiber.Map{"error": "Achievement has been modified, reload it and try again"})
+    }
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement"})
     }
+    if err := s.assignPeriod(ctx, ref, period); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to assign academic period"})
+    }
 
     return c.JSON(fiber.Map{"message": "Achievement updated successfully"})
 }
 
+// patchableFields are the members of an achievement a student may change with PATCH
+var patchableFields = []string{"title", "description", "achievementType", "details", "customFields", "tags"}
+
+// PatchAchievement godoc
+// @Summary Patch Achievement
+// @Description Partially update a draft achievement with a JSON Merge Patch (RFC 7396). Only title, description, achievementType, details, customFields and tags can be changed; null removes a field.
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Param If-Match header string true "ETag from GET /achievements/{id}"
+// @Param request body object true "Merge patch"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,403,404,409,412,415,428,500 {object} map[string]interface{}
+// @Router /achievements/{id} [patch]
+func (s *AchievementService) PatchAchievement(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:update") {
+        return fiber.ErrForbidden
+    }
+
+    achievementID, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"})
+    }
+
+    userID, err := getUserIDFromToken(c)
+    if err != nil {
+        return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+    }
+
+    studentID, err := s.pgRepo.GetStudentByUserID(ctx, userID)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Student profile not found"})
+    }
+
+    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+    }
+
+    if ref.StudentID != studentID {
+        return c.Status(403).JSON(fiber.Map{"error": "Forbidden: You do not own this data"})
+    }
+
+    if ref.Status != "draft" {
+        return c.Status(400).JSON(fiber.Map{"error": "Only draft achievements can be updated"})
+    }
+
+    if !c.Is("json") && !strings.HasPrefix(c.Get(fiber.HeaderContentType), "application/merge-patch+json") {
+        return c.Status(415).JSON(fiber.Map{"error": "Content-Type must be application/merge-patch+json"})
+    }
+
+    var patch map[string]interface{}
+    if err := json.Unmarshal(c.Body(), &patch); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Body must be a JSON object"})
+    }
+
+    if errs := utils.CheckPatchFields(patch, patchableFields); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    current, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement details"})
+    }
+
+    doc, err := json.Marshal(current)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to apply patch"})
+    }
+    merged, err := utils.MergePatch(doc, c.Body())
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid merge patch"})
+    }
+
+    var updated modelMongo.Achievement
+    if err := json.Unmarshal(merged, &updated); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid body", "details": err.Error()})
+    }
+
+    // fields outside the whitelist always come from the stored document
+    updated.ID = current.ID
+    updated.StudentID = current.StudentID
+    updated.Attachments = current.Attachments
+    updated.Points = current.Points
+
+    def, err := s.findType(ctx, updated.AchievementType)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load achievement type"})
+    }
+    if errs := utils.ValidateAchievement(updated, def); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if status, msg := s.claimVersion(c, ref); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    period := s.periodFor(ctx, updated, ref.CreatedAt)
+    updated.PeriodID = periodIDString(period)
+
+    err = s.mongoRepo.UpdateOne(ctx, ref.MongoAchievementID, updated)
+    if err != nil {
+        s.releaseVersion(c, ref)
+    }
+    if errors.Is(err, repoMongo.ErrVersionConflict) {
+        return c.Status(409).JSON(fiber.Map{"error": "Achievement has been modified, reload it and try again"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement"})
+    }
+    if err := s.assignPeriod(ctx, ref, period); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to assign academic period"})
+    }
+
+    return c.JSON(fiber.Map{"message": "Achievement updated successfully", "data": updated})
+}
+
 // GetAchievementHistory godoc
 // @Summary Get Achievement History
 // @Description Get status history log of an achievement
@@ -670,84 +1276,254 @@ func (s *AchievementService) GetAchievementHistory(c *fiber.Ctx) error {
     return c.JSON(history)
 }
 
-// UploadAttachments godoc
-// @Summary Upload Attachment
-// @Description Upload a file attachment for an achievement (Draft only)
+// modifiedSinceVerification reports whether the content of a verified achievement
+// no longer matches the version the lecturer approved
+func modifiedSinceVerification(a modelMongo.Achievement) bool {
+    return a.VerifiedHash != "" && a.VerifiedHash != a.ContentHashFormat(a.VerifiedHashFormat)
+}
+
+// loadVersionedAchievement resolves the achievement reference, applies the read
+// policy and returns the current MongoDB document. A non-nil error has already
+// been written to the response.
+func (s *AchievementService) loadVersionedAchievement(c *fiber.Ctx) (*modelPg.AchievementReference, *modelMongo.Achievement, error) {
+    ctx := c.Context()
+
+    achievementID, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return nil, nil, c.Status(400).JSON(fiber.Map{"error": "Invalid achievement ID"})
+    }
+
+    userID, err := getUserIDFromToken(c)
+    if err != nil {
+        return nil, nil, c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+    }
+
+    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
+    if err != nil {
+        return nil, nil, c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+    }
+
+    if _, status, msg := s.checkReadAccess(c, userID, ref); status != 0 {
+        return nil, nil, c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    current, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID)
+    if err != nil {
+        return nil, nil, c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement details"})
+    }
+
+    return &ref, current, nil
+}
+
+// findVersion returns version v of an achievement; the current document is
+// returned as is, older versions come from the archive
+func (s *AchievementService) findVersion(ctx context.Context, mongoID string, current *modelMongo.Achievement, v int) (*modelMongo.AchievementVersion, error) {
+    if v == current.CurrentVersion() {
+        return &modelMongo.AchievementVersion{
+            AchievementID: mongoID,
+            Version:       v,
+            Hash:          current.ContentHash(),
+            HashFormat:    modelMongo.CurrentHashFormat,
+            Snapshot:      *current,
+            ArchivedAt:    current.UpdatedAt,
+        }, nil
+    }
+    return s.mongoRepo.GetVersion(ctx, mongoID, v)
+}
+
+// GetAchievementVersions godoc
+// @Summary Get Achievement Versions
+// @Description List all stored versions of an achievement, oldest first, including the current one
 // @Tags Achievements
 // @Security BearerAuth
-// @Accept multipart/form-data
 // @Produce json
 // @Param id path string true "Achievement ID (UUID)"
-// @Param file formData file true "File to upload"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400,401,403,404,500 {object} map[string]interface{}
-// @Router /achievements/{id}/attachments [post]
-func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
-    ctx := c.Context()
-    if !middleware.HasPermission(c, "achievement:create") {
-    return fiber.ErrForbidden
+// @Router /achievements/{id}/versions [get]
+func (s *AchievementService) GetAchievementVersions(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
     }
 
-    achievementID, err := uuid.Parse(c.Params("id"))
-    if err != nil {
-        return c.Status(400).JSON(fiber.Map{"error": "Invalid ID"}) 
+    ref, current, err := s.loadVersionedAchievement(c)
+    if ref == nil {
+        return err
     }
 
-    userID, err := getUserIDFromToken(c)
+    archived, err := s.mongoRepo.GetVersions(c.Context(), ref.MongoAchievementID)
     if err != nil {
-        return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"}) 
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement versions"})
     }
 
-    studentID, err := s.pgRepo.GetStudentByUserID(ctx, userID)
-    if err != nil {
-        return c.Status(404).JSON(fiber.Map{"error": "Student profile not found"}) 
+    versions := make([]map[string]interface{}, 0, len(archived)+1)
+    for _, v := range archived {
+        if v.Version == current.CurrentVersion() {
+            continue
+        }
+        versions = append(versions, map[string]interface{}{
+            "version":    v.Version,
+            "hash":       v.Hash,
+            "hashFormat": v.HashFormat,
+            "archivedAt": v.ArchivedAt,
+        })
     }
+    versions = append(versions, map[string]interface{}{
+        "version":    current.CurrentVersion(),
+        "hash":       current.ContentHash(),
+        "hashFormat": modelMongo.CurrentHashFormat,
+        "updatedAt":  current.UpdatedAt,
+        "current":    true,
+    })
 
-    ref, err := s.pgRepo.GetReferenceByID(ctx, achievementID)
-    if err != nil {
-        return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"}) 
+    return c.JSON(fiber.Map{
+        "currentVersion":            current.CurrentVersion(),
+        "verifiedVersion":           current.VerifiedVersion,
+        "modifiedSinceVerification": modifiedSinceVerification(*current),
+        "versions":                  versions,
+    })
+}
+
+// GetAchievementVersion godoc
+// @Summary Get Achievement Version
+// @Description Get the full document of an achievement as it was at the given version
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Param v path int true "Version number"
+// @Success 200 {object} modelMongo.AchievementVersion
+// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Router /achievements/{id}/versions/{v} [get]
+func (s *AchievementService) GetAchievementVersion(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
     }
 
-    if ref.StudentID != studentID {
-        return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
+    v, err := c.ParamsInt("v")
+    if err != nil || v < 1 {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid version"})
     }
-    if ref.Status != "draft" {
-        return c.Status(400).JSON(fiber.Map{"error": "Cannot upload files to submitted/verified achievements"})
+
+    ref, current, err := s.loadVersionedAchievement(c)
+    if ref == nil {
+        return err
     }
 
-    file, err := c.FormFile("file")
+    version, err := s.findVersion(c.Context(), ref.MongoAchievementID, current, v)
     if err != nil {
-        return c.Status(400).JSON(fiber.Map{"error": "No file uploaded"})
+        return c.Status(404).JSON(fiber.Map{"error": "Version not found"})
     }
 
-    uploadDir := "./uploads"
-    if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-        os.Mkdir(uploadDir, 0755)
+    return c.JSON(version)
+}
+
+// DiffAchievementVersions godoc
+// @Summary Diff Achievement Versions
+// @Description Get a field-level diff between two versions of an achievement. Defaults to the verified (or previous) version against the current one.
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Param from query int false "Base version"
+// @Param to query int false "Target version"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,403,404,500 {object} map[string]interface{}
+// @Router /achievements/{id}/versions/diff [get]
+func (s *AchievementService) DiffAchievementVersions(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
     }
 
-    filename := uuid.New().String() + filepath.Ext(file.Filename)
-    filePath := fmt.Sprintf("%s/%s", uploadDir, filename)
+    ref, current, err := s.loadVersionedAchievement(c)
+    if ref == nil {
+        return err
+    }
 
-    if err := c.SaveFile(file, filePath); err != nil {
-        return c.Status(500).JSON(fiber.Map{"error": "Failed to save file to disk"})
+    to := c.QueryInt("to", current.CurrentVersion())
+    defaultFrom := current.VerifiedVersion
+    if defaultFrom == 0 {
+        defaultFrom = to - 1
     }
+    from := c.QueryInt("from", defaultFrom)
 
-    fileURL := fmt.Sprintf("/uploads/%s", filename)
-    
-    attachment := modelMongo.Attachment{
-        FileName:   file.Filename,
-        FileURL:    fileURL,
-        FileType:   file.Header.Get("Content-Type"),
-        UploadedAt: time.Now(),
+    if from < 1 || to < 1 || from > current.CurrentVersion() || to > current.CurrentVersion() {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid version range"})
     }
 
-    err = s.mongoRepo.AddAttachment(ctx, ref.MongoAchievementID, attachment)
+    ctx := c.Context()
+    base, err := s.findVersion(ctx, ref.MongoAchievementID, current, from)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": fmt.Sprintf("Version %d not found", from)})
+    }
+    target, err := s.findVersion(ctx, ref.MongoAchievementID, current, to)
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"error": "Failed to update database info", "details": err.Error()})
+        return c.Status(404).JSON(fiber.Map{"error": fmt.Sprintf("Version %d not found", to)})
     }
 
     return c.JSON(fiber.Map{
-        "message": "File uploaded successfully", 
-        "data": attachment,
+        "from":    from,
+        "to":      to,
+        "changes": utils.DiffAchievements(base.Snapshot, target.Snapshot),
     })
-}
\ No newline at end of file
+}
+
+// flagDuplicates compares a freshly submitted achievement with existing ones and
+// stores a warning for each likely duplicate. Detection never blocks a submission.
+func (s *AchievementService) flagDuplicates(ctx context.Context, ref modelPg.AchievementReference) {
+    detail, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID)
+    if err != nil {
+        log.Printf("duplicates: failed to load %s: %v", ref.ID, err)
+        return
+    }
+
+    candidates, err := s.mongoRepo.FindDuplicateCandidates(ctx, *detail)
+    if err != nil {
+        log.Printf("duplicates: failed to find candidates for %s: %v", ref.ID, err)
+        return
+    }
+    if len(candidates) == 0 {
+        return
+    }
+
+    var mongoIDs []string
+    for _, cand := range candidates {
+        mongoIDs = append(mongoIDs, cand.ID.Hex())
+    }
+
+    refs, err := s.pgRepo.GetReferencesByMongoIDs(ctx, mongoIDs)
+    if err != nil {
+        log.Printf("duplicates: failed to load candidate references for %s: %v", ref.ID, err)
+        return
+    }
+    refByMongo := make(map[string]modelPg.AchievementReference)
+    for _, r := range refs {
+        refByMongo[r.MongoAchievementID] = r
+    }
+
+    var warnings []modelMongo.DuplicateWarning
+    for _, cand := range candidates {
+        other, ok := refByMongo[cand.ID.Hex()]
+        if !ok || other.ID == ref.ID {
+            continue
+        }
+
+        reasons := utils.DuplicateReasons(*detail, cand)
+        if len(reasons) == 0 {
+            continue
+        }
+
+        warnings = append(warnings, modelMongo.DuplicateWarning{
+            AchievementID: other.ID.String(),
+            MongoID:       cand.ID.Hex(),
+            StudentID:     cand.StudentID,
+            Title:         cand.Title,
+            Reasons:       reasons,
+            DetectedAt:    time.Now(),
+        })
+    }
+
+    if err := s.mongoRepo.SetDuplicateWarnings(ctx, ref.MongoAchievementID, warnings); err != nil {
+        log.Printf("duplicates: failed to store warnings for %s: %v", ref.ID, err)
+    }
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "log"
+    "sort"
+    "strings"
+    "time"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    "StudenAchievementReportingSystem/app/storage"
+    "StudenAchievementReportingSystem/config"
+    "StudenAchievementReportingSystem/middleware"
+    "github.com/gofiber/fiber/v2"
+)
+
+// storedReference counts the attachments using one stored file
+type storedReference struct {
+    checksum    string
+    attachments int
+}
+
+// CheckStorage compares the attachment storage with the attachments of every
+// document, in every tenant and including the trash. Files neither an
+// attachment nor a blob refers to are orphans once they are older than grace,
+// as uploads store their file before the document is updated. With repair,
+// orphans are deleted, attachments whose file is gone are marked missing and
+// the mark is cleared from those whose file is back. Blob reference counts
+// that disagree with the attachments are only reported: uploads in progress
+// change them while the check runs.
+func (s *AchievementService) CheckStorage(ctx context.Context, repair bool, grace time.Duration) (*modelMongo.StorageCheckReport, error) {
+    report := &modelMongo.StorageCheckReport{
+        CheckedAt: time.Now(),
+        Orphans:   []modelMongo.OrphanFile{},
+        Missing:   []modelMongo.MissingAttachment{},
+        BlobDrift: []modelMongo.BlobDrift{},
+        Repaired:  repair,
+    }
+
+    // the database is read before storage is listed, so a file stored in
+    // between can only look like a young orphan, never like a missing file
+    docs, err := s.mongoRepo.FindStoredAttachments(ctx)
+    if err != nil {
+        return nil, err
+    }
+    blobs, err := s.blobs.List(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    referenced := make(map[string]bool)
+    refs := make(map[string]*storedReference)
+    for _, doc := range docs {
+        for _, att := range doc.Attachments {
+            // the scan deleted the files of infected attachments
+            if att.StorageKey == "" || (att.Scan != nil && att.Scan.Status == modelMongo.ScanInfected) {
+                continue
+            }
+            report.Attachments++
+            referenced[att.StorageKey] = true
+            referenced[previewKey(att.StorageKey)] = true
+            if refs[att.StorageKey] == nil {
+                refs[att.StorageKey] = &storedReference{checksum: att.Checksum}
+            }
+            refs[att.StorageKey].attachments++
+        }
+    }
+    for _, b := range blobs {
+        referenced[b.StorageKey] = true
+        referenced[previewKey(b.StorageKey)] = true
+    }
+
+    stored := make(map[string]bool)
+    cutoff := report.CheckedAt.Add(-grace)
+    err = s.files.List(ctx, func(o storage.Object) error {
+        report.Files++
+        stored[o.Key] = true
+        if !referenced[o.Key] && o.ModTime.Before(cutoff) {
+            report.Orphans = append(report.Orphans, modelMongo.OrphanFile{Key: o.Key, Size: o.Size, ModTime: o.ModTime})
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Key < report.Orphans[j].Key })
+
+    for _, doc := range docs {
+        for _, att := range doc.Attachments {
+            if att.StorageKey == "" || (att.Scan != nil && att.Scan.Status == modelMongo.ScanInfected) {
+                continue
+            }
+
+            if stored[att.StorageKey] || s.fileExists(ctx, att.StorageKey) {
+                if repair && att.MissingAt != nil {
+                    if err := s.mongoRepo.MarkAttachmentMissing(ctx, doc.ID.Hex(), att.ID, att.StorageKey, nil); err != nil {
+                        return nil, err
+                    }
+                    report.Restored++
+                }
+                continue
+            }
+
+            missing := modelMongo.MissingAttachment{
+                MongoID:      doc.ID.Hex(),
+                AttachmentID: att.ID,
+                FileName:     att.FileName,
+                StorageKey:   att.StorageKey,
+                MissingAt:    att.MissingAt,
+            }
+            if repair && att.MissingAt == nil {
+                now := time.Now()
+                if err := s.mongoRepo.MarkAttachmentMissing(ctx, doc.ID.Hex(), att.ID, att.StorageKey, &now); err != nil {
+                    return nil, err
+                }
+                missing.MissingAt = &now
+                report.Marked++
+            }
+            report.Missing = append(report.Missing, missing)
+        }
+    }
+
+    registered := make(map[string]bool, len(blobs))
+    for _, b := range blobs {
+        registered[b.StorageKey] = true
+        attachments := 0
+        if ref := refs[b.StorageKey]; ref != nil {
+            attachments = ref.attachments
+        }
+        if b.RefCount != attachments {
+            report.BlobDrift = append(report.BlobDrift, modelMongo.BlobDrift{Checksum: b.Checksum, StorageKey: b.StorageKey, RefCount: b.RefCount, Attachments: attachments})
+        }
+    }
+    for key, ref := range refs {
+        // shared files of attachments whose blob is gone
+        if strings.HasPrefix(key, blobKeyPrefix) && !registered[key] {
+            report.BlobDrift = append(report.BlobDrift, modelMongo.BlobDrift{Checksum: ref.checksum, StorageKey: key, Attachments: ref.attachments})
+        }
+    }
+    sort.Slice(report.BlobDrift, func(i, j int) bool { return report.BlobDrift[i].StorageKey < report.BlobDrift[j].StorageKey })
+
+    if repair {
+        for _, o := range report.Orphans {
+            if err := s.files.Delete(ctx, o.Key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+                log.Printf("storage check: failed to remove %s: %v", o.Key, err)
+                continue
+            }
+            report.Deleted++
+        }
+    }
+    return report, nil
+}
+
+// fileExists looks up a file the listing did not show before it is reported
+// missing. Errors other than not found count as present, so an unreachable
+// backend never gets attachments marked.
+func (s *AchievementService) fileExists(ctx context.Context, key string) bool {
+    body, err := s.files.Open(ctx, key)
+    if err == nil {
+        body.Close()
+        return true
+    }
+    if !errors.Is(err, storage.ErrNotFound) {
+        log.Printf("storage check: failed to open %s: %v", key, err)
+        return true
+    }
+    return false
+}
+
+// RunStorageCheck checks the attachment storage every cfg.Interval until ctx
+// is cancelled, repairing it when cfg.Repair is set
+func (s *AchievementService) RunStorageCheck(ctx context.Context, cfg config.StorageCheckConfig) {
+    ticker := time.NewTicker(cfg.Interval)
+    defer ticker.Stop()
+
+    for {
+        report, err := s.CheckStorage(ctx, cfg.Repair, cfg.Grace)
+        if err != nil {
+            log.Printf("storage check failed: %v", err)
+        } else if len(report.Orphans)+len(report.Missing)+len(report.BlobDrift) > 0 {
+            log.Printf("storage check: %d orphaned files, %d missing files, %d blob counts off; deleted %d, marked %d, restored %d",
+                len(report.Orphans), len(report.Missing), len(report.BlobDrift), report.Deleted, report.Marked, report.Restored)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// GetStorageCheck godoc
+// @Summary Check Attachment Storage
+// @Description Compare the attachment storage with the attachments of every tenant without changing anything. Lists files no attachment refers to (older than STORAGE_CHECK_GRACE_MINUTES), attachments whose file is missing and shared files whose reference count is off.
+// @Tags Storage
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} modelMongo.StorageCheckReport
+// @Failure 401,403,500 {object} map[string]interface{}
+// @Router /storage/check [get]
+func (s *AchievementService) GetStorageCheck(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:storage") {
+    return fiber.ErrForbidden
+    }
+
+    report, err := s.CheckStorage(c.Context(), false, config.LoadStorageCheck().Grace)
+    if err != nil {
+        log.Printf("storage check failed: %v", err)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to check storage"})
+    }
+    return c.JSON(report)
+}
+
+// RepairStorage godoc
+// @Summary Repair Attachment Storage
+// @Description Run the storage check and fix what it finds: orphaned files are deleted, attachments whose file is missing are marked (downloads answer 410) and the mark is cleared when the file is back. Blob reference counts are only reported.
+// @Tags Storage
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} modelMongo.StorageCheckReport
+// @Failure 401,403,500 {object} map[string]interface{}
+// @Router /storage/check/repair [post]
+func (s *AchievementService) RepairStorage(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:storage") {
+    return fiber.ErrForbidden
+    }
+
+    report, err := s.CheckStorage(c.Context(), true, config.LoadStorageCheck().Grace)
+    if err != nil {
+        log.Printf("storage repair failed: %v", err)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to repair storage"})
+    }
+    return c.JSON(report)
+}
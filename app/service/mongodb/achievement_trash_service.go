@@ -0,0 +1,190 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "log"
+    "time"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    "StudenAchievementReportingSystem/config"
+    "StudenAchievementReportingSystem/middleware"
+    "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetTrash godoc
+// @Summary Get Deleted Achievements
+// @Description List the student's deleted achievements that can still be restored
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401,403,404,500 {object} map[string]interface{}
+// @Router /achievements/trash [get]
+func (s *AchievementService) GetTrash(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:delete") {
+        return fiber.ErrForbidden
+    }
+
+    userID, err := getUserIDFromToken(c)
+    if err != nil {
+        return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+    }
+
+    studentID, err := s.pgRepo.GetStudentByUserID(ctx, userID)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Student profile not found"})
+    }
+
+    retention := config.LoadTrash().Retention
+    refs, err := s.pgRepo.GetTrash(ctx, studentID, time.Now().Add(-retention))
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch trash"})
+    }
+
+    mongoIDs := make([]string, 0, len(refs))
+    for _, ref := range refs {
+        mongoIDs = append(mongoIDs, ref.MongoAchievementID)
+    }
+
+    details := make(map[string]modelMongo.Achievement)
+    if len(mongoIDs) > 0 {
+        docs, err := s.mongoRepo.FindAllDetails(ctx, mongoIDs)
+        if err != nil {
+            return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement details"})
+        }
+        for _, d := range docs {
+            details[d.ID.Hex()] = d
+        }
+    }
+
+    items := make([]fiber.Map, 0, len(refs))
+    for _, ref := range refs {
+        d := details[ref.MongoAchievementID]
+        items = append(items, fiber.Map{
+            "id":              ref.ID,
+            "title":           d.Title,
+            "achievementType": d.AchievementType,
+            "createdAt":       ref.CreatedAt,
+            "deletedAt":       ref.DeletedAt,
+            "purgeAt":         ref.DeletedAt.Add(retention),
+        })
+    }
+
+    return c.JSON(fiber.Map{"data": items})
+}
+
+// RestoreAchievement godoc
+// @Summary Restore Achievement
+// @Description Restore a deleted achievement from the trash as a draft (Owner only, within the retention window)
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Achievement ID (UUID)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,403,404,410,500 {object} map[string]interface{}
+// @Router /achievements/{id}/restore [post]
+func (s *AchievementService) RestoreAchievement(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:delete") {
+        return fiber.ErrForbidden
+    }
+
+    achievementID, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid achievement ID"})
+    }
+
+    userID, err := getUserIDFromToken(c)
+    if err != nil {
+        return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+    }
+
+    studentID, err := s.pgRepo.GetStudentByUserID(ctx, userID)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Student profile not found"})
+    }
+
+    ref, err := s.pgRepo.GetDeletedReferenceByID(ctx, achievementID)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Achievement not found in trash"})
+    }
+
+    if ref.StudentID != studentID {
+        return c.Status(403).JSON(fiber.Map{"error": "Forbidden: You do not own this data"})
+    }
+
+    if ref.DeletedAt == nil || time.Since(*ref.DeletedAt) > config.LoadTrash().Retention {
+        return c.Status(410).JSON(fiber.Map{"error": "Retention window has passed, the achievement can no longer be restored"})
+    }
+
+    if err := s.mongoRepo.RestoreFromTrash(ctx, ref.MongoAchievementID); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to restore achievement details"})
+    }
+
+    if err := s.pgRepo.RestoreReference(ctx, achievementID); err != nil {
+        _ = s.mongoRepo.MoveToTrash(ctx, ref.MongoAchievementID)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to restore achievement"})
+    }
+
+    return c.JSON(fiber.Map{"message": "Achievement restored", "id": achievementID, "status": "draft"})
+}
+
+// PurgeTrash permanently removes achievements deleted before the given time,
+// MongoDB document and uploaded files first, then the Postgres reference, so an
+// interrupted purge is picked up again on the next run. It returns how many
+// achievements were purged.
+func (s *AchievementService) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+    refs, err := s.pgRepo.GetPurgeableReferences(ctx, deletedBefore)
+    if err != nil {
+        return 0, err
+    }
+
+    purged := 0
+    for _, ref := range refs {
+        detail, err := s.mongoRepo.FindOne(ctx, ref.MongoAchievementID)
+        switch {
+        case err == nil:
+            s.removeStoredFiles(ctx, detail.Attachments)
+            if err := s.mongoRepo.DeleteAchievement(ctx, ref.MongoAchievementID); err != nil {
+                log.Printf("trash purge: failed to delete achievement %s: %v", ref.ID, err)
+                continue
+            }
+        case errors.Is(err, mongo.ErrNoDocuments):
+            // document already gone, only the reference is left
+        default:
+            log.Printf("trash purge: failed to load achievement %s: %v", ref.ID, err)
+            continue
+        }
+
+        if err := s.pgRepo.PurgeReference(ctx, ref.ID); err != nil {
+            log.Printf("trash purge: failed to delete reference %s: %v", ref.ID, err)
+            continue
+        }
+        purged++
+    }
+    return purged, nil
+}
+
+// RunTrashPurge purges expired achievements every cfg.PurgeInterval until ctx is done
+func (s *AchievementService) RunTrashPurge(ctx context.Context, cfg config.TrashConfig) {
+    ticker := time.NewTicker(cfg.PurgeInterval)
+    defer ticker.Stop()
+
+    for {
+        n, err := s.PurgeTrash(ctx, time.Now().Add(-cfg.Retention))
+        if err != nil {
+            log.Printf("trash purge failed: %v", err)
+        } else if n > 0 {
+            log.Printf("trash purge: removed %d achievements", n)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "regexp"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    repoMongo "StudenAchievementReportingSystem/app/repository/mongodb"
+    "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
+    "github.com/gofiber/fiber/v2"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+var typeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+type AchievementTypeService struct {
+    typeRepo repoMongo.AchievementTypeRepository
+}
+
+func NewAchievementTypeService(t repoMongo.AchievementTypeRepository) *AchievementTypeService {
+    return &AchievementTypeService{typeRepo: t}
+}
+
+// builtInTypes lists the types backed by the typed AchievementDetails fields.
+// A registry entry with the same code overrides name, schema and point rules.
+func builtInTypes() []modelMongo.AchievementTypeDefinition {
+    names := map[string]string{
+        utils.TypeCompetition:   "Competition",
+        utils.TypePublication:   "Publication",
+        utils.TypeOrganization:  "Organization",
+        utils.TypeCertification: "Certification",
+        utils.TypeOther:         "Other",
+    }
+
+    var list []modelMongo.AchievementTypeDefinition
+    for _, code := range utils.AchievementTypes {
+        list = append(list, modelMongo.AchievementTypeDefinition{
+            Code:                    code,
+            Name:                    names[code],
+            BuiltIn:                 true,
+            IsActive:                true,
+            RequiredAttachmentKinds: []string{},
+        })
+    }
+    return list
+}
+
+// GetAllTypes godoc
+// @Summary Get Achievement Types
+// @Description Get built-in and admin-defined achievement types with their custom field schemas and UI hints
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param all query bool false "Include inactive types (requires manage:achievement_types)"
+// @Success 200 {array} modelMongo.AchievementTypeDefinition
+// @Failure 403,500 {object} map[string]interface{}
+// @Router /achievement-types [get]
+func (s *AchievementTypeService) GetAllTypes(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    activeOnly := !(c.QueryBool("all") && middleware.HasPermission(c, "manage:achievement_types"))
+
+    registered, err := s.typeRepo.FindAll(c.Context(), false)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement types"})
+    }
+
+    byCode := make(map[string]modelMongo.AchievementTypeDefinition)
+    for _, t := range registered {
+        byCode[t.Code] = t
+    }
+
+    result := make([]modelMongo.AchievementTypeDefinition, 0)
+    for _, t := range builtInTypes() {
+        if override, ok := byCode[t.Code]; ok {
+            t = override
+            t.BuiltIn = true
+            delete(byCode, t.Code)
+        }
+        if !activeOnly || t.IsActive {
+            result = append(result, t)
+        }
+    }
+    for _, t := range registered {
+        if _, ok := byCode[t.Code]; ok && (!activeOnly || t.IsActive) {
+            result = append(result, t)
+        }
+    }
+
+    return c.JSON(result)
+}
+
+// GetTypeByCode godoc
+// @Summary Get Achievement Type
+// @Description Get a single achievement type definition by code
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param code path string true "Type code"
+// @Success 200 {object} modelMongo.AchievementTypeDefinition
+// @Failure 403,404 {object} map[string]interface{}
+// @Router /achievement-types/{code} [get]
+func (s *AchievementTypeService) GetTypeByCode(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    code := c.Params("code")
+    def, err := s.typeRepo.FindByCode(c.Context(), code)
+    if err == nil {
+        def.BuiltIn = utils.IsBuiltInType(code)
+        return c.JSON(def)
+    }
+
+    for _, t := range builtInTypes() {
+        if t.Code == code {
+            return c.JSON(t)
+        }
+    }
+    return c.Status(404).JSON(fiber.Map{"error": "Achievement type not found"})
+}
+
+// CreateType godoc
+// @Summary Create Achievement Type
+// @Description Register a new achievement type with a JSON Schema for its custom fields (Admin only)
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body modelMongo.AchievementTypeDefinition true "Type Definition"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400,403,409,500 {object} map[string]interface{}
+// @Router /achievement-types [post]
+func (s *AchievementTypeService) CreateType(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:achievement_types") {
+        return fiber.ErrForbidden
+    }
+
+    var req modelMongo.AchievementTypeDefinition
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+
+    if errs := validateTypeDefinition(req, true); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    req.BuiltIn = utils.IsBuiltInType(req.Code)
+    req.IsActive = true
+    if req.RequiredAttachmentKinds == nil {
+        req.RequiredAttachmentKinds = []string{}
+    }
+
+    id, err := s.typeRepo.Insert(c.Context(), req)
+    if errors.Is(err, repoMongo.ErrTypeCodeExists) {
+        return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to save achievement type"})
+    }
+
+    return c.Status(201).JSON(fiber.Map{
+        "message": "Achievement type created successfully",
+        "id":      id,
+        "code":    req.Code,
+    })
+}
+
+// UpdateType godoc
+// @Summary Update Achievement Type
+// @Description Update schema, UI hints, point rules or required attachments of an achievement type (Admin only)
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param code path string true "Type code"
+// @Param request body modelMongo.AchievementTypeDefinition true "Type Definition"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,500 {object} map[string]interface{}
+// @Router /achievement-types/{code} [put]
+func (s *AchievementTypeService) UpdateType(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:achievement_types") {
+        return fiber.ErrForbidden
+    }
+
+    code := c.Params("code")
+
+    var req modelMongo.AchievementTypeDefinition
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    req.Code = code
+
+    if errs := validateTypeDefinition(req, false); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+    if req.RequiredAttachmentKinds == nil {
+        req.RequiredAttachmentKinds = []string{}
+    }
+
+    err := s.typeRepo.Update(c.Context(), code, req)
+    if errors.Is(err, mongo.ErrNoDocuments) && utils.IsBuiltInType(code) {
+        // first customisation of a built-in type creates its registry entry
+        req.BuiltIn = true
+        req.IsActive = true
+        _, err = s.typeRepo.Insert(c.Context(), req)
+    }
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return c.Status(404).JSON(fiber.Map{"error": "Achievement type not found"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement type"})
+    }
+
+    return c.JSON(fiber.Map{"message": "Achievement type updated successfully"})
+}
+
+// DeleteType godoc
+// @Summary Deactivate Achievement Type
+// @Description Deactivate an admin-defined achievement type. Existing achievements keep their data. (Admin only)
+// @Tags Achievements
+// @Security BearerAuth
+// @Produce json
+// @Param code path string true "Type code"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,500 {object} map[string]interface{}
+// @Router /achievement-types/{code} [delete]
+func (s *AchievementTypeService) DeleteType(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:achievement_types") {
+        return fiber.ErrForbidden
+    }
+
+    code := c.Params("code")
+    if utils.IsBuiltInType(code) {
+        return c.Status(400).JSON(fiber.Map{"error": "Built-in achievement types cannot be deactivated"})
+    }
+
+    err := s.typeRepo.SetActive(c.Context(), code, false)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return c.Status(404).JSON(fiber.Map{"error": "Achievement type not found"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to deactivate achievement type"})
+    }
+
+    return c.JSON(fiber.Map{"message": "Achievement type deactivated"})
+}
+
+func validateTypeDefinition(def modelMongo.AchievementTypeDefinition, isNew bool) []utils.FieldError {
+    var errs []utils.FieldError
+
+    if isNew && !typeCodePattern.MatchString(def.Code) {
+        errs = append(errs, utils.FieldError{Field: "code", Message: "must be 2-50 lowercase letters, digits or underscores"})
+    }
+    if def.Name == "" {
+        errs = append(errs, utils.FieldError{Field: "name", Message: "is required"})
+    }
+    if def.CustomFieldSchema != nil {
+        if def.CustomFieldSchema.Type != "object" {
+            errs = append(errs, utils.FieldError{Field: "customFieldSchema.type", Message: "must be object"})
+        }
+        errs = append(errs, utils.CheckJSONSchema(def.CustomFieldSchema, "customFieldSchema")...)
+    }
+    if def.PointRule.DefaultPoints < 0 {
+        errs = append(errs, utils.FieldError{Field: "pointRule.defaultPoints", Message: "must not be negative"})
+    }
+    for level, p := range def.PointRule.ByLevel {
+        if p < 0 {
+            errs = append(errs, utils.FieldError{Field: "pointRule.byLevel." + level, Message: "must not be negative"})
+        }
+    }
+    for i, kind := range def.RequiredAttachmentKinds {
+        errs = append(errs, utils.ValidateAttachmentInfo(fmt.Sprintf("requiredAttachmentKinds[%d].", i), "", kind)...)
+    }
+
+    return errs
+}
@@ -0,0 +1,494 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+    repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/app/storage"
+    "StudenAchievementReportingSystem/config"
+    "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
+    "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
+)
+
+// tusVersion is the only version of the tus protocol the upload endpoints speak
+const tusVersion = "1.0.0"
+
+// tusExtensions are the optional parts of the protocol that are supported
+const tusExtensions = "creation,expiration,termination"
+
+// uploadPath is the route of one resumable upload, returned as its Location
+const uploadPath = "/api/v1/achievements/%s/uploads/%s"
+
+// maxOpenUploads bounds the unfinished resumable uploads of one user, so
+// abandoned uploads cannot fill the staging disk before they expire
+const maxOpenUploads = 5
+
+// UploadService accepts attachments in chunks through the tus 1.0 protocol.
+// A completed upload goes through the same checks as a direct upload and is
+// added to the achievement like one.
+type UploadService struct {
+    achievements *AchievementService
+    sessions     repoPg.UploadSessionRepository
+    staging      *storage.Staging
+    expiry       time.Duration
+}
+
+func NewUploadService(a *AchievementService, u repoPg.UploadSessionRepository, st *storage.Staging, expiry time.Duration) *UploadService {
+    return &UploadService{achievements: a, sessions: u, staging: st, expiry: expiry}
+}
+
+// tusStart sets the headers every tus response carries and rejects clients
+// speaking another protocol version. A non-zero status is the response to
+// send instead.
+func tusStart(c *fiber.Ctx) int {
+    c.Set("Tus-Resumable", tusVersion)
+    if c.Get("Tus-Resumable") != tusVersion {
+        c.Set("Tus-Version", tusVersion)
+        return fiber.StatusPreconditionFailed
+    }
+    return 0
+}
+
+// tusError answers with status; HEAD responses cannot carry the message
+func tusError(c *fiber.Ctx, status int, msg string) error {
+    if c.Method() == fiber.MethodHead {
+        return c.SendStatus(status)
+    }
+    return c.Status(status).JSON(fiber.Map{"error": msg})
+}
+
+// parseUploadMetadata decodes an Upload-Metadata header: comma separated
+// keys, each followed by its base64 encoded value
+func parseUploadMetadata(header string) (map[string]string, error) {
+    meta := map[string]string{}
+    if strings.TrimSpace(header) == "" {
+        return meta, nil
+    }
+    for _, pair := range strings.Split(header, ",") {
+        fields := strings.Fields(pair)
+        if len(fields) == 0 || len(fields) > 2 {
+            return nil, errors.New("malformed Upload-Metadata")
+        }
+        value := ""
+        if len(fields) == 2 {
+            decoded, err := base64.StdEncoding.DecodeString(fields[1])
+            if err != nil {
+                return nil, fmt.Errorf("Upload-Metadata value of %s is not base64", fields[0])
+            }
+            value = string(decoded)
+        }
+        meta[fields[0]] = value
+    }
+    return meta, nil
+}
+
+// checkDeclaredUpload rejects an upload before any bytes are sent when its
+// extension belongs to no allowed type or it is larger than that type allows.
+// The content is checked again once the upload is complete.
+func (s *UploadService) checkDeclaredUpload(c *fiber.Ctx, filename string, size int64) (int, string) {
+    ext := filepath.Ext(filename)
+    var declared *utils.FileType
+    for i := range utils.FileTypes {
+        if utils.FileTypes[i].HasExtension(ext) {
+            declared = &utils.FileTypes[i]
+            break
+        }
+    }
+    if declared == nil {
+        return 415, "Unsupported file type"
+    }
+
+    allowed, err := s.achievements.uploadTypes.GetByCode(c.Context(), declared.Code)
+    if errors.Is(err, sql.ErrNoRows) {
+        return 415, fmt.Sprintf("Uploading %s files is not allowed", declared.MimeType)
+    }
+    if err != nil {
+        return 500, "Failed to check attachment type"
+    }
+    if size > allowed.MaxSizeBytes {
+        return 413, fmt.Sprintf("File exceeds the limit of %d bytes for %s", allowed.MaxSizeBytes, declared.MimeType)
+    }
+    return 0, ""
+}
+
+// sessionFor loads the upload addressed by the request. Uploads of other
+// users or other achievements are reported as missing.
+func (s *UploadService) sessionFor(c *fiber.Ctx) (*modelPg.UploadSession, int, string) {
+    uploadID, err := uuid.Parse(c.Params("uploadId"))
+    if err != nil {
+        return nil, 404, "Upload not found"
+    }
+    userID, err := getUserIDFromToken(c)
+    if err != nil {
+        return nil, 401, "Unauthorized"
+    }
+
+    session, err := s.sessions.GetByID(c.Context(), uploadID)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil, 404, "Upload not found"
+    }
+    if err != nil {
+        return nil, 500, "Failed to fetch upload"
+    }
+    if session.UserID != userID || session.AchievementID.String() != c.Params("id") {
+        return nil, 404, "Upload not found"
+    }
+    return session, 0, ""
+}
+
+// discard removes an upload and the bytes received for it
+func (s *UploadService) discard(ctx context.Context, id uuid.UUID) {
+    if err := s.staging.Remove(id.String()); err != nil {
+        log.Printf("uploads: failed to remove staged %s: %v", id, err)
+    }
+    if err := s.sessions.Delete(ctx, id); err != nil {
+        log.Printf("uploads: failed to delete session %s: %v", id, err)
+    }
+}
+
+func setUploadHeaders(c *fiber.Ctx, session *modelPg.UploadSession) {
+    c.Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+    c.Set("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// UploadOptions godoc
+// @Summary Resumable Upload Capabilities
+// @Description tus 1.0 discovery: the supported versions, extensions and the largest file accepted.
+// @Tags Achievements
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Success 204 "Tus-Version, Tus-Extension and Tus-Max-Size headers"
+// @Router /achievements/{id}/uploads [options]
+func (s *UploadService) UploadOptions(c *fiber.Ctx) error {
+    c.Set("Tus-Resumable", tusVersion)
+    c.Set("Tus-Version", tusVersion)
+    c.Set("Tus-Extension", tusExtensions)
+    c.Set("Tus-Max-Size", strconv.FormatInt(utils.MaxResumableUploadSize, 10))
+    return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateUpload godoc
+// @Summary Start Resumable Upload
+// @Description Start a tus 1.0 upload of one attachment (Draft only). Upload-Metadata carries the base64 encoded `filename` and optional `caption` and `kind`. The returned Location takes the file in chunks of at most 10 MB and expires when no chunk arrives in time. The storage quotas are checked when the upload starts and again when it completes.
+// @Tags Achievements
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param Tus-Resumable header string true "1.0.0"
+// @Param Upload-Length header integer true "File size in bytes"
+// @Param Upload-Metadata header string true "filename <base64>,caption <base64>,kind <base64>"
+// @Success 201 "Location and Upload-Expires headers"
+// @Failure 400,401,403,404,412,413,415,429,500 {object} map[string]interface{}
+// @Router /achievements/{id}/uploads [post]
+func (s *UploadService) CreateUpload(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+    if status := tusStart(c); status != 0 {
+        return tusError(c, status, "Unsupported tus version")
+    }
+
+    ref, status, msg := s.achievements.draftForAttachments(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    userID, _ := getUserIDFromToken(c)
+
+    if c.Get("Upload-Defer-Length") != "" {
+        return c.Status(400).JSON(fiber.Map{"error": "Upload-Length must be known when the upload starts"})
+    }
+    length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+    if err != nil || length <= 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid Upload-Length"})
+    }
+    if length > utils.MaxResumableUploadSize {
+        return c.Status(413).JSON(fiber.Map{"error": fmt.Sprintf("Files cannot be larger than %d bytes", utils.MaxResumableUploadSize)})
+    }
+
+    meta, err := parseUploadMetadata(c.Get("Upload-Metadata"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    filename := filepath.Base(strings.ReplaceAll(strings.TrimSpace(meta["filename"]), "\\", "/"))
+    caption := strings.TrimSpace(meta["caption"])
+    kind := strings.TrimSpace(meta["kind"])
+
+    var errs []utils.FieldError
+    if filename == "." || filename == "/" {
+        errs = append(errs, utils.FieldError{Field: "filename", Message: "is required"})
+    }
+    errs = append(errs, utils.ValidateAttachmentInfo("", caption, kind)...)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if status, msg := s.checkDeclaredUpload(c, filename, length); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    if status, msg := s.achievements.checkQuota(ctx, ref, length, 1, 0); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    now := time.Now()
+    open, err := s.sessions.CountByUser(ctx, userID, now)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to count uploads"})
+    }
+    if open >= maxOpenUploads {
+        return c.Status(429).JSON(fiber.Map{"error": fmt.Sprintf("At most %d uploads can be unfinished at once", maxOpenUploads)})
+    }
+
+    session := &modelPg.UploadSession{
+        AchievementID: ref.ID,
+        UserID:        userID,
+        FileName:      filename,
+        Caption:       caption,
+        Kind:          strings.ToLower(kind),
+        Length:        length,
+        ExpiresAt:     now.Add(s.expiry),
+    }
+    if err := s.sessions.Create(ctx, session); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to start upload"})
+    }
+
+    c.Set(fiber.HeaderLocation, fmt.Sprintf(uploadPath, ref.ID, session.ID))
+    c.Set("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+    return c.SendStatus(fiber.StatusCreated)
+}
+
+// UploadStatus godoc
+// @Summary Resumable Upload Offset
+// @Description How many bytes of a tus upload were received, to resume it from there.
+// @Tags Achievements
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param uploadId path string true "Upload ID"
+// @Param Tus-Resumable header string true "1.0.0"
+// @Success 200 "Upload-Offset, Upload-Length and Upload-Expires headers"
+// @Failure 401,403,404,410,412
+// @Router /achievements/{id}/uploads/{uploadId} [head]
+func (s *UploadService) UploadStatus(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+    if status := tusStart(c); status != 0 {
+        return tusError(c, status, "Unsupported tus version")
+    }
+
+    session, status, msg := s.sessionFor(c)
+    if status != 0 {
+        return tusError(c, status, msg)
+    }
+    if time.Now().After(session.ExpiresAt) {
+        return tusError(c, 410, "Upload expired")
+    }
+
+    setUploadHeaders(c, session)
+    c.Set("Upload-Length", strconv.FormatInt(session.Length, 10))
+    c.Set(fiber.HeaderCacheControl, "no-store")
+    return c.SendStatus(fiber.StatusOK)
+}
+
+// UploadChunk godoc
+// @Summary Send Resumable Upload Chunk
+// @Description Append up to 10 MB to a tus upload at Upload-Offset. The chunk that completes the file adds it to the achievement like a direct upload; its ID is returned in the Attachment-Id header. If that step fails with a server error, resend an empty chunk at the final offset to retry it.
+// @Tags Achievements
+// @Security BearerAuth
+// @Accept application/offset+octet-stream
+// @Param id path string true "Achievement ID (UUID)"
+// @Param uploadId path string true "Upload ID"
+// @Param Tus-Resumable header string true "1.0.0"
+// @Param Upload-Offset header integer true "Bytes received so far"
+// @Success 204 "Upload-Offset and Upload-Expires headers, Attachment-Id once complete"
+// @Failure 400,401,403,404,409,410,412,413,415,500 {object} map[string]interface{}
+// @Router /achievements/{id}/uploads/{uploadId} [patch]
+func (s *UploadService) UploadChunk(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+    if status := tusStart(c); status != 0 {
+        return tusError(c, status, "Unsupported tus version")
+    }
+    if c.Get(fiber.HeaderContentType) != "application/offset+octet-stream" {
+        return c.Status(415).JSON(fiber.Map{"error": "Content-Type must be application/offset+octet-stream"})
+    }
+    offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+    if err != nil || offset < 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid Upload-Offset"})
+    }
+
+    session, status, msg := s.sessionFor(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    now := time.Now()
+    if now.After(session.ExpiresAt) {
+        return c.Status(410).JSON(fiber.Map{"error": "Upload expired"})
+    }
+    if offset != session.Offset {
+        c.Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+        return c.Status(409).JSON(fiber.Map{"error": "Upload-Offset does not match the bytes received"})
+    }
+    chunk := c.Body()
+    end := offset + int64(len(chunk))
+    if end > session.Length {
+        return c.Status(413).JSON(fiber.Map{"error": "Chunk goes beyond Upload-Length"})
+    }
+
+    // the chunk is staged while the session is locked at this offset
+    var writeErr error
+    expiresAt := now.Add(s.expiry)
+    err = s.sessions.Advance(ctx, session.ID, offset, end, expiresAt, func() error {
+        writeErr = s.staging.Write(session.ID.String(), offset, chunk)
+        return writeErr
+    })
+    if errors.Is(err, sql.ErrNoRows) {
+        return c.Status(409).JSON(fiber.Map{"error": "Upload was changed by another request"})
+    }
+    if writeErr != nil {
+        log.Printf("uploads: failed to stage %s: %v", session.ID, writeErr)
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to store chunk"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update upload"})
+    }
+    session.Offset = end
+    session.ExpiresAt = expiresAt
+    setUploadHeaders(c, session)
+
+    if !session.Complete() {
+        return c.SendStatus(fiber.StatusNoContent)
+    }
+
+    attachment, status, msg := s.complete(c, session)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    c.Set("Attachment-Id", attachment.ID)
+    return c.SendStatus(fiber.StatusNoContent)
+}
+
+// complete hands a fully received file to the attachment pipeline. The
+// upload is removed unless that failed on the server side, so the client can
+// retry the last chunk.
+func (s *UploadService) complete(c *fiber.Ctx, session *modelPg.UploadSession) (attachment modelMongo.Attachment, status int, msg string) {
+    ctx := c.Context()
+    defer func() {
+        if status < 500 {
+            s.discard(ctx, session.ID)
+        }
+    }()
+
+    // the achievement may have been submitted while the file was uploading
+    ref, status, msg := s.achievements.draftForAttachments(c)
+    if status != 0 {
+        return attachment, status, msg
+    }
+
+    // files added since the upload started may have used up the quota
+    if status, msg := s.achievements.checkQuota(ctx, ref, session.Length, 1, 0); status != 0 {
+        return attachment, status, msg
+    }
+
+    staged, err := s.staging.Open(session.ID.String())
+    if err != nil {
+        log.Printf("uploads: failed to open staged %s: %v", session.ID, err)
+        return attachment, 500, "Failed to read upload"
+    }
+    defer staged.Close()
+
+    attachment, status, msg = s.achievements.storeFile(c, session.FileName, session.Length, staged)
+    if status != 0 {
+        return attachment, status, msg
+    }
+    attachment.ID = uuid.New().String()
+    attachment.Caption = session.Caption
+    attachment.Kind = session.Kind
+
+    if err := s.achievements.mongoRepo.AddAttachments(ctx, ref.MongoAchievementID, []modelMongo.Attachment{attachment}); err != nil {
+        s.achievements.removeStoredFiles(ctx, []modelMongo.Attachment{attachment})
+        return attachment, 500, "Failed to update database info"
+    }
+    if err := s.achievements.bumpForAttachments(c, ref); err != nil {
+        log.Printf("uploads: failed to bump version of %s: %v", ref.ID, err)
+    }
+    return attachment, 0, ""
+}
+
+// CancelUpload godoc
+// @Summary Cancel Resumable Upload
+// @Description tus termination: drop an unfinished upload and the bytes received for it.
+// @Tags Achievements
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param uploadId path string true "Upload ID"
+// @Param Tus-Resumable header string true "1.0.0"
+// @Success 204
+// @Failure 401,403,404,412 {object} map[string]interface{}
+// @Router /achievements/{id}/uploads/{uploadId} [delete]
+func (s *UploadService) CancelUpload(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:create") {
+    return fiber.ErrForbidden
+    }
+    if status := tusStart(c); status != 0 {
+        return tusError(c, status, "Unsupported tus version")
+    }
+
+    session, status, msg := s.sessionFor(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    s.discard(c.Context(), session.ID)
+    return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PurgeExpiredUploads removes resumable uploads that received no chunk before
+// they expired, together with their staged bytes
+func (s *UploadService) PurgeExpiredUploads(ctx context.Context) (int, error) {
+    ids, err := s.sessions.DeleteExpired(ctx, time.Now())
+    if err != nil {
+        return 0, err
+    }
+    for _, id := range ids {
+        if err := s.staging.Remove(id.String()); err != nil {
+            log.Printf("uploads: failed to remove staged %s: %v", id, err)
+        }
+    }
+    return len(ids), nil
+}
+
+// RunUploadCleanup purges abandoned uploads every cfg.CleanupInterval until
+// ctx is cancelled
+func (s *UploadService) RunUploadCleanup(ctx context.Context, cfg config.ResumableUploadConfig) {
+    ticker := time.NewTicker(cfg.CleanupInterval)
+    defer ticker.Stop()
+
+    for {
+        n, err := s.PurgeExpiredUploads(ctx)
+        if err != nil {
+            log.Printf("upload cleanup failed: %v", err)
+        } else if n > 0 {
+            log.Printf("upload cleanup: removed %d abandoned uploads", n)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+    "context"
+
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+    repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+)
+
+// studentInScope reports whether the student belongs to a program study the coordinator oversees
+func studentInScope(ctx context.Context, students repoPg.StudentRepository, scope *modelPg.CoordinatorScope, studentID string) bool {
+    if students == nil {
+        return false
+    }
+    ids, err := students.GetStudentsByIDs(ctx, []string{studentID})
+    if err != nil || len(ids) == 0 {
+        return false
+    }
+    return scope.Covers(ids[0].ProgramStudyID)
+}
@@ -1,40 +1,146 @@
 package service
 
 import (
+    "context"
+    "sort"
+    modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
     "github.com/gofiber/fiber/v2"
     "github.com/google/uuid"
     repoMongo "StudenAchievementReportingSystem/app/repository/mongodb"
     repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/config"
     "StudenAchievementReportingSystem/middleware"
 )
 
 type ReportService struct {
     mongoRepo   repoMongo.AchievementRepository
     studentRepo repoPg.StudentRepository
+    pgRepo      repoPg.AchievementRepoPostgres
+    periods     repoPg.AcademicPeriodRepository
+    orgRepo     repoPg.OrganizationRepository
 }
 
-func NewReportService(m repoMongo.AchievementRepository, s repoPg.StudentRepository) *ReportService {
-    return &ReportService{mongoRepo: m, studentRepo: s}
+func NewReportService(m repoMongo.AchievementRepository, s repoPg.StudentRepository, p repoPg.AchievementRepoPostgres, ap repoPg.AcademicPeriodRepository, o repoPg.OrganizationRepository) *ReportService {
+    return &ReportService{mongoRepo: m, studentRepo: s, pgRepo: p, periods: ap, orgRepo: o}
+}
+
+// report groupings of GetOrganizationReport
+var unitOf = map[string]func(modelPg.StudentUnits) *modelPg.OrgUnit{
+    "program_study": func(u modelPg.StudentUnits) *modelPg.OrgUnit { return u.ProgramStudy },
+    "department":    func(u modelPg.StudentUnits) *modelPg.OrgUnit { return u.Department },
+    "faculty":       func(u modelPg.StudentUnits) *modelPg.OrgUnit { return u.Faculty },
+}
+
+// statsFilter reads the report filters from the query string and limits
+// coordinators to the students of their scope
+func (s *ReportService) statsFilter(c *fiber.Ctx) (modelMongo.StatsFilter, int, string) {
+    var f modelMongo.StatsFilter
+    if value := c.Query("period"); value != "" {
+        period, status, msg := resolvePeriod(c.Context(), s.periods, value)
+        if status != 0 {
+            return f, status, msg
+        }
+        f.PeriodID = period.ID.String()
+    }
+
+    inScope, err := s.scopedStudents(c)
+    if err != nil {
+        return f, 500, "Failed to load coordinator scope"
+    }
+    if inScope != nil {
+        f.StudentIDs = make([]string, 0, len(inScope))
+        for id := range inScope {
+            f.StudentIDs = append(f.StudentIDs, id)
+        }
+        sort.Strings(f.StudentIDs)
+    }
+    return f, 0, ""
+}
+
+// scopedStudents returns the ids of the students a coordinator oversees, or nil
+// for callers whose reports are not limited to a scope
+func (s *ReportService) scopedStudents(c *fiber.Ctx) (map[string]bool, error) {
+    scope, err := middleware.CoordinatorScope(c, s.orgRepo)
+    if err != nil || scope == nil {
+        return nil, err
+    }
+
+    inScope := make(map[string]bool)
+    if s.orgRepo == nil || len(scope.Covered) == 0 {
+        return inScope, nil
+    }
+    units, err := s.orgRepo.GetStudentUnits(c.Context())
+    if err != nil {
+        return nil, err
+    }
+    for _, su := range units {
+        if su.ProgramStudy != nil && scope.Covers(&su.ProgramStudy.ID) {
+            inScope[su.StudentID.String()] = true
+        }
+    }
+    return inScope, nil
+}
+
+// namePeriods replaces the period ids of a distribution with the period names
+func (s *ReportService) namePeriods(ctx context.Context, byID map[string]int) map[string]int {
+    if s.periods == nil || len(byID) == 0 {
+        return byID
+    }
+    periods, err := s.periods.GetAll(ctx)
+    if err != nil {
+        return byID
+    }
+
+    names := make(map[string]string, len(periods))
+    for _, p := range periods {
+        names[p.ID.String()] = p.Name
+    }
+    named := make(map[string]int, len(byID))
+    for id, n := range byID {
+        if name, ok := names[id]; ok {
+            named[name] += n
+        } else {
+            named[id] += n
+        }
+    }
+    return named
+}
+
+func containsString(list []string, v string) bool {
+    for _, item := range list {
+        if item == v {
+            return true
+        }
+    }
+    return false
 }
 
 // GetStatistics godoc
 // @Summary Get Global Statistics
-// @Description Get achievement statistics and leaderboard (Admin only)
+// @Description Get achievement statistics and leaderboard. Coordinators only see the students of their scope.
 // @Tags Reports
 // @Security BearerAuth
 // @Produce json
+// @Param period query string false "Academic period ID, or active"
 // @Success 200 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400,404,500 {object} map[string]interface{}
 // @Router /reports/statistics [get]
 func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
     ctx := c.Context()
     if !middleware.HasPermission(c, "report:students") {
     return fiber.ErrForbidden
     }
-    stats, err := s.mongoRepo.GetGlobalStats(ctx)
+    filter, status, msg := s.statsFilter(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    stats, err := s.mongoRepo.GetGlobalStats(ctx, filter)
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": "Failed to generate stats"})
     }
+    stats.PeriodDistribution = s.namePeriods(ctx, stats.PeriodDistribution)
 
     var studentIDs []string
     for _, top := range stats.PointsDistribution {
@@ -57,13 +163,14 @@ func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
 
 // GetStudentReport godoc
 // @Summary Get Student Report
-// @Description Get specific statistics for a student
+// @Description Get specific statistics for a student. Coordinators can only report on students of their scope.
 // @Tags Reports
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "Student UUID"
+// @Param period query string false "Academic period ID, or active"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400,500 {object} map[string]interface{}
+// @Failure 400,403,404,500 {object} map[string]interface{}
 // @Router /reports/student/{id} [get]
 func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
     ctx := c.Context()
@@ -72,7 +179,15 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
     }
     targetStudentID := c.Params("id")
 
-    stats, err := s.mongoRepo.GetStudentStats(ctx, targetStudentID)
+    filter, status, msg := s.statsFilter(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    if filter.StudentIDs != nil && !containsString(filter.StudentIDs, targetStudentID) {
+        return c.Status(403).JSON(fiber.Map{"error": "Forbidden: This student is outside your coordinator scope"})
+    }
+
+    stats, err := s.mongoRepo.GetStudentStats(ctx, targetStudentID, filter)
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": "Failed to get student stats"})
     }
@@ -90,3 +205,214 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
 
     return c.JSON(stats)
 }
+
+// GetOrganizationReport godoc
+// @Summary Get Statistics by Organization Unit
+// @Description Students, achievements and points per program study, department or faculty. Coordinators only see the students of their scope.
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Param groupBy query string false "program_study (default), department or faculty"
+// @Param period query string false "Academic period ID, or active"
+// @Success 200 {array} modelMongo.UnitStatistics
+// @Failure 400,403,404,500 {object} map[string]interface{}
+// @Router /reports/organization [get]
+func (s *ReportService) GetOrganizationReport(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "report:students") {
+        return fiber.ErrForbidden
+    }
+
+    groupBy := c.Query("groupBy", "program_study")
+    pick, ok := unitOf[groupBy]
+    if !ok {
+        return c.Status(400).JSON(fiber.Map{"error": "groupBy must be one of: program_study, department, faculty"})
+    }
+    filter, status, msg := s.statsFilter(c)
+    if status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    units, err := s.orgRepo.GetStudentUnits(ctx)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch students"})
+    }
+    totals, err := s.mongoRepo.GetStudentTotals(ctx, filter)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to generate stats"})
+    }
+
+    rows := make(map[string]*modelMongo.UnitStatistics)
+    row := func(u *modelPg.OrgUnit) *modelMongo.UnitStatistics {
+        key := ""
+        if u != nil {
+            key = u.ID.String()
+        }
+        if r, ok := rows[key]; ok {
+            return r
+        }
+        r := &modelMongo.UnitStatistics{Name: "Unassigned"}
+        if u != nil {
+            r.ID, r.Code, r.Name = key, u.Code, u.Name
+        }
+        rows[key] = r
+        return r
+    }
+
+    inScope := make(map[string]bool, len(filter.StudentIDs))
+    for _, id := range filter.StudentIDs {
+        inScope[id] = true
+    }
+
+    byStudent := make(map[string]*modelPg.OrgUnit, len(units))
+    for _, su := range units {
+        if filter.StudentIDs != nil && !inScope[su.StudentID.String()] {
+            continue
+        }
+        u := pick(su)
+        byStudent[su.StudentID.String()] = u
+        row(u).Students++
+    }
+    // achievements of students that no longer exist count as unassigned
+    for _, t := range totals {
+        r := row(byStudent[t.StudentID])
+        r.Achievements += t.Achievements
+        r.TotalPoints += t.TotalPoints
+    }
+
+    report := make([]modelMongo.UnitStatistics, 0, len(rows))
+    for _, r := range rows {
+        report = append(report, *r)
+    }
+    sort.Slice(report, func(i, j int) bool {
+        if report[i].TotalPoints != report[j].TotalPoints {
+            return report[i].TotalPoints > report[j].TotalPoints
+        }
+        return report[i].Name < report[j].Name
+    })
+
+    return c.JSON(report)
+}
+
+// GetDuplicateReport godoc
+// @Summary Get Suspected Duplicates
+// @Description List submitted achievements flagged as possible duplicates of other achievements. Coordinators only see the students of their scope.
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} modelMongo.DuplicateReportItem
+// @Failure 403,500 {object} map[string]interface{}
+// @Router /reports/duplicates [get]
+func (s *ReportService) GetDuplicateReport(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "report:students") {
+        return fiber.ErrForbidden
+    }
+
+    flagged, err := s.mongoRepo.FindFlaggedDuplicates(ctx)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch suspected duplicates"})
+    }
+
+    inScope, err := s.scopedStudents(c)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load coordinator scope"})
+    }
+    if inScope != nil {
+        visible := flagged[:0]
+        for _, a := range flagged {
+            if inScope[a.StudentID] {
+                visible = append(visible, a)
+            }
+        }
+        flagged = visible
+    }
+
+    var mongoIDs, studentIDs []string
+    for _, a := range flagged {
+        mongoIDs = append(mongoIDs, a.ID.Hex())
+        studentIDs = append(studentIDs, a.StudentID)
+    }
+
+    refs, err := s.pgRepo.GetReferencesByMongoIDs(ctx, mongoIDs)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement references"})
+    }
+    refByMongo := make(map[string]int)
+    for i, r := range refs {
+        refByMongo[r.MongoAchievementID] = i
+    }
+
+    names := make(map[string]string)
+    students, _ := s.studentRepo.GetStudentsByIDs(ctx, studentIDs)
+    for _, stud := range students {
+        names[stud.ID.String()] = stud.FullName
+    }
+
+    report := make([]modelMongo.DuplicateReportItem, 0)
+    for _, a := range flagged {
+        i, ok := refByMongo[a.ID.Hex()]
+        if !ok {
+            continue
+        }
+        report = append(report, modelMongo.DuplicateReportItem{
+            AchievementID: refs[i].ID.String(),
+            Status:        refs[i].Status,
+            StudentID:     a.StudentID,
+            StudentName:   names[a.StudentID],
+            Title:         a.Title,
+            Type:          a.AchievementType,
+            Warnings:      a.DuplicateWarnings,
+        })
+    }
+
+    return c.JSON(report)
+}
+
+// GetStorageReport godoc
+// @Summary Get Storage Usage Report
+// @Description List the students whose attachments take the most space, with whether they are over the student quota (e.g. after it was lowered)
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Number of students (default 20, at most 100)"
+// @Success 200 {array} modelMongo.StorageReportItem
+// @Failure 400,403,500 {object} map[string]interface{}
+// @Router /reports/storage [get]
+func (s *ReportService) GetStorageReport(c *fiber.Ctx) error {
+    ctx := c.Context()
+    if !middleware.HasPermission(c, "report:storage") {
+        return fiber.ErrForbidden
+    }
+
+    limit := c.QueryInt("limit", 20)
+    if limit < 1 || limit > 100 {
+        return c.Status(400).JSON(fiber.Map{"error": "limit must be between 1 and 100"})
+    }
+
+    report, err := s.mongoRepo.TopStorageUsers(ctx, limit)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to compute storage usage"})
+    }
+
+    studentIDs := make([]string, 0, len(report))
+    for _, r := range report {
+        studentIDs = append(studentIDs, r.StudentID)
+    }
+    names := make(map[string]string)
+    students, _ := s.studentRepo.GetStudentsByIDs(ctx, studentIDs)
+    for _, stud := range students {
+        names[stud.ID.String()] = stud.FullName
+    }
+
+    quotas := config.LoadStorageQuotas()
+    for i := range report {
+        report[i].StudentName = names[report[i].StudentID]
+        report[i].OverQuota = (quotas.StudentBytes > 0 && report[i].Bytes > quotas.StudentBytes) ||
+            (quotas.StudentFiles > 0 && report[i].Files > quotas.StudentFiles)
+    }
+    if report == nil {
+        report = []modelMongo.StorageReportItem{}
+    }
+    return c.JSON(report)
+}
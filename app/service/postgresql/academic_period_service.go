@@ -0,0 +1,290 @@
+package service
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    repo "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
+    "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
+)
+
+type AcademicPeriodService struct {
+    periodRepo repo.AcademicPeriodRepository
+}
+
+func NewAcademicPeriodService(r repo.AcademicPeriodRepository) *AcademicPeriodService {
+    return &AcademicPeriodService{periodRepo: r}
+}
+
+// AcademicPeriodRequest is the body of create and update. Dates are YYYY-MM-DD;
+// submissionDeadline may also be an RFC 3339 timestamp, a plain date means the end of that day.
+type AcademicPeriodRequest struct {
+    Name               string `json:"name"`
+    AcademicYear       string `json:"academicYear" example:"2024/2025"`
+    Semester           string `json:"semester" example:"ganjil"`
+    StartDate          string `json:"startDate" example:"2024-09-01"`
+    EndDate            string `json:"endDate" example:"2025-01-31"`
+    SubmissionDeadline string `json:"submissionDeadline" example:"2025-02-15"`
+    IsActive           bool   `json:"isActive"`
+}
+
+// GetAllPeriods godoc
+// @Summary Get Academic Periods
+// @Description List academic periods, newest first
+// @Tags Academic Periods
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.AcademicPeriod
+// @Failure 403,500 {object} map[string]interface{}
+// @Router /academic-periods [get]
+func (s *AcademicPeriodService) GetAllPeriods(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    periods, err := s.periodRepo.GetAll(c.Context())
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch academic periods"})
+    }
+    return c.JSON(periods)
+}
+
+// GetPeriodByID godoc
+// @Summary Get Academic Period
+// @Description Get a single academic period
+// @Tags Academic Periods
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Period UUID"
+// @Success 200 {object} models.AcademicPeriod
+// @Failure 400,403,404 {object} map[string]interface{}
+// @Router /academic-periods/{id} [get]
+func (s *AcademicPeriodService) GetPeriodByID(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid period ID"})
+    }
+
+    period, err := s.periodRepo.GetByID(c.Context(), id)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Academic period not found"})
+    }
+    return c.JSON(period)
+}
+
+// CreatePeriod godoc
+// @Summary Create Academic Period
+// @Description Create a semester with its date range and submission deadline (Admin only). Marking it active clears the flag on the previous active period.
+// @Tags Academic Periods
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body AcademicPeriodRequest true "Academic Period"
+// @Success 201 {object} models.AcademicPeriod
+// @Failure 400,403,409,500 {object} map[string]interface{}
+// @Router /academic-periods [post]
+func (s *AcademicPeriodService) CreatePeriod(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:academic_periods") {
+        return fiber.ErrForbidden
+    }
+
+    var req AcademicPeriodRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+
+    period, errs := parsePeriodRequest(req)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if status, msg := s.checkOverlap(c, period); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    id, err := s.periodRepo.Create(c.Context(), period)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to save academic period: " + err.Error()})
+    }
+    period.ID = id
+
+    return c.Status(201).JSON(period)
+}
+
+// UpdatePeriod godoc
+// @Summary Update Academic Period
+// @Description Change the dates, deadline or active flag of an academic period (Admin only)
+// @Tags Academic Periods
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Period UUID"
+// @Param request body AcademicPeriodRequest true "Academic Period"
+// @Success 200 {object} models.AcademicPeriod
+// @Failure 400,403,404,409,500 {object} map[string]interface{}
+// @Router /academic-periods/{id} [put]
+func (s *AcademicPeriodService) UpdatePeriod(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:academic_periods") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid period ID"})
+    }
+
+    var req AcademicPeriodRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+
+    period, errs := parsePeriodRequest(req)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+    period.ID = id
+
+    if status, msg := s.checkOverlap(c, period); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    err = s.periodRepo.Update(c.Context(), period)
+    if errors.Is(err, sql.ErrNoRows) {
+        return c.Status(404).JSON(fiber.Map{"error": "Academic period not found"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update academic period: " + err.Error()})
+    }
+
+    return c.JSON(period)
+}
+
+// DeletePeriod godoc
+// @Summary Delete Academic Period
+// @Description Delete an academic period that no achievement is assigned to (Admin only)
+// @Tags Academic Periods
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Period UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,409,500 {object} map[string]interface{}
+// @Router /academic-periods/{id} [delete]
+func (s *AcademicPeriodService) DeletePeriod(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:academic_periods") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid period ID"})
+    }
+
+    err = s.periodRepo.Delete(c.Context(), id)
+    if errors.Is(err, repo.ErrPeriodInUse) {
+        return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+    }
+    if errors.Is(err, sql.ErrNoRows) {
+        return c.Status(404).JSON(fiber.Map{"error": "Academic period not found"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to delete academic period"})
+    }
+
+    return c.JSON(fiber.Map{"message": "Academic period deleted"})
+}
+
+// checkOverlap refuses date ranges that overlap another period, since every
+// event date must map to a single period
+func (s *AcademicPeriodService) checkOverlap(c *fiber.Ctx, p models.AcademicPeriod) (int, string) {
+    overlap, err := s.periodRepo.HasOverlap(c.Context(), p.StartDate, p.EndDate, p.ID)
+    if err != nil {
+        return 500, "Failed to check academic periods"
+    }
+    if overlap {
+        return 409, "Date range overlaps another academic period"
+    }
+    return 0, ""
+}
+
+func parsePeriodRequest(req AcademicPeriodRequest) (models.AcademicPeriod, []utils.FieldError) {
+    var errs []utils.FieldError
+    p := models.AcademicPeriod{
+        Name:         strings.TrimSpace(req.Name),
+        AcademicYear: strings.TrimSpace(req.AcademicYear),
+        Semester:     strings.ToLower(strings.TrimSpace(req.Semester)),
+        IsActive:     req.IsActive,
+    }
+
+    if !validAcademicYear(p.AcademicYear) {
+        errs = append(errs, utils.FieldError{Field: "academicYear", Message: "must look like 2024/2025"})
+    }
+    switch p.Semester {
+    case models.SemesterGanjil, models.SemesterGenap, models.SemesterPendek:
+    default:
+        errs = append(errs, utils.FieldError{Field: "semester", Message: "must be one of: ganjil, genap, pendek"})
+    }
+
+    start, err := time.Parse("2006-01-02", req.StartDate)
+    if err != nil {
+        errs = append(errs, utils.FieldError{Field: "startDate", Message: "must be a date (YYYY-MM-DD)"})
+    }
+    end, err2 := time.Parse("2006-01-02", req.EndDate)
+    if err2 != nil {
+        errs = append(errs, utils.FieldError{Field: "endDate", Message: "must be a date (YYYY-MM-DD)"})
+    }
+    if err == nil && err2 == nil && end.Before(start) {
+        errs = append(errs, utils.FieldError{Field: "endDate", Message: "must not be before startDate"})
+    }
+    p.StartDate, p.EndDate = start, end
+
+    if req.SubmissionDeadline != "" {
+        deadline, err := parseDeadline(req.SubmissionDeadline)
+        if err != nil {
+            errs = append(errs, utils.FieldError{Field: "submissionDeadline", Message: "must be a date (YYYY-MM-DD) or an RFC 3339 timestamp"})
+        } else if deadline.Before(start) {
+            errs = append(errs, utils.FieldError{Field: "submissionDeadline", Message: "must not be before startDate"})
+        } else {
+            p.SubmissionDeadline = &deadline
+        }
+    }
+
+    if p.Name == "" && len(errs) == 0 {
+        p.Name = fmt.Sprintf("%s %s", strings.ToUpper(p.Semester[:1])+p.Semester[1:], p.AcademicYear)
+    }
+
+    return p, errs
+}
+
+// validAcademicYear accepts "YYYY/YYYY" where the second year follows the first
+func validAcademicYear(v string) bool {
+    parts := strings.Split(v, "/")
+    if len(parts) != 2 || len(parts[0]) != 4 || len(parts[1]) != 4 {
+        return false
+    }
+    from, err1 := strconv.Atoi(parts[0])
+    to, err2 := strconv.Atoi(parts[1])
+    return err1 == nil && err2 == nil && to == from+1
+}
+
+func parseDeadline(v string) (time.Time, error) {
+    if t, err := time.Parse(time.RFC3339, v); err == nil {
+        return t, nil
+    }
+    day, err := time.ParseInLocation("2006-01-02", v, time.Local)
+    if err != nil {
+        return time.Time{}, err
+    }
+    return day.AddDate(0, 0, 1).Add(-time.Second), nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+    "errors"
     "time"
     "golang.org/x/crypto/bcrypt"
     models "StudenAchievementReportingSystem/app/models/postgresql"
@@ -33,7 +34,7 @@ func (s *AdminService) GetAllUsers(c *fiber.Ctx) error {
         return fiber.ErrForbidden
     }
 
-    users, err := s.adminRepo.GetAllUsers()
+    users, err := s.adminRepo.GetAllUsers(c.Context())
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
@@ -64,7 +65,7 @@ func (s *AdminService) GetUserByID(c *fiber.Ctx) error {
         return fiber.ErrForbidden
     }
 
-    user, err := s.adminRepo.GetUserByID(paramID)
+    user, err := s.adminRepo.GetUserByID(c.Context(), paramID)
     if err != nil {
         return c.Status(404).JSON(fiber.Map{"error": "user not found"})
     }
@@ -100,7 +101,10 @@ func (s *AdminService) CreateUser(c *fiber.Ctx) error {
     hashed, _ := bcrypt.GenerateFromPassword([]byte(req.PasswordHash), bcrypt.DefaultCost)
     req.PasswordHash = string(hashed)
 
-    if err := s.adminRepo.CreateUser(&req); err != nil {
+    if err := s.adminRepo.CreateUser(c.Context(), &req); err != nil {
+        if errors.Is(err, repo.ErrUserOrRoleNotFound) {
+            return c.Status(400).JSON(fiber.Map{"error": "role not found"})
+        }
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
@@ -117,7 +121,7 @@ func (s *AdminService) CreateUser(c *fiber.Ctx) error {
 // @Param id path string true "User UUID"
 // @Param request body models.User true "User Data"
 // @Success 200 {object} models.User
-// @Failure 400,403,500 {object} map[string]interface{}
+// @Failure 400,403,404,500 {object} map[string]interface{}
 // @Router /users/{id} [put]
 func (s *AdminService) UpdateUser(c *fiber.Ctx) error {
     paramID := c.Params("id")
@@ -139,7 +143,10 @@ func (s *AdminService) UpdateUser(c *fiber.Ctx) error {
 
     req.ID = targetID
 
-    if err := s.adminRepo.UpdateUser(&req); err != nil {
+    if err := s.adminRepo.UpdateUser(c.Context(), &req); err != nil {
+        if errors.Is(err, repo.ErrUserOrRoleNotFound) {
+            return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+        }
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
@@ -166,7 +173,7 @@ func (s *AdminService) DeleteUser(c *fiber.Ctx) error {
         return fiber.ErrForbidden
     }
 
-	if err := s.adminRepo.DeleteUser(targetID); err != nil {
+	if err := s.adminRepo.DeleteUser(c.Context(), targetID); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -182,7 +189,7 @@ func (s *AdminService) DeleteUser(c *fiber.Ctx) error {
 // @Param id path string true "User UUID"
 // @Param request body object{roleId=string} true "Role ID"
 // @Success 200 {object} map[string]string
-// @Failure 400,403,500 {object} map[string]interface{}
+// @Failure 400,403,404,500 {object} map[string]interface{}
 // @Router /users/{id}/role [put]
 func (s *AdminService) AssignRole(c *fiber.Ctx) error {
     if !middleware.HasPermission(c, "manage:users") {
@@ -199,7 +206,10 @@ func (s *AdminService) AssignRole(c *fiber.Ctx) error {
     userID, _ := uuid.Parse(c.Params("id"))
     roleID, _ := uuid.Parse(req.RoleID)
 
-    if err := s.adminRepo.AssignRole(userID, roleID); err != nil {
+    if err := s.adminRepo.AssignRole(c.Context(), userID, roleID); err != nil {
+        if errors.Is(err, repo.ErrUserOrRoleNotFound) {
+            return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+        }
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
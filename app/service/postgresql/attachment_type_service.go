@@ -0,0 +1,133 @@
+package service
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    repo "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
+    "github.com/gofiber/fiber/v2"
+)
+
+type AttachmentTypeService struct {
+    typeRepo repo.AttachmentTypeRepository
+}
+
+func NewAttachmentTypeService(r repo.AttachmentTypeRepository) *AttachmentTypeService {
+    return &AttachmentTypeService{typeRepo: r}
+}
+
+// AttachmentTypeRequest sets the size limit of an allowed file type
+type AttachmentTypeRequest struct {
+    MaxSizeBytes int64 `json:"maxSizeBytes" example:"5242880"`
+}
+
+// AttachmentTypeResponse is a detectable file type and whether it may be uploaded
+type AttachmentTypeResponse struct {
+    utils.FileType
+    Allowed      bool  `json:"allowed"`
+    MaxSizeBytes int64 `json:"maxSizeBytes,omitempty"`
+}
+
+// GetAttachmentTypes godoc
+// @Summary Get Attachment Types
+// @Description List the file types uploads are recognised as, whether each is allowed and its size limit
+// @Tags Attachment Types
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} AttachmentTypeResponse
+// @Failure 403,500 {object} map[string]interface{}
+// @Router /attachment-types [get]
+func (s *AttachmentTypeService) GetAttachmentTypes(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    allowed, err := s.typeRepo.GetAll(c.Context())
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch attachment types"})
+    }
+    limits := make(map[string]int64, len(allowed))
+    for _, t := range allowed {
+        limits[t.Code] = t.MaxSizeBytes
+    }
+
+    list := make([]AttachmentTypeResponse, 0, len(utils.FileTypes))
+    for _, ft := range utils.FileTypes {
+        limit, ok := limits[ft.Code]
+        list = append(list, AttachmentTypeResponse{FileType: ft, Allowed: ok, MaxSizeBytes: limit})
+    }
+    return c.JSON(list)
+}
+
+// AllowAttachmentType godoc
+// @Summary Allow Attachment Type
+// @Description Allow uploads of a file type or change its size limit (Super Admin only). The limit cannot exceed 512 MB; files over 10 MB can only be sent as resumable uploads.
+// @Tags Attachment Types
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param code path string true "File type code (pdf, png, jpeg, gif, webp)"
+// @Param request body AttachmentTypeRequest true "Size limit"
+// @Success 200 {object} models.AttachmentType
+// @Failure 400,403,404,500 {object} map[string]interface{}
+// @Router /attachment-types/{code} [put]
+func (s *AttachmentTypeService) AllowAttachmentType(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:attachment_types") {
+        return fiber.ErrForbidden
+    }
+
+    ft := utils.FindFileType(c.Params("code"))
+    if ft == nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Unknown file type"})
+    }
+
+    var req AttachmentTypeRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    if req.MaxSizeBytes <= 0 || req.MaxSizeBytes > utils.MaxResumableUploadSize {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": []utils.FieldError{
+            {Field: "maxSizeBytes", Message: fmt.Sprintf("must be between 1 and %d", utils.MaxResumableUploadSize)},
+        }})
+    }
+
+    t := models.AttachmentType{Code: ft.Code, MaxSizeBytes: req.MaxSizeBytes}
+    if err := s.typeRepo.Save(c.Context(), t); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment type"})
+    }
+
+    saved, err := s.typeRepo.GetByCode(c.Context(), ft.Code)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch attachment type"})
+    }
+    return c.JSON(saved)
+}
+
+// DisallowAttachmentType godoc
+// @Summary Disallow Attachment Type
+// @Description Stop accepting uploads of a file type (Super Admin only). Files already uploaded are kept.
+// @Tags Attachment Types
+// @Security BearerAuth
+// @Produce json
+// @Param code path string true "File type code"
+// @Success 200 {object} map[string]string
+// @Failure 403,404,500 {object} map[string]interface{}
+// @Router /attachment-types/{code} [delete]
+func (s *AttachmentTypeService) DisallowAttachmentType(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:attachment_types") {
+        return fiber.ErrForbidden
+    }
+
+    err := s.typeRepo.Delete(c.Context(), c.Params("code"))
+    if errors.Is(err, sql.ErrNoRows) {
+        return c.Status(404).JSON(fiber.Map{"error": "Attachment type is not allowed"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to remove attachment type"})
+    }
+    return c.JSON(fiber.Map{"message": "Attachment type disallowed"})
+}
@@ -27,7 +27,7 @@ func (s *LecturerService) GetAllLecturers(c *fiber.Ctx) error {
 		if !middleware.HasPermission(c, "manage:lecturers") {
 		return fiber.ErrForbidden
 	}
-	data, err := s.lecturerRepo.GetAllLecturers()
+	data, err := s.lecturerRepo.GetAllLecturers(c.Context())
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -41,7 +41,7 @@ func (s *LecturerService) GetLecturerByID(c *fiber.Ctx) error {
 
 	id, _ := uuid.Parse(c.Params("id"))
 
-	lecturer, err := s.lecturerRepo.GetLecturerByID(id)
+	lecturer, err := s.lecturerRepo.GetLecturerByID(c.Context(), id)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "lecture r not found"})
 	}
@@ -64,7 +64,7 @@ func (s *LecturerService) GetAdvisees(c *fiber.Ctx) error {
 	}
 	id, _ := uuid.Parse(c.Params("id"))
 
-	students, err := s.lecturerRepo.GetAdvisees(id)
+	students, err := s.lecturerRepo.GetAdvisees(c.Context(), id)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -0,0 +1,72 @@
+package service
+
+import (
+    "database/sql"
+    "errors"
+
+    repo "StudenAchievementReportingSystem/app/repository/postgresql"
+    "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
+)
+
+type NotificationService struct {
+    notificationRepo repo.NotificationRepository
+}
+
+func NewNotificationService(r repo.NotificationRepository) *NotificationService {
+    return &NotificationService{notificationRepo: r}
+}
+
+// GetNotifications godoc
+// @Summary Get Notifications
+// @Description List the latest notifications of the current user, newest first
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {array} models.Notification
+// @Failure 401,500 {object} map[string]interface{}
+// @Router /notifications [get]
+func (s *NotificationService) GetNotifications(c *fiber.Ctx) error {
+    userID := actorID(c)
+    if userID == nil {
+        return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+    }
+
+    list, err := s.notificationRepo.GetByUser(c.Context(), *userID, c.QueryBool("unread"))
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch notifications"})
+    }
+    return c.JSON(list)
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark Notification Read
+// @Description Mark a notification of the current user as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Notification ID (UUID)"
+// @Success 200 {object} map[string]string
+// @Failure 400,401,404,500 {object} map[string]interface{}
+// @Router /notifications/{id}/read [post]
+func (s *NotificationService) MarkNotificationRead(c *fiber.Ctx) error {
+    userID := actorID(c)
+    if userID == nil {
+        return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid notification ID"})
+    }
+
+    err = s.notificationRepo.MarkRead(c.Context(), id, *userID)
+    if errors.Is(err, sql.ErrNoRows) {
+        return c.Status(404).JSON(fiber.Map{"error": "Notification not found"})
+    }
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to update notification"})
+    }
+    return c.JSON(fiber.Map{"message": "Notification marked as read"})
+}
@@ -0,0 +1,734 @@
+package service
+
+import (
+    "database/sql"
+    "errors"
+    "regexp"
+    "strings"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    repo "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
+    "github.com/gofiber/fiber/v2"
+    "github.com/google/uuid"
+)
+
+type OrganizationService struct {
+    orgRepo repo.OrganizationRepository
+}
+
+func NewOrganizationService(r repo.OrganizationRepository) *OrganizationService {
+    return &OrganizationService{orgRepo: r}
+}
+
+// OrgUnitRequest is the body of create and update for faculties, departments
+// and program studies. facultyId is only read for departments, departmentId
+// only for program studies.
+type OrgUnitRequest struct {
+    Code         string `json:"code" example:"IF"`
+    Name         string `json:"name" example:"Informatika"`
+    FacultyID    string `json:"facultyId,omitempty"`
+    DepartmentID string `json:"departmentId,omitempty"`
+}
+
+// MergeRequest lists the units to fold into the one in the path
+type MergeRequest struct {
+    SourceIDs []string `json:"sourceIds"`
+}
+
+var orgCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9-]{0,49}$`)
+
+// validateUnit normalizes code and name and checks them
+func validateUnit(req *OrgUnitRequest) []utils.FieldError {
+    var errs []utils.FieldError
+    req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+    req.Name = strings.Join(strings.Fields(req.Name), " ")
+
+    if !orgCodePattern.MatchString(req.Code) {
+        errs = append(errs, utils.FieldError{Field: "code", Message: "must be 1-50 letters, digits or dashes"})
+    }
+    if req.Name == "" || len(req.Name) > 150 {
+        errs = append(errs, utils.FieldError{Field: "name", Message: "is required and at most 150 characters"})
+    }
+    return errs
+}
+
+func parseParent(field, value string, errs []utils.FieldError) (uuid.UUID, []utils.FieldError) {
+    id, err := uuid.Parse(value)
+    if err != nil {
+        errs = append(errs, utils.FieldError{Field: field, Message: "must be a valid UUID"})
+    }
+    return id, errs
+}
+
+// optionalUUID reads an optional UUID query parameter
+func optionalUUID(c *fiber.Ctx, name string) (*uuid.UUID, bool) {
+    value := c.Query(name)
+    if value == "" {
+        return nil, true
+    }
+    id, err := uuid.Parse(value)
+    if err != nil {
+        return nil, false
+    }
+    return &id, true
+}
+
+// unitError turns repository errors into responses; what names the unit for messages
+func unitError(c *fiber.Ctx, err error, what string) error {
+    switch {
+    case errors.Is(err, sql.ErrNoRows):
+        return c.Status(404).JSON(fiber.Map{"error": what + " not found"})
+    case errors.Is(err, repo.ErrDuplicateCode), errors.Is(err, repo.ErrUnitInUse):
+        return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+    case errors.Is(err, repo.ErrParentNotFound):
+        return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    return c.Status(500).JSON(fiber.Map{"error": "Failed to save " + strings.ToLower(what)})
+}
+
+func parseMergeRequest(c *fiber.Ctx, target uuid.UUID) ([]uuid.UUID, []utils.FieldError, error) {
+    var req MergeRequest
+    if err := c.BodyParser(&req); err != nil {
+        return nil, nil, err
+    }
+
+    var errs []utils.FieldError
+    if len(req.SourceIDs) == 0 {
+        errs = append(errs, utils.FieldError{Field: "sourceIds", Message: "must list at least one ID"})
+    }
+    ids := make([]uuid.UUID, 0, len(req.SourceIDs))
+    for _, v := range req.SourceIDs {
+        id, err := uuid.Parse(v)
+        if err != nil || id == target {
+            errs = append(errs, utils.FieldError{Field: "sourceIds", Message: v + " is not a valid source ID"})
+            continue
+        }
+        ids = append(ids, id)
+    }
+    return ids, errs, nil
+}
+
+// GetFaculties godoc
+// @Summary Get Faculties
+// @Description List faculties
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Faculty
+// @Failure 403,500 {object} map[string]interface{}
+// @Router /faculties [get]
+func (s *OrganizationService) GetFaculties(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    list, err := s.orgRepo.GetFaculties(c.Context())
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch faculties"})
+    }
+    return c.JSON(list)
+}
+
+// GetFacultyByID godoc
+// @Summary Get Faculty
+// @Description Get a single faculty
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Faculty UUID"
+// @Success 200 {object} models.Faculty
+// @Failure 400,403,404 {object} map[string]interface{}
+// @Router /faculties/{id} [get]
+func (s *OrganizationService) GetFacultyByID(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid faculty ID"})
+    }
+
+    f, err := s.orgRepo.GetFacultyByID(c.Context(), id)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Faculty not found"})
+    }
+    return c.JSON(f)
+}
+
+// CreateFaculty godoc
+// @Summary Create Faculty
+// @Description Create a faculty (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body OrgUnitRequest true "Faculty"
+// @Success 201 {object} models.Faculty
+// @Failure 400,403,409,500 {object} map[string]interface{}
+// @Router /faculties [post]
+func (s *OrganizationService) CreateFaculty(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    var req OrgUnitRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    if errs := validateUnit(&req); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    f := models.Faculty{Code: req.Code, Name: req.Name}
+    id, err := s.orgRepo.CreateFaculty(c.Context(), f)
+    if err != nil {
+        return unitError(c, err, "Faculty")
+    }
+    f.ID = id
+
+    return c.Status(201).JSON(f)
+}
+
+// UpdateFaculty godoc
+// @Summary Update Faculty
+// @Description Rename a faculty or change its code (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Faculty UUID"
+// @Param request body OrgUnitRequest true "Faculty"
+// @Success 200 {object} models.Faculty
+// @Failure 400,403,404,409,500 {object} map[string]interface{}
+// @Router /faculties/{id} [put]
+func (s *OrganizationService) UpdateFaculty(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid faculty ID"})
+    }
+
+    var req OrgUnitRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    if errs := validateUnit(&req); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    f := models.Faculty{ID: id, Code: req.Code, Name: req.Name}
+    if err := s.orgRepo.UpdateFaculty(c.Context(), f); err != nil {
+        return unitError(c, err, "Faculty")
+    }
+    return c.JSON(f)
+}
+
+// DeleteFaculty godoc
+// @Summary Delete Faculty
+// @Description Delete a faculty without departments (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Faculty UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,409,500 {object} map[string]interface{}
+// @Router /faculties/{id} [delete]
+func (s *OrganizationService) DeleteFaculty(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid faculty ID"})
+    }
+
+    if err := s.orgRepo.DeleteFaculty(c.Context(), id); err != nil {
+        return unitError(c, err, "Faculty")
+    }
+    return c.JSON(fiber.Map{"message": "Faculty deleted"})
+}
+
+// GetDepartments godoc
+// @Summary Get Departments
+// @Description List departments, optionally of one faculty
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param facultyId query string false "Faculty UUID"
+// @Success 200 {array} models.Department
+// @Failure 400,403,500 {object} map[string]interface{}
+// @Router /departments [get]
+func (s *OrganizationService) GetDepartments(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    facultyID, ok := optionalUUID(c, "facultyId")
+    if !ok {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid faculty ID"})
+    }
+
+    list, err := s.orgRepo.GetDepartments(c.Context(), facultyID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch departments"})
+    }
+    return c.JSON(list)
+}
+
+// GetDepartmentByID godoc
+// @Summary Get Department
+// @Description Get a single department
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Department UUID"
+// @Success 200 {object} models.Department
+// @Failure 400,403,404 {object} map[string]interface{}
+// @Router /departments/{id} [get]
+func (s *OrganizationService) GetDepartmentByID(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid department ID"})
+    }
+
+    d, err := s.orgRepo.GetDepartmentByID(c.Context(), id)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Department not found"})
+    }
+    return c.JSON(d)
+}
+
+// CreateDepartment godoc
+// @Summary Create Department
+// @Description Create a department in a faculty (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body OrgUnitRequest true "Department with facultyId"
+// @Success 201 {object} models.Department
+// @Failure 400,403,409,500 {object} map[string]interface{}
+// @Router /departments [post]
+func (s *OrganizationService) CreateDepartment(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    var req OrgUnitRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    errs := validateUnit(&req)
+    facultyID, errs := parseParent("facultyId", req.FacultyID, errs)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    d := models.Department{FacultyID: facultyID, Code: req.Code, Name: req.Name}
+    id, err := s.orgRepo.CreateDepartment(c.Context(), d)
+    if err != nil {
+        return unitError(c, err, "Department")
+    }
+    d.ID = id
+
+    return c.Status(201).JSON(d)
+}
+
+// UpdateDepartment godoc
+// @Summary Update Department
+// @Description Rename a department or move it to another faculty (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Department UUID"
+// @Param request body OrgUnitRequest true "Department with facultyId"
+// @Success 200 {object} models.Department
+// @Failure 400,403,404,409,500 {object} map[string]interface{}
+// @Router /departments/{id} [put]
+func (s *OrganizationService) UpdateDepartment(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid department ID"})
+    }
+
+    var req OrgUnitRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    errs := validateUnit(&req)
+    facultyID, errs := parseParent("facultyId", req.FacultyID, errs)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    d := models.Department{ID: id, FacultyID: facultyID, Code: req.Code, Name: req.Name}
+    if err := s.orgRepo.UpdateDepartment(c.Context(), d); err != nil {
+        return unitError(c, err, "Department")
+    }
+    return c.JSON(d)
+}
+
+// DeleteDepartment godoc
+// @Summary Delete Department
+// @Description Delete a department without program studies or lecturers (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Department UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,409,500 {object} map[string]interface{}
+// @Router /departments/{id} [delete]
+func (s *OrganizationService) DeleteDepartment(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid department ID"})
+    }
+
+    if err := s.orgRepo.DeleteDepartment(c.Context(), id); err != nil {
+        return unitError(c, err, "Department")
+    }
+    return c.JSON(fiber.Map{"message": "Department deleted"})
+}
+
+// MergeDepartments godoc
+// @Summary Merge Departments
+// @Description Move the lecturers and program studies of the source departments to this one and delete the sources (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Target department UUID"
+// @Param request body MergeRequest true "Departments to merge"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,500 {object} map[string]interface{}
+// @Router /departments/{id}/merge [post]
+func (s *OrganizationService) MergeDepartments(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid department ID"})
+    }
+
+    sources, errs, err := parseMergeRequest(c, id)
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if err := s.orgRepo.MergeDepartments(c.Context(), id, sources); err != nil {
+        return unitError(c, err, "Department")
+    }
+    return c.JSON(fiber.Map{"message": "Departments merged"})
+}
+
+// GetProgramStudies godoc
+// @Summary Get Program Studies
+// @Description List program studies, optionally of one department
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param departmentId query string false "Department UUID"
+// @Success 200 {array} models.ProgramStudy
+// @Failure 400,403,500 {object} map[string]interface{}
+// @Router /program-studies [get]
+func (s *OrganizationService) GetProgramStudies(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    departmentID, ok := optionalUUID(c, "departmentId")
+    if !ok {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid department ID"})
+    }
+
+    list, err := s.orgRepo.GetProgramStudies(c.Context(), departmentID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch program studies"})
+    }
+    return c.JSON(list)
+}
+
+// GetProgramStudyByID godoc
+// @Summary Get Program Study
+// @Description Get a single program study
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Program study UUID"
+// @Success 200 {object} models.ProgramStudy
+// @Failure 400,403,404 {object} map[string]interface{}
+// @Router /program-studies/{id} [get]
+func (s *OrganizationService) GetProgramStudyByID(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "achievement:read") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid program study ID"})
+    }
+
+    p, err := s.orgRepo.GetProgramStudyByID(c.Context(), id)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "Program study not found"})
+    }
+    return c.JSON(p)
+}
+
+// CreateProgramStudy godoc
+// @Summary Create Program Study
+// @Description Create a program study in a department (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body OrgUnitRequest true "Program study with departmentId"
+// @Success 201 {object} models.ProgramStudy
+// @Failure 400,403,409,500 {object} map[string]interface{}
+// @Router /program-studies [post]
+func (s *OrganizationService) CreateProgramStudy(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    var req OrgUnitRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    errs := validateUnit(&req)
+    departmentID, errs := parseParent("departmentId", req.DepartmentID, errs)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    p := models.ProgramStudy{DepartmentID: departmentID, Code: req.Code, Name: req.Name}
+    id, err := s.orgRepo.CreateProgramStudy(c.Context(), p)
+    if err != nil {
+        return unitError(c, err, "Program study")
+    }
+    p.ID = id
+
+    return c.Status(201).JSON(p)
+}
+
+// UpdateProgramStudy godoc
+// @Summary Update Program Study
+// @Description Rename a program study or move it to another department (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Program study UUID"
+// @Param request body OrgUnitRequest true "Program study with departmentId"
+// @Success 200 {object} models.ProgramStudy
+// @Failure 400,403,404,409,500 {object} map[string]interface{}
+// @Router /program-studies/{id} [put]
+func (s *OrganizationService) UpdateProgramStudy(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid program study ID"})
+    }
+
+    var req OrgUnitRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    errs := validateUnit(&req)
+    departmentID, errs := parseParent("departmentId", req.DepartmentID, errs)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    p := models.ProgramStudy{ID: id, DepartmentID: departmentID, Code: req.Code, Name: req.Name}
+    if err := s.orgRepo.UpdateProgramStudy(c.Context(), p); err != nil {
+        return unitError(c, err, "Program study")
+    }
+    return c.JSON(p)
+}
+
+// DeleteProgramStudy godoc
+// @Summary Delete Program Study
+// @Description Delete a program study without students (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Program study UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,409,500 {object} map[string]interface{}
+// @Router /program-studies/{id} [delete]
+func (s *OrganizationService) DeleteProgramStudy(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid program study ID"})
+    }
+
+    if err := s.orgRepo.DeleteProgramStudy(c.Context(), id); err != nil {
+        return unitError(c, err, "Program study")
+    }
+    return c.JSON(fiber.Map{"message": "Program study deleted"})
+}
+
+// MergeProgramStudies godoc
+// @Summary Merge Program Studies
+// @Description Move the students of the source program studies (e.g. spelling variants left by the migration) to this one and delete the sources (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Target program study UUID"
+// @Param request body MergeRequest true "Program studies to merge"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,500 {object} map[string]interface{}
+// @Router /program-studies/{id}/merge [post]
+func (s *OrganizationService) MergeProgramStudies(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:organization") {
+        return fiber.ErrForbidden
+    }
+
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid program study ID"})
+    }
+
+    sources, errs, err := parseMergeRequest(c, id)
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if err := s.orgRepo.MergeProgramStudies(c.Context(), id, sources); err != nil {
+        return unitError(c, err, "Program study")
+    }
+    return c.JSON(fiber.Map{"message": "Program studies merged"})
+}
+
+// CoordinatorScopeRequest lists what a coordinator oversees. A department
+// covers all of its program studies, including ones added later.
+type CoordinatorScopeRequest struct {
+    DepartmentIDs   []string `json:"departmentIds"`
+    ProgramStudyIDs []string `json:"programStudyIds"`
+}
+
+func parseIDList(field string, values []string, errs []utils.FieldError) ([]uuid.UUID, []utils.FieldError) {
+    ids := make([]uuid.UUID, 0, len(values))
+    for _, v := range values {
+        id, err := uuid.Parse(v)
+        if err != nil {
+            errs = append(errs, utils.FieldError{Field: field, Message: v + " is not a valid UUID"})
+            continue
+        }
+        ids = append(ids, id)
+    }
+    return ids, errs
+}
+
+// GetCoordinatorScope godoc
+// @Summary Get Coordinator Scope
+// @Description Departments and program studies a coordinator oversees (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Produce json
+// @Param userId path string true "Coordinator user UUID"
+// @Success 200 {object} models.CoordinatorScope
+// @Failure 400,403,500 {object} map[string]interface{}
+// @Router /coordinators/{userId}/scope [get]
+func (s *OrganizationService) GetCoordinatorScope(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:users") {
+        return fiber.ErrForbidden
+    }
+
+    userID, err := uuid.Parse(c.Params("userId"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid user ID"})
+    }
+
+    scope, err := s.orgRepo.GetCoordinatorScope(c.Context(), userID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch coordinator scope"})
+    }
+    return c.JSON(scope)
+}
+
+// SetCoordinatorScope godoc
+// @Summary Set Coordinator Scope
+// @Description Replace the departments and program studies a coordinator oversees (Admin only)
+// @Tags Organization
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param userId path string true "Coordinator user UUID"
+// @Param request body CoordinatorScopeRequest true "Scope"
+// @Success 200 {object} models.CoordinatorScope
+// @Failure 400,403,500 {object} map[string]interface{}
+// @Router /coordinators/{userId}/scope [put]
+func (s *OrganizationService) SetCoordinatorScope(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:users") {
+        return fiber.ErrForbidden
+    }
+
+    userID, err := uuid.Parse(c.Params("userId"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid user ID"})
+    }
+
+    var req CoordinatorScopeRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+
+    var errs []utils.FieldError
+    scope := models.CoordinatorScope{UserID: userID}
+    scope.DepartmentIDs, errs = parseIDList("departmentIds", req.DepartmentIDs, errs)
+    scope.ProgramStudyIDs, errs = parseIDList("programStudyIds", req.ProgramStudyIDs, errs)
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    if err := s.orgRepo.SetCoordinatorScope(c.Context(), scope); err != nil {
+        if errors.Is(err, repo.ErrParentNotFound) {
+            return c.Status(400).JSON(fiber.Map{"error": "Unknown user, department or program study"})
+        }
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to save coordinator scope"})
+    }
+
+    saved, err := s.orgRepo.GetCoordinatorScope(c.Context(), userID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch coordinator scope"})
+    }
+    return c.JSON(saved)
+}
@@ -1,25 +1,54 @@
 package service
 
 import (
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "io"
+    "strings"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
     repo "StudenAchievementReportingSystem/app/repository/postgresql"
     mongoRepo "StudenAchievementReportingSystem/app/repository/mongodb"
     "github.com/gofiber/fiber/v2"
     "github.com/google/uuid"
     "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
 )
 
 type StudentService struct {
     studentRepo     repo.StudentRepository
     achievementRepo mongoRepo.AchievementRepository
+    orgRepo         repo.OrganizationRepository
+}
+
+func NewStudentService(r repo.StudentRepository, a mongoRepo.AchievementRepository, o repo.OrganizationRepository) *StudentService {
+    return &StudentService{studentRepo: r, achievementRepo: a, orgRepo: o}
 }
 
-func NewStudentService(r repo.StudentRepository, a mongoRepo.AchievementRepository) *StudentService {
-    return &StudentService{studentRepo: r, achievementRepo: a}
+// checkScope refuses coordinators access to students outside their scope.
+// A non-zero status is the response to send.
+func (s *StudentService) checkScope(c *fiber.Ctx, studentID uuid.UUID) (int, string) {
+    scope, err := middleware.CoordinatorScope(c, s.orgRepo)
+    if err != nil {
+        return 500, "Failed to load coordinator scope"
+    }
+    if scope == nil {
+        return 0, ""
+    }
+    student, err := s.studentRepo.GetStudentByID(c.Context(), studentID)
+    if err != nil {
+        return 404, "student not found"
+    }
+    if !scope.Covers(student.ProgramStudyID) {
+        return 403, "Forbidden: This student is outside your coordinator scope"
+    }
+    return 0, ""
 }
 
 // GetAllStudents godoc
 // @Summary Get All Students
-// @Description Get list of all students
+// @Description Get list of all students. Coordinators only get the students of their scope.
 // @Tags Students & Lecturers
 // @Security BearerAuth
 // @Produce json
@@ -29,10 +58,23 @@ func (s *StudentService) GetAllStudents(c *fiber.Ctx) error {
         if !middleware.HasPermission(c, "manage:students") {
 		return fiber.ErrForbidden
 	}
+    scope, err := middleware.CoordinatorScope(c, s.orgRepo)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load coordinator scope"})
+    }
     data, err := s.studentRepo.GetAllStudents(c.Context())
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
+    if scope != nil {
+        inScope := make([]models.Student, 0, len(data))
+        for _, st := range data {
+            if scope.Covers(st.ProgramStudyID) {
+                inScope = append(inScope, st)
+            }
+        }
+        data = inScope
+    }
     return c.JSON(data)
 }
 
@@ -59,6 +101,13 @@ func (s *StudentService) GetStudentByID(c *fiber.Ctx) error {
     if err != nil {
         return c.Status(404).JSON(fiber.Map{"error": "student not found"})
     }
+    scope, err := middleware.CoordinatorScope(c, s.orgRepo)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load coordinator scope"})
+    }
+    if scope != nil && !scope.Covers(student.ProgramStudyID) {
+        return c.Status(403).JSON(fiber.Map{"error": "Forbidden: This student is outside your coordinator scope"})
+    }
 
     return c.JSON(student)
 }
@@ -80,7 +129,10 @@ func (s *StudentService) GetStudentAchievements(c *fiber.Ctx) error {
     if err != nil {
         return c.Status(400).JSON(fiber.Map{"error": "Invalid UUID format"})
     }
-    achievements, err := s.achievementRepo.GetStudentAchievements(id)
+    if status, msg := s.checkScope(c, id); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+    achievements, err := s.achievementRepo.GetStudentAchievements(c.Context(), id)
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
@@ -88,15 +140,97 @@ func (s *StudentService) GetStudentAchievements(c *fiber.Ctx) error {
     return c.JSON(achievements)
 }
 
+// maxAdvisorCSVRows bounds a CSV reassignment, which runs in one transaction
+const maxAdvisorCSVRows = 5000
+
+// TransferAdviseesRequest moves all advisees of one lecturer to another
+type TransferAdviseesRequest struct {
+    FromLecturerID string `json:"fromLecturerId"`
+    ToLecturerID   string `json:"toLecturerId"`
+}
+
+// actorID is the user making the change, recorded as assigned_by
+func actorID(c *fiber.Ctx) *uuid.UUID {
+    if id, ok := c.Locals("user_id").(uuid.UUID); ok {
+        return &id
+    }
+    return nil
+}
+
+// advisorError turns repository errors of advisor changes into responses
+func advisorError(c *fiber.Ctx, err error) error {
+    switch {
+    case errors.Is(err, repo.ErrStudentOutOfScope):
+        return c.Status(403).JSON(fiber.Map{"error": "Forbidden: " + err.Error()})
+    case errors.Is(err, repo.ErrStudentNotFound), errors.Is(err, repo.ErrLecturerNotActive):
+        return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+    }
+    return c.Status(500).JSON(fiber.Map{"error": "Failed to reassign advisor"})
+}
+
+// parseAdvisorCSV reads rows of student,lecturer from a CSV with a header line.
+// Either column holds a UUID or the student or lecturer number.
+func parseAdvisorCSV(r io.Reader) ([]models.AdvisorChange, []utils.FieldError, error) {
+    reader := csv.NewReader(r)
+    reader.TrimLeadingSpace = true
+
+    header, err := reader.Read()
+    if err != nil {
+        return nil, nil, err
+    }
+    studentCol, lecturerCol := -1, -1
+    for i, name := range header {
+        switch strings.ToLower(strings.TrimSpace(name)) {
+        case "student":
+            studentCol = i
+        case "lecturer":
+            lecturerCol = i
+        }
+    }
+    if studentCol < 0 || lecturerCol < 0 {
+        return nil, []utils.FieldError{{Field: "file", Message: "header must have student and lecturer columns"}}, nil
+    }
+
+    var changes []models.AdvisorChange
+    var errs []utils.FieldError
+    for line := 2; ; line++ {
+        record, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, nil, err
+        }
+        if len(changes) == maxAdvisorCSVRows {
+            return nil, []utils.FieldError{{Field: "file", Message: fmt.Sprintf("at most %d rows per upload", maxAdvisorCSVRows)}}, nil
+        }
+        ch := models.AdvisorChange{
+            Student:  strings.TrimSpace(record[studentCol]),
+            Lecturer: strings.TrimSpace(record[lecturerCol]),
+        }
+        if ch.Student == "" || ch.Lecturer == "" {
+            errs = append(errs, utils.FieldError{Field: fmt.Sprintf("line %d", line), Message: "student and lecturer are required"})
+            continue
+        }
+        changes = append(changes, ch)
+    }
+    if len(changes) == 0 && len(errs) == 0 {
+        errs = append(errs, utils.FieldError{Field: "file", Message: "has no rows"})
+    }
+    return changes, errs, nil
+}
+
 // UpdateAdvisor godoc
 // @Summary Update Student Advisor
-// @Description Assign or change lecturer advisor for a student
+// @Description Assign or change lecturer advisor for a student. The change is kept in the advisor history and submitted achievements move to the new advisor for verification. Coordinators can only reassign students in their scope.
 // @Tags Students & Lecturers
 // @Security BearerAuth
 // @Accept json
+// @Produce json
 // @Param id path string true "Student UUID"
 // @Param request body object{lecturerId=string} true "Lecturer UUID"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} models.ReassignResult
+// @Failure 400,403,404,500 {object} map[string]interface{}
 // @Router /students/{id}/advisor [put]
 func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
 
@@ -116,10 +250,110 @@ func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
     lecturerID, err := uuid.Parse(body.LecturerID)
     if err != nil { return c.Status(400).JSON(fiber.Map{"error": "Invalid Lecturer ID"}) }
 
-    err = s.studentRepo.UpdateAdvisor(c.Context(), studentID, lecturerID)
+    if status, msg := s.checkScope(c, studentID); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    result, err := s.studentRepo.UpdateAdvisor(c.Context(), studentID, lecturerID, actorID(c))
+    if errors.Is(err, repo.ErrStudentNotFound) {
+        return c.Status(404).JSON(fiber.Map{"error": "student not found"})
+    }
     if err != nil {
-        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+        return advisorError(c, err)
+    }
+
+    return c.JSON(result)
+}
+
+// GetAdvisorHistory godoc
+// @Summary Get Advisor History
+// @Description List the advisors of a student with the dates they were in charge, the current one first
+// @Tags Students & Lecturers
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Student UUID"
+// @Success 200 {array} models.AdvisorAssignment
+// @Failure 400,403,404,500 {object} map[string]interface{}
+// @Router /students/{id}/advisors [get]
+func (s *StudentService) GetAdvisorHistory(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:students") {
+        return fiber.ErrForbidden
+    }
+    id, err := uuid.Parse(c.Params("id"))
+    if err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid UUID format"})
+    }
+    if status, msg := s.checkScope(c, id); status != 0 {
+        return c.Status(status).JSON(fiber.Map{"error": msg})
+    }
+
+    history, err := s.studentRepo.GetAdvisorHistory(c.Context(), id)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch advisor history"})
+    }
+    return c.JSON(history)
+}
+
+// ReassignAdvisors godoc
+// @Summary Reassign Advisors in Bulk
+// @Description Move all advisees of one lecturer to another (JSON body), or apply a CSV upload with student and lecturer columns holding UUIDs or student/lecturer numbers. All changes are applied or none; submitted achievements move to the new advisors. Coordinators only move students in their scope.
+// @Tags Students & Lecturers
+// @Security BearerAuth
+// @Accept json,mpfd
+// @Produce json
+// @Param request body TransferAdviseesRequest false "Lecturer to lecturer transfer"
+// @Param file formData file false "CSV with student,lecturer columns"
+// @Success 200 {object} models.ReassignResult
+// @Failure 400,403,500 {object} map[string]interface{}
+// @Router /students/advisors/reassign [post]
+func (s *StudentService) ReassignAdvisors(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "manage:lecturers") {
+        return fiber.ErrForbidden
+    }
+    scope, err := middleware.CoordinatorScope(c, s.orgRepo)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to load coordinator scope"})
     }
 
-    return c.JSON(fiber.Map{"message": "advisor updated"})
+    var result *models.ReassignResult
+    if file, ferr := c.FormFile("file"); ferr == nil {
+        f, err := file.Open()
+        if err != nil {
+            return c.Status(400).JSON(fiber.Map{"error": "Failed to read file"})
+        }
+        defer f.Close()
+
+        changes, errs, err := parseAdvisorCSV(f)
+        if err != nil {
+            return c.Status(400).JSON(fiber.Map{"error": "Invalid CSV: " + err.Error()})
+        }
+        if len(errs) > 0 {
+            return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+        }
+        result, err = s.studentRepo.ReassignAdvisors(c.Context(), changes, actorID(c), scope)
+        if err != nil {
+            return advisorError(c, err)
+        }
+        return c.JSON(result)
+    }
+
+    var req TransferAdviseesRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    var errs []utils.FieldError
+    fromID, errs := parseParent("fromLecturerId", req.FromLecturerID, errs)
+    toID, errs := parseParent("toLecturerId", req.ToLecturerID, errs)
+    if len(errs) == 0 && fromID == toID {
+        errs = append(errs, utils.FieldError{Field: "toLecturerId", Message: "must differ from fromLecturerId"})
+    }
+    if len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    result, err = s.studentRepo.TransferAdvisees(c.Context(), fromID, toID, actorID(c), scope)
+    if err != nil {
+        return advisorError(c, err)
+    }
+    return c.JSON(result)
 }
\ No newline at end of file
@@ -0,0 +1,91 @@
+package service
+
+import (
+    "errors"
+
+    models "StudenAchievementReportingSystem/app/models/postgresql"
+    repo "StudenAchievementReportingSystem/app/repository/postgresql"
+    "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
+    "github.com/gofiber/fiber/v2"
+)
+
+type TenantService struct {
+    tenantRepo repo.TenantRepository
+}
+
+func NewTenantService(r repo.TenantRepository) *TenantService {
+    return &TenantService{tenantRepo: r}
+}
+
+// TenantRequest is the body of tenant creation
+type TenantRequest struct {
+    Code string `json:"code" example:"FT"`
+    Name string `json:"name" example:"Fakultas Teknik"`
+}
+
+// validateTenant applies the code and name rules of organization units
+func validateTenant(req *TenantRequest) []utils.FieldError {
+    unit := OrgUnitRequest{Code: req.Code, Name: req.Name}
+    errs := validateUnit(&unit)
+    req.Code, req.Name = unit.Code, unit.Name
+    return errs
+}
+
+// GetTenants godoc
+// @Summary Get Tenants
+// @Description List tenants (Super Admin only)
+// @Tags Tenants
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Tenant
+// @Failure 403,500 {object} map[string]interface{}
+// @Router /tenants [get]
+func (s *TenantService) GetTenants(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "tenant:all") {
+        return fiber.ErrForbidden
+    }
+
+    list, err := s.tenantRepo.GetAll(c.Context())
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch tenants"})
+    }
+    return c.JSON(list)
+}
+
+// CreateTenant godoc
+// @Summary Create Tenant
+// @Description Create a tenant (Super Admin only). Its users, faculties and achievement types are created afterwards by sending X-Tenant-ID.
+// @Tags Tenants
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body TenantRequest true "Tenant"
+// @Success 201 {object} models.Tenant
+// @Failure 400,403,409,500 {object} map[string]interface{}
+// @Router /tenants [post]
+func (s *TenantService) CreateTenant(c *fiber.Ctx) error {
+    if !middleware.HasPermission(c, "tenant:all") {
+        return fiber.ErrForbidden
+    }
+
+    var req TenantRequest
+    if err := c.BodyParser(&req); err != nil {
+        return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+    }
+    if errs := validateTenant(&req); len(errs) > 0 {
+        return c.Status(400).JSON(fiber.Map{"error": "Validation failed", "details": errs})
+    }
+
+    t := models.Tenant{Code: req.Code, Name: req.Name}
+    id, err := s.tenantRepo.Create(c.Context(), t)
+    if err != nil {
+        if errors.Is(err, repo.ErrDuplicateCode) {
+            return c.Status(409).JSON(fiber.Map{"error": "tenant code already exists"})
+        }
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to save tenant"})
+    }
+    t.ID = id
+
+    return c.Status(201).JSON(t)
+}
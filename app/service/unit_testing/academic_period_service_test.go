@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+	"StudenAchievementReportingSystem/app/service/postgresql"
+)
+
+func setupAcademicPeriodTest() (*service.AcademicPeriodService, *mocks.MockAcademicPeriodRepo) {
+	mockPeriod := new(mocks.MockAcademicPeriodRepo)
+	return service.NewAcademicPeriodService(mockPeriod), mockPeriod
+}
+
+func TestCreateAcademicPeriod(t *testing.T) {
+	t.Run("Success: Default Name And End Of Day Deadline", func(t *testing.T) {
+		svc, mockPeriod := setupAcademicPeriodTest()
+		app := setupPermissionApp("manage:academic_periods")
+
+		newID := uuid.New()
+		mockPeriod.On("HasOverlap", mock.Anything, mock.Anything, mock.Anything, uuid.Nil).Return(false, nil)
+		mockPeriod.On("Create", mock.Anything, mock.MatchedBy(func(p models.AcademicPeriod) bool {
+			return p.Name == "Ganjil 2024/2025" &&
+				p.StartDate.Equal(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)) &&
+				p.SubmissionDeadline != nil && p.SubmissionDeadline.Day() == 15 && p.SubmissionDeadline.Hour() == 23 &&
+				p.IsActive
+		})).Return(newID, nil)
+
+		app.Post("/academic-periods", svc.CreatePeriod)
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"academicYear": "2024/2025", "semester": "Ganjil",
+			"startDate": "2024-09-01", "endDate": "2025-01-31",
+			"submissionDeadline": "2025-02-15", "isActive": true,
+		})
+		req := httptest.NewRequest("POST", "/academic-periods", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 201, resp.StatusCode)
+		mockPeriod.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Invalid Fields", func(t *testing.T) {
+		svc, mockPeriod := setupAcademicPeriodTest()
+		app := setupPermissionApp("manage:academic_periods")
+		app.Post("/academic-periods", svc.CreatePeriod)
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"academicYear": "2024/2026", "semester": "summer",
+			"startDate": "2025-01-31", "endDate": "2024-09-01",
+		})
+		req := httptest.NewRequest("POST", "/academic-periods", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+
+		var res struct {
+			Details []map[string]string `json:"details"`
+		}
+		json.NewDecoder(resp.Body).Decode(&res)
+		assert.Len(t, res.Details, 3)
+		mockPeriod.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Overlapping Period", func(t *testing.T) {
+		svc, mockPeriod := setupAcademicPeriodTest()
+		app := setupPermissionApp("manage:academic_periods")
+
+		mockPeriod.On("HasOverlap", mock.Anything, mock.Anything, mock.Anything, uuid.Nil).Return(true, nil)
+		app.Post("/academic-periods", svc.CreatePeriod)
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"academicYear": "2024/2025", "semester": "genap",
+			"startDate": "2025-01-15", "endDate": "2025-06-30",
+		})
+		req := httptest.NewRequest("POST", "/academic-periods", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 409, resp.StatusCode)
+		mockPeriod.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Requires Permission", func(t *testing.T) {
+		svc, _ := setupAcademicPeriodTest()
+		app := setupPermissionApp("achievement:read")
+		app.Post("/academic-periods", svc.CreatePeriod)
+
+		resp, _ := app.Test(httptest.NewRequest("POST", "/academic-periods", nil))
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+}
+
+func TestDeleteAcademicPeriodInUse(t *testing.T) {
+	svc, mockPeriod := setupAcademicPeriodTest()
+	app := setupPermissionApp("manage:academic_periods")
+
+	id := uuid.New()
+	mockPeriod.On("Delete", mock.Anything, id).Return(repoPg.ErrPeriodInUse)
+	app.Delete("/academic-periods/:id", svc.DeletePeriod)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/academic-periods/"+id.String(), nil))
+	assert.Equal(t, 409, resp.StatusCode)
+}
@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/mongodb"
+)
+
+func setupCoordinatorAchievementTest() (*service.AchievementService, *mocks.MockAchievementPgRepo, *mocks.MockOrganizationRepo) {
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockOrg := new(mocks.MockOrganizationRepo)
+	deps := testAchievementDeps()
+	deps.References = mockPg
+	deps.Organization = mockOrg
+	svc := service.NewAchievementService(deps)
+	return svc, mockPg, mockOrg
+}
+
+func TestGetAllAchievementsCoordinatorScope(t *testing.T) {
+	t.Run("Success: Limited To Covered Program Studies", func(t *testing.T) {
+		svc, mockPg, mockOrg := setupCoordinatorAchievementTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read", "oversight:scoped")
+
+		covered := []uuid.UUID{uuid.New(), uuid.New()}
+		mockOrg.On("GetCoordinatorScope", mock.Anything, userID).Return(&modelPg.CoordinatorScope{UserID: userID, Covered: covered}, nil)
+		mockPg.On("GetAllReferences", mock.Anything, mock.MatchedBy(func(f map[string]interface{}) bool {
+			statuses, _ := f["status"].([]string)
+			ids, _ := f["program_study_ids"].([]uuid.UUID)
+			return len(ids) == 2 && assert.ObjectsAreEqual([]string{"submitted", "verified", "rejected"}, statuses)
+		}), 10, 0, "").Return([]modelPg.AchievementReference{}, int64(0), nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements?status=draft", nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockPg.AssertExpectations(t)
+		mockPg.AssertNotCalled(t, "GetStudentByUserID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: Empty Scope Sees Nothing", func(t *testing.T) {
+		svc, mockPg, mockOrg := setupCoordinatorAchievementTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read", "oversight:scoped")
+
+		mockOrg.On("GetCoordinatorScope", mock.Anything, userID).Return(&modelPg.CoordinatorScope{UserID: userID}, nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements", nil))
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var res modelPg.PaginatedResponse
+		json.NewDecoder(resp.Body).Decode(&res)
+		assert.Equal(t, 0, res.Meta.TotalData)
+		mockPg.AssertNotCalled(t, "GetAllReferences", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
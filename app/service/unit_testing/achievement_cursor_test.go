@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func TestCursorCodec(t *testing.T) {
+	c := modelPg.Cursor{CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 123, time.UTC), ID: uuid.New(), Backward: true}
+
+	decoded, err := utils.DecodeCursor(utils.EncodeCursor(c))
+	assert.NoError(t, err)
+	assert.True(t, c.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, c.ID, decoded.ID)
+	assert.True(t, decoded.Backward)
+
+	_, err = utils.DecodeCursor("not-a-cursor")
+	assert.ErrorIs(t, err, utils.ErrInvalidCursor)
+}
+
+func TestGetAllAchievementsCursor(t *testing.T) {
+	t.Run("Success: First Page Has Only Next Cursor", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		now := time.Now().UTC()
+		refs := []modelPg.AchievementReference{
+			{ID: uuid.New(), MongoAchievementID: "a", CreatedAt: now},
+			{ID: uuid.New(), MongoAchievementID: "b", CreatedAt: now.Add(-time.Minute)},
+		}
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a student"))
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockPg.On("GetReferencesByCursor", mock.Anything, mock.Anything, 2, (*modelPg.Cursor)(nil), "").Return(refs, true, nil)
+		mockPg.On("EstimateReferences", mock.Anything, mock.Anything).Return(int64(25000), nil)
+		mockMongo.On("FindAllDetails", mock.Anything, []string{"a", "b"}).Return([]modelMongo.Achievement{}, nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		req := httptest.NewRequest("GET", "/achievements?pagination=cursor&limit=2&total=approx", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body modelPg.PaginatedResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Len(t, body.Data, 2)
+		assert.Empty(t, body.Meta.PrevCursor)
+		assert.Equal(t, int64(25000), *body.Meta.ApproxTotal)
+		mockPg.AssertNotCalled(t, "GetAllReferences", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		next, err := utils.DecodeCursor(body.Meta.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, refs[1].ID, next.ID)
+		assert.False(t, next.Backward)
+	})
+
+	t.Run("Success: Last Page Has Only Prev Cursor", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		cursor := modelPg.Cursor{CreatedAt: time.Now().UTC(), ID: uuid.New()}
+		refs := []modelPg.AchievementReference{{ID: uuid.New(), MongoAchievementID: "c", CreatedAt: cursor.CreatedAt.Add(-time.Hour)}}
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a student"))
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockPg.On("GetReferencesByCursor", mock.Anything, mock.Anything, 10, mock.MatchedBy(func(c *modelPg.Cursor) bool {
+			return c != nil && c.ID == cursor.ID && !c.Backward
+		}), "").Return(refs, false, nil)
+		mockMongo.On("FindAllDetails", mock.Anything, []string{"c"}).Return([]modelMongo.Achievement{}, nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		req := httptest.NewRequest("GET", "/achievements?cursor="+utils.EncodeCursor(cursor), nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body modelPg.PaginatedResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Empty(t, body.Meta.NextCursor)
+		assert.Nil(t, body.Meta.ApproxTotal)
+
+		prev, err := utils.DecodeCursor(body.Meta.PrevCursor)
+		assert.NoError(t, err)
+		assert.True(t, prev.Backward)
+		assert.Equal(t, refs[0].ID, prev.ID)
+	})
+
+	t.Run("Fail: Invalid Cursor", func(t *testing.T) {
+		svc, _, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a student"))
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		req := httptest.NewRequest("GET", "/achievements?cursor=garbage", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func TestIfMatch(t *testing.T) {
+	assert.Equal(t, `"3"`, utils.ETag(3))
+	assert.True(t, utils.IfMatch(`"3"`, 3))
+	assert.True(t, utils.IfMatch(`"2", "3"`, 3))
+	assert.False(t, utils.IfMatch(`*`, 3))
+	assert.False(t, utils.IfMatch(`"2"`, 3))
+	assert.False(t, utils.IfMatch(`W/"3"`, 3))
+}
+
+func TestAchievementPreconditions(t *testing.T) {
+	t.Run("Fail: Missing If-Match", func(t *testing.T) {
+		svc, _, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, Status: "draft", Version: 2,
+		}, nil)
+
+		app.Delete("/achievements/:id", svc.DeleteAchievement)
+
+		req := httptest.NewRequest("DELETE", "/achievements/"+achievementID.String(), nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 428, resp.StatusCode)
+		mockPg.AssertNotCalled(t, "DeleteReference", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Wildcard If-Match", func(t *testing.T) {
+		svc, _, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, Status: "draft", Version: 2,
+		}, nil)
+
+		app.Delete("/achievements/:id", svc.DeleteAchievement)
+
+		req := httptest.NewRequest("DELETE", "/achievements/"+achievementID.String(), nil)
+		req.Header.Set("If-Match", "*")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 428, resp.StatusCode)
+		mockPg.AssertNotCalled(t, "BumpVersion", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Stale ETag", func(t *testing.T) {
+		svc, _, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, Status: "draft", Version: 2,
+		}, nil)
+
+		app.Delete("/achievements/:id", svc.DeleteAchievement)
+
+		req := httptest.NewRequest("DELETE", "/achievements/"+achievementID.String(), nil)
+		req.Header.Set("If-Match", `"1"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 412, resp.StatusCode)
+		mockPg.AssertNotCalled(t, "DeleteReference", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Lost Race Against Concurrent Writer", func(t *testing.T) {
+		svc, _, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, Status: "draft", Version: 2,
+		}, nil)
+		mockPg.On("BumpVersion", mock.Anything, achievementID, 2).Return(repoPg.ErrVersionConflict)
+
+		app.Delete("/achievements/:id", svc.DeleteAchievement)
+
+		req := httptest.NewRequest("DELETE", "/achievements/"+achievementID.String(), nil)
+		req.Header.Set("If-Match", `"2"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 412, resp.StatusCode)
+		mockPg.AssertNotCalled(t, "DeleteReference", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: Matching ETag", func(t *testing.T) {
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, MongoAchievementID: "mongo_id", Status: "draft", Version: 2,
+		}, nil)
+		mockPg.On("BumpVersion", mock.Anything, achievementID, 2).Return(nil)
+		mockPg.On("DeleteReference", mock.Anything, achievementID).Return(nil)
+		mockMongo.On("MoveToTrash", mock.Anything, "mongo_id").Return(nil)
+
+		app.Delete("/achievements/:id", svc.DeleteAchievement)
+
+		req := httptest.NewRequest("DELETE", "/achievements/"+achievementID.String(), nil)
+		req.Header.Set("If-Match", `"2"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+	})
+
+	t.Run("Fail: Version Is Released When The Write Fails", func(t *testing.T) {
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, MongoAchievementID: "mongo_id", Status: "draft", Version: 2,
+		}, nil)
+		mockPg.On("BumpVersion", mock.Anything, achievementID, 2).Return(nil)
+		mockPg.On("ReleaseVersion", mock.Anything, achievementID, 3).Return(nil)
+		mockMongo.On("MoveToTrash", mock.Anything, "mongo_id").Return(errors.New("mongo down"))
+
+		app.Delete("/achievements/:id", svc.DeleteAchievement)
+
+		req := httptest.NewRequest("DELETE", "/achievements/"+achievementID.String(), nil)
+		req.Header.Set("If-Match", `"2"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 500, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("ETag"))
+		mockPg.AssertCalled(t, "ReleaseVersion", mock.Anything, achievementID, 3)
+	})
+}
@@ -0,0 +1,177 @@
+package service_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/mongodb"
+)
+
+func setupExpandServiceTest() (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockAchievementPgRepo, *mocks.MockLecturerRepo, *mocks.MockStudentRepo) {
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockLecturer := new(mocks.MockLecturerRepo)
+	mockStudent := new(mocks.MockStudentRepo)
+	mockType := new(mocks.MockAchievementTypeRepo)
+	mockType.On("FindByCode", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments).Maybe()
+
+	mockPeriod := new(mocks.MockAcademicPeriodRepo)
+	mockPeriod.On("FindByDate", mock.Anything, mock.Anything).Return(nil, errors.New("not found")).Maybe()
+
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.Lecturers = mockLecturer
+	deps.Types = mockType
+	deps.Students = mockStudent
+	deps.Periods = mockPeriod
+	svc := service.NewAchievementService(deps)
+	return svc, mockMongo, mockPg, mockLecturer, mockStudent
+}
+
+func TestGetAllAchievementsFieldsAndExpand(t *testing.T) {
+	t.Run("Success: Expands In Batch And Selects Fields", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer, mockStudent := setupExpandServiceTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		studentID := uuid.New()
+		verifierUserID := uuid.New()
+		idA, idB := primitive.NewObjectID(), primitive.NewObjectID()
+		refs := []modelPg.AchievementReference{
+			{ID: uuid.New(), StudentID: studentID, MongoAchievementID: idA.Hex(), Status: "verified", VerifiedBy: &verifierUserID, CreatedAt: time.Now()},
+			{ID: uuid.New(), StudentID: studentID, MongoAchievementID: idB.Hex(), Status: "submitted", CreatedAt: time.Now()},
+		}
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a student"))
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockPg.On("GetAllReferences", mock.Anything, mock.Anything, 10, 0, "").Return(refs, int64(2), nil)
+		mockMongo.On("FindAllDetails", mock.Anything, []string{idA.Hex(), idB.Hex()}).Return([]modelMongo.Achievement{
+			{ID: idA, Title: "Juara 1", Attachments: []modelMongo.Attachment{{FileName: "sertifikat.pdf"}}},
+			{ID: idB, Title: "Seminar"},
+		}, nil)
+		mockStudent.On("GetStudentsByIDs", mock.Anything, []string{studentID.String()}).Return([]modelPg.StudentWithUser{
+			{ID: studentID, FullName: "Budi", ProgramStudy: "Informatika"},
+		}, nil).Once()
+		mockLecturer.On("GetLecturersByUserIDs", mock.Anything, []uuid.UUID{verifierUserID}).Return([]modelPg.LecturerSummary{
+			{ID: uuid.New(), UserID: verifierUserID, FullName: "Dr. Sari"},
+		}, nil).Once()
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		req := httptest.NewRequest("GET", "/achievements?fields=title,student,verifier,attachments&expand=student,verifier,attachments", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Len(t, body.Data, 2)
+
+		first := body.Data[0]
+		assert.Equal(t, refs[0].ID.String(), first["id"])
+		assert.Equal(t, "Juara 1", first["title"])
+		assert.NotContains(t, first, "status")
+		assert.Equal(t, "Budi", first["student"].(map[string]interface{})["fullName"])
+		assert.Equal(t, "Dr. Sari", first["verifier"].(map[string]interface{})["fullName"])
+		assert.Len(t, first["attachments"], 1)
+
+		second := body.Data[1]
+		assert.Nil(t, second["verifier"])
+		assert.Len(t, second["attachments"], 0)
+
+		mockStudent.AssertExpectations(t)
+		mockLecturer.AssertExpectations(t)
+	})
+
+	t.Run("Error: Unknown Field Or Expansion", func(t *testing.T) {
+		svc, _, mockPg, mockLecturer, _ := setupExpandServiceTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		req := httptest.NewRequest("GET", "/achievements?fields=title,password&expand=advisor", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+
+		var body struct {
+			Details []map[string]string `json:"details"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Len(t, body.Details, 2)
+		mockPg.AssertNotCalled(t, "GetAllReferences", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockLecturer.AssertNotCalled(t, "GetLecturersByUserIDs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetAchievementDetailFieldsAndExpand(t *testing.T) {
+	setup := func() (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockStudentRepo, uuid.UUID, modelPg.AchievementReference) {
+		svc, mockMongo, mockPg, mockLecturer, mockStudent := setupExpandServiceTest()
+		userID := uuid.New()
+		mongoID := primitive.NewObjectID()
+		ref := modelPg.AchievementReference{ID: uuid.New(), StudentID: uuid.New(), MongoAchievementID: mongoID.Hex(), Status: "draft", Version: 1}
+
+		mockPg.On("GetReferenceByID", mock.Anything, ref.ID).Return(ref, nil)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer")).Maybe()
+		mockMongo.On("FindOne", mock.Anything, mongoID.Hex()).Return(&modelMongo.Achievement{
+			ID:          mongoID,
+			Title:       "Juara 1",
+			Attachments: []modelMongo.Attachment{{FileName: "sertifikat.pdf"}},
+		}, nil)
+		return svc, mockMongo, mockStudent, userID, ref
+	}
+
+	t.Run("Success: Attachments Embedded By Default", func(t *testing.T) {
+		svc, _, _, userID, ref := setup()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+		app.Get("/achievements/:id", svc.GetAchievementDetail)
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String(), nil))
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		details := body["details"].(map[string]interface{})
+		assert.Equal(t, "Juara 1", details["title"])
+		assert.Len(t, details["attachments"], 1)
+		assert.NotContains(t, body, "attachments")
+	})
+
+	t.Run("Success: Expand And Select Fields", func(t *testing.T) {
+		svc, _, mockStudent, userID, ref := setup()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+		app.Get("/achievements/:id", svc.GetAchievementDetail)
+
+		mockStudent.On("GetStudentsByIDs", mock.Anything, []string{ref.StudentID.String()}).Return([]modelPg.StudentWithUser{
+			{ID: ref.StudentID, FullName: "Budi", ProgramStudy: "Informatika"},
+		}, nil)
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"?fields=status,student,attachments&expand=student,attachments", nil))
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, ref.ID.String(), body["id"])
+		assert.Equal(t, "draft", body["status"])
+		assert.NotContains(t, body, "details")
+		assert.Equal(t, "Informatika", body["student"].(map[string]interface{})["programStudy"])
+		assert.Len(t, body["attachments"], 1)
+	})
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	repoMongo "StudenAchievementReportingSystem/app/repository/mongodb"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func TestMergePatch(t *testing.T) {
+	doc := []byte(`{"title":"A","tags":["x","y"],"details":{"rank":2,"location":"Malang"}}`)
+	patch := []byte(`{"tags":["z"],"details":{"rank":null,"organizer":"ITS"}}`)
+
+	merged, err := utils.MergePatch(doc, patch)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"title":"A","tags":["z"],"details":{"location":"Malang","organizer":"ITS"}}`, string(merged))
+
+	errs := utils.CheckPatchFields(map[string]interface{}{"title": "B", "points": 100, "studentId": "x"}, []string{"title"})
+	assert.Equal(t, []utils.FieldError{
+		{Field: "points", Message: "cannot be changed"},
+		{Field: "studentId", Message: "cannot be changed"},
+	}, errs)
+}
+
+func TestPatchAchievement(t *testing.T) {
+	t.Run("Success: Patch Keeps Untouched Fields", func(t *testing.T) {
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		mongoID := primitive.NewObjectID()
+		app := setupAchievementAppWithPermissions(userID, "achievement:update")
+
+		current := &modelMongo.Achievement{
+			ID:              mongoID,
+			StudentID:       studentID.String(),
+			AchievementType: "other",
+			Title:           "Seminar Nasional",
+			Description:     "Pembicara",
+			Tags:            []string{"seminar"},
+			Attachments:     []modelMongo.Attachment{{FileName: "sertifikat.pdf"}},
+			Points:          10,
+		}
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, MongoAchievementID: mongoID.Hex(), Status: "draft", Version: 1,
+		}, nil)
+		mockPg.On("BumpVersion", mock.Anything, achievementID, 1).Return(nil)
+		mockMongo.On("FindOne", mock.Anything, mongoID.Hex()).Return(current, nil)
+		mockMongo.On("UpdateOne", mock.Anything, mongoID.Hex(), mock.MatchedBy(func(a modelMongo.Achievement) bool {
+			return a.Title == "Seminar Internasional" && a.Description == "Pembicara" &&
+				len(a.Tags) == 1 && a.Tags[0] == "seminar" && len(a.Attachments) == 1 && a.Points == 10
+		})).Return(nil)
+
+		app.Patch("/achievements/:id", svc.PatchAchievement)
+
+		body, _ := json.Marshal(map[string]interface{}{"title": "Seminar Internasional"})
+		req := httptest.NewRequest("PATCH", "/achievements/"+achievementID.String(), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", `"1"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockMongo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Document Changed Concurrently", func(t *testing.T) {
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		mongoID := primitive.NewObjectID()
+		app := setupAchievementAppWithPermissions(userID, "achievement:update")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, MongoAchievementID: mongoID.Hex(), Status: "draft", Version: 1,
+		}, nil)
+		mockPg.On("BumpVersion", mock.Anything, achievementID, 1).Return(nil)
+		mockMongo.On("FindOne", mock.Anything, mongoID.Hex()).Return(&modelMongo.Achievement{
+			ID: mongoID, StudentID: studentID.String(), AchievementType: "other", Title: "Seminar Nasional",
+		}, nil)
+		mockMongo.On("UpdateOne", mock.Anything, mongoID.Hex(), mock.Anything).Return(repoMongo.ErrVersionConflict)
+		mockPg.On("ReleaseVersion", mock.Anything, achievementID, 2).Return(nil)
+
+		app.Patch("/achievements/:id", svc.PatchAchievement)
+
+		req := httptest.NewRequest("PATCH", "/achievements/"+achievementID.String(), bytes.NewBufferString(`{"title":"Seminar Internasional"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", `"1"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 409, resp.StatusCode)
+		mockPg.AssertCalled(t, "ReleaseVersion", mock.Anything, achievementID, 2)
+	})
+
+	t.Run("Fail: Points Are Not Editable", func(t *testing.T) {
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:update")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, Status: "draft", Version: 1,
+		}, nil)
+
+		app.Patch("/achievements/:id", svc.PatchAchievement)
+
+		req := httptest.NewRequest("PATCH", "/achievements/"+achievementID.String(), bytes.NewBufferString(`{"points":500}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", `"1"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockMongo.AssertNotCalled(t, "UpdateOne", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
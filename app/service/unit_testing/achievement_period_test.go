@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/mongodb"
+)
+
+func setupPeriodAchievementTest() (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockAchievementPgRepo, *mocks.MockAcademicPeriodRepo) {
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockLecturer := new(mocks.MockLecturerRepo)
+	mockLecturer.On("GetLecturerByUserID", mock.Anything, mock.Anything).Return(uuid.Nil, errors.New("not a lecturer")).Maybe()
+	mockType := new(mocks.MockAchievementTypeRepo)
+	mockType.On("FindByCode", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments).Maybe()
+	mockPeriod := new(mocks.MockAcademicPeriodRepo)
+
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.Lecturers = mockLecturer
+	deps.Types = mockType
+	deps.Periods = mockPeriod
+	svc := service.NewAchievementService(deps)
+	return svc, mockMongo, mockPg, mockPeriod
+}
+
+func ganjil2024() *modelPg.AcademicPeriod {
+	deadline := time.Date(2025, 2, 15, 23, 59, 59, 0, time.UTC)
+	return &modelPg.AcademicPeriod{
+		ID:                 uuid.New(),
+		Name:               "Ganjil 2024/2025",
+		AcademicYear:       "2024/2025",
+		Semester:           modelPg.SemesterGanjil,
+		StartDate:          time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:            time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		SubmissionDeadline: &deadline,
+	}
+}
+
+func TestCreateAchievementAssignsPeriod(t *testing.T) {
+	svc, mockMongo, mockPg, mockPeriod := setupPeriodAchievementTest()
+	userID := uuid.New()
+	app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+	studentID := uuid.New()
+	period := ganjil2024()
+	eventDate := time.Date(2024, 11, 15, 0, 0, 0, 0, time.UTC)
+
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+	mockPeriod.On("FindByDate", mock.Anything, eventDate).Return(period, nil)
+	mockMongo.On("InsertOne", mock.Anything, mock.MatchedBy(func(a modelMongo.Achievement) bool {
+		return a.PeriodID == period.ID.String()
+	})).Return(primitive.NewObjectID().Hex(), nil)
+	mockPg.On("Create", mock.Anything, mock.MatchedBy(func(r modelPg.AchievementReference) bool {
+		return r.PeriodID != nil && *r.PeriodID == period.ID
+	})).Return(uuid.New(), nil)
+
+	app.Post("/achievements", svc.CreateAchievement)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"title":           "Seminar Nasional",
+		"achievementType": "other",
+		"details":         map[string]interface{}{"eventDate": eventDate},
+	})
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 201, resp.StatusCode)
+	mockMongo.AssertExpectations(t)
+	mockPg.AssertExpectations(t)
+}
+
+func TestSubmitAchievementAfterPeriodDeadline(t *testing.T) {
+	svc, _, mockPg, mockPeriod := setupPeriodAchievementTest()
+	userID := uuid.New()
+	app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+	studentID := uuid.New()
+	period := ganjil2024()
+	ref := modelPg.AchievementReference{ID: uuid.New(), StudentID: studentID, Status: "draft", Version: 1, PeriodID: &period.ID}
+
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+	mockPg.On("GetReferenceByID", mock.Anything, ref.ID).Return(ref, nil)
+	mockPeriod.On("GetByID", mock.Anything, period.ID).Return(period, nil)
+
+	app.Post("/achievements/:id/submit", svc.SubmitAchievement)
+
+	req := httptest.NewRequest("POST", "/achievements/"+ref.ID.String()+"/submit", nil)
+	req.Header.Set("If-Match", `"1"`)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 400, resp.StatusCode)
+
+	var res map[string]string
+	json.NewDecoder(resp.Body).Decode(&res)
+	assert.Contains(t, res["error"], "Ganjil 2024/2025")
+	mockPg.AssertNotCalled(t, "SubmitReference", mock.Anything, mock.Anything)
+	mockPg.AssertNotCalled(t, "BumpVersion", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetAllAchievementsByPeriod(t *testing.T) {
+	t.Run("Success: Active Period Filter", func(t *testing.T) {
+		svc, _, mockPg, mockPeriod := setupPeriodAchievementTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		period := ganjil2024()
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a student"))
+		mockPeriod.On("GetActive", mock.Anything).Return(period, nil)
+		mockPg.On("GetAllReferences", mock.Anything, mock.MatchedBy(func(f map[string]interface{}) bool {
+			return f["period_id"] == period.ID
+		}), 10, 0, "").Return([]modelPg.AchievementReference{}, int64(0), nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements?period=active", nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockPg.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Invalid Period", func(t *testing.T) {
+		svc, _, mockPg, _ := setupPeriodAchievementTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		app.Get("/achievements", svc.GetAllAchievements)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements?period=ganjil", nil))
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockPg.AssertNotCalled(t, "GetAllReferences", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAssignPeriods(t *testing.T) {
+	svc, mockMongo, mockPg, mockPeriod := setupPeriodAchievementTest()
+
+	period := ganjil2024()
+	inside, outside := primitive.NewObjectID(), primitive.NewObjectID()
+	refs := []modelPg.AchievementReference{
+		{ID: uuid.New(), MongoAchievementID: inside.Hex(), CreatedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), MongoAchievementID: outside.Hex(), CreatedAt: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	mockPeriod.On("GetAll", mock.Anything).Return([]modelPg.AcademicPeriod{*period}, nil)
+	mockPg.On("GetReferencesWithoutPeriod", mock.Anything, (*modelPg.Cursor)(nil), 500).Return(refs, nil)
+	mockMongo.On("FindAllDetails", mock.Anything, []string{inside.Hex(), outside.Hex()}).Return([]modelMongo.Achievement{
+		{ID: inside, Details: modelMongo.AchievementDetails{EventDate: time.Date(2024, 10, 5, 0, 0, 0, 0, time.UTC)}},
+		{ID: outside},
+	}, nil)
+	mockPg.On("SetPeriod", mock.Anything, refs[0].ID, &period.ID).Return(nil)
+	mockMongo.On("SetPeriod", mock.Anything, inside.Hex(), period.ID.String()).Return(nil)
+
+	n, err := svc.AssignPeriods(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	mockPg.AssertNumberOfCalls(t, "SetPeriod", 1)
+	mockMongo.AssertExpectations(t)
+}
@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+)
+
+func TestGetAllAchievementsSearch(t *testing.T) {
+	t.Run("Success: Filters Are Matched In MongoDB", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID, studentID := uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		first, second := primitive.NewObjectID(), primitive.NewObjectID()
+		refs := []modelPg.AchievementReference{
+			{ID: uuid.New(), StudentID: studentID, MongoAchievementID: first.Hex(), Status: "draft"},
+			{ID: uuid.New(), StudentID: studentID, MongoAchievementID: second.Hex(), Status: "verified"},
+		}
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockPg.On("GetReferencesByCursor", mock.Anything, mock.MatchedBy(func(f map[string]interface{}) bool {
+			return f["student_id"] == studentID && f["program_study"] == "Informatika"
+		}), 500, (*modelPg.Cursor)(nil), "").Return(refs, false, nil)
+		mockMongo.On("MatchIDs", mock.Anything, []string{first.Hex(), second.Hex()}, mock.MatchedBy(func(f modelMongo.AchievementFilter) bool {
+			return f.Search == "gemastik" && len(f.Types) == 1 && f.Types[0] == "competition" &&
+				f.DateFrom != nil && f.DateTo != nil && f.DateTo.Format("2006-01-02") == "2025-01-01" &&
+				f.MinPoints != nil && *f.MinPoints == 10
+		})).Return([]string{second.Hex()}, nil)
+		mockMongo.On("FindAllDetails", mock.Anything, []string{second.Hex()}).Return([]modelMongo.Achievement{{ID: second, Title: "Gemastik"}}, nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		req := httptest.NewRequest("GET", "/achievements?search=gemastik&type=competition&dateFrom=2024-01-01&dateTo=2024-12-31&minPoints=10&programStudy=Informatika", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body modelPg.PaginatedResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Len(t, body.Data, 1)
+		assert.Equal(t, 1, body.Meta.TotalData)
+		assert.Equal(t, "Gemastik", body.Data[0].(map[string]interface{})["title"])
+		mockMongo.AssertExpectations(t)
+	})
+
+	t.Run("Success: Pages Across Reference Batches", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID, studentID := uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		batch := make([]modelPg.AchievementReference, 500)
+		for i := range batch {
+			batch[i] = modelPg.AchievementReference{ID: uuid.New(), StudentID: studentID, MongoAchievementID: primitive.NewObjectID().Hex()}
+		}
+		last := primitive.NewObjectID()
+		tail := []modelPg.AchievementReference{{ID: uuid.New(), StudentID: studentID, MongoAchievementID: last.Hex()}}
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockPg.On("GetReferencesByCursor", mock.Anything, mock.Anything, 500, (*modelPg.Cursor)(nil), "").Return(batch, true, nil)
+		mockPg.On("GetReferencesByCursor", mock.Anything, mock.Anything, 500, mock.MatchedBy(func(c *modelPg.Cursor) bool {
+			return c != nil && c.ID == batch[499].ID && !c.Backward
+		}), "").Return(tail, false, nil)
+		mockMongo.On("MatchIDs", mock.Anything, mock.MatchedBy(func(ids []string) bool { return len(ids) == 500 }), mock.Anything).Return([]string{batch[7].MongoAchievementID}, nil)
+		mockMongo.On("MatchIDs", mock.Anything, []string{last.Hex()}, mock.Anything).Return([]string{last.Hex()}, nil)
+		mockMongo.On("FindAllDetails", mock.Anything, []string{last.Hex()}).Return([]modelMongo.Achievement{{ID: last, Title: "Last"}}, nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements?search=gemastik&page=2&limit=1", nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body modelPg.PaginatedResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Equal(t, 2, body.Meta.TotalData)
+		if assert.Len(t, body.Data, 1) {
+			assert.Equal(t, "Last", body.Data[0].(map[string]interface{})["title"])
+		}
+		assert.False(t, *body.Meta.HasMore)
+		mockPg.AssertNotCalled(t, "GetAllReferences", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: Stops After The Requested Page", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID, studentID := uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		batch := make([]modelPg.AchievementReference, 500)
+		for i := range batch {
+			batch[i] = modelPg.AchievementReference{ID: uuid.New(), StudentID: studentID, MongoAchievementID: primitive.NewObjectID().Hex()}
+		}
+		first := primitive.NewObjectID()
+		batch[3].MongoAchievementID = first.Hex()
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockPg.On("GetReferencesByCursor", mock.Anything, mock.Anything, 500, (*modelPg.Cursor)(nil), "").Return(batch, true, nil)
+		mockMongo.On("MatchIDs", mock.Anything, mock.Anything, mock.Anything).Return([]string{first.Hex(), batch[9].MongoAchievementID}, nil)
+		mockMongo.On("FindAllDetails", mock.Anything, []string{first.Hex()}).Return([]modelMongo.Achievement{{ID: first, Title: "First"}}, nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements?search=gemastik&limit=1", nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body modelPg.PaginatedResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Len(t, body.Data, 1)
+		assert.True(t, *body.Meta.HasMore)
+		// the second batch is never read
+		mockPg.AssertNumberOfCalls(t, "GetReferencesByCursor", 1)
+	})
+
+	t.Run("Success: Keeps Postgres Order Without Document Filters", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID, studentID := uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		first, second := primitive.NewObjectID(), primitive.NewObjectID()
+		refs := []modelPg.AchievementReference{
+			{ID: uuid.New(), StudentID: studentID, MongoAchievementID: first.Hex()},
+			{ID: uuid.New(), StudentID: studentID, MongoAchievementID: second.Hex()},
+		}
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockPg.On("GetAllReferences", mock.Anything, mock.Anything, 10, 0, "").Return(refs, int64(2), nil)
+		// the second document is missing, the item must still be listed
+		mockMongo.On("FindAllDetails", mock.Anything, []string{first.Hex(), second.Hex()}).Return([]modelMongo.Achievement{{ID: first, Title: "First"}}, nil)
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		req := httptest.NewRequest("GET", "/achievements", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body modelPg.PaginatedResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		assert.Len(t, body.Data, 2)
+		assert.Equal(t, "First", body.Data[0].(map[string]interface{})["title"])
+		assert.Equal(t, 2, body.Meta.TotalData)
+	})
+
+	t.Run("Fail: Invalid Date", func(t *testing.T) {
+		svc, _, _, _ := setupAchievementServiceTest()
+		app := setupAchievementAppWithPermissions(uuid.New(), "achievement:read")
+
+		app.Get("/achievements", svc.GetAllAchievements)
+
+		req := httptest.NewRequest("GET", "/achievements?dateFrom=31-12-2024", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+)
+
+func TestRestoreAchievement(t *testing.T) {
+	t.Run("Success: Restore Within Retention Window", func(t *testing.T) {
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		deletedAt := time.Now().Add(-time.Hour)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetDeletedReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, MongoAchievementID: "mongo_id", Status: "deleted", DeletedAt: &deletedAt,
+		}, nil)
+		mockMongo.On("RestoreFromTrash", mock.Anything, "mongo_id").Return(nil)
+		mockPg.On("RestoreReference", mock.Anything, achievementID).Return(nil)
+
+		app.Post("/achievements/:id/restore", svc.RestoreAchievement)
+
+		req := httptest.NewRequest("POST", "/achievements/"+achievementID.String()+"/restore", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockPg.AssertExpectations(t)
+		mockMongo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Retention Window Passed", func(t *testing.T) {
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+		userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		deletedAt := time.Now().Add(-365 * 24 * time.Hour)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetDeletedReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: studentID, MongoAchievementID: "mongo_id", Status: "deleted", DeletedAt: &deletedAt,
+		}, nil)
+
+		app.Post("/achievements/:id/restore", svc.RestoreAchievement)
+
+		req := httptest.NewRequest("POST", "/achievements/"+achievementID.String()+"/restore", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 410, resp.StatusCode)
+		mockMongo.AssertNotCalled(t, "RestoreFromTrash", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Not The Owner", func(t *testing.T) {
+		svc, _, mockPg, _ := setupAchievementServiceTest()
+		userID, achievementID := uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:delete")
+
+		deletedAt := time.Now()
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(uuid.New(), nil)
+		mockPg.On("GetDeletedReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, StudentID: uuid.New(), Status: "deleted", DeletedAt: &deletedAt,
+		}, nil)
+
+		app.Post("/achievements/:id/restore", svc.RestoreAchievement)
+
+		req := httptest.NewRequest("POST", "/achievements/"+achievementID.String()+"/restore", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+	before := time.Now()
+
+	expired := modelPg.AchievementReference{ID: uuid.New(), MongoAchievementID: "expired"}
+	orphan := modelPg.AchievementReference{ID: uuid.New(), MongoAchievementID: "orphan"}
+	broken := modelPg.AchievementReference{ID: uuid.New(), MongoAchievementID: "broken"}
+
+	mockPg.On("GetPurgeableReferences", mock.Anything, before).Return([]modelPg.AchievementReference{expired, orphan, broken}, nil)
+	mockMongo.On("FindOne", mock.Anything, "expired").Return(&modelMongo.Achievement{}, nil)
+	mockMongo.On("DeleteAchievement", mock.Anything, "expired").Return(nil)
+	mockMongo.On("FindOne", mock.Anything, "orphan").Return(nil, mongo.ErrNoDocuments)
+	mockMongo.On("FindOne", mock.Anything, "broken").Return(nil, errors.New("connection reset"))
+	mockPg.On("PurgeReference", mock.Anything, expired.ID).Return(nil)
+	mockPg.On("PurgeReference", mock.Anything, orphan.ID).Return(nil)
+
+	n, err := svc.PurgeTrash(t.Context(), before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	mockPg.AssertNotCalled(t, "PurgeReference", mock.Anything, broken.ID)
+	mockMongo.AssertExpectations(t)
+}
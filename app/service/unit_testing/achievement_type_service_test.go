@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/mongodb"
+)
+
+// --- SETUP HELPERS ---
+
+func setupAchievementTypeServiceTest() (*service.AchievementTypeService, *mocks.MockAchievementTypeRepo) {
+	mockType := new(mocks.MockAchievementTypeRepo)
+	svc := service.NewAchievementTypeService(mockType)
+	return svc, mockType
+}
+
+func setupPermissionApp(permissions ...string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("permissions", permissions)
+		return c.Next()
+	})
+	return app
+}
+
+// --- TEST CASES ---
+
+func TestGetAllAchievementTypes(t *testing.T) {
+	t.Run("Success: Built-in And Custom Types", func(t *testing.T) {
+		svc, mockType := setupAchievementTypeServiceTest()
+		app := setupPermissionApp("achievement:read")
+
+		mockType.On("FindAll", mock.Anything, false).Return([]modelMongo.AchievementTypeDefinition{
+			{Code: "competition", Name: "Lomba", IsActive: true, PointRule: modelMongo.PointRule{DefaultPoints: 20}},
+			{Code: "patent", Name: "Paten", IsActive: true},
+			{Code: "startup", Name: "Startup", IsActive: false},
+		}, nil)
+
+		app.Get("/achievement-types", svc.GetAllTypes)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievement-types", nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body []modelMongo.AchievementTypeDefinition
+		json.NewDecoder(resp.Body).Decode(&body)
+
+		codes := map[string]modelMongo.AchievementTypeDefinition{}
+		for _, d := range body {
+			codes[d.Code] = d
+		}
+		assert.Len(t, body, 6)
+		assert.Equal(t, "Lomba", codes["competition"].Name)
+		assert.True(t, codes["competition"].BuiltIn)
+		assert.Contains(t, codes, "patent")
+		assert.NotContains(t, codes, "startup")
+	})
+}
+
+func TestCreateAchievementType(t *testing.T) {
+	t.Run("Success: Create Type With Schema", func(t *testing.T) {
+		svc, mockType := setupAchievementTypeServiceTest()
+		app := setupPermissionApp("manage:achievement_types")
+
+		mockType.On("Insert", mock.Anything, mock.MatchedBy(func(d modelMongo.AchievementTypeDefinition) bool {
+			return d.Code == "patent" && d.IsActive && !d.BuiltIn
+		})).Return("abc123", nil)
+
+		app.Post("/achievement-types", svc.CreateType)
+
+		body, _ := json.Marshal(modelMongo.AchievementTypeDefinition{
+			Code: "patent",
+			Name: "Paten",
+			CustomFieldSchema: &modelMongo.JSONSchema{
+				Type:       "object",
+				Required:   []string{"patentNumber"},
+				Properties: map[string]*modelMongo.JSONSchema{"patentNumber": {Type: "string"}},
+			},
+		})
+		req := httptest.NewRequest("POST", "/achievement-types", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 201, resp.StatusCode)
+		mockType.AssertExpectations(t)
+	})
+
+	t.Run("Error: Invalid Schema", func(t *testing.T) {
+		svc, _ := setupAchievementTypeServiceTest()
+		app := setupPermissionApp("manage:achievement_types")
+		app.Post("/achievement-types", svc.CreateType)
+
+		body, _ := json.Marshal(modelMongo.AchievementTypeDefinition{
+			Code: "patent",
+			Name: "Paten",
+			CustomFieldSchema: &modelMongo.JSONSchema{
+				Type:     "object",
+				Required: []string{"patentNumber"},
+			},
+		})
+		req := httptest.NewRequest("POST", "/achievement-types", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+
+	t.Run("Error: Unknown Required Attachment Kind", func(t *testing.T) {
+		svc, mockType := setupAchievementTypeServiceTest()
+		app := setupPermissionApp("manage:achievement_types")
+		app.Post("/achievement-types", svc.CreateType)
+
+		body, _ := json.Marshal(modelMongo.AchievementTypeDefinition{
+			Code:                    "internship",
+			Name:                    "Magang",
+			RequiredAttachmentKinds: []string{"certificate", "transcript"},
+		})
+		req := httptest.NewRequest("POST", "/achievement-types", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		respBody, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(respBody), "requiredAttachmentKinds[1].kind")
+		mockType.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error: Forbidden Without Permission", func(t *testing.T) {
+		svc, _ := setupAchievementTypeServiceTest()
+		app := setupPermissionApp("achievement:read")
+		app.Post("/achievement-types", svc.CreateType)
+
+		resp, _ := app.Test(httptest.NewRequest("POST", "/achievement-types", nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+}
+
+func TestDeleteAchievementType(t *testing.T) {
+	t.Run("Error: Built-in Type", func(t *testing.T) {
+		svc, _ := setupAchievementTypeServiceTest()
+		app := setupPermissionApp("manage:achievement_types")
+		app.Delete("/achievement-types/:code", svc.DeleteType)
+
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievement-types/competition", nil))
+
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+
+	t.Run("Error: Unknown Type", func(t *testing.T) {
+		svc, mockType := setupAchievementTypeServiceTest()
+		app := setupPermissionApp("manage:achievement_types")
+		mockType.On("SetActive", mock.Anything, "patent", false).Return(errors.New("boom"))
+		app.Delete("/achievement-types/:code", svc.DeleteType)
+
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievement-types/patent", nil))
+
+		assert.Equal(t, 500, resp.StatusCode)
+	})
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func hasField(errs []utils.FieldError, field string) bool {
+	for _, e := range errs {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateAchievement(t *testing.T) {
+	t.Run("Success: Valid Competition", func(t *testing.T) {
+		errs := utils.ValidateAchievement(modelMongo.Achievement{
+			Title:           "Gemastik",
+			AchievementType: "competition",
+			Details: modelMongo.AchievementDetails{
+				CompetitionName:  "Gemastik XVI",
+				CompetitionLevel: "national",
+				MedalType:        "gold",
+				Rank:             1,
+			},
+		}, nil)
+
+		assert.Empty(t, errs)
+	})
+
+	t.Run("Error: Competition Without Name And With ISSN", func(t *testing.T) {
+		errs := utils.ValidateAchievement(modelMongo.Achievement{
+			Title:           "Gemastik",
+			AchievementType: "competition",
+			Details: modelMongo.AchievementDetails{
+				CompetitionLevel: "galaxy",
+				ISSN:             "0317-8471",
+			},
+		}, nil)
+
+		assert.True(t, hasField(errs, "details.competitionName"))
+		assert.True(t, hasField(errs, "details.competitionLevel"))
+		assert.True(t, hasField(errs, "details.issn"))
+	})
+
+	t.Run("Error: Unknown Type", func(t *testing.T) {
+		errs := utils.ValidateAchievement(modelMongo.Achievement{Title: "X", AchievementType: "hobby"}, nil)
+
+		assert.True(t, hasField(errs, "achievementType"))
+	})
+
+	t.Run("Error: Organization End Before Start", func(t *testing.T) {
+		start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		errs := utils.ValidateAchievement(modelMongo.Achievement{
+			Title:           "BEM",
+			AchievementType: "organization",
+			Details: modelMongo.AchievementDetails{
+				OrganizationName: "BEM Fakultas",
+				Position:         "Ketua",
+				StartDate:        start,
+				EndDate:          start.AddDate(0, -1, 0),
+			},
+		}, nil)
+
+		assert.True(t, hasField(errs, "details.endDate"))
+	})
+
+	t.Run("Success: Registered Custom Type", func(t *testing.T) {
+		def := &modelMongo.AchievementTypeDefinition{
+			Code:     "patent",
+			IsActive: true,
+			CustomFieldSchema: &modelMongo.JSONSchema{
+				Type:     "object",
+				Required: []string{"patentNumber"},
+				Properties: map[string]*modelMongo.JSONSchema{
+					"patentNumber": {Type: "string"},
+					"filedAt":      {Type: "string", Format: "date"},
+				},
+			},
+		}
+
+		errs := utils.ValidateAchievement(modelMongo.Achievement{
+			Title:           "Paten Alat Ukur",
+			AchievementType: "patent",
+			CustomFields:    map[string]interface{}{"patentNumber": "IDP000012345", "filedAt": "2024-02-01"},
+		}, def)
+		assert.Empty(t, errs)
+
+		errs = utils.ValidateAchievement(modelMongo.Achievement{
+			Title:           "Paten Alat Ukur",
+			AchievementType: "patent",
+			CustomFields:    map[string]interface{}{"filedAt": "01/02/2024"},
+		}, def)
+		assert.True(t, hasField(errs, "customFields.patentNumber"))
+		assert.True(t, hasField(errs, "customFields.filedAt"))
+	})
+
+	t.Run("Error: Expired Certification", func(t *testing.T) {
+		errs := utils.ValidateAchievement(modelMongo.Achievement{
+			Title:           "AWS",
+			AchievementType: "certification",
+			Details: modelMongo.AchievementDetails{
+				CertificationName: "AWS Cloud Practitioner",
+				IssuedBy:          "Amazon",
+				ValidUntil:        time.Now().AddDate(-1, 0, 0),
+			},
+		}, nil)
+
+		assert.True(t, hasField(errs, "details.validUntil"))
+	})
+}
+
+func TestValidISSN(t *testing.T) {
+	assert.True(t, utils.ValidISSN("0317-8471"))
+	assert.True(t, utils.ValidISSN("2434-561X"))
+	assert.False(t, utils.ValidISSN("0317-8472"))
+	assert.False(t, utils.ValidISSN("1234"))
+}
@@ -0,0 +1,208 @@
+package service_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func TestDiffAchievements(t *testing.T) {
+	old := modelMongo.Achievement{
+		Title:   "Juara 2 Gemastik",
+		Details: modelMongo.AchievementDetails{Rank: 2, CompetitionName: "Gemastik"},
+		Tags:    []string{"it"},
+		Points:  10,
+		Version: 1,
+	}
+	updated := old
+	updated.Title = "Juara 1 Gemastik"
+	updated.Details.Rank = 1
+	updated.Tags = []string{"it", "national"}
+	updated.Points = 50
+	updated.Version = 2
+
+	changes := utils.DiffAchievements(old, updated)
+
+	fields := map[string]modelMongo.FieldChange{}
+	for _, ch := range changes {
+		fields[ch.Field] = ch
+	}
+
+	assert.Len(t, changes, 3)
+	assert.Equal(t, "Juara 2 Gemastik", fields["title"].Old)
+	assert.Equal(t, "Juara 1 Gemastik", fields["title"].New)
+	assert.Equal(t, float64(2), fields["details.rank"].Old)
+	assert.Nil(t, fields["tags.1"].Old)
+	assert.Equal(t, "national", fields["tags.1"].New)
+	assert.Empty(t, utils.DiffAchievements(old, old))
+}
+
+func TestContentHash(t *testing.T) {
+	a := modelMongo.Achievement{Title: "Lomba", Details: modelMongo.AchievementDetails{Rank: 1}}
+	b := a
+	b.Points = 100
+	b.Version = 3
+
+	assert.Equal(t, a.ContentHash(), b.ContentHash(), "review fields must not change the hash")
+
+	b.Details.Rank = 2
+	assert.NotEqual(t, a.ContentHash(), b.ContentHash())
+
+	// moving a file to another storage key is not a change to the claim
+	c := a
+	c.Attachments = []modelMongo.Attachment{{ID: "1", FileName: "sertifikat.pdf", StorageKey: "a.pdf", Checksum: "abc"}}
+	d := c
+	d.Attachments = []modelMongo.Attachment{{ID: "1", FileName: "sertifikat.pdf", StorageKey: "sha256/ab/abc.pdf", Checksum: "abc"}}
+	assert.Equal(t, c.ContentHash(), d.ContentHash())
+	assert.NotEqual(t, c.ContentHashFormat(modelMongo.HashFormatLegacy), d.ContentHashFormat(modelMongo.HashFormatLegacy))
+}
+
+func TestModifiedSinceLegacyVerification(t *testing.T) {
+	svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+	userID, studentID, achievementID := uuid.New(), uuid.New(), uuid.New()
+	mongoID := primitive.NewObjectID()
+	app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+	// verified before hashes carried a format
+	current := modelMongo.Achievement{ID: mongoID, Title: "Juara 1", Version: 1, VerifiedVersion: 1,
+		Attachments: []modelMongo.Attachment{{ID: "1", FileName: "sertifikat.pdf", StorageKey: "a.pdf"}}}
+	current.VerifiedHash = current.ContentHashFormat(modelMongo.HashFormatLegacy)
+
+	mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{ID: achievementID, StudentID: studentID, MongoAchievementID: mongoID.Hex(), Status: "verified"}, nil)
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+	mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+	mockMongo.On("FindOne", mock.Anything, mongoID.Hex()).Return(&current, nil)
+	mockMongo.On("GetVersions", mock.Anything, mongoID.Hex()).Return([]modelMongo.AchievementVersion{}, nil)
+
+	app.Get("/achievements/:id/versions", svc.GetAchievementVersions)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+achievementID.String()+"/versions", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body struct {
+		ModifiedSinceVerification bool `json:"modifiedSinceVerification"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.False(t, body.ModifiedSinceVerification)
+}
+
+func TestGetAchievementVersions(t *testing.T) {
+	t.Run("Success: Lists Archived And Current Versions", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID := uuid.New()
+		studentID := uuid.New()
+		achievementID := uuid.New()
+		mongoID := primitive.NewObjectID()
+
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		ref := modelPg.AchievementReference{ID: achievementID, StudentID: studentID, MongoAchievementID: mongoID.Hex(), Status: "verified"}
+		verified := modelMongo.Achievement{ID: mongoID, Title: "Juara 1", Version: 1}
+		current := verified
+		current.Title = "Juara 1 Nasional"
+		current.Version = 2
+		current.VerifiedVersion = 1
+		current.VerifiedHash = verified.ContentHash()
+		current.VerifiedHashFormat = modelMongo.CurrentHashFormat
+
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(ref, nil)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockMongo.On("FindOne", mock.Anything, mongoID.Hex()).Return(&current, nil)
+		mockMongo.On("GetVersions", mock.Anything, mongoID.Hex()).Return([]modelMongo.AchievementVersion{
+			{AchievementID: mongoID.Hex(), Version: 1, Hash: verified.ContentHash(), Snapshot: verified},
+		}, nil)
+
+		app.Get("/achievements/:id/versions", svc.GetAchievementVersions)
+
+		req := httptest.NewRequest("GET", "/achievements/"+achievementID.String()+"/versions", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body struct {
+			CurrentVersion            int                      `json:"currentVersion"`
+			VerifiedVersion           int                      `json:"verifiedVersion"`
+			ModifiedSinceVerification bool                     `json:"modifiedSinceVerification"`
+			Versions                  []map[string]interface{} `json:"versions"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+
+		assert.Equal(t, 2, body.CurrentVersion)
+		assert.Equal(t, 1, body.VerifiedVersion)
+		assert.True(t, body.ModifiedSinceVerification)
+		assert.Len(t, body.Versions, 2)
+	})
+
+	t.Run("Fail: Other Student Cannot See Versions", func(t *testing.T) {
+		svc, _, mockPg, _ := setupAchievementServiceTest()
+		userID := uuid.New()
+		achievementID := uuid.New()
+
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		ref := modelPg.AchievementReference{ID: achievementID, StudentID: uuid.New(), Status: "draft"}
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(ref, nil)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(uuid.New(), nil)
+
+		app.Get("/achievements/:id/versions", svc.GetAchievementVersions)
+
+		req := httptest.NewRequest("GET", "/achievements/"+achievementID.String()+"/versions", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+}
+
+func TestDiffAchievementVersions(t *testing.T) {
+	t.Run("Success: Diff Verified Against Current", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID := uuid.New()
+		studentID := uuid.New()
+		achievementID := uuid.New()
+		mongoID := primitive.NewObjectID()
+
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		ref := modelPg.AchievementReference{ID: achievementID, StudentID: studentID, MongoAchievementID: mongoID.Hex(), Status: "verified"}
+		verified := modelMongo.Achievement{ID: mongoID, Title: "Juara 1", Version: 1}
+		current := verified
+		current.Title = "Juara 1 Nasional"
+		current.Version = 2
+		current.VerifiedVersion = 1
+
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(ref, nil)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+		mockMongo.On("FindOne", mock.Anything, mongoID.Hex()).Return(&current, nil)
+		mockMongo.On("GetVersion", mock.Anything, mongoID.Hex(), 1).Return(&modelMongo.AchievementVersion{Version: 1, Snapshot: verified}, nil)
+
+		app.Get("/achievements/:id/versions/diff", svc.DiffAchievementVersions)
+
+		req := httptest.NewRequest("GET", "/achievements/"+achievementID.String()+"/versions/diff", nil)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body struct {
+			From    int                      `json:"from"`
+			To      int                      `json:"to"`
+			Changes []modelMongo.FieldChange `json:"changes"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+
+		assert.Equal(t, 1, body.From)
+		assert.Equal(t, 2, body.To)
+		assert.Len(t, body.Changes, 1)
+		assert.Equal(t, "title", body.Changes[0].Field)
+	})
+}
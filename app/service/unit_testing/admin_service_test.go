@@ -45,7 +45,7 @@ func TestGetAllUsers(t *testing.T) {
 			{ID: uuid.New(), Username: "user2"},
 		}
 
-		mockRepo.On("GetAllUsers").Return(mockData, nil)
+		mockRepo.On("GetAllUsers", mock.Anything).Return(mockData, nil)
 		app.Get("/users", svc.GetAllUsers)
 
 		req := httptest.NewRequest("GET", "/users", nil)
@@ -80,7 +80,7 @@ func TestCreateUser(t *testing.T) {
 			Email:        "admin@test.com",
 		}
 
-		mockRepo.On("CreateUser", mock.MatchedBy(func(u *models.User) bool {
+		mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
 			return u.Username == "new_admin" && u.PasswordHash != "raw_password"
 		})).Return(nil)
 
@@ -104,7 +104,7 @@ func TestGetUserByID(t *testing.T) {
 		app := setupApp("student", myID)
 
 		mockUser := &models.User{ID: myID, Username: "me"}
-		mockRepo.On("GetUserByID", myID).Return(mockUser, nil)
+		mockRepo.On("GetUserByID", mock.Anything, myID).Return(mockUser, nil)
 
 		app.Get("/users/:id", svc.GetUserByID)
 
@@ -0,0 +1,157 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/postgresql"
+)
+
+func csvUpload(t *testing.T, content string) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "advisors.csv")
+	assert.NoError(t, err)
+	part.Write([]byte(content))
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func TestReassignAdvisorsCSV(t *testing.T) {
+	t.Run("Success: Rows By Number", func(t *testing.T) {
+		mockStudentRepo := new(mocks.MockStudentRepo)
+		svc := service.NewStudentService(mockStudentRepo, new(mocks.MockAchievementRepo), new(mocks.MockOrganizationRepo))
+		adminID := uuid.New()
+		app := setupAchievementAppWithPermissions(adminID, "manage:lecturers")
+
+		changes := []models.AdvisorChange{
+			{Student: "2101001", Lecturer: "198001"},
+			{Student: "2101002", Lecturer: "198002"},
+		}
+		mockStudentRepo.On("ReassignAdvisors", mock.Anything, changes, &adminID, (*models.CoordinatorScope)(nil)).
+			Return(&models.ReassignResult{Reassigned: 2, PendingSubmissions: 3}, nil)
+
+		app.Post("/students/advisors/reassign", svc.ReassignAdvisors)
+		body, contentType := csvUpload(t, "student,lecturer\n2101001, 198001\n2101002,198002\n")
+		req := httptest.NewRequest("POST", "/students/advisors/reassign", body)
+		req.Header.Set("Content-Type", contentType)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		var res models.ReassignResult
+		json.NewDecoder(resp.Body).Decode(&res)
+		assert.Equal(t, 3, res.PendingSubmissions)
+		mockStudentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Missing Header", func(t *testing.T) {
+		mockStudentRepo := new(mocks.MockStudentRepo)
+		svc := service.NewStudentService(mockStudentRepo, new(mocks.MockAchievementRepo), new(mocks.MockOrganizationRepo))
+		app := setupAchievementAppWithPermissions(uuid.New(), "manage:lecturers")
+
+		app.Post("/students/advisors/reassign", svc.ReassignAdvisors)
+		body, contentType := csvUpload(t, "2101001,198001\n")
+		req := httptest.NewRequest("POST", "/students/advisors/reassign", body)
+		req.Header.Set("Content-Type", contentType)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockStudentRepo.AssertNotCalled(t, "ReassignAdvisors", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Inactive Lecturer Rolls Back", func(t *testing.T) {
+		mockStudentRepo := new(mocks.MockStudentRepo)
+		svc := service.NewStudentService(mockStudentRepo, new(mocks.MockAchievementRepo), new(mocks.MockOrganizationRepo))
+		app := setupAchievementAppWithPermissions(uuid.New(), "manage:lecturers")
+
+		mockStudentRepo.On("ReassignAdvisors", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, fmt.Errorf("2101002: %w", repo.ErrLecturerNotActive))
+
+		app.Post("/students/advisors/reassign", svc.ReassignAdvisors)
+		body, contentType := csvUpload(t, "student,lecturer\n2101001,198001\n2101002,198099\n")
+		req := httptest.NewRequest("POST", "/students/advisors/reassign", body)
+		req.Header.Set("Content-Type", contentType)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		var res map[string]string
+		json.NewDecoder(resp.Body).Decode(&res)
+		assert.Contains(t, res["error"], "2101002")
+	})
+}
+
+func TestTransferAdvisees(t *testing.T) {
+	t.Run("Success: Coordinator Scope Passed On", func(t *testing.T) {
+		mockStudentRepo := new(mocks.MockStudentRepo)
+		mockOrg := new(mocks.MockOrganizationRepo)
+		svc := service.NewStudentService(mockStudentRepo, new(mocks.MockAchievementRepo), mockOrg)
+		coordinatorID := uuid.New()
+		app := setupAchievementAppWithPermissions(coordinatorID, "manage:lecturers", "oversight:scoped")
+
+		scope := &models.CoordinatorScope{UserID: coordinatorID, Covered: []uuid.UUID{uuid.New()}}
+		mockOrg.On("GetCoordinatorScope", mock.Anything, coordinatorID).Return(scope, nil)
+		fromID, toID := uuid.New(), uuid.New()
+		mockStudentRepo.On("TransferAdvisees", mock.Anything, fromID, toID, &coordinatorID, scope).
+			Return(&models.ReassignResult{Reassigned: 4}, nil)
+
+		app.Post("/students/advisors/reassign", svc.ReassignAdvisors)
+		body, _ := json.Marshal(map[string]string{"fromLecturerId": fromID.String(), "toLecturerId": toID.String()})
+		req := httptest.NewRequest("POST", "/students/advisors/reassign", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockStudentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Same Lecturer", func(t *testing.T) {
+		mockStudentRepo := new(mocks.MockStudentRepo)
+		svc := service.NewStudentService(mockStudentRepo, new(mocks.MockAchievementRepo), new(mocks.MockOrganizationRepo))
+		app := setupAchievementAppWithPermissions(uuid.New(), "manage:lecturers")
+
+		id := uuid.New().String()
+		app.Post("/students/advisors/reassign", svc.ReassignAdvisors)
+		body, _ := json.Marshal(map[string]string{"fromLecturerId": id, "toLecturerId": id})
+		req := httptest.NewRequest("POST", "/students/advisors/reassign", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockStudentRepo.AssertNotCalled(t, "TransferAdvisees", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetAdvisorHistory(t *testing.T) {
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	svc := service.NewStudentService(mockStudentRepo, new(mocks.MockAchievementRepo), new(mocks.MockOrganizationRepo))
+	app := setupAchievementAppWithPermissions(uuid.New(), "manage:students")
+
+	studentID := uuid.New()
+	ended := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	mockStudentRepo.On("GetAdvisorHistory", mock.Anything, studentID).Return([]models.AdvisorAssignment{
+		{ID: uuid.New(), StudentID: studentID, LecturerID: uuid.New(), LecturerName: "Dr. Budi", EffectiveFrom: ended},
+		{ID: uuid.New(), StudentID: studentID, LecturerID: uuid.New(), LecturerName: "Dr. Sari", EffectiveFrom: ended.AddDate(-1, 0, 0), EffectiveTo: &ended},
+	}, nil)
+
+	app.Get("/students/:id/advisors", svc.GetAdvisorHistory)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/students/"+studentID.String()+"/advisors", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var history []models.AdvisorAssignment
+	json.NewDecoder(resp.Body).Decode(&history)
+	assert.Len(t, history, 2)
+	assert.Nil(t, history[0].EffectiveTo)
+	assert.Equal(t, "Dr. Sari", history[1].LecturerName)
+}
@@ -4,27 +4,64 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 
 	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
 	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
 	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/scanner"
 	"StudenAchievementReportingSystem/app/service/mongodb"
+	"StudenAchievementReportingSystem/utils"
 )
 
 // --- SETUP HELPERS ---
 
+// testAchievementDeps returns AchievementService dependencies made of fresh
+// mocks, the shared test storage, a scanner that passes everything and the
+// embedded previews. Tests replace the ones they set expectations on.
+func testAchievementDeps() service.AchievementDeps {
+	return service.AchievementDeps{
+		Achievements:    new(mocks.MockAchievementMongoRepo),
+		References:      new(mocks.MockAchievementPgRepo),
+		Lecturers:       new(mocks.MockLecturerRepo),
+		Types:           new(mocks.MockAchievementTypeRepo),
+		Students:        new(mocks.MockStudentRepo),
+		Periods:         new(mocks.MockAcademicPeriodRepo),
+		Organization:    new(mocks.MockOrganizationRepo),
+		Files:           testStorage(),
+		AttachmentTypes: new(mocks.MockAttachmentTypeRepo),
+		Scanner:         scanner.NewNoop(),
+		Notifications:   new(mocks.MockNotificationRepo),
+		Blobs:           new(mocks.MockAttachmentBlobRepo),
+		Previews:        testPreviews(),
+	}
+}
+
 func setupAchievementServiceTest() (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockAchievementPgRepo, *mocks.MockLecturerRepo) {
 	mockMongo := new(mocks.MockAchievementMongoRepo)
 	mockPg := new(mocks.MockAchievementPgRepo)
 	mockLecturer := new(mocks.MockLecturerRepo)
+	mockType := new(mocks.MockAchievementTypeRepo)
+	mockType.On("FindByCode", mock.Anything, mock.Anything).Return(nil, mongo.ErrNoDocuments).Maybe()
+
+	mockPeriod := new(mocks.MockAcademicPeriodRepo)
+	mockPeriod.On("FindByDate", mock.Anything, mock.Anything).Return(nil, errors.New("not found")).Maybe()
 
-	svc := service.NewAchievementService(mockMongo, mockPg, mockLecturer)
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.Lecturers = mockLecturer
+	deps.Types = mockType
+	deps.Periods = mockPeriod
+	svc := service.NewAchievementService(deps)
 
 	return svc, mockMongo, mockPg, mockLecturer
 }
@@ -39,6 +76,16 @@ func setupAchievementApp(roleName string, userID uuid.UUID) *fiber.App {
 	return app
 }
 
+func setupAchievementAppWithPermissions(userID uuid.UUID, permissions ...string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("permissions", permissions)
+		return c.Next()
+	})
+	return app
+}
+
 // --- TEST CASES ---
 
 func TestCreateAchievement(t *testing.T) {
@@ -52,8 +99,9 @@ func TestCreateAchievement(t *testing.T) {
 		newRefID := uuid.New()
 
 		reqBody := modelMongo.Achievement{
-			Title:       "Lomba Coding",
-			Description: "Juara 1",
+			Title:           "Lomba Coding",
+			Description:     "Juara 1",
+			AchievementType: "other",
 		}
 
 		// 1. Mock GetStudentByUserID (PG)
@@ -101,7 +149,7 @@ func TestCreateAchievement(t *testing.T) {
 
 func TestSubmitAchievement(t *testing.T) {
 	t.Run("Success: Submit Draft", func(t *testing.T) {
-		svc, _, mockPg, _ := setupAchievementServiceTest()
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
 		userID := uuid.New()
 		app := setupAchievementApp("mahasiswa", userID)
 
@@ -123,6 +171,7 @@ func TestSubmitAchievement(t *testing.T) {
 
 		// 3. Submit Action
 		mockPg.On("SubmitReference", mock.Anything, achievementID).Return(nil)
+		mockMongo.On("FindOne", mock.Anything, mock.Anything).Return(nil, errors.New("not found")).Maybe()
 
 		app.Post("/achievements/:id/submit", svc.SubmitAchievement)
 
@@ -133,6 +182,63 @@ func TestSubmitAchievement(t *testing.T) {
 		mockPg.AssertExpectations(t)
 	})
 
+	t.Run("Success: Submit Flags Possible Duplicate", func(t *testing.T) {
+		svc, mockMongo, mockPg, _ := setupAchievementServiceTest()
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		achievementID := uuid.New()
+		studentID := uuid.New()
+		mongoID := primitive.NewObjectID()
+		otherMongoID := primitive.NewObjectID()
+		otherRefID := uuid.New()
+
+		ref := modelPg.AchievementReference{
+			ID:                 achievementID,
+			StudentID:          studentID,
+			MongoAchievementID: mongoID.Hex(),
+			Status:             "draft",
+			Version:            1,
+		}
+		detail := &modelMongo.Achievement{
+			ID:              mongoID,
+			StudentID:       studentID.String(),
+			Title:           "AWS Cloud Practitioner",
+			AchievementType: "certification",
+			Details:         modelMongo.AchievementDetails{CertificationNumber: "AWS-123"},
+		}
+		other := modelMongo.Achievement{
+			ID:              otherMongoID,
+			StudentID:       studentID.String(),
+			Title:           "AWS Certified Cloud Practitioner",
+			AchievementType: "certification",
+			Details:         modelMongo.AchievementDetails{CertificationNumber: "aws-123"},
+		}
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(studentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(ref, nil)
+		mockPg.On("BumpVersion", mock.Anything, achievementID, 1).Return(nil)
+		mockPg.On("SubmitReference", mock.Anything, achievementID).Return(nil)
+		mockMongo.On("FindOne", mock.Anything, mongoID.Hex()).Return(detail, nil)
+		mockMongo.On("FindDuplicateCandidates", mock.Anything, *detail).Return([]modelMongo.Achievement{other}, nil)
+		mockPg.On("GetReferencesByMongoIDs", mock.Anything, []string{otherMongoID.Hex()}).Return([]modelPg.AchievementReference{
+			{ID: otherRefID, MongoAchievementID: otherMongoID.Hex(), Status: "verified"},
+		}, nil)
+		mockMongo.On("SetDuplicateWarnings", mock.Anything, mongoID.Hex(), mock.MatchedBy(func(w []modelMongo.DuplicateWarning) bool {
+			return len(w) == 1 && w[0].AchievementID == otherRefID.String() && w[0].Reasons[0] == "same certification number"
+		})).Return(nil)
+
+		app.Post("/achievements/:id/submit", svc.SubmitAchievement)
+
+		req := httptest.NewRequest("POST", "/achievements/"+achievementID.String()+"/submit", nil)
+		req.Header.Set("If-Match", `"1"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockMongo.AssertExpectations(t)
+		mockPg.AssertExpectations(t)
+	})
+
 	t.Run("Error: Cannot Submit Non-Draft", func(t *testing.T) {
 		svc, _, mockPg, _ := setupAchievementServiceTest()
 		userID := uuid.New()
@@ -159,6 +265,90 @@ func TestSubmitAchievement(t *testing.T) {
 	})
 }
 
+func TestSubmitRequiredAttachmentKinds(t *testing.T) {
+	setup := func(t *testing.T, attachments []modelMongo.Attachment, typeErr error) (*fiber.App, *mocks.MockAchievementPgRepo, uuid.UUID) {
+		mockMongo := new(mocks.MockAchievementMongoRepo)
+		mockPg := new(mocks.MockAchievementPgRepo)
+		mockType := new(mocks.MockAchievementTypeRepo)
+		deps := testAchievementDeps()
+		deps.Achievements = mockMongo
+		deps.References = mockPg
+		deps.Types = mockType
+		svc := service.NewAchievementService(deps)
+
+		userID := uuid.New()
+		ref := modelPg.AchievementReference{ID: uuid.New(), StudentID: uuid.New(), MongoAchievementID: primitive.NewObjectID().Hex(), Status: "draft", Version: 1}
+		detail := &modelMongo.Achievement{Title: "Magang Industri", AchievementType: "internship", Attachments: attachments}
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+		mockPg.On("GetReferenceByID", mock.Anything, ref.ID).Return(ref, nil)
+		mockPg.On("BumpVersion", mock.Anything, ref.ID, 1).Return(nil).Maybe()
+		mockPg.On("SubmitReference", mock.Anything, ref.ID).Return(nil).Maybe()
+		mockMongo.On("FindOne", mock.Anything, ref.MongoAchievementID).Return(detail, nil)
+		mockMongo.On("FindDuplicateCandidates", mock.Anything, mock.Anything).Return([]modelMongo.Achievement{}, nil).Maybe()
+		if typeErr != nil {
+			mockType.On("FindByCode", mock.Anything, "internship").Return(nil, typeErr)
+		} else {
+			mockType.On("FindByCode", mock.Anything, "internship").Return(&modelMongo.AchievementTypeDefinition{
+				Code:                    "internship",
+				IsActive:                true,
+				RequiredAttachmentKinds: []string{"certificate", "assignment_letter"},
+			}, nil)
+		}
+
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		app.Post("/achievements/:id/submit", svc.SubmitAchievement)
+		return app, mockPg, ref.ID
+	}
+	submit := func(app *fiber.App, id uuid.UUID) *http.Response {
+		req := httptest.NewRequest("POST", "/achievements/"+id.String()+"/submit", nil)
+		req.Header.Set("If-Match", `"1"`)
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	t.Run("Success: Required Kinds Attached", func(t *testing.T) {
+		app, mockPg, id := setup(t, []modelMongo.Attachment{
+			{ID: "1", Kind: "certificate", Scan: &modelMongo.AttachmentScan{Status: modelMongo.ScanClean}},
+			{ID: "2", Kind: "assignment_letter"},
+		}, nil)
+
+		resp := submit(app, id)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockPg.AssertCalled(t, "SubmitReference", mock.Anything, id)
+	})
+
+	t.Run("Error: Missing Kinds Listed", func(t *testing.T) {
+		// the certificate was removed by the malware scan
+		app, mockPg, id := setup(t, []modelMongo.Attachment{
+			{ID: "1", Kind: "certificate", Scan: &modelMongo.AttachmentScan{Status: modelMongo.ScanInfected}},
+			{ID: "2", Kind: "photo"},
+		}, nil)
+
+		resp := submit(app, id)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		var body struct {
+			Details []utils.FieldError `json:"details"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, []utils.FieldError{
+			{Field: "attachments", Message: "needs an attachment of kind certificate"},
+			{Field: "attachments", Message: "needs an attachment of kind assignment_letter"},
+		}, body.Details)
+		mockPg.AssertNotCalled(t, "SubmitReference", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Error: Type Registry Unavailable", func(t *testing.T) {
+		app, mockPg, id := setup(t, nil, errors.New("server selection timeout"))
+
+		resp := submit(app, id)
+
+		assert.Equal(t, 500, resp.StatusCode)
+		mockPg.AssertNotCalled(t, "SubmitReference", mock.Anything, mock.Anything)
+	})
+}
+
 func TestVerifyAchievement(t *testing.T) {
 	t.Run("Success: Lecturer Verifies Achievement", func(t *testing.T) {
 		svc, _, mockPg, mockLecturer := setupAchievementServiceTest()
@@ -188,4 +378,28 @@ func TestVerifyAchievement(t *testing.T) {
 
 		assert.Equal(t, 200, resp.StatusCode)
 	})
+
+	t.Run("Fail: Status Is Kept When The Pin Fails", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer := setupAchievementServiceTest()
+		userID, achievementID := uuid.New(), uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:verify")
+
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.New(), nil)
+		mockPg.On("GetReferenceByID", mock.Anything, achievementID).Return(modelPg.AchievementReference{
+			ID: achievementID, MongoAchievementID: "mongo1", Status: "submitted", Version: 1,
+		}, nil)
+		mockPg.On("BumpVersion", mock.Anything, achievementID, 1).Return(nil)
+		mockPg.On("ReleaseVersion", mock.Anything, achievementID, 2).Return(nil)
+		mockMongo.On("PinVerifiedVersion", mock.Anything, "mongo1", 25).Return(errors.New("mongo down"))
+
+		app.Post("/achievements/:id/verify", svc.VerifyAchievement)
+
+		req := httptest.NewRequest("POST", "/achievements/"+achievementID.String()+"/verify", bytes.NewBufferString(`{"points":25}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 500, resp.StatusCode)
+		mockPg.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
\ No newline at end of file
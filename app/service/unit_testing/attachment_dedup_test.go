@@ -0,0 +1,172 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	service "StudenAchievementReportingSystem/app/service/mongodb"
+	"StudenAchievementReportingSystem/app/storage"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// setupDedupTest is setupUploadTest with the blob repository under the test's control
+func setupDedupTest(t *testing.T) (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockAttachmentBlobRepo, uuid.UUID, modelPg.AchievementReference) {
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockTypes := new(mocks.MockAttachmentTypeRepo)
+	mockBlobs := new(mocks.MockAttachmentBlobRepo)
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.AttachmentTypes = mockTypes
+	deps.Blobs = mockBlobs
+	svc := service.NewAchievementService(deps)
+
+	userID := uuid.New()
+	ref := modelPg.AchievementReference{ID: uuid.New(), StudentID: uuid.New(), MongoAchievementID: "mongo1", Status: "draft", Version: 1}
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+	mockPg.On("GetReferenceByID", mock.Anything, ref.ID).Return(ref, nil)
+	mockPg.On("BumpVersion", mock.Anything, ref.ID, 1).Return(nil).Maybe()
+	mockMongo.On("StorageByAchievement", mock.Anything, ref.StudentID.String()).Return([]modelMongo.AchievementStorage{}, nil).Maybe()
+	mockTypes.On("GetByCode", mock.Anything, "pdf").Return(&modelPg.AttachmentType{Code: "pdf", MaxSizeBytes: 1024}, nil).Maybe()
+	return svc, mockMongo, mockBlobs, userID, ref
+}
+
+func TestUploadDeduplication(t *testing.T) {
+	checksum := sha256Hex(samplePDF)
+	key := "sha256/" + checksum[:2] + "/" + checksum + ".pdf"
+
+	t.Run("Success: New Content Stored Under Its Hash", func(t *testing.T) {
+		svc, mockMongo, mockBlobs, userID, ref := setupDedupTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		t.Cleanup(func() { testStorage().Delete(context.Background(), key) })
+
+		mockBlobs.On("Acquire", mock.Anything, modelPg.AttachmentBlob{Checksum: checksum, StorageKey: key, SizeBytes: int64(len(samplePDF)), ContentType: "application/pdf"}).
+			Return(func(b modelPg.AttachmentBlob) *modelPg.AttachmentBlob { return &b }, true, nil)
+		var stored modelMongo.Attachment
+		mockMongo.On("AddAttachments", mock.Anything, "mongo1", mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]modelMongo.Attachment)[0]
+		}).Return(nil)
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", "Sertifikat.JPEG.pdf", "application/pdf", samplePDF))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, key, stored.StorageKey)
+		assert.Equal(t, checksum, stored.Checksum)
+		body, err := testStorage().Open(context.Background(), key)
+		if assert.NoError(t, err) {
+			body.Close()
+		}
+	})
+
+	t.Run("Success: Known Content Not Stored Again", func(t *testing.T) {
+		svc, mockMongo, mockBlobs, userID, ref := setupDedupTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		testStorage().Delete(context.Background(), key)
+
+		mockBlobs.On("Acquire", mock.Anything, mock.Anything).
+			Return(&modelPg.AttachmentBlob{Checksum: checksum, StorageKey: key, RefCount: 2}, false, nil)
+		var stored modelMongo.Attachment
+		mockMongo.On("AddAttachments", mock.Anything, "mongo1", mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]modelMongo.Attachment)[0]
+		}).Return(nil)
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", "lagi.pdf", "application/pdf", samplePDF))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, key, stored.StorageKey)
+		_, err := testStorage().Open(context.Background(), key)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("Fail: Registry Unavailable", func(t *testing.T) {
+		svc, mockMongo, mockBlobs, userID, ref := setupDedupTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		mockBlobs.On("Acquire", mock.Anything, mock.Anything).Return(nil, false, errors.New("db down"))
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", "sertifikat.pdf", "application/pdf", samplePDF))
+
+		assert.Equal(t, 500, resp.StatusCode)
+		mockMongo.AssertNotCalled(t, "AddAttachments", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteDeduplicatedAttachment(t *testing.T) {
+	svc, mockMongo, mockBlobs, userID, ref := setupDedupTest(t)
+	app := setupAchievementAppWithPermissions(userID, "achievement:create")
+	checksum := sha256Hex(samplePDF)
+	att := modelMongo.Attachment{ID: uuid.New().String(), FileName: "sertifikat.pdf", Checksum: checksum}
+	att.StorageKey = "sha256/" + checksum[:2] + "/" + checksum + ".pdf"
+	assert.NoError(t, testStorage().Put(context.Background(), att.StorageKey, strings.NewReader(samplePDF), 0, "application/pdf"))
+	t.Cleanup(func() { testStorage().Delete(context.Background(), att.StorageKey) })
+
+	mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{att}}, nil)
+	mockMongo.On("RemoveAttachment", mock.Anything, "mongo1", att.ID).Return(nil)
+	// last reference, so the file goes as well
+	mockBlobs.On("Release", mock.Anything, checksum).Return(nil, att.StorageKey)
+
+	app.Delete("/achievements/:id/attachments/:attachmentId", svc.DeleteAttachment)
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID, nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	mockBlobs.AssertExpectations(t)
+	_, err := testStorage().Open(context.Background(), att.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestDownloadIntegrityCheck(t *testing.T) {
+	t.Run("Success: Digest Sent", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer, ref, att := setupAttachmentDownloadTest(t)
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+		att.Checksum = sha256Hex("%PDF-1.4")
+		mockMongo.ExpectedCalls = nil
+		mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{att}}, nil)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+
+		app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID, nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		sum := sha256.Sum256([]byte("%PDF-1.4"))
+		assert.Equal(t, "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":", resp.Header.Get("Repr-Digest"))
+	})
+
+	t.Run("Fail: Stored File Changed", func(t *testing.T) {
+		svc, mockMongo, mockPg, mockLecturer, ref, att := setupAttachmentDownloadTest(t)
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+		att.Checksum = sha256Hex("%PDF-1.7 original")
+		mockMongo.ExpectedCalls = nil
+		mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{att}}, nil)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+
+		app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID, nil))
+
+		assert.Equal(t, 500, resp.StatusCode)
+	})
+}
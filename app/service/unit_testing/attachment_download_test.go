@@ -0,0 +1,208 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/mongodb"
+	"StudenAchievementReportingSystem/app/storage"
+	"StudenAchievementReportingSystem/config"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func setupAttachmentDownloadTest(t *testing.T) (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockAchievementPgRepo, *mocks.MockLecturerRepo, modelPg.AchievementReference, modelMongo.Attachment) {
+	t.Setenv("SIGNED_URL_SECRET", "test-secret")
+
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockLecturer := new(mocks.MockLecturerRepo)
+	files := testStorage()
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.Lecturers = mockLecturer
+	deps.Files = files
+	svc := service.NewAchievementService(deps)
+
+	att := modelMongo.Attachment{ID: uuid.New().String(), FileName: "sertifikat.pdf", FileType: "application/pdf"}
+	att.StorageKey = att.ID + ".pdf"
+	assert.NoError(t, files.Put(context.Background(), att.StorageKey, strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+	t.Cleanup(func() { files.Delete(context.Background(), att.StorageKey) })
+
+	ref := modelPg.AchievementReference{ID: uuid.New(), StudentID: uuid.New(), MongoAchievementID: "mongo1", Status: "submitted", Version: 1}
+	mockPg.On("GetReferenceByID", mock.Anything, ref.ID).Return(ref, nil)
+	mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{att}}, nil)
+	return svc, mockMongo, mockPg, mockLecturer, ref, att
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	t.Run("Success: Owner Downloads", func(t *testing.T) {
+		svc, _, mockPg, mockLecturer, ref, att := setupAttachmentDownloadTest(t)
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+
+		app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID, nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+		assert.Equal(t, `inline; filename=sertifikat.pdf`, resp.Header.Get("Content-Disposition"))
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "%PDF-1.4", string(body))
+	})
+
+	t.Run("Fail: Other Student", func(t *testing.T) {
+		svc, _, mockPg, _, ref, att := setupAttachmentDownloadTest(t)
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(uuid.New(), nil)
+
+		app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID, nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+
+	t.Run("Fail: Unknown Attachment", func(t *testing.T) {
+		svc, _, mockPg, mockLecturer, ref, _ := setupAttachmentDownloadTest(t)
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+
+		app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/"+uuid.New().String(), nil))
+
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+}
+
+func TestDownloadSignedAttachment(t *testing.T) {
+	svc, _, _, _, ref, att := setupAttachmentDownloadTest(t)
+	app := setupAchievementAppWithPermissions(uuid.Nil)
+	app.Get("/api/v1/files/achievements/:id/attachments/:attachmentId", svc.DownloadSignedAttachment)
+
+	path := "/api/v1/files/achievements/" + ref.ID.String() + "/attachments/" + att.ID
+	link := utils.SignURL(path, time.Now())
+
+	t.Run("Success: Valid Link", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest("GET", link, nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "%PDF-1.4", string(body))
+	})
+
+	t.Run("Fail: Link For Another Attachment", func(t *testing.T) {
+		u, _ := url.Parse(link)
+		other := "/api/v1/files/achievements/" + ref.ID.String() + "/attachments/" + uuid.New().String()
+		resp, _ := app.Test(httptest.NewRequest("GET", other+"?"+u.RawQuery, nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+
+	t.Run("Fail: Expired Link", func(t *testing.T) {
+		expired := utils.SignURL(path, time.Now().Add(-time.Hour))
+		resp, _ := app.Test(httptest.NewRequest("GET", expired, nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+	t.Run("Fail: Secret Not Set", func(t *testing.T) {
+		t.Setenv("SIGNED_URL_SECRET", "")
+		unsigned := utils.SignURL(path, time.Now())
+		resp, _ := app.Test(httptest.NewRequest("GET", unsigned, nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+}
+
+func TestSignedURLConfigValidate(t *testing.T) {
+	jwt := strings.Repeat("j", 40)
+	t.Setenv("JWT_SECRET", jwt)
+
+	for _, secret := range []string{"", jwt, "change-me", "rahasia_tautan_lampiran_ini", "short-but-random-9f3a"} {
+		t.Setenv("SIGNED_URL_SECRET", secret)
+		assert.Error(t, config.LoadSignedURL().Validate(), secret)
+	}
+
+	t.Setenv("SIGNED_URL_SECRET", "4f9c2e7a1b8d6f3e0a5c9b2d7e1f4a8c")
+	assert.NoError(t, config.LoadSignedURL().Validate())
+}
+
+func TestDownloadAttachmentFromS3(t *testing.T) {
+	goodSum := sha256.Sum256([]byte("%PDF-1.4"))
+	fake := &fakeS3{objects: map[string]string{
+		"/attachments/old.pdf":           "%PDF-1.4",
+		"/attachments/sha256/ab/abc.pdf": "%PDF-tampered",
+		"/attachments/sha256/cd/cde.pdf": "%PDF-1.4",
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockLecturer := new(mocks.MockLecturerRepo)
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.Lecturers = mockLecturer
+	deps.Files = storage.NewS3(storage.S3Config{Endpoint: server.URL, Bucket: "attachments", AccessKey: "minio", SecretKey: "minio123", PathStyle: true, URLExpiry: time.Minute})
+	svc := service.NewAchievementService(deps)
+
+	userID := uuid.New()
+	ref := modelPg.AchievementReference{ID: uuid.New(), StudentID: uuid.New(), MongoAchievementID: "mongo1", Status: "submitted", Version: 1}
+	old := modelMongo.Attachment{ID: "old", FileName: "old.pdf", FileType: "application/pdf", StorageKey: "old.pdf"}
+	checked := modelMongo.Attachment{ID: "checked", FileName: "abc.pdf", FileType: "application/pdf", StorageKey: "sha256/ab/abc.pdf", Checksum: strings.Repeat("0", 64)}
+	mockPg.On("GetReferenceByID", mock.Anything, ref.ID).Return(ref, nil)
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+	mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+	intact := modelMongo.Attachment{ID: "intact", FileName: "cde.pdf", FileType: "application/pdf", StorageKey: "sha256/cd/cde.pdf", Checksum: hex.EncodeToString(goodSum[:])}
+	mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{old, checked, intact}}, nil)
+
+	app := setupAchievementAppWithPermissions(userID, "achievement:read")
+	app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+
+	t.Run("Success: File Without Checksum Is Redirected", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/old", nil))
+
+		assert.Equal(t, 302, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Location"), "/attachments/old.pdf")
+	})
+
+	t.Run("Success: Checked File Is Read Once", func(t *testing.T) {
+		fake.reads = 0
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/intact", nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "%PDF-1.4", string(body))
+		assert.Equal(t, 1, fake.reads)
+	})
+
+	t.Run("Fail: Tampered File Is Not Redirected", func(t *testing.T) {
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/checked", nil))
+
+		assert.Equal(t, 500, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Location"))
+	})
+}
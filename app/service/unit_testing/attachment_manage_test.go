@@ -0,0 +1,200 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	"StudenAchievementReportingSystem/app/storage"
+)
+
+// multipartRequest sends files (name, content pairs) in repeated "file" parts
+// followed by the given form fields
+func multipartRequest(method, path string, files [][2]string, fields map[string][]string) *http.Request {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, f := range files {
+		part, _ := w.CreateFormFile("file", f[0])
+		part.Write([]byte(f[1]))
+	}
+	for name, values := range fields {
+		for _, v := range values {
+			w.WriteField(name, v)
+		}
+	}
+	w.Close()
+
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestUploadMultipleAttachments(t *testing.T) {
+	t.Run("Success: Files Added In Order", func(t *testing.T) {
+		svc, mockMongo, mockTypes, userID, ref := setupUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		mockTypes.On("GetByCode", mock.Anything, "png").Return(nil, nil).Maybe()
+
+		var stored []modelMongo.Attachment
+		mockMongo.On("AddAttachments", mock.Anything, "mongo1", mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]modelMongo.Attachment)
+		}).Return(nil)
+		t.Cleanup(func() {
+			for _, att := range stored {
+				testStorage().Delete(context.Background(), att.StorageKey)
+			}
+		})
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(multipartRequest("POST", "/achievements/"+ref.ID.String()+"/attachments",
+			[][2]string{{"sertifikat.pdf", samplePDF}, {"surat-tugas.pdf", samplePDF}},
+			map[string][]string{"caption": {"Sertifikat juara 1", "Surat tugas"}, "kind": {"certificate", "Assignment_Letter"}},
+		))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		if assert.Len(t, stored, 2) {
+			assert.Equal(t, "sertifikat.pdf", stored[0].FileName)
+			assert.Equal(t, "certificate", stored[0].Kind)
+			assert.Equal(t, "Surat tugas", stored[1].Caption)
+			assert.Equal(t, "assignment_letter", stored[1].Kind)
+			assert.NotEqual(t, stored[0].ID, stored[1].ID)
+		}
+	})
+
+	t.Run("Fail: One Bad File Stores Nothing", func(t *testing.T) {
+		svc, mockMongo, _, userID, ref := setupUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(multipartRequest("POST", "/achievements/"+ref.ID.String()+"/attachments",
+			[][2]string{{"sertifikat.pdf", samplePDF}, {"script.pdf", "#!/bin/sh"}}, nil,
+		))
+
+		assert.Equal(t, 415, resp.StatusCode)
+		mockMongo.AssertNotCalled(t, "AddAttachments", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Unknown Kind", func(t *testing.T) {
+		svc, _, _, userID, ref := setupUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(multipartRequest("POST", "/achievements/"+ref.ID.String()+"/attachments",
+			[][2]string{{"sertifikat.pdf", samplePDF}}, map[string][]string{"kind": {"selfie"}},
+		))
+
+		assert.Equal(t, 400, resp.StatusCode)
+		var res map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&res)
+		assert.Contains(t, res["details"].([]interface{})[0].(map[string]interface{})["field"], "files[0].kind")
+	})
+}
+
+// existingAttachment stores a file and makes FindOne return it on the draft
+func existingAttachment(t *testing.T, mockMongo interface {
+	On(string, ...interface{}) *mock.Call
+}) modelMongo.Attachment {
+	att := modelMongo.Attachment{ID: uuid.New().String(), FileName: "lama.pdf", Caption: "Sertifikat", Kind: "certificate"}
+	att.StorageKey = uuid.New().String() + ".pdf"
+	assert.NoError(t, testStorage().Put(context.Background(), att.StorageKey, strings.NewReader(samplePDF), 0, "application/pdf"))
+	t.Cleanup(func() { testStorage().Delete(context.Background(), att.StorageKey) })
+	mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{att}}, nil)
+	return att
+}
+
+func TestReplaceAttachment(t *testing.T) {
+	svc, mockMongo, _, userID, ref := setupUploadTest(t)
+	app := setupAchievementAppWithPermissions(userID, "achievement:create")
+	old := existingAttachment(t, mockMongo)
+
+	var replaced modelMongo.Attachment
+	mockMongo.On("ReplaceAttachment", mock.Anything, "mongo1", mock.Anything).Run(func(args mock.Arguments) {
+		replaced = args.Get(2).(modelMongo.Attachment)
+	}).Return(nil)
+
+	app.Put("/achievements/:id/attachments/:attachmentId", svc.ReplaceAttachment)
+	resp, _ := app.Test(multipartRequest("PUT", "/achievements/"+ref.ID.String()+"/attachments/"+old.ID,
+		[][2]string{{"baru.pdf", samplePDF}}, nil,
+	))
+	t.Cleanup(func() { testStorage().Delete(context.Background(), replaced.StorageKey) })
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, old.ID, replaced.ID)
+	assert.Equal(t, "baru.pdf", replaced.FileName)
+	assert.Equal(t, "Sertifikat", replaced.Caption)
+	assert.Equal(t, modelMongo.ScanPending, replaced.Scan.Status)
+	assert.NotEqual(t, old.StorageKey, replaced.StorageKey)
+	_, err := testStorage().Open(context.Background(), old.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	t.Run("Success: File Removed", func(t *testing.T) {
+		svc, mockMongo, _, userID, ref := setupUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		att := existingAttachment(t, mockMongo)
+
+		mockMongo.On("RemoveAttachment", mock.Anything, "mongo1", att.ID).Return(nil)
+
+		app.Delete("/achievements/:id/attachments/:attachmentId", svc.DeleteAttachment)
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID, nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		_, err := testStorage().Open(context.Background(), att.StorageKey)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("Fail: Not Found", func(t *testing.T) {
+		svc, mockMongo, _, userID, ref := setupUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		existingAttachment(t, mockMongo)
+
+		app.Delete("/achievements/:id/attachments/:attachmentId", svc.DeleteAttachment)
+		resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/"+ref.ID.String()+"/attachments/"+uuid.New().String(), nil))
+
+		assert.Equal(t, 404, resp.StatusCode)
+		mockMongo.AssertNotCalled(t, "RemoveAttachment", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestReorderAttachments(t *testing.T) {
+	t.Run("Fail: Incomplete Order", func(t *testing.T) {
+		svc, mockMongo, _, userID, ref := setupUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		existingAttachment(t, mockMongo)
+
+		app.Put("/achievements/:id/attachments/order", svc.ReorderAttachments)
+		req := httptest.NewRequest("PUT", "/achievements/"+ref.ID.String()+"/attachments/order", bytes.NewBufferString(`{"order":[]}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockMongo.AssertNotCalled(t, "ReorderAttachments", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: Order Saved", func(t *testing.T) {
+		svc, mockMongo, _, userID, ref := setupUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		att := existingAttachment(t, mockMongo)
+
+		mockMongo.On("ReorderAttachments", mock.Anything, "mongo1", []string{att.ID}).Return(nil)
+
+		app.Put("/achievements/:id/attachments/order", svc.ReorderAttachments)
+		req := httptest.NewRequest("PUT", "/achievements/"+ref.ID.String()+"/attachments/order", bytes.NewBufferString(`{"order":["`+att.ID+`"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockMongo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,256 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	"StudenAchievementReportingSystem/app/preview"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/scanner"
+	service "StudenAchievementReportingSystem/app/service/mongodb"
+	"StudenAchievementReportingSystem/app/storage"
+	"StudenAchievementReportingSystem/config"
+)
+
+func testPreviews() preview.Generator {
+	return preview.New(preview.NewEmbeddedPDF())
+}
+
+func solidImage(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// scannedPDF is a one-page PDF whose page is a JPEG, as scanners produce
+func scannedPDF(t *testing.T, w, h int) string {
+	var page bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&page, solidImage(w, h, color.RGBA{R: 200, A: 0xff}), nil))
+	return fmt.Sprintf("%%PDF-1.4\n"+
+		"1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n"+
+		"2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj\n"+
+		"3 0 obj << /Type /Page /Parent 2 0 R /Resources << /XObject << /Im0 4 0 R >> >> >> endobj\n"+
+		"4 0 obj << /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream\nendobj\n"+
+		"%%%%EOF\n", w, h, page.Len(), page.String())
+}
+
+func TestThumbnail(t *testing.T) {
+	t.Run("Success: Wide Image Fits Preview Size", func(t *testing.T) {
+		thumb := preview.Thumbnail(solidImage(800, 400, color.Black), preview.Size)
+
+		assert.Equal(t, image.Rect(0, 0, 320, 160), thumb.Bounds())
+	})
+
+	t.Run("Success: Small Image Keeps Its Size", func(t *testing.T) {
+		thumb := preview.Thumbnail(solidImage(40, 90, color.Black), preview.Size)
+
+		assert.Equal(t, image.Rect(0, 0, 40, 90), thumb.Bounds())
+	})
+
+	t.Run("Success: Transparency Becomes White", func(t *testing.T) {
+		thumb := preview.Thumbnail(solidImage(10, 10, color.Transparent), preview.Size)
+
+		assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, thumb.At(5, 5))
+	})
+}
+
+func TestPreviewFromConfig(t *testing.T) {
+	// a pdftoppm on the PATH is not used unless it is asked for
+	g, err := preview.FromConfig(config.PreviewConfig{PdftoppmPath: "true"})
+	assert.NoError(t, err)
+	out, err := g.Generate(context.Background(), "application/pdf", strings.NewReader(scannedPDF(t, 16, 16)))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, out)
+
+	_, err = preview.FromConfig(config.PreviewConfig{PDFRenderer: "pdftoppm", PdftoppmPath: "/nonexistent/pdftoppm"})
+	assert.Error(t, err)
+	_, err = preview.FromConfig(config.PreviewConfig{PDFRenderer: "ghostscript"})
+	assert.Error(t, err)
+}
+
+func TestGeneratePreview(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success: Image", func(t *testing.T) {
+		var src bytes.Buffer
+		assert.NoError(t, png.Encode(&src, solidImage(300, 600, color.White)))
+
+		out, err := testPreviews().Generate(ctx, "image/png", &src)
+
+		assert.NoError(t, err)
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+		assert.NoError(t, err)
+		assert.Equal(t, [2]int{160, 320}, [2]int{cfg.Width, cfg.Height})
+	})
+
+	t.Run("Success: Scanned PDF", func(t *testing.T) {
+		out, err := testPreviews().Generate(ctx, "application/pdf", strings.NewReader(scannedPDF(t, 64, 48)))
+
+		assert.NoError(t, err)
+		img, err := jpeg.Decode(bytes.NewReader(out))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 64, 48), img.Bounds())
+	})
+
+	t.Run("Fail: Oversized Page Image Is Not Decoded", func(t *testing.T) {
+		pdf := []byte(scannedPDF(t, 8, 8))
+		// declare a 20000x20000 page in the JPEG frame header
+		sof := bytes.Index(pdf, []byte{0xff, 0xc0})
+		copy(pdf[sof+5:], []byte{0x4e, 0x20, 0x4e, 0x20})
+
+		_, err := testPreviews().Generate(ctx, "application/pdf", bytes.NewReader(pdf))
+
+		assert.ErrorIs(t, err, preview.ErrUnsupported)
+		assert.ErrorContains(t, err, "20000x20000")
+	})
+
+	t.Run("Fail: PDF Without Page Image", func(t *testing.T) {
+		_, err := testPreviews().Generate(ctx, "application/pdf", strings.NewReader(samplePDF))
+
+		assert.ErrorIs(t, err, preview.ErrUnsupported)
+	})
+
+	t.Run("Fail: Corrupt Image", func(t *testing.T) {
+		_, err := testPreviews().Generate(ctx, "image/png", strings.NewReader("\x89PNG\r\n\x1a\nbroken"))
+
+		assert.ErrorIs(t, err, preview.ErrUnsupported)
+	})
+
+	t.Run("Fail: Other Type", func(t *testing.T) {
+		_, err := testPreviews().Generate(ctx, "video/mp4", strings.NewReader("...."))
+
+		assert.ErrorIs(t, err, preview.ErrUnsupported)
+	})
+}
+
+// setupPreviewTest stores a file awaiting its preview
+func setupPreviewTest(t *testing.T, content string) (*service.AchievementService, *mocks.MockAchievementMongoRepo, storage.Storage, modelMongo.Achievement) {
+	svc, mockMongo, _, files, doc := setupScanTest(t, scanner.NewNoop())
+	att := doc.Attachments[0]
+	att.FileType = "application/pdf"
+	att.Scan = &modelMongo.AttachmentScan{Status: modelMongo.ScanClean}
+	att.Preview = &modelMongo.AttachmentPreview{Status: modelMongo.PreviewPending}
+	assert.NoError(t, files.Put(context.Background(), att.StorageKey, strings.NewReader(content), int64(len(content)), "application/pdf"))
+	t.Cleanup(func() { files.Delete(context.Background(), att.StorageKey+".preview.jpg") })
+
+	doc.Attachments = []modelMongo.Attachment{att}
+	mockMongo.ExpectedCalls = nil
+	mockMongo.On("FindPendingPreviews", mock.Anything, mock.Anything).Return([]modelMongo.Achievement{doc}, nil)
+	return svc, mockMongo, files, doc
+}
+
+func TestGeneratePendingPreviews(t *testing.T) {
+	t.Run("Success: Preview Stored And Ready", func(t *testing.T) {
+		svc, mockMongo, files, doc := setupPreviewTest(t, scannedPDF(t, 640, 480))
+		att := doc.Attachments[0]
+
+		mockMongo.On("SetAttachmentPreview", mock.Anything, doc.ID.Hex(), att.ID, att.StorageKey, mock.MatchedBy(func(p modelMongo.AttachmentPreview) bool {
+			return p.Status == modelMongo.PreviewReady && p.GeneratedAt != nil
+		})).Return(nil)
+
+		n, err := svc.GeneratePendingPreviews(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		mockMongo.AssertExpectations(t)
+		body, err := files.Open(context.Background(), att.StorageKey+".preview.jpg")
+		if assert.NoError(t, err) {
+			defer body.Close()
+			cfg, err := jpeg.DecodeConfig(body)
+			assert.NoError(t, err)
+			assert.Equal(t, [2]int{320, 240}, [2]int{cfg.Width, cfg.Height})
+		}
+	})
+
+	t.Run("Success: Unsupported File Marked Unavailable", func(t *testing.T) {
+		svc, mockMongo, files, doc := setupPreviewTest(t, samplePDF)
+		att := doc.Attachments[0]
+
+		mockMongo.On("SetAttachmentPreview", mock.Anything, doc.ID.Hex(), att.ID, att.StorageKey, mock.MatchedBy(func(p modelMongo.AttachmentPreview) bool {
+			return p.Status == modelMongo.PreviewUnavailable
+		})).Return(nil)
+
+		n, err := svc.GeneratePendingPreviews(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		mockMongo.AssertExpectations(t)
+		_, err = files.Open(context.Background(), att.StorageKey+".preview.jpg")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("Fail: Missing File Stays Pending", func(t *testing.T) {
+		svc, mockMongo, files, doc := setupPreviewTest(t, samplePDF)
+		assert.NoError(t, files.Delete(context.Background(), doc.Attachments[0].StorageKey))
+
+		n, err := svc.GeneratePendingPreviews(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		mockMongo.AssertNotCalled(t, "SetAttachmentPreview", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDownloadPreview(t *testing.T) {
+	request := func(t *testing.T, p *modelMongo.AttachmentPreview) *http.Response {
+		svc, mockMongo, mockPg, mockLecturer, ref, att := setupAttachmentDownloadTest(t)
+		userID := uuid.New()
+		app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+		att.Preview = p
+		var thumb bytes.Buffer
+		assert.NoError(t, jpeg.Encode(&thumb, solidImage(8, 8, color.White), nil))
+		files := testStorage()
+		assert.NoError(t, files.Put(context.Background(), att.StorageKey+".preview.jpg", bytes.NewReader(thumb.Bytes()), int64(thumb.Len()), "image/jpeg"))
+		t.Cleanup(func() { files.Delete(context.Background(), att.StorageKey+".preview.jpg") })
+
+		mockMongo.ExpectedCalls = nil
+		mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{att}}, nil)
+		mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+		mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+
+		app.Get("/achievements/:id/attachments/:attachmentId/preview", svc.DownloadPreview)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID+"/preview", nil))
+		return resp
+	}
+
+	t.Run("Success: Ready Preview", func(t *testing.T) {
+		resp := request(t, &modelMongo.AttachmentPreview{Status: modelMongo.PreviewReady})
+
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+		_, err := jpeg.DecodeConfig(resp.Body)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Fail: Preview Pending", func(t *testing.T) {
+		resp := request(t, &modelMongo.AttachmentPreview{Status: modelMongo.PreviewPending})
+
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+
+	t.Run("Fail: No Preview", func(t *testing.T) {
+		resp := request(t, nil)
+
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+}
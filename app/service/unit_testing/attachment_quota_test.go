@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	service "StudenAchievementReportingSystem/app/service/mongodb"
+)
+
+// setupQuotaTest is setupUploadTest with the student's current usage: the
+// achievement being uploaded to and another one
+func setupQuotaTest(t *testing.T, current, other modelMongo.StorageUsage) (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockAchievementPgRepo, uuid.UUID, modelPg.AchievementReference, []modelMongo.AchievementStorage) {
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockTypes := new(mocks.MockAttachmentTypeRepo)
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.AttachmentTypes = mockTypes
+	deps.Blobs = newBlobRepo()
+	svc := service.NewAchievementService(deps)
+
+	userID := uuid.New()
+	currentID, otherID := primitive.NewObjectID(), primitive.NewObjectID()
+	ref := modelPg.AchievementReference{ID: uuid.New(), StudentID: uuid.New(), MongoAchievementID: currentID.Hex(), Status: "draft", Version: 1}
+	usage := []modelMongo.AchievementStorage{
+		{MongoID: currentID, Title: "Lomba Debat", StorageUsage: current},
+		{MongoID: otherID, Title: "Seminar", StorageUsage: other},
+	}
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+	mockPg.On("GetReferenceByID", mock.Anything, ref.ID).Return(ref, nil).Maybe()
+	mockPg.On("BumpVersion", mock.Anything, ref.ID, 1).Return(nil).Maybe()
+	mockTypes.On("GetByCode", mock.Anything, "pdf").Return(&modelPg.AttachmentType{Code: "pdf", MaxSizeBytes: 1024}, nil).Maybe()
+	mockMongo.On("StorageByAchievement", mock.Anything, ref.StudentID.String()).Return(usage, nil)
+	return svc, mockMongo, mockPg, userID, ref, usage
+}
+
+func TestUploadStorageQuota(t *testing.T) {
+	t.Run("Success: Within Quotas", func(t *testing.T) {
+		t.Setenv("STORAGE_QUOTA_STUDENT_FILES", "3")
+		svc, mockMongo, _, userID, ref, _ := setupQuotaTest(t, modelMongo.StorageUsage{Bytes: 100, Files: 1}, modelMongo.StorageUsage{Bytes: 100, Files: 1})
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		var stored modelMongo.Attachment
+		mockMongo.On("AddAttachments", mock.Anything, ref.MongoAchievementID, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]modelMongo.Attachment)[0]
+		}).Return(nil)
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", "sertifikat.pdf", "application/pdf", samplePDF))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, int64(len(samplePDF)), stored.FileSize)
+	})
+
+	t.Run("Fail: Student File Quota", func(t *testing.T) {
+		t.Setenv("STORAGE_QUOTA_STUDENT_FILES", "2")
+		svc, mockMongo, _, userID, ref, _ := setupQuotaTest(t, modelMongo.StorageUsage{Bytes: 100, Files: 1}, modelMongo.StorageUsage{Bytes: 100, Files: 1})
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", "sertifikat.pdf", "application/pdf", samplePDF))
+
+		assert.Equal(t, 413, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "limited to 2 files")
+		mockMongo.AssertNotCalled(t, "AddAttachments", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Achievement Size Quota", func(t *testing.T) {
+		t.Setenv("STORAGE_QUOTA_ACHIEVEMENT_MB", "1")
+		svc, mockMongo, _, userID, ref, _ := setupQuotaTest(t, modelMongo.StorageUsage{Bytes: 1024*1024 - 10, Files: 1}, modelMongo.StorageUsage{})
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", "sertifikat.pdf", "application/pdf", samplePDF))
+
+		assert.Equal(t, 413, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "an achievement are limited to 1 MB")
+		mockMongo.AssertNotCalled(t, "AddAttachments", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: Quota Off", func(t *testing.T) {
+		t.Setenv("STORAGE_QUOTA_STUDENT_FILES", "0")
+		t.Setenv("STORAGE_QUOTA_ACHIEVEMENT_FILES", "0")
+		svc, mockMongo, _, userID, ref, _ := setupQuotaTest(t, modelMongo.StorageUsage{Files: 500}, modelMongo.StorageUsage{})
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		mockMongo.On("AddAttachments", mock.Anything, ref.MongoAchievementID, mock.Anything).Return(nil)
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", "sertifikat.pdf", "application/pdf", samplePDF))
+
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+}
+
+func TestResumableUploadStorageQuota(t *testing.T) {
+	t.Setenv("STORAGE_QUOTA_STUDENT_MB", "1")
+	achievements, mockMongo, _, userID, ref := setupUploadTest(t)
+	mockSessions := new(mocks.MockUploadSessionRepo)
+	svc := service.NewUploadService(achievements, mockSessions, nil, 0)
+	app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+	mockMongo.ExpectedCalls = nil
+	mockMongo.On("StorageByAchievement", mock.Anything, ref.StudentID.String()).Return([]modelMongo.AchievementStorage{
+		{MongoID: primitive.NewObjectID(), StorageUsage: modelMongo.StorageUsage{Bytes: 1024 * 1024, Files: 3}},
+	}, nil)
+
+	app.Post("/achievements/:id/uploads", svc.CreateUpload)
+	resp, _ := app.Test(tusRequest("POST", "/achievements/"+ref.ID.String()+"/uploads", nil, map[string]string{
+		"Upload-Length":   "500",
+		"Upload-Metadata": uploadMetadata("sertifikat.pdf"),
+	}))
+
+	assert.Equal(t, 413, resp.StatusCode)
+	mockSessions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestGetMyStorage(t *testing.T) {
+	t.Setenv("STORAGE_QUOTA_STUDENT_MB", "10")
+	t.Setenv("STORAGE_QUOTA_STUDENT_FILES", "50")
+	svc, _, mockPg, userID, ref, usage := setupQuotaTest(t, modelMongo.StorageUsage{Bytes: 300, Files: 2}, modelMongo.StorageUsage{Bytes: 200, Files: 1})
+	app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+	// the other achievement is in the trash
+	mockPg.On("GetReferencesByMongoIDs", mock.Anything, []string{usage[0].MongoID.Hex(), usage[1].MongoID.Hex()}).
+		Return([]modelPg.AchievementReference{ref}, nil)
+
+	app.Get("/me/storage", svc.GetMyStorage)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/me/storage", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var result modelMongo.StudentStorage
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, modelMongo.StorageUsage{Bytes: 500, Files: 3}, result.Used)
+	assert.Equal(t, modelMongo.StorageUsage{Bytes: 10 * 1024 * 1024, Files: 50}, result.Quota)
+	assert.Equal(t, modelMongo.StorageUsage{Bytes: 100 * 1024 * 1024, Files: 20}, result.AchievementQuota)
+	if assert.Len(t, result.Achievements, 2) {
+		assert.Equal(t, ref.ID.String(), result.Achievements[0].AchievementID)
+		assert.False(t, result.Achievements[0].InTrash)
+		assert.Equal(t, "Seminar", result.Achievements[1].Title)
+		assert.True(t, result.Achievements[1].InTrash)
+	}
+}
+
+func TestBackfillAttachmentSizes(t *testing.T) {
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	files := testStorage()
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.Files = files
+	svc := service.NewAchievementService(deps)
+
+	stored := modelMongo.Attachment{ID: uuid.New().String(), StorageKey: uuid.New().String() + ".pdf"}
+	missing := modelMongo.Attachment{ID: uuid.New().String(), StorageKey: uuid.New().String() + ".pdf"}
+	assert.NoError(t, files.Put(context.Background(), stored.StorageKey, strings.NewReader(samplePDF), int64(len(samplePDF)), "application/pdf"))
+	t.Cleanup(func() { files.Delete(context.Background(), stored.StorageKey) })
+
+	doc := modelMongo.Achievement{ID: primitive.NewObjectID(), Attachments: []modelMongo.Attachment{stored, missing}}
+	mockMongo.On("FindUnsizedAttachments", mock.Anything).Return([]modelMongo.Achievement{doc}, nil)
+	mockMongo.On("SetAttachmentSize", mock.Anything, doc.ID.Hex(), stored.ID, stored.StorageKey, int64(len(samplePDF))).Return(nil)
+
+	n, err := svc.BackfillAttachmentSizes(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	mockMongo.AssertExpectations(t)
+	mockMongo.AssertNotCalled(t, "SetAttachmentSize", mock.Anything, mock.Anything, missing.ID, mock.Anything, mock.Anything)
+}
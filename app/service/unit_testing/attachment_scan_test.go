@@ -0,0 +1,194 @@
+package service_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/scanner"
+	"StudenAchievementReportingSystem/app/service/mongodb"
+	"StudenAchievementReportingSystem/app/storage"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd answers INSTREAM like clamd, flagging streams that contain the
+// EICAR test string
+func fakeClamd(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if cmd, _ := r.ReadString(0); cmd != "zINSTREAM\x00" {
+					io.WriteString(conn, "UNKNOWN COMMAND\x00")
+					return
+				}
+				var body bytes.Buffer
+				size := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(r, size); err != nil {
+						return
+					}
+					n := binary.BigEndian.Uint32(size)
+					if n == 0 {
+						break
+					}
+					io.CopyN(&body, r, int64(n))
+				}
+				if strings.Contains(body.String(), "EICAR-STANDARD-ANTIVIRUS-TEST-FILE") {
+					io.WriteString(conn, "stream: Eicar-Test-Signature FOUND\x00")
+					return
+				}
+				io.WriteString(conn, "stream: OK\x00")
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestClamAVScanner(t *testing.T) {
+	clamd := scanner.NewClamAV(fakeClamd(t), 5*time.Second)
+	ctx := context.Background()
+
+	verdict, err := clamd.Scan(ctx, strings.NewReader(samplePDF))
+	assert.NoError(t, err)
+	assert.True(t, verdict.Clean)
+
+	// larger than one chunk, with the signature at the end
+	verdict, err = clamd.Scan(ctx, strings.NewReader(strings.Repeat("a", 100*1024)+eicar))
+	assert.NoError(t, err)
+	assert.False(t, verdict.Clean)
+	assert.Equal(t, "Eicar-Test-Signature", verdict.Signature)
+
+	_, err = scanner.NewClamAV("127.0.0.1:1", time.Second).Scan(ctx, strings.NewReader(samplePDF))
+	assert.Error(t, err)
+}
+
+func setupScanTest(t *testing.T, sc scanner.Scanner) (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockNotificationRepo, storage.Storage, modelMongo.Achievement) {
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockStudent := new(mocks.MockStudentRepo)
+	mockNotify := new(mocks.MockNotificationRepo)
+	files := testStorage()
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.Students = mockStudent
+	deps.Files = files
+	deps.Scanner = sc
+	deps.Notifications = mockNotify
+	svc := service.NewAchievementService(deps)
+
+	studentID := uuid.New()
+	att := modelMongo.Attachment{ID: uuid.New().String(), FileName: "sertifikat.pdf", Scan: &modelMongo.AttachmentScan{Status: modelMongo.ScanPending}}
+	att.StorageKey = att.ID + ".pdf"
+	assert.NoError(t, files.Put(context.Background(), att.StorageKey, strings.NewReader(samplePDF), int64(len(samplePDF)), "application/pdf"))
+	t.Cleanup(func() { files.Delete(context.Background(), att.StorageKey) })
+
+	doc := modelMongo.Achievement{ID: primitive.NewObjectID(), StudentID: studentID.String(), Title: "Lomba Debat", Attachments: []modelMongo.Attachment{att}}
+	mockMongo.On("FindPendingScans", mock.Anything, mock.Anything).Return([]modelMongo.Achievement{doc}, nil)
+	mockStudent.On("GetStudentByID", mock.Anything, studentID).Return(&modelPg.Student{ID: studentID, UserID: uuid.New()}, nil).Maybe()
+	mockPg.On("GetReferencesByMongoIDs", mock.Anything, []string{doc.ID.Hex()}).Return([]modelPg.AchievementReference{{ID: uuid.New()}}, nil).Maybe()
+	return svc, mockMongo, mockNotify, files, doc
+}
+
+func TestScanPendingAttachments(t *testing.T) {
+	t.Run("Success: Clean File Released", func(t *testing.T) {
+		svc, mockMongo, mockNotify, files, doc := setupScanTest(t, scanner.NewNoop())
+		att := doc.Attachments[0]
+
+		mockMongo.On("SetAttachmentScan", mock.Anything, doc.ID.Hex(), att.ID, mock.MatchedBy(func(s modelMongo.AttachmentScan) bool {
+			return s.Status == modelMongo.ScanClean && s.ScannedAt != nil
+		})).Return(nil)
+
+		n, err := svc.ScanPendingAttachments(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		mockMongo.AssertExpectations(t)
+		mockNotify.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		_, err = files.Open(context.Background(), att.StorageKey)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success: Infected File Removed And Student Notified", func(t *testing.T) {
+		infected := scanner.Func(func(ctx context.Context, r io.Reader) (scanner.Verdict, error) {
+			return scanner.Verdict{Signature: "Eicar-Test-Signature"}, nil
+		})
+		svc, mockMongo, mockNotify, files, doc := setupScanTest(t, infected)
+		att := doc.Attachments[0]
+
+		mockMongo.On("SetAttachmentScan", mock.Anything, doc.ID.Hex(), att.ID, mock.MatchedBy(func(s modelMongo.AttachmentScan) bool {
+			return s.Status == modelMongo.ScanInfected && s.Signature == "Eicar-Test-Signature"
+		})).Return(nil)
+		mockNotify.On("Create", mock.Anything, mock.MatchedBy(func(n modelPg.Notification) bool {
+			return n.Kind == modelPg.NotificationAttachmentInfected && n.AchievementID != nil && strings.Contains(n.Message, "sertifikat.pdf")
+		})).Return(nil)
+
+		n, err := svc.ScanPendingAttachments(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		mockMongo.AssertExpectations(t)
+		mockNotify.AssertExpectations(t)
+		_, err = files.Open(context.Background(), att.StorageKey)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("Fail: Scanner Down Keeps File Pending", func(t *testing.T) {
+		down := scanner.Func(func(ctx context.Context, r io.Reader) (scanner.Verdict, error) {
+			return scanner.Verdict{}, errors.New("connection refused")
+		})
+		svc, mockMongo, _, _, _ := setupScanTest(t, down)
+
+		n, err := svc.ScanPendingAttachments(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		mockMongo.AssertNotCalled(t, "SetAttachmentScan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDownloadQuarantinedAttachment(t *testing.T) {
+	svc, mockMongo, mockPg, mockLecturer, ref, att := setupAttachmentDownloadTest(t)
+	userID := uuid.New()
+	app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+	att.Scan = &modelMongo.AttachmentScan{Status: modelMongo.ScanPending}
+	mockMongo.ExpectedCalls = nil
+	mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{att}}, nil)
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+	mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+
+	app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID, nil))
+
+	assert.Equal(t, 409, resp.StatusCode)
+}
@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/postgresql"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func TestGetAttachmentTypes(t *testing.T) {
+	mockRepo := new(mocks.MockAttachmentTypeRepo)
+	svc := service.NewAttachmentTypeService(mockRepo)
+	app := setupPermissionApp("achievement:read")
+
+	mockRepo.On("GetAll", mock.Anything).Return([]models.AttachmentType{{Code: "pdf", MaxSizeBytes: 1 << 20}}, nil)
+
+	app.Get("/attachment-types", svc.GetAttachmentTypes)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/attachment-types", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var res []service.AttachmentTypeResponse
+	json.NewDecoder(resp.Body).Decode(&res)
+	assert.Len(t, res, len(utils.FileTypes))
+	for _, ft := range res {
+		assert.Equal(t, ft.Code == "pdf", ft.Allowed, ft.Code)
+	}
+}
+
+func TestAllowAttachmentType(t *testing.T) {
+	t.Run("Success: Limit Saved", func(t *testing.T) {
+		mockRepo := new(mocks.MockAttachmentTypeRepo)
+		svc := service.NewAttachmentTypeService(mockRepo)
+		app := setupPermissionApp("manage:attachment_types")
+
+		mockRepo.On("Save", mock.Anything, models.AttachmentType{Code: "gif", MaxSizeBytes: 2048}).Return(nil)
+		mockRepo.On("GetByCode", mock.Anything, "gif").Return(&models.AttachmentType{Code: "gif", MaxSizeBytes: 2048}, nil)
+
+		app.Put("/attachment-types/:code", svc.AllowAttachmentType)
+		req := httptest.NewRequest("PUT", "/attachment-types/gif", bytes.NewBufferString(`{"maxSizeBytes":2048}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Limit Above Upload Size", func(t *testing.T) {
+		mockRepo := new(mocks.MockAttachmentTypeRepo)
+		svc := service.NewAttachmentTypeService(mockRepo)
+		app := setupPermissionApp("manage:attachment_types")
+
+		app.Put("/attachment-types/:code", svc.AllowAttachmentType)
+		req := httptest.NewRequest("PUT", "/attachment-types/pdf", bytes.NewBufferString(`{"maxSizeBytes":1073741824}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Unknown Type", func(t *testing.T) {
+		svc := service.NewAttachmentTypeService(new(mocks.MockAttachmentTypeRepo))
+		app := setupPermissionApp("manage:attachment_types")
+
+		app.Put("/attachment-types/:code", svc.AllowAttachmentType)
+		req := httptest.NewRequest("PUT", "/attachment-types/exe", bytes.NewBufferString(`{"maxSizeBytes":2048}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+
+	t.Run("Fail: Not Super Admin", func(t *testing.T) {
+		svc := service.NewAttachmentTypeService(new(mocks.MockAttachmentTypeRepo))
+		app := setupPermissionApp("achievement:read")
+
+		app.Put("/attachment-types/:code", svc.AllowAttachmentType)
+		resp, _ := app.Test(httptest.NewRequest("PUT", "/attachment-types/pdf", nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+}
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/mongodb"
+	appfiber "StudenAchievementReportingSystem/fiber"
+	"StudenAchievementReportingSystem/utils"
+)
+
+const samplePDF = "%PDF-1.4\n1 0 obj\n<<>>\nendobj\n"
+
+func uploadRequest(path, filename, contentType, content string) *http.Request {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	header := make(map[string][]string)
+	header["Content-Disposition"] = []string{`form-data; name="file"; filename="` + filename + `"`}
+	header["Content-Type"] = []string{contentType}
+	part, _ := w.CreatePart(header)
+	part.Write([]byte(content))
+	w.Close()
+
+	req := httptest.NewRequest("POST", path, &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+// newBlobRepo registers every upload as a new blob and every release as not
+// the last one
+func newBlobRepo() *mocks.MockAttachmentBlobRepo {
+	blobs := new(mocks.MockAttachmentBlobRepo)
+	blobs.On("Acquire", mock.Anything, mock.Anything).Return(func(b modelPg.AttachmentBlob) *modelPg.AttachmentBlob { return &b }, true, nil).Maybe()
+	blobs.On("Release", mock.Anything, mock.Anything).Return(nil, "").Maybe()
+	return blobs
+}
+
+func setupUploadTest(t *testing.T) (*service.AchievementService, *mocks.MockAchievementMongoRepo, *mocks.MockAttachmentTypeRepo, uuid.UUID, modelPg.AchievementReference) {
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockPg := new(mocks.MockAchievementPgRepo)
+	mockTypes := new(mocks.MockAttachmentTypeRepo)
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.References = mockPg
+	deps.AttachmentTypes = mockTypes
+	deps.Blobs = newBlobRepo()
+	svc := service.NewAchievementService(deps)
+
+	userID := uuid.New()
+	ref := modelPg.AchievementReference{ID: uuid.New(), StudentID: uuid.New(), MongoAchievementID: "mongo1", Status: "draft", Version: 1}
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+	mockPg.On("GetReferenceByID", mock.Anything, ref.ID).Return(ref, nil)
+	mockPg.On("BumpVersion", mock.Anything, ref.ID, 1).Return(nil).Maybe()
+	mockMongo.On("StorageByAchievement", mock.Anything, ref.StudentID.String()).Return([]modelMongo.AchievementStorage{}, nil).Maybe()
+	mockTypes.On("GetByCode", mock.Anything, "pdf").Return(&modelPg.AttachmentType{Code: "pdf", MaxSizeBytes: 1024}, nil).Maybe()
+	mockTypes.On("GetByCode", mock.Anything, "gif").Return(nil, sql.ErrNoRows).Maybe()
+	return svc, mockMongo, mockTypes, userID, ref
+}
+
+func TestUploadAttachmentsContentChecks(t *testing.T) {
+	t.Run("Success: Detected Type Stored", func(t *testing.T) {
+		svc, mockMongo, _, userID, ref := setupUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+		var stored modelMongo.Attachment
+		mockMongo.On("AddAttachments", mock.Anything, "mongo1", mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]modelMongo.Attachment)[0]
+		}).Return(nil)
+
+		app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+		resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", "sertifikat.PDF", "text/plain", samplePDF))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "application/pdf", stored.FileType)
+		t.Cleanup(func() { testStorage().Delete(context.Background(), stored.StorageKey) })
+	})
+
+	cases := []struct {
+		name, filename, content string
+		status                  int
+	}{
+		{"Fail: Unknown Content", "notes.pdf", "<html><script>alert(1)</script></html>", 415},
+		{"Fail: Extension Mismatch", "photo.png", samplePDF, 400},
+		{"Fail: Type Not Allowed", "badge.gif", "GIF89a\x01\x00\x01\x00", 415},
+		{"Fail: Over Type Limit", "big.pdf", samplePDF + string(make([]byte, 2048)), 413},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, mockMongo, _, userID, ref := setupUploadTest(t)
+			app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+			app.Post("/achievements/:id/attachments", svc.UploadAttachments)
+			resp, _ := app.Test(uploadRequest("/achievements/"+ref.ID.String()+"/attachments", tc.filename, "application/pdf", tc.content))
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			mockMongo.AssertNotCalled(t, "AddAttachments", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUploadRequestTooLarge(t *testing.T) {
+	app := appfiber.SetupFiber()
+	// fiber turns bodies over BodyLimit into this error before routing
+	app.Post("/achievements/:id/attachments", func(c *fiber.Ctx) error { return fiber.ErrRequestEntityTooLarge })
+
+	resp, err := app.Test(uploadRequest("/achievements/"+uuid.New().String()+"/attachments", "a.pdf", "application/pdf", samplePDF))
+	assert.NoError(t, err)
+	assert.Equal(t, 413, resp.StatusCode)
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Contains(t, body["message"], strconv.Itoa(utils.MaxUploadSize))
+	assert.Contains(t, body["message"], "resumable uploads")
+}
@@ -0,0 +1,47 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func TestNormalizeText(t *testing.T) {
+	assert.Equal(t, "juara 1 gemastik 2024", utils.NormalizeText("  Juara 1 - GEMASTIK  2024!"))
+	assert.Equal(t, "", utils.NormalizeText(" -- "))
+}
+
+func TestDuplicateReasons(t *testing.T) {
+	eventDate := time.Date(2024, 10, 5, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Same Title And Event Date", func(t *testing.T) {
+		a := modelMongo.Achievement{StudentID: "s1", Title: "Juara 1 Gemastik", Details: modelMongo.AchievementDetails{EventDate: eventDate}}
+		b := modelMongo.Achievement{StudentID: "s1", Title: "juara 1 - GEMASTIK", Details: modelMongo.AchievementDetails{EventDate: eventDate.Add(3 * time.Hour)}}
+
+		assert.Equal(t, []string{utils.ReasonTitleAndDate}, utils.DuplicateReasons(a, b))
+	})
+
+	t.Run("Same Attachment Checksum", func(t *testing.T) {
+		a := modelMongo.Achievement{Title: "A", Attachments: []modelMongo.Attachment{{Checksum: "abc"}}}
+		b := modelMongo.Achievement{Title: "B", Attachments: []modelMongo.Attachment{{Checksum: "xyz"}, {Checksum: "abc"}}}
+
+		assert.Equal(t, []string{utils.ReasonAttachment}, utils.DuplicateReasons(a, b))
+	})
+
+	t.Run("Two Students Claim Same Rank", func(t *testing.T) {
+		a := modelMongo.Achievement{StudentID: "s1", Title: "Lomba", Details: modelMongo.AchievementDetails{CompetitionName: "Gemastik", Rank: 1, EventDate: eventDate}}
+		b := modelMongo.Achievement{StudentID: "s2", Title: "Kompetisi", Details: modelMongo.AchievementDetails{CompetitionName: "GEMASTIK", Rank: 1, EventDate: eventDate}}
+
+		assert.Contains(t, utils.DuplicateReasons(a, b), utils.ReasonCompetitionRank)
+	})
+
+	t.Run("No Match", func(t *testing.T) {
+		a := modelMongo.Achievement{StudentID: "s1", Title: "Lomba A", Details: modelMongo.AchievementDetails{CompetitionName: "Gemastik", Rank: 1}}
+		b := modelMongo.Achievement{StudentID: "s2", Title: "Lomba B", Details: modelMongo.AchievementDetails{CompetitionName: "Gemastik", Rank: 2}}
+
+		assert.Empty(t, utils.DuplicateReasons(a, b))
+	})
+}
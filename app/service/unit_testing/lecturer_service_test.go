@@ -7,6 +7,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	models "StudenAchievementReportingSystem/app/models/postgresql"
 	"StudenAchievementReportingSystem/app/repository/mocks"
 	"StudenAchievementReportingSystem/app/service/postgresql"
@@ -36,7 +37,7 @@ func TestGetAllLecturers(t *testing.T) {
 			{ID: uuid.New(), LecturerID: "D002", Department: "IT"},
 		}
 
-		mockRepo.On("GetAllLecturers").Return(mockData, nil)
+		mockRepo.On("GetAllLecturers", mock.Anything).Return(mockData, nil)
 
 		app.Get("/lecturers", svc.GetAllLecturers)
 
@@ -51,7 +52,7 @@ func TestGetAllLecturers(t *testing.T) {
 		svc, mockRepo := setupLecturerServiceTest()
 		app := setupSimpleApp()
 
-		mockRepo.On("GetAllLecturers").Return(nil, errors.New("db error"))
+		mockRepo.On("GetAllLecturers", mock.Anything).Return(nil, errors.New("db error"))
 
 		app.Get("/lecturers", svc.GetAllLecturers)
 
@@ -70,7 +71,7 @@ func TestGetLecturerByID(t *testing.T) {
 		targetID := uuid.New()
 		mockLecturer := &models.Lecturer{ID: targetID, LecturerID: "D123"}
 
-		mockRepo.On("GetLecturerByID", targetID).Return(mockLecturer, nil)
+		mockRepo.On("GetLecturerByID", mock.Anything, targetID).Return(mockLecturer, nil)
 
 		app.Get("/lecturers/:id", svc.GetLecturerByID)
 
@@ -87,7 +88,7 @@ func TestGetLecturerByID(t *testing.T) {
 
 		targetID := uuid.New()
 
-		mockRepo.On("GetLecturerByID", targetID).Return(nil, errors.New("lecturer not found"))
+		mockRepo.On("GetLecturerByID", mock.Anything, targetID).Return(nil, errors.New("lecturer not found"))
 
 		app.Get("/lecturers/:id", svc.GetLecturerByID)
 
@@ -109,7 +110,7 @@ func TestGetAdvisees(t *testing.T) {
 			{ID: uuid.New(), StudentID: "S2"},
 		}
 
-		mockRepo.On("GetAdvisees", lecturerID).Return(mockStudents, nil)
+		mockRepo.On("GetAdvisees", mock.Anything, lecturerID).Return(mockStudents, nil)
 
 		app.Get("/lecturers/:id/advisees", svc.GetAdvisees)
 
@@ -126,7 +127,7 @@ func TestGetAdvisees(t *testing.T) {
 
 		lecturerID := uuid.New()
 
-		mockRepo.On("GetAdvisees", lecturerID).Return(nil, errors.New("db fail"))
+		mockRepo.On("GetAdvisees", mock.Anything, lecturerID).Return(nil, errors.New("db fail"))
 
 		app.Get("/lecturers/:id/advisees", svc.GetAdvisees)
 
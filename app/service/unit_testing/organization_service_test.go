@@ -0,0 +1,174 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	repoPg "StudenAchievementReportingSystem/app/repository/postgresql"
+	"StudenAchievementReportingSystem/app/service/postgresql"
+)
+
+func setupOrganizationTest() (*service.OrganizationService, *mocks.MockOrganizationRepo) {
+	mockOrg := new(mocks.MockOrganizationRepo)
+	return service.NewOrganizationService(mockOrg), mockOrg
+}
+
+func TestCreateProgramStudy(t *testing.T) {
+	t.Run("Success: Normalizes Code And Name", func(t *testing.T) {
+		svc, mockOrg := setupOrganizationTest()
+		app := setupPermissionApp("manage:organization")
+
+		departmentID := uuid.New()
+		mockOrg.On("CreateProgramStudy", mock.Anything, models.ProgramStudy{
+			DepartmentID: departmentID, Code: "IF", Name: "Teknik Informatika",
+		}).Return(uuid.New(), nil)
+
+		app.Post("/program-studies", svc.CreateProgramStudy)
+
+		body, _ := json.Marshal(map[string]string{"code": " if ", "name": "Teknik   Informatika", "departmentId": departmentID.String()})
+		req := httptest.NewRequest("POST", "/program-studies", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 201, resp.StatusCode)
+		mockOrg.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Invalid Fields", func(t *testing.T) {
+		svc, mockOrg := setupOrganizationTest()
+		app := setupPermissionApp("manage:organization")
+		app.Post("/program-studies", svc.CreateProgramStudy)
+
+		body, _ := json.Marshal(map[string]string{"code": "I F", "name": "", "departmentId": "nope"})
+		req := httptest.NewRequest("POST", "/program-studies", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+
+		var res struct {
+			Details []map[string]string `json:"details"`
+		}
+		json.NewDecoder(resp.Body).Decode(&res)
+		assert.Len(t, res.Details, 3)
+		mockOrg.AssertNotCalled(t, "CreateProgramStudy", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Duplicate Code", func(t *testing.T) {
+		svc, mockOrg := setupOrganizationTest()
+		app := setupPermissionApp("manage:organization")
+
+		mockOrg.On("CreateProgramStudy", mock.Anything, mock.Anything).Return(uuid.Nil, repoPg.ErrDuplicateCode)
+		app.Post("/program-studies", svc.CreateProgramStudy)
+
+		body, _ := json.Marshal(map[string]string{"code": "IF", "name": "Informatika", "departmentId": uuid.New().String()})
+		req := httptest.NewRequest("POST", "/program-studies", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 409, resp.StatusCode)
+	})
+}
+
+func TestMergeProgramStudies(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		svc, mockOrg := setupOrganizationTest()
+		app := setupPermissionApp("manage:organization")
+
+		target, source := uuid.New(), uuid.New()
+		mockOrg.On("MergeProgramStudies", mock.Anything, target, []uuid.UUID{source}).Return(nil)
+		app.Post("/program-studies/:id/merge", svc.MergeProgramStudies)
+
+		body, _ := json.Marshal(map[string][]string{"sourceIds": {source.String()}})
+		req := httptest.NewRequest("POST", "/program-studies/"+target.String()+"/merge", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockOrg.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Merge Into Itself", func(t *testing.T) {
+		svc, mockOrg := setupOrganizationTest()
+		app := setupPermissionApp("manage:organization")
+		app.Post("/program-studies/:id/merge", svc.MergeProgramStudies)
+
+		target := uuid.New()
+		body, _ := json.Marshal(map[string][]string{"sourceIds": {target.String()}})
+		req := httptest.NewRequest("POST", "/program-studies/"+target.String()+"/merge", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockOrg.AssertNotCalled(t, "MergeProgramStudies", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteDepartmentInUse(t *testing.T) {
+	svc, mockOrg := setupOrganizationTest()
+	app := setupPermissionApp("manage:organization")
+
+	id := uuid.New()
+	mockOrg.On("DeleteDepartment", mock.Anything, id).Return(repoPg.ErrUnitInUse)
+	app.Delete("/departments/:id", svc.DeleteDepartment)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/departments/"+id.String(), nil))
+	assert.Equal(t, 409, resp.StatusCode)
+}
+
+func TestSetCoordinatorScope(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		svc, mockOrg := setupOrganizationTest()
+		app := setupPermissionApp("manage:users")
+
+		userID, departmentID := uuid.New(), uuid.New()
+		scope := models.CoordinatorScope{UserID: userID, DepartmentIDs: []uuid.UUID{departmentID}, ProgramStudyIDs: []uuid.UUID{}}
+		mockOrg.On("SetCoordinatorScope", mock.Anything, scope).Return(nil)
+		mockOrg.On("GetCoordinatorScope", mock.Anything, userID).Return(&scope, nil)
+		app.Put("/coordinators/:userId/scope", svc.SetCoordinatorScope)
+
+		body, _ := json.Marshal(map[string][]string{"departmentIds": {departmentID.String()}})
+		req := httptest.NewRequest("PUT", "/coordinators/"+userID.String()+"/scope", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 200, resp.StatusCode)
+		mockOrg.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Invalid IDs", func(t *testing.T) {
+		svc, mockOrg := setupOrganizationTest()
+		app := setupPermissionApp("manage:users")
+		app.Put("/coordinators/:userId/scope", svc.SetCoordinatorScope)
+
+		body, _ := json.Marshal(map[string][]string{"programStudyIds": {"nope"}})
+		req := httptest.NewRequest("PUT", "/coordinators/"+uuid.New().String()+"/scope", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockOrg.AssertNotCalled(t, "SetCoordinatorScope", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Unknown Department", func(t *testing.T) {
+		svc, mockOrg := setupOrganizationTest()
+		app := setupPermissionApp("manage:users")
+		mockOrg.On("SetCoordinatorScope", mock.Anything, mock.Anything).Return(repoPg.ErrParentNotFound)
+		app.Put("/coordinators/:userId/scope", svc.SetCoordinatorScope)
+
+		body, _ := json.Marshal(map[string][]string{"departmentIds": {uuid.New().String()}})
+		req := httptest.NewRequest("PUT", "/coordinators/"+uuid.New().String()+"/scope", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
@@ -21,8 +21,9 @@ func setupReportServiceTest() (*service.ReportService, *mocks.MockAchievementRep
 	// Gunakan MockAchievementRepo (MongoDB) dan MockStudentRepo (Postgres)
 	mockMongo := new(mocks.MockAchievementRepo)
 	mockPg := new(mocks.MockStudentRepo)
+	mockAchPg := new(mocks.MockAchievementPgRepo)
 
-	svc := service.NewReportService(mockMongo, mockPg)
+	svc := service.NewReportService(mockMongo, mockPg, mockAchPg, new(mocks.MockAcademicPeriodRepo), new(mocks.MockOrganizationRepo))
 
 	return svc, mockMongo, mockPg
 }
@@ -58,7 +59,7 @@ func TestGetStatistics(t *testing.T) {
 		}
 
 		// Expectation 1: Panggil Mongo GetGlobalStats
-		mockMongo.On("GetGlobalStats", mock.Anything).Return(mockStats, nil)
+		mockMongo.On("GetGlobalStats", mock.Anything, mock.Anything).Return(mockStats, nil)
 
 		// Expectation 2: Panggil Postgres GetStudentsByIDs dengan ID dari hasil mongo
 		mockPg.On("GetStudentsByIDs", mock.Anything, []string{studentUUID.String()}).Return(mockStudentDetails, nil)
@@ -86,7 +87,7 @@ func TestGetStatistics(t *testing.T) {
 		svc, mockMongo, _ := setupReportServiceTest()
 		app := setupReportApp()
 
-		mockMongo.On("GetGlobalStats", mock.Anything).Return(nil, errors.New("mongo connection failed"))
+		mockMongo.On("GetGlobalStats", mock.Anything, mock.Anything).Return(nil, errors.New("mongo connection failed"))
 
 		app.Get("/stats", svc.GetStatistics)
 		req := httptest.NewRequest("GET", "/stats", nil)
@@ -118,7 +119,7 @@ func TestGetStudentReport(t *testing.T) {
 
 		// Expectation
 		// Perhatikan: Service memanggil Mongo dulu menggunakan String ID
-		mockMongo.On("GetStudentStats", mock.Anything, targetID.String()).Return(mockStats, nil)
+		mockMongo.On("GetStudentStats", mock.Anything, targetID.String(), mock.Anything).Return(mockStats, nil)
 		// Lalu memanggil Postgres menggunakan UUID
 		mockPg.On("GetStudentByID", mock.Anything, targetID).Return(mockProfile, nil)
 
@@ -138,7 +139,7 @@ func TestGetStudentReport(t *testing.T) {
 		app := setupReportApp()
 		targetID := uuid.New().String()
 
-		mockMongo.On("GetStudentStats", mock.Anything, targetID).Return(nil, errors.New("db error"))
+		mockMongo.On("GetStudentStats", mock.Anything, targetID, mock.Anything).Return(nil, errors.New("db error"))
 
 		app.Get("/report/:id", svc.GetStudentReport)
 		req := httptest.NewRequest("GET", "/report/"+targetID, nil)
@@ -154,7 +155,7 @@ func TestGetStudentReport(t *testing.T) {
 
 		// Mock Mongo tetap dipanggil karena urutan kode di service: Mongo dulu -> baru Parse UUID
 		mockStats := &modelMongo.StudentStatistics{}
-		mockMongo.On("GetStudentStats", mock.Anything, invalidID).Return(mockStats, nil)
+		mockMongo.On("GetStudentStats", mock.Anything, invalidID, mock.Anything).Return(mockStats, nil)
 
 		app.Get("/report/:id", svc.GetStudentReport)
 		req := httptest.NewRequest("GET", "/report/"+invalidID, nil)
@@ -170,7 +171,7 @@ func TestGetStudentReport(t *testing.T) {
 
 		mockStats := &modelMongo.StudentStatistics{TotalPoints: 10}
 
-		mockMongo.On("GetStudentStats", mock.Anything, targetID.String()).Return(mockStats, nil)
+		mockMongo.On("GetStudentStats", mock.Anything, targetID.String(), mock.Anything).Return(mockStats, nil)
 		// Mock Postgres return error/not found
 		mockPg.On("GetStudentByID", mock.Anything, targetID).Return(nil, errors.New("student not found"))
 
@@ -185,4 +186,160 @@ func TestGetStudentReport(t *testing.T) {
 		json.NewDecoder(resp.Body).Decode(&body)
 		assert.Empty(t, body.StudentName)
 	})
-}
\ No newline at end of file
+}
+func TestGetOrganizationReport(t *testing.T) {
+	setup := func() (*service.ReportService, *mocks.MockAchievementRepo, *mocks.MockOrganizationRepo) {
+		mockMongo := new(mocks.MockAchievementRepo)
+		mockOrg := new(mocks.MockOrganizationRepo)
+		svc := service.NewReportService(mockMongo, new(mocks.MockStudentRepo), new(mocks.MockAchievementPgRepo), new(mocks.MockAcademicPeriodRepo), mockOrg)
+		return svc, mockMongo, mockOrg
+	}
+
+	t.Run("Success: Grouped By Department", func(t *testing.T) {
+		svc, mockMongo, mockOrg := setup()
+		app := setupPermissionApp("report:students")
+
+		informatika := &models.OrgUnit{ID: uuid.New(), Code: "IF", Name: "Informatika"}
+		sistemInformasi := &models.OrgUnit{ID: uuid.New(), Code: "SI", Name: "Sistem Informasi"}
+		teknik := &models.OrgUnit{ID: uuid.New(), Code: "TI", Name: "Teknik Informatika"}
+		a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+		mockOrg.On("GetStudentUnits", mock.Anything).Return([]models.StudentUnits{
+			{StudentID: a, ProgramStudy: informatika, Department: teknik},
+			{StudentID: b, ProgramStudy: sistemInformasi, Department: teknik},
+			{StudentID: c},
+		}, nil)
+		mockMongo.On("GetStudentTotals", mock.Anything, modelMongo.StatsFilter{}).Return([]modelMongo.StudentTotal{
+			{StudentID: a.String(), Achievements: 2, TotalPoints: 30},
+			{StudentID: b.String(), Achievements: 1, TotalPoints: 10},
+			{StudentID: d.String(), Achievements: 1, TotalPoints: 5},
+		}, nil)
+
+		app.Get("/reports/organization", svc.GetOrganizationReport)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/reports/organization?groupBy=department", nil))
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var rows []modelMongo.UnitStatistics
+		json.NewDecoder(resp.Body).Decode(&rows)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, modelMongo.UnitStatistics{ID: teknik.ID.String(), Code: "TI", Name: "Teknik Informatika", Students: 2, Achievements: 3, TotalPoints: 40}, rows[0])
+		assert.Equal(t, modelMongo.UnitStatistics{Name: "Unassigned", Students: 1, Achievements: 1, TotalPoints: 5}, rows[1])
+	})
+
+	t.Run("Fail: Unknown Grouping", func(t *testing.T) {
+		svc, mockMongo, mockOrg := setup()
+		app := setupPermissionApp("report:students")
+
+		app.Get("/reports/organization", svc.GetOrganizationReport)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/reports/organization?groupBy=major", nil))
+
+		assert.Equal(t, 400, resp.StatusCode)
+		mockOrg.AssertNotCalled(t, "GetStudentUnits", mock.Anything)
+		mockMongo.AssertNotCalled(t, "GetStudentTotals", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetStudentReportCoordinatorScope(t *testing.T) {
+	setup := func() (*service.ReportService, *mocks.MockAchievementRepo, *mocks.MockOrganizationRepo, uuid.UUID, uuid.UUID) {
+		mockMongo := new(mocks.MockAchievementRepo)
+		mockOrg := new(mocks.MockOrganizationRepo)
+		svc := service.NewReportService(mockMongo, new(mocks.MockStudentRepo), new(mocks.MockAchievementPgRepo), new(mocks.MockAcademicPeriodRepo), mockOrg)
+
+		coordinatorID, covered := uuid.New(), uuid.New()
+		mockOrg.On("GetCoordinatorScope", mock.Anything, coordinatorID).Return(&models.CoordinatorScope{
+			UserID: coordinatorID, Covered: []uuid.UUID{covered},
+		}, nil)
+		return svc, mockMongo, mockOrg, coordinatorID, covered
+	}
+
+	t.Run("Fail: Student Outside Scope", func(t *testing.T) {
+		svc, mockMongo, mockOrg, coordinatorID, covered := setup()
+		app := setupAchievementAppWithPermissions(coordinatorID, "report:students", "oversight:scoped")
+
+		inScope, outside := uuid.New(), uuid.New()
+		mockOrg.On("GetStudentUnits", mock.Anything).Return([]models.StudentUnits{
+			{StudentID: inScope, ProgramStudy: &models.OrgUnit{ID: covered}},
+			{StudentID: outside, ProgramStudy: &models.OrgUnit{ID: uuid.New()}},
+		}, nil)
+
+		app.Get("/reports/student/:id", svc.GetStudentReport)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/reports/student/"+outside.String(), nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+		mockMongo.AssertNotCalled(t, "GetStudentStats", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success: Statistics Limited To Scope", func(t *testing.T) {
+		svc, mockMongo, mockOrg, coordinatorID, covered := setup()
+		app := setupAchievementAppWithPermissions(coordinatorID, "report:students", "oversight:scoped")
+
+		inScope := uuid.New()
+		mockOrg.On("GetStudentUnits", mock.Anything).Return([]models.StudentUnits{
+			{StudentID: inScope, ProgramStudy: &models.OrgUnit{ID: covered}},
+			{StudentID: uuid.New()},
+		}, nil)
+		mockMongo.On("GetStudentTotals", mock.Anything, modelMongo.StatsFilter{StudentIDs: []string{inScope.String()}}).
+			Return([]modelMongo.StudentTotal{{StudentID: inScope.String(), Achievements: 1, TotalPoints: 10}}, nil)
+
+		app.Get("/reports/organization", svc.GetOrganizationReport)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/reports/organization", nil))
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var rows []modelMongo.UnitStatistics
+		json.NewDecoder(resp.Body).Decode(&rows)
+		assert.Len(t, rows, 1)
+		assert.Equal(t, covered.String(), rows[0].ID)
+		mockMongo.AssertExpectations(t)
+	})
+}
+
+func TestGetStorageReport(t *testing.T) {
+	t.Run("Success: Largest Consumers With Names", func(t *testing.T) {
+		t.Setenv("STORAGE_QUOTA_STUDENT_MB", "1")
+		svc, mockMongo, mockPg := setupReportServiceTest()
+		app := setupAchievementAppWithPermissions(uuid.New(), "report:storage")
+
+		big, small := uuid.New(), uuid.New()
+		mockMongo.On("TopStorageUsers", mock.Anything, 5).Return([]modelMongo.StorageReportItem{
+			{StudentID: big.String(), StorageUsage: modelMongo.StorageUsage{Bytes: 2 * 1024 * 1024, Files: 4}, Achievements: 2},
+			{StudentID: small.String(), StorageUsage: modelMongo.StorageUsage{Bytes: 1000, Files: 1}, Achievements: 1},
+		}, nil)
+		mockPg.On("GetStudentsByIDs", mock.Anything, []string{big.String(), small.String()}).Return([]models.StudentWithUser{
+			{ID: big, FullName: "Budi Santoso"},
+			{ID: small, FullName: "Siti Aminah"},
+		}, nil)
+
+		app.Get("/reports/storage", svc.GetStorageReport)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/reports/storage?limit=5", nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		var report []modelMongo.StorageReportItem
+		json.NewDecoder(resp.Body).Decode(&report)
+		if assert.Len(t, report, 2) {
+			assert.Equal(t, "Budi Santoso", report[0].StudentName)
+			assert.True(t, report[0].OverQuota)
+			assert.Equal(t, "Siti Aminah", report[1].StudentName)
+			assert.False(t, report[1].OverQuota)
+		}
+	})
+
+	t.Run("Fail: Invalid Limit", func(t *testing.T) {
+		svc, _, _ := setupReportServiceTest()
+		app := setupAchievementAppWithPermissions(uuid.New(), "report:storage")
+
+		app.Get("/reports/storage", svc.GetStorageReport)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/reports/storage?limit=1000", nil))
+
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+
+	t.Run("Fail: Not Admin", func(t *testing.T) {
+		svc, _, _ := setupReportServiceTest()
+		app := setupAchievementAppWithPermissions(uuid.New(), "report:students")
+
+		app.Get("/reports/storage", svc.GetStorageReport)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/reports/storage", nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+}
@@ -0,0 +1,248 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	service "StudenAchievementReportingSystem/app/service/mongodb"
+	"StudenAchievementReportingSystem/app/storage"
+)
+
+func setupResumableUploadTest(t *testing.T) (*service.UploadService, *mocks.MockUploadSessionRepo, *mocks.MockAchievementMongoRepo, string, uuid.UUID, modelPg.AchievementReference) {
+	achievements, mockMongo, _, userID, ref := setupUploadTest(t)
+	mockSessions := new(mocks.MockUploadSessionRepo)
+	stagingDir := t.TempDir()
+	svc := service.NewUploadService(achievements, mockSessions, storage.NewStaging(stagingDir), time.Hour)
+	return svc, mockSessions, mockMongo, stagingDir, userID, ref
+}
+
+func tusRequest(method, path string, body []byte, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func uploadMetadata(filename string) string {
+	return "filename " + base64.StdEncoding.EncodeToString([]byte(filename)) +
+		",kind " + base64.StdEncoding.EncodeToString([]byte("certificate"))
+}
+
+func TestCreateResumableUpload(t *testing.T) {
+	t.Run("Success: Session Created", func(t *testing.T) {
+		svc, mockSessions, _, _, userID, ref := setupResumableUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		sessionID := uuid.New()
+
+		mockSessions.On("CountByUser", mock.Anything, userID, mock.Anything).Return(0, nil)
+		mockSessions.On("Create", mock.Anything, mock.MatchedBy(func(u *modelPg.UploadSession) bool {
+			return u.AchievementID == ref.ID && u.UserID == userID && u.FileName == "sertifikat.pdf" && u.Kind == "certificate" && u.Length == 500
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*modelPg.UploadSession).ID = sessionID
+		}).Return(nil)
+
+		app.Post("/achievements/:id/uploads", svc.CreateUpload)
+		resp, _ := app.Test(tusRequest("POST", "/achievements/"+ref.ID.String()+"/uploads", nil, map[string]string{
+			"Upload-Length":   "500",
+			"Upload-Metadata": uploadMetadata("sertifikat.pdf"),
+		}))
+
+		assert.Equal(t, 201, resp.StatusCode)
+		assert.Equal(t, "/api/v1/achievements/"+ref.ID.String()+"/uploads/"+sessionID.String(), resp.Header.Get("Location"))
+		assert.Equal(t, "1.0.0", resp.Header.Get("Tus-Resumable"))
+		assert.NotEmpty(t, resp.Header.Get("Upload-Expires"))
+	})
+
+	cases := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{"Fail: Missing Tus-Resumable", map[string]string{"Tus-Resumable": "", "Upload-Length": "500", "Upload-Metadata": uploadMetadata("a.pdf")}, 412},
+		{"Fail: Missing Length", map[string]string{"Upload-Metadata": uploadMetadata("a.pdf")}, 400},
+		{"Fail: Missing Filename", map[string]string{"Upload-Length": "500"}, 400},
+		{"Fail: Disallowed Type", map[string]string{"Upload-Length": "500", "Upload-Metadata": uploadMetadata("a.gif")}, 415},
+		{"Fail: Over Type Limit", map[string]string{"Upload-Length": "2048", "Upload-Metadata": uploadMetadata("a.pdf")}, 413},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, mockSessions, _, _, userID, ref := setupResumableUploadTest(t)
+			app := setupAchievementAppWithPermissions(userID, "achievement:create")
+
+			app.Post("/achievements/:id/uploads", svc.CreateUpload)
+			resp, _ := app.Test(tusRequest("POST", "/achievements/"+ref.ID.String()+"/uploads", nil, tc.headers))
+
+			assert.Equal(t, tc.status, resp.StatusCode)
+			mockSessions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Fail: Too Many Unfinished Uploads", func(t *testing.T) {
+		svc, mockSessions, _, _, userID, ref := setupResumableUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		mockSessions.On("CountByUser", mock.Anything, userID, mock.Anything).Return(5, nil)
+
+		app.Post("/achievements/:id/uploads", svc.CreateUpload)
+		resp, _ := app.Test(tusRequest("POST", "/achievements/"+ref.ID.String()+"/uploads", nil, map[string]string{
+			"Upload-Length":   "500",
+			"Upload-Metadata": uploadMetadata("sertifikat.pdf"),
+		}))
+
+		assert.Equal(t, 429, resp.StatusCode)
+		mockSessions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestResumableUploadChunks(t *testing.T) {
+	newSession := func(userID uuid.UUID, ref modelPg.AchievementReference, filename string, length int) *modelPg.UploadSession {
+		return &modelPg.UploadSession{ID: uuid.New(), AchievementID: ref.ID, UserID: userID, FileName: filename, Kind: "certificate", Length: int64(length), ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	patch := func(ref modelPg.AchievementReference, session *modelPg.UploadSession, offset int, chunk string) *http.Request {
+		return tusRequest("PATCH", "/achievements/"+ref.ID.String()+"/uploads/"+session.ID.String(), []byte(chunk), map[string]string{
+			"Content-Type":  "application/offset+octet-stream",
+			"Upload-Offset": strconv.Itoa(offset),
+		})
+	}
+
+	t.Run("Success: Resumed And Completed", func(t *testing.T) {
+		svc, mockSessions, mockMongo, stagingDir, userID, ref := setupResumableUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		session := newSession(userID, ref, "sertifikat.pdf", len(samplePDF))
+		half := len(samplePDF) / 2
+
+		mockSessions.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+		mockSessions.On("Advance", mock.Anything, session.ID, int64(0), int64(half), mock.Anything).Return(nil)
+		mockSessions.On("Advance", mock.Anything, session.ID, int64(half), int64(len(samplePDF)), mock.Anything).Return(nil)
+		mockSessions.On("Delete", mock.Anything, session.ID).Return(nil)
+		var stored modelMongo.Attachment
+		mockMongo.On("AddAttachments", mock.Anything, "mongo1", mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]modelMongo.Attachment)[0]
+		}).Return(nil)
+		t.Cleanup(func() { testStorage().Delete(context.Background(), stored.StorageKey) })
+
+		app.Head("/achievements/:id/uploads/:uploadId", svc.UploadStatus)
+		app.Patch("/achievements/:id/uploads/:uploadId", svc.UploadChunk)
+
+		resp, _ := app.Test(patch(ref, session, 0, samplePDF[:half]))
+		assert.Equal(t, 204, resp.StatusCode)
+		assert.Equal(t, strconv.Itoa(half), resp.Header.Get("Upload-Offset"))
+
+		// the client lost track of the offset and asks for it
+		resp, _ = app.Test(tusRequest("HEAD", "/achievements/"+ref.ID.String()+"/uploads/"+session.ID.String(), nil, nil))
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, strconv.Itoa(half), resp.Header.Get("Upload-Offset"))
+		assert.Equal(t, strconv.Itoa(len(samplePDF)), resp.Header.Get("Upload-Length"))
+
+		resp, _ = app.Test(patch(ref, session, half, samplePDF[half:]))
+		assert.Equal(t, 204, resp.StatusCode)
+		assert.Equal(t, stored.ID, resp.Header.Get("Attachment-Id"))
+
+		sum := sha256.Sum256([]byte(samplePDF))
+		assert.Equal(t, hex.EncodeToString(sum[:]), stored.Checksum)
+		assert.Equal(t, "application/pdf", stored.FileType)
+		assert.Equal(t, "certificate", stored.Kind)
+		assert.Equal(t, modelMongo.ScanPending, stored.Scan.Status)
+		_, err := os.Stat(filepath.Join(stagingDir, session.ID.String()))
+		assert.True(t, os.IsNotExist(err))
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Offset Mismatch", func(t *testing.T) {
+		svc, mockSessions, _, _, userID, ref := setupResumableUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		session := newSession(userID, ref, "sertifikat.pdf", len(samplePDF))
+		session.Offset = 10
+		mockSessions.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+
+		app.Patch("/achievements/:id/uploads/:uploadId", svc.UploadChunk)
+		resp, _ := app.Test(patch(ref, session, 0, samplePDF))
+
+		assert.Equal(t, 409, resp.StatusCode)
+		assert.Equal(t, "10", resp.Header.Get("Upload-Offset"))
+		mockSessions.AssertNotCalled(t, "Advance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail: Chunk Sent Concurrently", func(t *testing.T) {
+		svc, mockSessions, _, stagingDir, userID, ref := setupResumableUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		session := newSession(userID, ref, "sertifikat.pdf", len(samplePDF))
+		mockSessions.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+		// another request advanced the upload while this one waited for the lock
+		mockSessions.On("Advance", mock.Anything, session.ID, int64(0), int64(len(samplePDF)), mock.Anything).Return(sql.ErrNoRows)
+
+		app.Patch("/achievements/:id/uploads/:uploadId", svc.UploadChunk)
+		resp, _ := app.Test(patch(ref, session, 0, samplePDF))
+
+		assert.Equal(t, 409, resp.StatusCode)
+		_, err := os.Stat(filepath.Join(stagingDir, session.ID.String()))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Fail: Upload Of Another User", func(t *testing.T) {
+		svc, mockSessions, _, _, userID, ref := setupResumableUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		session := newSession(uuid.New(), ref, "sertifikat.pdf", len(samplePDF))
+		mockSessions.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+
+		app.Patch("/achievements/:id/uploads/:uploadId", svc.UploadChunk)
+		resp, _ := app.Test(patch(ref, session, 0, samplePDF))
+
+		assert.Equal(t, 404, resp.StatusCode)
+	})
+
+	t.Run("Fail: Content Does Not Match Extension", func(t *testing.T) {
+		svc, mockSessions, mockMongo, stagingDir, userID, ref := setupResumableUploadTest(t)
+		app := setupAchievementAppWithPermissions(userID, "achievement:create")
+		script := "#!/bin/sh\necho hi\n"
+		session := newSession(userID, ref, "sertifikat.pdf", len(script))
+
+		mockSessions.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+		mockSessions.On("Advance", mock.Anything, session.ID, int64(0), int64(len(script)), mock.Anything).Return(nil)
+		mockSessions.On("Delete", mock.Anything, session.ID).Return(nil)
+
+		app.Patch("/achievements/:id/uploads/:uploadId", svc.UploadChunk)
+		resp, _ := app.Test(patch(ref, session, 0, script))
+
+		assert.Equal(t, 415, resp.StatusCode)
+		mockMongo.AssertNotCalled(t, "AddAttachments", mock.Anything, mock.Anything, mock.Anything)
+		mockSessions.AssertCalled(t, "Delete", mock.Anything, session.ID)
+		_, err := os.Stat(filepath.Join(stagingDir, session.ID.String()))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestPurgeExpiredUploads(t *testing.T) {
+	svc, mockSessions, _, stagingDir, _, _ := setupResumableUploadTest(t)
+	expired := uuid.New()
+	staged := filepath.Join(stagingDir, expired.String())
+	assert.NoError(t, os.WriteFile(staged, []byte("%PDF"), 0644))
+
+	mockSessions.On("DeleteExpired", mock.Anything, mock.Anything).Return([]uuid.UUID{expired}, nil)
+
+	n, err := svc.PurgeExpiredUploads(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = os.Stat(staged)
+	assert.True(t, os.IsNotExist(err))
+}
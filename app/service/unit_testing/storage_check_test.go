@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+	modelPg "StudenAchievementReportingSystem/app/models/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	service "StudenAchievementReportingSystem/app/service/mongodb"
+	"StudenAchievementReportingSystem/app/storage"
+)
+
+// setupStorageCheckTest fills a storage of its own with a referenced file and
+// its preview, a shared file, an old orphan and a fresh upload, and a document
+// that also refers to a lost file, a file that is back and an infected one
+func setupStorageCheckTest(t *testing.T) (*service.AchievementService, *mocks.MockAchievementMongoRepo, storage.Storage, modelMongo.Achievement) {
+	dir := t.TempDir()
+	files := storage.NewLocal(dir)
+	mockMongo := new(mocks.MockAchievementMongoRepo)
+	mockBlobs := new(mocks.MockAttachmentBlobRepo)
+	deps := testAchievementDeps()
+	deps.Achievements = mockMongo
+	deps.Files = files
+	deps.Blobs = mockBlobs
+	svc := service.NewAchievementService(deps)
+
+	old := time.Now().Add(-2 * time.Hour)
+	for _, key := range []string{"a.pdf", "a.pdf.preview.jpg", "sha256/ab/abc.pdf", "back.pdf", "orphan.pdf", "fresh.pdf"} {
+		assert.NoError(t, files.Put(context.Background(), key, strings.NewReader("%PDF"), 4, "application/pdf"))
+		if key != "fresh.pdf" {
+			assert.NoError(t, os.Chtimes(filepath.Join(dir, filepath.FromSlash(key)), old, old))
+		}
+	}
+
+	marked := old
+	doc := modelMongo.Achievement{ID: primitive.NewObjectID(), Attachments: []modelMongo.Attachment{
+		{ID: "a", FileName: "a.pdf", StorageKey: "a.pdf"},
+		{ID: "shared", FileName: "abc.pdf", StorageKey: "sha256/ab/abc.pdf", Checksum: "abc"},
+		{ID: "gone", FileName: "gone.pdf", StorageKey: "gone.pdf"},
+		{ID: "back", FileName: "back.pdf", StorageKey: "back.pdf", MissingAt: &marked},
+		{ID: "infected", FileName: "virus.pdf", StorageKey: "virus.pdf", Scan: &modelMongo.AttachmentScan{Status: modelMongo.ScanInfected}},
+	}}
+	mockMongo.On("FindStoredAttachments", mock.Anything).Return([]modelMongo.Achievement{doc}, nil)
+	mockBlobs.On("List", mock.Anything).Return([]modelPg.AttachmentBlob{{Checksum: "abc", StorageKey: "sha256/ab/abc.pdf", RefCount: 2}}, nil)
+	return svc, mockMongo, files, doc
+}
+
+func TestCheckStorage(t *testing.T) {
+	t.Run("Success: Report Only", func(t *testing.T) {
+		svc, mockMongo, files, _ := setupStorageCheckTest(t)
+
+		report, err := svc.CheckStorage(context.Background(), false, time.Hour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6, report.Files)
+		assert.Equal(t, 4, report.Attachments)
+		if assert.Len(t, report.Orphans, 1) {
+			assert.Equal(t, "orphan.pdf", report.Orphans[0].Key)
+			assert.Equal(t, int64(4), report.Orphans[0].Size)
+		}
+		if assert.Len(t, report.Missing, 1) {
+			assert.Equal(t, "gone", report.Missing[0].AttachmentID)
+			assert.Nil(t, report.Missing[0].MissingAt)
+		}
+		assert.Equal(t, []modelMongo.BlobDrift{{Checksum: "abc", StorageKey: "sha256/ab/abc.pdf", RefCount: 2, Attachments: 1}}, report.BlobDrift)
+		assert.Zero(t, report.Deleted+report.Marked+report.Restored)
+		mockMongo.AssertNotCalled(t, "MarkAttachmentMissing", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		_, err = files.Open(context.Background(), "orphan.pdf")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success: Repair", func(t *testing.T) {
+		svc, mockMongo, files, doc := setupStorageCheckTest(t)
+		mockMongo.On("MarkAttachmentMissing", mock.Anything, doc.ID.Hex(), "gone", "gone.pdf", mock.MatchedBy(func(at *time.Time) bool { return at != nil })).Return(nil)
+		mockMongo.On("MarkAttachmentMissing", mock.Anything, doc.ID.Hex(), "back", "back.pdf", (*time.Time)(nil)).Return(nil)
+
+		report, err := svc.CheckStorage(context.Background(), true, time.Hour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Deleted)
+		assert.Equal(t, 1, report.Marked)
+		assert.Equal(t, 1, report.Restored)
+		if assert.Len(t, report.Missing, 1) {
+			assert.NotNil(t, report.Missing[0].MissingAt)
+		}
+		mockMongo.AssertExpectations(t)
+		_, err = files.Open(context.Background(), "orphan.pdf")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		_, err = files.Open(context.Background(), "fresh.pdf")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success: Already Marked", func(t *testing.T) {
+		svc, mockMongo, _, doc := setupStorageCheckTest(t)
+		marked := time.Now().Add(-24 * time.Hour)
+		doc.Attachments = []modelMongo.Attachment{{ID: "gone", StorageKey: "gone.pdf", MissingAt: &marked}}
+		mockMongo.ExpectedCalls = nil
+		mockMongo.On("FindStoredAttachments", mock.Anything).Return([]modelMongo.Achievement{doc}, nil)
+
+		report, err := svc.CheckStorage(context.Background(), true, time.Hour)
+
+		assert.NoError(t, err)
+		assert.Zero(t, report.Marked)
+		if assert.Len(t, report.Missing, 1) {
+			assert.Equal(t, marked, *report.Missing[0].MissingAt)
+		}
+		mockMongo.AssertNotCalled(t, "MarkAttachmentMissing", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetStorageCheck(t *testing.T) {
+	t.Run("Success: Super Admin", func(t *testing.T) {
+		svc, _, _, _ := setupStorageCheckTest(t)
+		app := setupAchievementAppWithPermissions(uuid.New(), "manage:storage")
+
+		app.Get("/storage/check", svc.GetStorageCheck)
+		resp, _ := app.Test(httptest.NewRequest("GET", "/storage/check", nil))
+
+		assert.Equal(t, 200, resp.StatusCode)
+		var report modelMongo.StorageCheckReport
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		assert.False(t, report.Repaired)
+		assert.Len(t, report.Orphans, 1)
+	})
+
+	t.Run("Fail: Forbidden", func(t *testing.T) {
+		svc, _, _, _ := setupStorageCheckTest(t)
+		app := setupAchievementAppWithPermissions(uuid.New(), "report:storage")
+
+		app.Post("/storage/check/repair", svc.RepairStorage)
+		resp, _ := app.Test(httptest.NewRequest("POST", "/storage/check/repair", nil))
+
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+}
+
+func TestDownloadMissingAttachment(t *testing.T) {
+	svc, mockMongo, mockPg, mockLecturer, ref, att := setupAttachmentDownloadTest(t)
+	userID := uuid.New()
+	app := setupAchievementAppWithPermissions(userID, "achievement:read")
+
+	missingAt := time.Now()
+	att.MissingAt = &missingAt
+	mockMongo.ExpectedCalls = nil
+	mockMongo.On("FindOne", mock.Anything, "mongo1").Return(&modelMongo.Achievement{Attachments: []modelMongo.Attachment{att}}, nil)
+	mockPg.On("GetStudentByUserID", mock.Anything, userID).Return(ref.StudentID, nil)
+	mockLecturer.On("GetLecturerByUserID", mock.Anything, userID).Return(uuid.Nil, errors.New("not a lecturer"))
+
+	app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/"+ref.ID.String()+"/attachments/"+att.ID, nil))
+
+	assert.Equal(t, 410, resp.StatusCode)
+}
@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"StudenAchievementReportingSystem/app/storage"
+)
+
+// testStorage keeps files of service tests out of the working directory
+func testStorage() storage.Storage {
+	return storage.NewLocal(filepath.Join(os.TempDir(), "achievement-test-uploads"))
+}
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	files := storage.NewLocal(t.TempDir())
+
+	assert.NoError(t, files.Put(ctx, "a1.pdf", strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+
+	r, err := files.Open(ctx, "a1.pdf")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "%PDF-1.4", string(body))
+
+	_, err = files.URL(ctx, "a1.pdf")
+	assert.ErrorIs(t, err, storage.ErrNoDirectURL)
+
+	assert.NoError(t, files.Delete(ctx, "a1.pdf"))
+	assert.NoError(t, files.Delete(ctx, "a1.pdf"))
+	_, err = files.Open(ctx, "a1.pdf")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	for _, key := range []string{"../etc/passwd", "/abs.pdf", "a/../../b", ""} {
+		assert.ErrorIs(t, files.Put(ctx, key, strings.NewReader("x"), 1, ""), storage.ErrInvalidKey, key)
+	}
+
+	assert.Empty(t, listed(t, storage.NewLocal(filepath.Join(t.TempDir(), "never-created"))))
+	assert.NoError(t, files.Put(ctx, "sha256/ab/abc.pdf", strings.NewReader("%PDF"), 4, "application/pdf"))
+	assert.Equal(t, map[string]int64{"sha256/ab/abc.pdf": 4}, listed(t, files))
+}
+
+// fakeS3 is a minimal S3 stand-in keeping objects in memory. It only accepts
+// requests signed with the configured access key.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	reads   int // object GETs served
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=minio/") || !strings.Contains(auth, "Signature=") || r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") == "2" {
+			f.list(w, r)
+			return
+		}
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.reads++
+		io.WriteString(w, body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// list answers ListObjectsV2 for the bucket in the path, two objects a page
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Path + "/"
+	var keys []string
+	for p := range f.objects {
+		if strings.HasPrefix(p, prefix) && strings.TrimPrefix(p, prefix) > r.URL.Query().Get("continuation-token") {
+			keys = append(keys, strings.TrimPrefix(p, prefix))
+		}
+	}
+	sort.Strings(keys)
+
+	truncated := len(keys) > 2
+	if truncated {
+		keys = keys[:2]
+	}
+	io.WriteString(w, "<ListBucketResult>")
+	for _, k := range keys {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size><LastModified>2024-05-01T10:00:00.000Z</LastModified></Contents>", k, len(f.objects[prefix+k]))
+	}
+	if truncated {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[len(keys)-1])
+	}
+	io.WriteString(w, "</ListBucketResult>")
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ctx := context.Background()
+	files := storage.NewS3(storage.S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "attachments",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+		PathStyle: true,
+		URLExpiry: 10 * time.Minute,
+	})
+
+	assert.NoError(t, files.Put(ctx, "a1.pdf", strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+	assert.Equal(t, "%PDF-1.4", fake.objects["/attachments/a1.pdf"])
+
+	r, err := files.Open(ctx, "a1.pdf")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "%PDF-1.4", string(body))
+
+	u, err := files.URL(ctx, "a1.pdf")
+	assert.NoError(t, err)
+	parsed, _ := url.Parse(u)
+	assert.Equal(t, "/attachments/a1.pdf", parsed.Path)
+	assert.Equal(t, "600", parsed.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, parsed.Query().Get("X-Amz-Signature"))
+
+	for _, key := range []string{"sha256/ab/abc.pdf", "b2.png"} {
+		assert.NoError(t, files.Put(ctx, key, strings.NewReader("x"), 1, ""))
+	}
+	assert.Equal(t, map[string]int64{"a1.pdf": 8, "b2.png": 1, "sha256/ab/abc.pdf": 1}, listed(t, files))
+
+	assert.NoError(t, files.Delete(ctx, "a1.pdf"))
+	_, err = files.Open(ctx, "a1.pdf")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestS3StorageTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	files := storage.NewS3(storage.S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "attachments",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+		PathStyle: true,
+		Timeout:   50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := files.Open(context.Background(), "a1.pdf")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// listed returns the keys and sizes of the stored files
+func listed(t *testing.T, files storage.Storage) map[string]int64 {
+	found := make(map[string]int64)
+	assert.NoError(t, files.List(context.Background(), func(o storage.Object) error {
+		found[o.Key] = o.Size
+		return nil
+	}))
+	return found
+}
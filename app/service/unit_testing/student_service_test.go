@@ -22,7 +22,7 @@ import (
 func setupStudentServiceTest() (*service.StudentService, *mocks.MockStudentRepo, *mocks.MockAchievementRepo) {
 	mockStudentRepo := new(mocks.MockStudentRepo)
 	mockAchievementRepo := new(mocks.MockAchievementRepo)
-	svc := service.NewStudentService(mockStudentRepo, mockAchievementRepo)
+	svc := service.NewStudentService(mockStudentRepo, mockAchievementRepo, new(mocks.MockOrganizationRepo))
 
 	return svc, mockStudentRepo, mockAchievementRepo
 }
@@ -143,7 +143,7 @@ func TestGetStudentAchievements(t *testing.T) {
 		},
 	}
 
-		mockAchievementRepo.On("GetStudentAchievements", targetID).Return(mockAchievements, nil)
+		mockAchievementRepo.On("GetStudentAchievements", mock.Anything, targetID).Return(mockAchievements, nil)
 
 		app.Get("/students/:id/achievements", svc.GetStudentAchievements)
 
@@ -159,7 +159,7 @@ func TestGetStudentAchievements(t *testing.T) {
 		app := setupStudentApp()
 
 		targetID := uuid.New()
-		mockAchievementRepo.On("GetStudentAchievements", targetID).Return(nil, errors.New("mongo error"))
+		mockAchievementRepo.On("GetStudentAchievements", mock.Anything, targetID).Return(nil, errors.New("mongo error"))
 
 		app.Get("/students/:id/achievements", svc.GetStudentAchievements)
 
@@ -178,7 +178,7 @@ func TestUpdateAdvisor(t *testing.T) {
 		studentID := uuid.New()
 		lecturerID := uuid.New()
 
-		mockStudentRepo.On("UpdateAdvisor", mock.Anything, studentID, lecturerID).Return(nil)
+		mockStudentRepo.On("UpdateAdvisor", mock.Anything, studentID, lecturerID, mock.Anything).Return(&models.ReassignResult{Reassigned: 1}, nil)
 
 		app.Put("/students/:id/advisor", svc.UpdateAdvisor)
 
@@ -211,4 +211,32 @@ func TestUpdateAdvisor(t *testing.T) {
 
 		assert.Equal(t, 400, resp.StatusCode)
 	})
-}
\ No newline at end of file
+}
+func TestGetAllStudentsCoordinatorScope(t *testing.T) {
+	mockStudentRepo := new(mocks.MockStudentRepo)
+	mockOrg := new(mocks.MockOrganizationRepo)
+	svc := service.NewStudentService(mockStudentRepo, new(mocks.MockAchievementRepo), mockOrg)
+
+	coordinatorID := uuid.New()
+	app := setupAchievementAppWithPermissions(coordinatorID, "manage:students", "oversight:scoped")
+
+	covered, other := uuid.New(), uuid.New()
+	mockOrg.On("GetCoordinatorScope", mock.Anything, coordinatorID).Return(&models.CoordinatorScope{
+		UserID: coordinatorID, ProgramStudyIDs: []uuid.UUID{covered}, Covered: []uuid.UUID{covered},
+	}, nil)
+	inScope := models.Student{ID: uuid.New(), StudentID: "111", ProgramStudyID: &covered}
+	mockStudentRepo.On("GetAllStudents", mock.Anything).Return([]models.Student{
+		inScope,
+		{ID: uuid.New(), StudentID: "222", ProgramStudyID: &other},
+		{ID: uuid.New(), StudentID: "333"},
+	}, nil)
+
+	app.Get("/students", svc.GetAllStudents)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/students", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var students []models.Student
+	json.NewDecoder(resp.Body).Decode(&students)
+	assert.Len(t, students, 1)
+	assert.Equal(t, inScope.ID, students[0].ID)
+}
@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	models "StudenAchievementReportingSystem/app/models/postgresql"
+	repo "StudenAchievementReportingSystem/app/repository/postgresql"
+	"StudenAchievementReportingSystem/app/repository/mocks"
+	"StudenAchievementReportingSystem/app/service/postgresql"
+	"StudenAchievementReportingSystem/middleware"
+	"StudenAchievementReportingSystem/utils"
+)
+
+func TestCreateTenant(t *testing.T) {
+	t.Run("Success: Code Normalized", func(t *testing.T) {
+		mockRepo := new(mocks.MockTenantRepo)
+		svc := service.NewTenantService(mockRepo)
+		app := setupPermissionApp("tenant:all")
+
+		newID := uuid.New()
+		mockRepo.On("Create", mock.Anything, models.Tenant{Code: "FT", Name: "Fakultas Teknik"}).Return(newID, nil)
+
+		app.Post("/tenants", svc.CreateTenant)
+		body, _ := json.Marshal(map[string]string{"code": " ft ", "name": "Fakultas  Teknik"})
+		req := httptest.NewRequest("POST", "/tenants", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 201, resp.StatusCode)
+		var res models.Tenant
+		json.NewDecoder(resp.Body).Decode(&res)
+		assert.Equal(t, newID, res.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail: Duplicate Code", func(t *testing.T) {
+		mockRepo := new(mocks.MockTenantRepo)
+		svc := service.NewTenantService(mockRepo)
+		app := setupPermissionApp("tenant:all")
+
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(uuid.Nil, repo.ErrDuplicateCode)
+
+		app.Post("/tenants", svc.CreateTenant)
+		body, _ := json.Marshal(map[string]string{"code": "FT", "name": "Fakultas Teknik"})
+		req := httptest.NewRequest("POST", "/tenants", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 409, resp.StatusCode)
+	})
+
+	t.Run("Fail: Tenant Admin Forbidden", func(t *testing.T) {
+		mockRepo := new(mocks.MockTenantRepo)
+		svc := service.NewTenantService(mockRepo)
+		app := setupPermissionApp("manage:users")
+
+		app.Post("/tenants", svc.CreateTenant)
+		body, _ := json.Marshal(map[string]string{"code": "FT", "name": "Fakultas Teknik"})
+		req := httptest.NewRequest("POST", "/tenants", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+
+		assert.Equal(t, 403, resp.StatusCode)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTenantFromContext(t *testing.T) {
+	id := uuid.New()
+
+	got, ok := utils.TenantFromContext(utils.WithTenant(context.Background(), id))
+	assert.True(t, ok)
+	assert.Equal(t, id, got)
+
+	_, ok = utils.TenantFromContext(utils.WithAllTenants(context.Background()))
+	assert.False(t, ok)
+
+	// a missing tenant scopes to no tenant instead of all of them
+	got, ok = utils.TenantFromContext(context.Background())
+	assert.True(t, ok)
+	assert.Equal(t, uuid.Nil, got)
+
+	got, ok = utils.TenantFromContext(utils.WithTenant(context.Background(), uuid.Nil))
+	assert.True(t, ok)
+	assert.Equal(t, uuid.Nil, got)
+}
+
+func TestAuthRequiredTenant(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	tenantID := uuid.New()
+
+	scope := func(t *testing.T, user *models.User, header string, permissions ...string) (int, string) {
+		token, err := utils.GenerateToken(user, "Mahasiswa", permissions)
+		assert.NoError(t, err)
+
+		app := fiber.New()
+		app.Get("/", middleware.AuthRequired(), func(c *fiber.Ctx) error {
+			id, ok := utils.TenantFromContext(c.Context())
+			if !ok {
+				return c.SendString("all")
+			}
+			return c.SendString(id.String())
+		})
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if header != "" {
+			req.Header.Set("X-Tenant-ID", header)
+		}
+		resp, _ := app.Test(req)
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("Success: Token Tenant", func(t *testing.T) {
+		status, body := scope(t, &models.User{ID: uuid.New(), TenantID: tenantID}, tenantID.String(), "achievement:read")
+		assert.Equal(t, 200, status)
+		assert.Equal(t, tenantID.String(), body)
+	})
+
+	t.Run("Success: Super Admin Across Tenants", func(t *testing.T) {
+		status, body := scope(t, &models.User{ID: uuid.New()}, "", "tenant:all")
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "all", body)
+	})
+
+	t.Run("Success: Super Admin Picks Tenant", func(t *testing.T) {
+		status, body := scope(t, &models.User{ID: uuid.New()}, tenantID.String(), "tenant:all")
+		assert.Equal(t, 200, status)
+		assert.Equal(t, tenantID.String(), body)
+	})
+
+	t.Run("Error: Token Without Tenant", func(t *testing.T) {
+		status, _ := scope(t, &models.User{ID: uuid.New()}, "", "achievement:read")
+		assert.Equal(t, 401, status)
+	})
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Staging keeps the bytes of unfinished resumable uploads on local disk,
+// where chunks can be appended; the finished file is then moved to Storage
+type Staging struct {
+	dir string
+}
+
+// NewStaging keeps partial uploads under dir, which is created when needed
+func NewStaging(dir string) *Staging {
+	return &Staging{dir: dir}
+}
+
+func (s *Staging) path(id string) (string, error) {
+	id, err := cleanKey(id)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, id), nil
+}
+
+// Write puts a chunk at offset. Anything beyond offset left by an earlier
+// write that was not acknowledged is discarded first.
+func (s *Staging) Write(id string, offset int64, chunk []byte) error {
+	p, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.WriteAt(chunk, offset); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *Staging) Open(id string) (*os.File, error) {
+	p, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Remove succeeds when the file is already gone
+func (s *Staging) Remove(id string) error {
+	p, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"StudenAchievementReportingSystem/config"
+)
+
+var (
+	ErrNotFound    = errors.New("object not found")
+	ErrInvalidKey  = errors.New("invalid storage key")
+	ErrNoDirectURL = errors.New("storage has no direct URLs")
+)
+
+// Storage keeps attachment files under keys. Documents only store the key.
+// URL returns a short-lived link that bypasses the API, or ErrNoDirectURL when
+// the backend can only be read through Open. List calls fn for every stored
+// file, in no particular order, and stops at the first error fn returns.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(ctx context.Context, key string) (string, error)
+	List(ctx context.Context, fn func(Object) error) error
+}
+
+// Object is a stored file as listed by List
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// FromConfig builds the driver selected by STORAGE_DRIVER
+func FromConfig(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocal(cfg.LocalDir), nil
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+			return nil, errors.New("storage: S3_ENDPOINT and S3_BUCKET are required for the s3 driver")
+		}
+		return NewS3(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+			Timeout:   cfg.S3Timeout,
+			URLExpiry: cfg.URLExpiry,
+		}), nil
+	}
+	return nil, fmt.Errorf("storage: unknown driver %q", cfg.Driver)
+}
+
+// cleanKey rejects keys that could escape the storage root
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key || strings.HasPrefix(key, "..") {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
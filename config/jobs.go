@@ -0,0 +1,22 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+type JobsConfig struct {
+	Enabled bool
+}
+
+// LoadJobs reads whether this instance runs the background jobs (JOBS_ENABLED,
+// default true). When several instances share the databases, leave it on for
+// exactly one of them, otherwise every instance purges, scans and checks the
+// same data.
+func LoadJobs() JobsConfig {
+	enabled, err := strconv.ParseBool(os.Getenv("JOBS_ENABLED"))
+	if err != nil {
+		enabled = true
+	}
+	return JobsConfig{Enabled: enabled}
+}
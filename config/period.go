@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type PeriodConfig struct {
+	AssignInterval time.Duration
+}
+
+// LoadPeriods reads how often achievements without an academic period are
+// matched against the configured periods (PERIOD_ASSIGN_INTERVAL_MINUTES, default 60)
+func LoadPeriods() PeriodConfig {
+	minutes, err := strconv.Atoi(os.Getenv("PERIOD_ASSIGN_INTERVAL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 60
+	}
+	return PeriodConfig{AssignInterval: time.Duration(minutes) * time.Minute}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type PreviewConfig struct {
+	PDFRenderer  string // "embedded" (also when empty) or "pdftoppm"
+	PdftoppmPath string
+	Interval     time.Duration
+}
+
+// LoadPreview reads how attachment previews are made. PREVIEW_PDF_RENDERER is
+// "embedded", the default, for the first JPEG image in the file, which is
+// best-effort and needs nothing outside the process, or "pdftoppm" to render
+// the first page with the poppler binary at PDFTOPPM_PATH (default pdftoppm on
+// the PATH), which then has to be installed. New attachments get their
+// previews every PREVIEW_INTERVAL_SECONDS (default 30).
+func LoadPreview() PreviewConfig {
+	interval, err := strconv.Atoi(os.Getenv("PREVIEW_INTERVAL_SECONDS"))
+	if err != nil || interval <= 0 {
+		interval = 30
+	}
+	return PreviewConfig{
+		PDFRenderer:  os.Getenv("PREVIEW_PDF_RENDERER"),
+		PdftoppmPath: envOr("PDFTOPPM_PATH", "pdftoppm"),
+		Interval:     time.Duration(interval) * time.Second,
+	}
+}
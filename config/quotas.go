@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// StorageQuotaConfig limits the attachments kept per student and per
+// achievement. A zero limit does not apply.
+type StorageQuotaConfig struct {
+	StudentBytes     int64
+	StudentFiles     int
+	AchievementBytes int64
+	AchievementFiles int
+}
+
+// LoadStorageQuotas reads the attachment quotas: STORAGE_QUOTA_STUDENT_MB
+// (default 500) and STORAGE_QUOTA_STUDENT_FILES (default 200) over all of a
+// student's achievements, STORAGE_QUOTA_ACHIEVEMENT_MB (default 100) and
+// STORAGE_QUOTA_ACHIEVEMENT_FILES (default 20) per achievement. 0 turns a
+// limit off.
+func LoadStorageQuotas() StorageQuotaConfig {
+	return StorageQuotaConfig{
+		StudentBytes:     int64(quotaOr("STORAGE_QUOTA_STUDENT_MB", 500)) * 1024 * 1024,
+		StudentFiles:     quotaOr("STORAGE_QUOTA_STUDENT_FILES", 200),
+		AchievementBytes: int64(quotaOr("STORAGE_QUOTA_ACHIEVEMENT_MB", 100)) * 1024 * 1024,
+		AchievementFiles: quotaOr("STORAGE_QUOTA_ACHIEVEMENT_FILES", 20),
+	}
+}
+
+// quotaOr reads a limit, keeping 0 as a valid setting
+func quotaOr(key string, fallback int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
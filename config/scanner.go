@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type ScannerConfig struct {
+	Driver       string // "none" or "clamav"
+	ClamAVAddr   string
+	Timeout      time.Duration
+	ScanInterval time.Duration
+}
+
+// LoadScanner reads how uploads are checked for malware. SCANNER_DRIVER is
+// "none" (default; every file is clean) or "clamav" for a clamd daemon at
+// CLAMAV_ADDR (default localhost:3310, or unix:/path for a socket). A scan
+// times out after SCAN_TIMEOUT_SECONDS (default 60), and quarantined uploads
+// are picked up every SCAN_INTERVAL_SECONDS (default 10).
+func LoadScanner() ScannerConfig {
+	timeout, err := strconv.Atoi(os.Getenv("SCAN_TIMEOUT_SECONDS"))
+	if err != nil || timeout <= 0 {
+		timeout = 60
+	}
+	interval, err := strconv.Atoi(os.Getenv("SCAN_INTERVAL_SECONDS"))
+	if err != nil || interval <= 0 {
+		interval = 10
+	}
+	return ScannerConfig{
+		Driver:       os.Getenv("SCANNER_DRIVER"),
+		ClamAVAddr:   envOr("CLAMAV_ADDR", "localhost:3310"),
+		Timeout:      time.Duration(timeout) * time.Second,
+		ScanInterval: time.Duration(interval) * time.Second,
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SignedURLConfig struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// MinSignedURLSecretLength is the shortest SIGNED_URL_SECRET accepted
+const MinSignedURLSecretLength = 32
+
+// placeholder values from examples and older checkouts that must never sign links
+var signedURLPlaceholders = []string{
+	"change-me",
+	"changeme",
+	"secret",
+	"rahasia_tautan_lampiran_ini",
+}
+
+// LoadSignedURL reads the key for signed download links (SIGNED_URL_SECRET)
+// and how long a link stays valid (SIGNED_URL_TTL_MINUTES, default 5)
+func LoadSignedURL() SignedURLConfig {
+	minutes, err := strconv.Atoi(os.Getenv("SIGNED_URL_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 5
+	}
+	return SignedURLConfig{Secret: []byte(os.Getenv("SIGNED_URL_SECRET")), TTL: time.Duration(minutes) * time.Minute}
+}
+
+// Validate requires a secret of its own that is long enough not to be
+// guessed, as signed links skip authentication and the tenant check
+func (c SignedURLConfig) Validate() error {
+	secret := string(c.Secret)
+	if secret == "" {
+		return errors.New("SIGNED_URL_SECRET is not set")
+	}
+	for _, p := range signedURLPlaceholders {
+		if strings.EqualFold(secret, p) {
+			return errors.New("SIGNED_URL_SECRET is a placeholder, generate a random one")
+		}
+	}
+	if len(c.Secret) < MinSignedURLSecretLength {
+		return errors.New("SIGNED_URL_SECRET must be at least " + strconv.Itoa(MinSignedURLSecretLength) + " bytes")
+	}
+	if jwt := os.Getenv("JWT_SECRET"); jwt != "" && secret == jwt {
+		return errors.New("SIGNED_URL_SECRET must differ from JWT_SECRET")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type StorageConfig struct {
+	Driver      string // "local" or "s3"
+	LocalDir    string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool
+	S3Timeout   time.Duration
+	URLExpiry   time.Duration
+}
+
+// LoadStorage reads where attachments are kept. STORAGE_DRIVER is "local"
+// (default; files in STORAGE_LOCAL_DIR, default ./uploads) or "s3" for S3 and
+// compatible servers (S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY,
+// S3_SECRET_KEY, and S3_PATH_STYLE=true for MinIO). S3 requests give up when
+// the server does not connect or answer within S3_TIMEOUT_SECONDS (default
+// 30). Presigned S3 URLs expire after STORAGE_URL_EXPIRY_MINUTES (default 15).
+func LoadStorage() StorageConfig {
+	minutes, err := strconv.Atoi(os.Getenv("STORAGE_URL_EXPIRY_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 15
+	}
+	pathStyle, _ := strconv.ParseBool(os.Getenv("S3_PATH_STYLE"))
+	timeout, err := strconv.Atoi(os.Getenv("S3_TIMEOUT_SECONDS"))
+	if err != nil || timeout <= 0 {
+		timeout = 30
+	}
+
+	return StorageConfig{
+		Driver:      os.Getenv("STORAGE_DRIVER"),
+		LocalDir:    envOr("STORAGE_LOCAL_DIR", "./uploads"),
+		S3Endpoint:  os.Getenv("S3_ENDPOINT"),
+		S3Region:    os.Getenv("S3_REGION"),
+		S3Bucket:    os.Getenv("S3_BUCKET"),
+		S3AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle: pathStyle,
+		S3Timeout:   time.Duration(timeout) * time.Second,
+		URLExpiry:   time.Duration(minutes) * time.Minute,
+	}
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
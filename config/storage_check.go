@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type StorageCheckConfig struct {
+	Interval time.Duration
+	Grace    time.Duration
+	Repair   bool
+}
+
+// LoadStorageCheck reads how often attachment storage is checked against the
+// database (STORAGE_CHECK_INTERVAL_HOURS, default 24), how old a file has to
+// be before it counts as orphaned (STORAGE_CHECK_GRACE_MINUTES, default 60)
+// and whether the scheduled check repairs what it finds (STORAGE_CHECK_REPAIR,
+// default false)
+func LoadStorageCheck() StorageCheckConfig {
+	hours, err := strconv.Atoi(os.Getenv("STORAGE_CHECK_INTERVAL_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	minutes, err := strconv.Atoi(os.Getenv("STORAGE_CHECK_GRACE_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 60
+	}
+	repair, _ := strconv.ParseBool(os.Getenv("STORAGE_CHECK_REPAIR"))
+	return StorageCheckConfig{
+		Interval: time.Duration(hours) * time.Hour,
+		Grace:    time.Duration(minutes) * time.Minute,
+		Repair:   repair,
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// LoadTrash reads how long deleted achievements stay restorable
+// (TRASH_RETENTION_DAYS, default 30) and how often expired ones are purged
+// (TRASH_PURGE_INTERVAL_MINUTES, default 60)
+func LoadTrash() TrashConfig {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	minutes, err := strconv.Atoi(os.Getenv("TRASH_PURGE_INTERVAL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 60
+	}
+	return TrashConfig{
+		Retention:     time.Duration(days) * 24 * time.Hour,
+		PurgeInterval: time.Duration(minutes) * time.Minute,
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type ResumableUploadConfig struct {
+	StagingDir      string
+	Expiry          time.Duration
+	CleanupInterval time.Duration
+}
+
+// LoadResumableUploads reads where unfinished resumable uploads are kept
+// (UPLOAD_STAGING_DIR, default ./uploads-partial), how long an upload may sit
+// idle before it is abandoned (UPLOAD_EXPIRY_HOURS, default 24) and how often
+// abandoned uploads are removed (UPLOAD_CLEANUP_INTERVAL_MINUTES, default 60)
+func LoadResumableUploads() ResumableUploadConfig {
+	hours, err := strconv.Atoi(os.Getenv("UPLOAD_EXPIRY_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	minutes, err := strconv.Atoi(os.Getenv("UPLOAD_CLEANUP_INTERVAL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		minutes = 60
+	}
+	return ResumableUploadConfig{
+		StagingDir:      envOr("UPLOAD_STAGING_DIR", "./uploads-partial"),
+		Expiry:          time.Duration(hours) * time.Hour,
+		CleanupInterval: time.Duration(minutes) * time.Minute,
+	}
+}
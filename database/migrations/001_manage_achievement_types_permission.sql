@@ -0,0 +1,12 @@
+-- Permission for managing the achievement type registry (MongoDB collection achievement_types)
+INSERT INTO permissions (id, name, resource, action, description)
+SELECT gen_random_uuid(), 'manage:achievement_types', 'achievement_types', 'manage', 'Create and update achievement types and their custom field schemas'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'manage:achievement_types');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE LOWER(r.name) = 'admin' AND p.name = 'manage:achievement_types'
+  AND NOT EXISTS (
+      SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  );
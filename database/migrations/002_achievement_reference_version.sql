@@ -0,0 +1,3 @@
+-- Version counter for optimistic concurrency; exposed to clients as the ETag of an achievement
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
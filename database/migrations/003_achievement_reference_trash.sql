@@ -0,0 +1,12 @@
+-- Deletion time of achievements in the trash; rows are purged after the retention window
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- rows deleted before the trash existed have no MongoDB document left, let the purge job remove them
+UPDATE achievement_references
+SET deleted_at = updated_at
+WHERE status = 'deleted' AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_deleted_at
+    ON achievement_references (deleted_at)
+    WHERE status = 'deleted';
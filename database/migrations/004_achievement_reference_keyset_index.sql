@@ -0,0 +1,4 @@
+-- Supports keyset pagination of GET /achievements on (created_at, id)
+CREATE INDEX IF NOT EXISTS idx_achievement_references_created_id
+    ON achievement_references (created_at DESC, id DESC)
+    WHERE status != 'deleted';
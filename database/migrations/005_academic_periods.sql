@@ -0,0 +1,38 @@
+-- Academic periods (semesters); achievements are assigned to the period of their event date
+CREATE TABLE IF NOT EXISTS academic_periods (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                VARCHAR(100) NOT NULL,
+    academic_year       VARCHAR(9) NOT NULL,
+    semester            VARCHAR(10) NOT NULL CHECK (semester IN ('ganjil', 'genap', 'pendek')),
+    start_date          DATE NOT NULL,
+    end_date            DATE NOT NULL,
+    submission_deadline TIMESTAMP,
+    is_active           BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (start_date <= end_date),
+    UNIQUE (academic_year, semester)
+);
+
+-- at most one period is marked as the current one
+CREATE UNIQUE INDEX IF NOT EXISTS idx_academic_periods_active
+    ON academic_periods (is_active)
+    WHERE is_active;
+
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS period_id UUID REFERENCES academic_periods(id);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_period
+    ON achievement_references (period_id);
+
+INSERT INTO permissions (id, name, resource, action, description)
+SELECT gen_random_uuid(), 'manage:academic_periods', 'academic_periods', 'manage', 'Create and update academic periods and their submission deadlines'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'manage:academic_periods');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE LOWER(r.name) = 'admin' AND p.name = 'manage:academic_periods'
+  AND NOT EXISTS (
+      SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  );
@@ -0,0 +1,168 @@
+-- Faculties, departments and program studies replace the free-text
+-- students.program_study and lecturers.department columns
+CREATE TABLE IF NOT EXISTS faculties (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code       VARCHAR(50) NOT NULL UNIQUE,
+    name       VARCHAR(150) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS departments (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    faculty_id UUID NOT NULL REFERENCES faculties(id),
+    code       VARCHAR(50) NOT NULL UNIQUE,
+    name       VARCHAR(150) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS program_studies (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    department_id UUID NOT NULL REFERENCES departments(id),
+    code          VARCHAR(50) NOT NULL UNIQUE,
+    name          VARCHAR(150) NOT NULL,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_departments_faculty ON departments (faculty_id);
+CREATE INDEX IF NOT EXISTS idx_program_studies_department ON program_studies (department_id);
+
+-- the old text columns stay for older clients but are no longer authoritative
+ALTER TABLE students
+    ADD COLUMN IF NOT EXISTS program_study_id UUID REFERENCES program_studies(id);
+ALTER TABLE lecturers
+    ADD COLUMN IF NOT EXISTS department_id UUID REFERENCES departments(id);
+
+CREATE INDEX IF NOT EXISTS idx_students_program_study ON students (program_study_id);
+CREATE INDEX IF NOT EXISTS idx_lecturers_department ON lecturers (department_id);
+
+-- Map the existing strings. Values that only differ in case or spacing become one
+-- entity; variants such as "Informatika" and "Teknik Informatika" are left apart
+-- and can be joined with POST /program-studies/:id/merge. Everything is placed
+-- under an "unmapped" faculty and department until an admin moves it. The
+-- sentinels below are skipped when the migration is run again.
+INSERT INTO faculties (code, name)
+VALUES ('UNMAPPED', 'Belum Dipetakan')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO departments (faculty_id, code, name)
+SELECT f.id, 'UNMAPPED', 'Belum Dipetakan'
+FROM faculties f
+WHERE f.code = 'UNMAPPED'
+ON CONFLICT (code) DO NOTHING;
+
+-- Codes are derived from the names. Names that derive the same code, or a code
+-- that is already taken, get a numeric suffix (TEKNIK-INFORMATIKA-2). If a code
+-- still clashes the migration stops and lists the names to rename first.
+CREATE TEMP TABLE new_departments AS
+SELECT name, CASE WHEN seq = 1 THEN base ELSE base || '-' || seq END AS code
+FROM (
+    SELECT b.name, b.base,
+           ROW_NUMBER() OVER (PARTITION BY b.base ORDER BY b.name)
+           + COALESCE((
+               SELECT MAX(CASE WHEN d.code = b.base THEN 1 ELSE SUBSTRING(d.code FROM LENGTH(b.base) + 2)::INTEGER END)
+               FROM departments d
+               WHERE d.code = b.base OR d.code ~ ('^' || b.base || '-[0-9]{1,4}$')
+           ), 0) AS seq
+    FROM (
+        SELECT v.name,
+               COALESCE(NULLIF(TRIM(BOTH '-' FROM LEFT(UPPER(REGEXP_REPLACE(v.name, '[^A-Za-z0-9]+', '-', 'g')), 45)), ''), 'DEPARTMENT') AS base
+        FROM (
+            SELECT MIN(REGEXP_REPLACE(TRIM(department), '\s+', ' ', 'g')) AS name
+            FROM lecturers
+            WHERE department_id IS NULL AND TRIM(COALESCE(department, '')) <> ''
+            GROUP BY LOWER(REGEXP_REPLACE(TRIM(department), '\s+', ' ', 'g'))
+        ) v
+        WHERE NOT EXISTS (SELECT 1 FROM departments d WHERE LOWER(d.name) = LOWER(v.name))
+    ) b
+) s;
+
+DO $$
+DECLARE
+    clashes TEXT;
+BEGIN
+    SELECT STRING_AGG(n.name || ' (' || n.code || ')', ', ' ORDER BY n.code) INTO clashes
+    FROM new_departments n
+    WHERE EXISTS (SELECT 1 FROM departments d WHERE d.code = n.code)
+       OR (SELECT COUNT(*) FROM new_departments o WHERE o.code = n.code) > 1;
+    IF clashes IS NOT NULL THEN
+        RAISE EXCEPTION 'department codes clash, rename these lecturer departments first: %', clashes;
+    END IF;
+END $$;
+
+INSERT INTO departments (faculty_id, code, name)
+SELECT f.id, n.code, n.name
+FROM new_departments n, faculties f
+WHERE f.code = 'UNMAPPED';
+
+DROP TABLE new_departments;
+
+UPDATE lecturers l
+SET department_id = d.id
+FROM departments d
+WHERE l.department_id IS NULL
+  AND LOWER(REGEXP_REPLACE(TRIM(l.department), '\s+', ' ', 'g')) = LOWER(d.name)
+  AND d.code <> 'UNMAPPED';
+
+CREATE TEMP TABLE new_program_studies AS
+SELECT name, CASE WHEN seq = 1 THEN base ELSE base || '-' || seq END AS code
+FROM (
+    SELECT b.name, b.base,
+           ROW_NUMBER() OVER (PARTITION BY b.base ORDER BY b.name)
+           + COALESCE((
+               SELECT MAX(CASE WHEN p.code = b.base THEN 1 ELSE SUBSTRING(p.code FROM LENGTH(b.base) + 2)::INTEGER END)
+               FROM program_studies p
+               WHERE p.code = b.base OR p.code ~ ('^' || b.base || '-[0-9]{1,4}$')
+           ), 0) AS seq
+    FROM (
+        SELECT v.name,
+               COALESCE(NULLIF(TRIM(BOTH '-' FROM LEFT(UPPER(REGEXP_REPLACE(v.name, '[^A-Za-z0-9]+', '-', 'g')), 45)), ''), 'PROGRAM') AS base
+        FROM (
+            SELECT MIN(REGEXP_REPLACE(TRIM(program_study), '\s+', ' ', 'g')) AS name
+            FROM students
+            WHERE program_study_id IS NULL AND TRIM(COALESCE(program_study, '')) <> ''
+            GROUP BY LOWER(REGEXP_REPLACE(TRIM(program_study), '\s+', ' ', 'g'))
+        ) v
+        WHERE NOT EXISTS (SELECT 1 FROM program_studies p WHERE LOWER(p.name) = LOWER(v.name))
+    ) b
+) s;
+
+DO $$
+DECLARE
+    clashes TEXT;
+BEGIN
+    SELECT STRING_AGG(n.name || ' (' || n.code || ')', ', ' ORDER BY n.code) INTO clashes
+    FROM new_program_studies n
+    WHERE EXISTS (SELECT 1 FROM program_studies p WHERE p.code = n.code)
+       OR (SELECT COUNT(*) FROM new_program_studies o WHERE o.code = n.code) > 1;
+    IF clashes IS NOT NULL THEN
+        RAISE EXCEPTION 'program study codes clash, rename these student program studies first: %', clashes;
+    END IF;
+END $$;
+
+INSERT INTO program_studies (department_id, code, name)
+SELECT d.id, n.code, n.name
+FROM new_program_studies n, departments d
+WHERE d.code = 'UNMAPPED';
+
+DROP TABLE new_program_studies;
+
+UPDATE students s
+SET program_study_id = p.id
+FROM program_studies p
+WHERE s.program_study_id IS NULL
+  AND LOWER(REGEXP_REPLACE(TRIM(s.program_study), '\s+', ' ', 'g')) = LOWER(p.name);
+
+INSERT INTO permissions (id, name, resource, action, description)
+SELECT gen_random_uuid(), 'manage:organization', 'organization', 'manage', 'Manage faculties, departments and program studies'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'manage:organization');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE LOWER(r.name) = 'admin' AND p.name = 'manage:organization'
+  AND NOT EXISTS (
+      SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  );
@@ -0,0 +1,32 @@
+-- Coordinators oversee the achievements, students and reports of the departments
+-- and program studies in their scope
+CREATE TABLE IF NOT EXISTS coordinator_scopes (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id          UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    department_id    UUID REFERENCES departments(id) ON DELETE CASCADE,
+    program_study_id UUID REFERENCES program_studies(id) ON DELETE CASCADE,
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (num_nonnulls(department_id, program_study_id) = 1),
+    UNIQUE (user_id, department_id),
+    UNIQUE (user_id, program_study_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_coordinator_scopes_user ON coordinator_scopes (user_id);
+
+INSERT INTO roles (id, name, description)
+SELECT gen_random_uuid(), 'Coordinator', 'Department or program study coordinator with oversight limited to their scope'
+WHERE NOT EXISTS (SELECT 1 FROM roles WHERE LOWER(name) = 'coordinator');
+
+-- holders only see the students of their coordinator scope
+INSERT INTO permissions (id, name, resource, action, description)
+SELECT gen_random_uuid(), 'oversight:scoped', 'oversight', 'scoped', 'Limit achievement, student and report access to the coordinator scope'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'oversight:scoped');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE LOWER(r.name) = 'coordinator'
+  AND p.name IN ('oversight:scoped', 'achievement:read', 'manage:students', 'manage:lecturers', 'report:students')
+  AND NOT EXISTS (
+      SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  );
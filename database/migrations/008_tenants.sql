@@ -0,0 +1,76 @@
+-- Tenants let several faculties or campuses share one deployment. Every user,
+-- student, lecturer, achievement reference and organization unit belongs to a
+-- tenant; roles without a tenant are shared by all of them.
+CREATE TABLE IF NOT EXISTS tenants (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code       VARCHAR(50) NOT NULL UNIQUE,
+    name       VARCHAR(150) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- existing data moves into the default tenant; the id is fixed so the API can
+-- backfill MongoDB documents with it
+INSERT INTO tenants (id, code, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'DEFAULT', 'Default')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+UPDATE users SET tenant_id = '00000000-0000-0000-0000-000000000001' WHERE tenant_id IS NULL;
+ALTER TABLE users ALTER COLUMN tenant_id SET NOT NULL;
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+UPDATE students s SET tenant_id = u.tenant_id FROM users u WHERE u.id = s.user_id AND s.tenant_id IS NULL;
+ALTER TABLE students ALTER COLUMN tenant_id SET NOT NULL;
+
+ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+UPDATE lecturers l SET tenant_id = u.tenant_id FROM users u WHERE u.id = l.user_id AND l.tenant_id IS NULL;
+ALTER TABLE lecturers ALTER COLUMN tenant_id SET NOT NULL;
+
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+UPDATE achievement_references ar SET tenant_id = s.tenant_id FROM students s WHERE s.id = ar.student_id AND ar.tenant_id IS NULL;
+ALTER TABLE achievement_references ALTER COLUMN tenant_id SET NOT NULL;
+
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+
+ALTER TABLE faculties ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+UPDATE faculties SET tenant_id = '00000000-0000-0000-0000-000000000001' WHERE tenant_id IS NULL;
+ALTER TABLE faculties ALTER COLUMN tenant_id SET NOT NULL;
+
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+UPDATE departments d SET tenant_id = f.tenant_id FROM faculties f WHERE f.id = d.faculty_id AND d.tenant_id IS NULL;
+ALTER TABLE departments ALTER COLUMN tenant_id SET NOT NULL;
+
+ALTER TABLE program_studies ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id);
+UPDATE program_studies p SET tenant_id = d.tenant_id FROM departments d WHERE d.id = p.department_id AND p.tenant_id IS NULL;
+ALTER TABLE program_studies ALTER COLUMN tenant_id SET NOT NULL;
+
+-- unit codes only have to be unique within a tenant
+ALTER TABLE faculties DROP CONSTRAINT IF EXISTS faculties_code_key;
+ALTER TABLE departments DROP CONSTRAINT IF EXISTS departments_code_key;
+ALTER TABLE program_studies DROP CONSTRAINT IF EXISTS program_studies_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_faculties_tenant_code ON faculties (tenant_id, code);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_departments_tenant_code ON departments (tenant_id, code);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_program_studies_tenant_code ON program_studies (tenant_id, code);
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_students_tenant ON students (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_lecturers_tenant ON lecturers (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_tenant ON achievement_references (tenant_id, created_at);
+
+-- holders are not bound to the tenant of their token
+INSERT INTO permissions (id, name, resource, action, description)
+SELECT gen_random_uuid(), 'tenant:all', 'tenant', 'all', 'Work across tenants and manage tenants'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'tenant:all');
+
+INSERT INTO roles (id, name, description)
+SELECT gen_random_uuid(), 'Super Admin', 'Administers every tenant'
+WHERE NOT EXISTS (SELECT 1 FROM roles WHERE LOWER(name) = 'super admin');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE LOWER(r.name) = 'super admin'
+  AND NOT EXISTS (
+      SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  );
@@ -0,0 +1,26 @@
+-- Advisor assignments keep who advised a student and when. students.advisor_id
+-- stays the current advisor and always matches the open assignment.
+CREATE TABLE IF NOT EXISTS advisor_assignments (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_id     UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    lecturer_id    UUID NOT NULL REFERENCES lecturers(id),
+    tenant_id      UUID NOT NULL REFERENCES tenants(id),
+    assigned_by    UUID REFERENCES users(id) ON DELETE SET NULL,
+    effective_from TIMESTAMP NOT NULL DEFAULT NOW(),
+    effective_to   TIMESTAMP,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+-- at most one open assignment per student
+CREATE UNIQUE INDEX IF NOT EXISTS uq_advisor_assignments_open ON advisor_assignments (student_id) WHERE effective_to IS NULL;
+CREATE INDEX IF NOT EXISTS idx_advisor_assignments_student ON advisor_assignments (student_id, effective_from DESC);
+CREATE INDEX IF NOT EXISTS idx_advisor_assignments_lecturer ON advisor_assignments (lecturer_id) WHERE effective_to IS NULL;
+
+-- current advisors become open assignments; when they started is unknown, so
+-- they count from the student's creation
+INSERT INTO advisor_assignments (student_id, lecturer_id, tenant_id, effective_from)
+SELECT s.id, s.advisor_id, s.tenant_id, s.created_at
+FROM students s
+WHERE s.advisor_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM advisor_assignments a WHERE a.student_id = s.id);
@@ -0,0 +1,25 @@
+-- File types students may upload as attachments and their size limits. Types
+-- are detected from the file content; codes match utils.FileTypes.
+CREATE TABLE IF NOT EXISTS attachment_types (
+    code           VARCHAR(20) PRIMARY KEY,
+    max_size_bytes BIGINT NOT NULL CHECK (max_size_bytes > 0),
+    updated_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO attachment_types (code, max_size_bytes)
+SELECT v.code, v.max_size_bytes
+FROM (VALUES ('pdf', 10485760), ('png', 5242880), ('jpeg', 5242880)) AS v(code, max_size_bytes)
+WHERE NOT EXISTS (SELECT 1 FROM attachment_types);
+
+-- the allowlist applies to every tenant, so only super admins change it
+INSERT INTO permissions (id, name, resource, action, description)
+SELECT gen_random_uuid(), 'manage:attachment_types', 'attachment_types', 'manage', 'Choose which file types may be uploaded and their size limits'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'manage:attachment_types');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE LOWER(r.name) = 'super admin' AND p.name = 'manage:attachment_types'
+  AND NOT EXISTS (
+      SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  );
@@ -0,0 +1,13 @@
+-- Messages for a user, such as an upload removed by the malware scan
+CREATE TABLE IF NOT EXISTS notifications (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind           VARCHAR(50) NOT NULL,
+    message        TEXT NOT NULL,
+    achievement_id UUID REFERENCES achievement_references(id) ON DELETE SET NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at        TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user
+    ON notifications (user_id, created_at DESC);
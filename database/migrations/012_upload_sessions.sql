@@ -0,0 +1,20 @@
+-- Resumable (tus) uploads that have not been completed yet. The received
+-- bytes are kept in the staging directory under the session id.
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    file_name      TEXT NOT NULL,
+    caption        TEXT NOT NULL DEFAULT '',
+    kind           VARCHAR(50) NOT NULL DEFAULT '',
+    upload_length  BIGINT NOT NULL CHECK (upload_length > 0),
+    upload_offset  BIGINT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at     TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_user
+    ON upload_sessions (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires
+    ON upload_sessions (expires_at);
@@ -0,0 +1,10 @@
+-- Attachment files stored once by their SHA-256; ref_count is the number of
+-- attachments using the file, which is deleted when it reaches zero
+CREATE TABLE IF NOT EXISTS attachment_blobs (
+    checksum     CHAR(64) PRIMARY KEY,
+    storage_key  TEXT NOT NULL,
+    size_bytes   BIGINT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    ref_count    INT NOT NULL CHECK (ref_count >= 0),
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,12 @@
+-- Report of the students using the most attachment storage
+INSERT INTO permissions (id, name, resource, action, description)
+SELECT gen_random_uuid(), 'report:storage', 'reports', 'storage', 'View the students using the most attachment storage'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'report:storage');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE LOWER(r.name) IN ('admin', 'super admin') AND p.name = 'report:storage'
+  AND NOT EXISTS (
+      SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  );
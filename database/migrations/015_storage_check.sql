@@ -0,0 +1,12 @@
+-- Checking and repairing attachment storage, which all tenants share
+INSERT INTO permissions (id, name, resource, action, description)
+SELECT gen_random_uuid(), 'manage:storage', 'storage', 'manage', 'Check attachment storage against the database and repair it'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'manage:storage');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE LOWER(r.name) = 'super admin' AND p.name = 'manage:storage'
+  AND NOT EXISTS (
+      SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id
+  );
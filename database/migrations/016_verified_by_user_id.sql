@@ -0,0 +1,6 @@
+-- verified_by holds the id of the user who verified or rejected an achievement.
+-- Verifications used to record the lecturer id instead; map those to the lecturer's user.
+UPDATE achievement_references r
+SET verified_by = l.user_id
+FROM lecturers l
+WHERE r.verified_by = l.id;
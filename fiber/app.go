@@ -2,18 +2,23 @@ package fiber
 import (
 	"github.com/gofiber/fiber/v2"
 	"fmt"
+	"StudenAchievementReportingSystem/utils"
 	
 	
 )
 
 func SetupFiber() *fiber.App {
 	app := fiber.New(fiber.Config{
-		BodyLimit: 10 * 1024 * 1024,
+		BodyLimit: utils.MaxRequestSize,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
 				code = e.Code
 			}
+			// bodies over the limit are refused before reaching a handler
+			if code == fiber.StatusRequestEntityTooLarge {
+				err = fmt.Errorf("Request body is larger than %d bytes: the files of one upload may total at most %d bytes, use resumable uploads for larger files", utils.MaxRequestSize, utils.MaxUploadSize)
+			}
 			
 			return c.Status(code).JSON(fiber.Map{
 				"error":   true,
@@ -1,14 +1,22 @@
 package route
 
 import (
+    "context"
     "database/sql"
+    "log"
     "github.com/gofiber/fiber/v2"
+    modelPg "StudenAchievementReportingSystem/app/models/postgresql"
     repoMongo "StudenAchievementReportingSystem/app/repository/mongodb"
     repoPostgre "StudenAchievementReportingSystem/app/repository/postgresql"
     mongoService "StudenAchievementReportingSystem/app/service/mongodb"
     postgreService "StudenAchievementReportingSystem/app/service/postgresql"
+    "StudenAchievementReportingSystem/app/preview"
+    "StudenAchievementReportingSystem/app/scanner"
+    "StudenAchievementReportingSystem/app/storage"
+    "StudenAchievementReportingSystem/config"
     "StudenAchievementReportingSystem/database"
     "StudenAchievementReportingSystem/middleware"
+    "StudenAchievementReportingSystem/utils"
 )
 
 func SetupPostgresRoutes(app *fiber.App, db *sql.DB) {
@@ -18,20 +26,104 @@ func SetupPostgresRoutes(app *fiber.App, db *sql.DB) {
     studentRepo := repoPostgre.NewStudentRepository(db)
     lecturerRepo := repoPostgre.NewLecturerRepository(db)
     achRepoPg := repoPostgre.NewAchievementRepoPostgres(db)
+    periodRepo := repoPostgre.NewAcademicPeriodRepository(db)
+    orgRepo := repoPostgre.NewOrganizationRepository(db)
+    tenantRepo := repoPostgre.NewTenantRepository(db)
+    attachmentTypeRepo := repoPostgre.NewAttachmentTypeRepository(db)
+    notificationRepo := repoPostgre.NewNotificationRepository(db)
+    uploadSessionRepo := repoPostgre.NewUploadSessionRepository(db)
+    blobRepo := repoPostgre.NewAttachmentBlobRepository(db)
     achRepoMongo := repoMongo.NewAchievementRepository(database.MongoDB)
+    achTypeRepo := repoMongo.NewAchievementTypeRepository(database.MongoDB)
+    if err := repoMongo.EnsureAchievementIndexes(context.Background(), database.MongoDB); err != nil {
+        log.Printf("failed to create achievement indexes: %v", err)
+    }
+    if err := repoMongo.BackfillTenant(context.Background(), database.MongoDB, modelPg.DefaultTenantID.String()); err != nil {
+        log.Printf("failed to backfill achievement tenants: %v", err)
+    }
+    if err := repoMongo.BackfillDuplicateKeys(context.Background(), database.MongoDB); err != nil {
+        log.Printf("failed to backfill achievement duplicate keys: %v", err)
+    }
+
+    if err := config.LoadSignedURL().Validate(); err != nil {
+        log.Fatalf("signed attachment links: %v", err)
+    }
+    storageCfg := config.LoadStorage()
+    files, err := storage.FromConfig(storageCfg)
+    if err != nil {
+        log.Fatalf("attachment storage: %v", err)
+    }
+    scannerCfg := config.LoadScanner()
+    malwareScanner, err := scanner.FromConfig(scannerCfg)
+    if err != nil {
+        log.Fatalf("malware scanner: %v", err)
+    }
+    if err := repoMongo.BackfillStorageKeys(context.Background(), database.MongoDB); err != nil {
+        log.Printf("failed to backfill attachment storage keys: %v", err)
+    }
+    previewCfg := config.LoadPreview()
+    previews, err := preview.FromConfig(previewCfg)
+    if err != nil {
+        log.Fatalf("attachment previews: %v", err)
+    }
+    if err := repoMongo.BackfillPreviews(context.Background(), database.MongoDB, []string{"application/pdf", "image/png", "image/jpeg", "image/gif"}); err != nil {
+        log.Printf("failed to backfill attachment previews: %v", err)
+    }
 
     // Services
     authService := postgreService.NewAuthService(userRepo)
     adminService := postgreService.NewAdminService(adminRepo, userRepo)
     lecturerService := postgreService.NewLecturerService(lecturerRepo)
-    studentService := postgreService.NewStudentService(studentRepo, achRepoMongo)
-    achievementService := mongoService.NewAchievementService(achRepoMongo, achRepoPg, lecturerRepo)
-	reportService := mongoService.NewReportService(achRepoMongo, studentRepo)
+    studentService := postgreService.NewStudentService(studentRepo, achRepoMongo, orgRepo)
+    periodService := postgreService.NewAcademicPeriodService(periodRepo)
+    orgService := postgreService.NewOrganizationService(orgRepo)
+    tenantService := postgreService.NewTenantService(tenantRepo)
+    attachmentTypeService := postgreService.NewAttachmentTypeService(attachmentTypeRepo)
+    notificationService := postgreService.NewNotificationService(notificationRepo)
+    achievementService := mongoService.NewAchievementService(mongoService.AchievementDeps{
+        Achievements:    achRepoMongo,
+        References:      achRepoPg,
+        Lecturers:       lecturerRepo,
+        Types:           achTypeRepo,
+        Students:        studentRepo,
+        Periods:         periodRepo,
+        Organization:    orgRepo,
+        Files:           files,
+        AttachmentTypes: attachmentTypeRepo,
+        Scanner:         malwareScanner,
+        Notifications:   notificationRepo,
+        Blobs:           blobRepo,
+        Previews:        previews,
+    })
+    uploadCfg := config.LoadResumableUploads()
+    uploadService := mongoService.NewUploadService(achievementService, uploadSessionRepo, storage.NewStaging(uploadCfg.StagingDir), uploadCfg.Expiry)
+    achievementTypeService := mongoService.NewAchievementTypeService(achTypeRepo)
+	reportService := mongoService.NewReportService(achRepoMongo, studentRepo, achRepoPg, periodRepo, orgRepo)
+
+    // Background jobs work across tenants, on the instance they are enabled for
+    if config.LoadJobs().Enabled {
+        jobs := utils.WithAllTenants(context.Background())
+        go func() {
+            if _, err := achievementService.BackfillAttachmentSizes(jobs); err != nil {
+                log.Printf("failed to backfill attachment sizes: %v", err)
+            }
+        }()
+        go achievementService.RunTrashPurge(jobs, config.LoadTrash())
+        go achievementService.RunPeriodAssignment(jobs, config.LoadPeriods())
+        go achievementService.RunAttachmentScan(jobs, scannerCfg)
+        go uploadService.RunUploadCleanup(jobs, uploadCfg)
+        go achievementService.RunPreviewGeneration(jobs, previewCfg)
+        go achievementService.RunStorageCheck(jobs, config.LoadStorageCheck())
+    } else {
+        log.Printf("background jobs are disabled on this instance (JOBS_ENABLED=false)")
+    }
 
-    // Static Files Config
-    app.Static("/uploads", "./uploads")   
     api := app.Group("/api/v1")
 
+    // Attachments behind signed links, which authorize the request themselves
+    api.Get("/files/achievements/:id/attachments/:attachmentId", achievementService.DownloadSignedAttachment)
+    api.Get("/files/achievements/:id/attachments/:attachmentId/preview", achievementService.DownloadSignedPreview)
+
     // 5.1 Authentication
     auth := api.Group("/auth")
     auth.Post("/login", authService.Login)
@@ -51,16 +143,98 @@ func SetupPostgresRoutes(app *fiber.App, db *sql.DB) {
     // 5.4 Achievements
     ach := api.Group("/achievements", middleware.AuthRequired())
     ach.Get("/", achievementService.GetAllAchievements)
+    ach.Get("/trash", achievementService.GetTrash)
     ach.Get("/:id", achievementService.GetAchievementDetail)
     ach.Get("/:id/history", achievementService.GetAchievementHistory)
+    ach.Get("/:id/versions", achievementService.GetAchievementVersions)
+    ach.Get("/:id/versions/diff", achievementService.DiffAchievementVersions)
+    ach.Get("/:id/versions/:v", achievementService.GetAchievementVersion)
     ach.Post("/", achievementService.CreateAchievement) 
     ach.Put("/:id", achievementService.UpdateAchievement)
+    ach.Patch("/:id", achievementService.PatchAchievement)
     ach.Delete("/:id",  achievementService.DeleteAchievement)
+    ach.Post("/:id/restore", achievementService.RestoreAchievement)
     ach.Post("/:id/submit", achievementService.SubmitAchievement)
     ach.Post("/:id/attachments", achievementService.UploadAttachments)
+    ach.Put("/:id/attachments/order", achievementService.ReorderAttachments)
+    ach.Get("/:id/attachments/:attachmentId", achievementService.DownloadAttachment)
+    ach.Get("/:id/attachments/:attachmentId/preview", achievementService.DownloadPreview)
+    ach.Put("/:id/attachments/:attachmentId", achievementService.ReplaceAttachment)
+    ach.Patch("/:id/attachments/:attachmentId", achievementService.UpdateAttachment)
+    ach.Delete("/:id/attachments/:attachmentId", achievementService.DeleteAttachment)
+    ach.Options("/:id/uploads", uploadService.UploadOptions)
+    ach.Post("/:id/uploads", uploadService.CreateUpload)
+    ach.Head("/:id/uploads/:uploadId", uploadService.UploadStatus)
+    ach.Patch("/:id/uploads/:uploadId", uploadService.UploadChunk)
+    ach.Delete("/:id/uploads/:uploadId", uploadService.CancelUpload)
     ach.Post("/:id/verify", achievementService.VerifyAchievement)
     ach.Post("/:id/reject", achievementService.RejectAchievement)
 
+    // 5.4.1 Achievement Types
+    achTypes := api.Group("/achievement-types", middleware.AuthRequired())
+    achTypes.Get("/", achievementTypeService.GetAllTypes)
+    achTypes.Get("/:code", achievementTypeService.GetTypeByCode)
+    achTypes.Post("/", achievementTypeService.CreateType)
+    achTypes.Put("/:code", achievementTypeService.UpdateType)
+    achTypes.Delete("/:code", achievementTypeService.DeleteType)
+
+    // 5.4.1.1 Attachment Types
+    attachmentTypes := api.Group("/attachment-types", middleware.AuthRequired())
+    attachmentTypes.Get("/", attachmentTypeService.GetAttachmentTypes)
+    attachmentTypes.Put("/:code", attachmentTypeService.AllowAttachmentType)
+    attachmentTypes.Delete("/:code", attachmentTypeService.DisallowAttachmentType)
+
+    // 5.4.2 Academic Periods
+    periods := api.Group("/academic-periods", middleware.AuthRequired())
+    periods.Get("/", periodService.GetAllPeriods)
+    periods.Get("/:id", periodService.GetPeriodByID)
+    periods.Post("/", periodService.CreatePeriod)
+    periods.Put("/:id", periodService.UpdatePeriod)
+    periods.Delete("/:id", periodService.DeletePeriod)
+
+    // 5.4.3 Organization
+    faculties := api.Group("/faculties", middleware.AuthRequired())
+    faculties.Get("/", orgService.GetFaculties)
+    faculties.Get("/:id", orgService.GetFacultyByID)
+    faculties.Post("/", orgService.CreateFaculty)
+    faculties.Put("/:id", orgService.UpdateFaculty)
+    faculties.Delete("/:id", orgService.DeleteFaculty)
+
+    departments := api.Group("/departments", middleware.AuthRequired())
+    departments.Get("/", orgService.GetDepartments)
+    departments.Get("/:id", orgService.GetDepartmentByID)
+    departments.Post("/", orgService.CreateDepartment)
+    departments.Put("/:id", orgService.UpdateDepartment)
+    departments.Delete("/:id", orgService.DeleteDepartment)
+    departments.Post("/:id/merge", orgService.MergeDepartments)
+
+    programStudies := api.Group("/program-studies", middleware.AuthRequired())
+    programStudies.Get("/", orgService.GetProgramStudies)
+    programStudies.Get("/:id", orgService.GetProgramStudyByID)
+    programStudies.Post("/", orgService.CreateProgramStudy)
+    programStudies.Put("/:id", orgService.UpdateProgramStudy)
+    programStudies.Delete("/:id", orgService.DeleteProgramStudy)
+    programStudies.Post("/:id/merge", orgService.MergeProgramStudies)
+
+    coordinators := api.Group("/coordinators", middleware.AuthRequired())
+    coordinators.Get("/:userId/scope", orgService.GetCoordinatorScope)
+    coordinators.Put("/:userId/scope", orgService.SetCoordinatorScope)
+
+    tenants := api.Group("/tenants", middleware.AuthRequired())
+    tenants.Get("/", tenantService.GetTenants)
+    tenants.Post("/", tenantService.CreateTenant)
+
+    storageAdmin := api.Group("/storage", middleware.AuthRequired())
+    storageAdmin.Get("/check", achievementService.GetStorageCheck)
+    storageAdmin.Post("/check/repair", achievementService.RepairStorage)
+
+    me := api.Group("/me", middleware.AuthRequired())
+    me.Get("/storage", achievementService.GetMyStorage)
+
+    notifications := api.Group("/notifications", middleware.AuthRequired())
+    notifications.Get("/", notificationService.GetNotifications)
+    notifications.Post("/:id/read", notificationService.MarkNotificationRead)
+
     // 5.5 Students & Lecturers
     student := api.Group("/students", middleware.AuthRequired())
     lecturer := api.Group("/lecturers", middleware.AuthRequired())
@@ -68,6 +242,8 @@ func SetupPostgresRoutes(app *fiber.App, db *sql.DB) {
     student.Get("/:id",   studentService.GetStudentByID)
     student.Get("/:id/achievements", studentService.GetStudentAchievements)
     student.Put("/:id/advisor",   studentService.UpdateAdvisor)
+    student.Get("/:id/advisors", studentService.GetAdvisorHistory)
+    student.Post("/advisors/reassign", studentService.ReassignAdvisors)
     lecturer.Get("/",   lecturerService.GetAllLecturers)
     lecturer.Get("/:id/advisees", lecturerService.GetAdvisees)
 
@@ -75,5 +251,8 @@ func SetupPostgresRoutes(app *fiber.App, db *sql.DB) {
 	reports := api.Group("/reports", middleware.AuthRequired())    
 	reports.Get("/statistics", reportService.GetStatistics)
 	reports.Get("/student/:id", reportService.GetStudentReport)
+	reports.Get("/organization", reportService.GetOrganizationReport)
+	reports.Get("/duplicates", reportService.GetDuplicateReport)
+	reports.Get("/storage", reportService.GetStorageReport)
 }
 
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+)
+
+// diffIgnored are bookkeeping and review fields that do not belong to the claim itself
+var diffIgnored = map[string]bool{
+	"id":                true,
+	"points":            true,
+	"periodId":          true,
+	"duplicateWarnings": true,
+	"version":           true,
+	"verifiedVersion":   true,
+	"verifiedHash":      true,
+	"createdAt":         true,
+	"updatedAt":         true,
+}
+
+// DiffAchievements returns the fields that differ between two versions of an
+// achievement, using the JSON field names joined with dots
+// (e.g. "details.rank", "attachments.0.fileName"). Results are sorted by field.
+func DiffAchievements(from, to modelMongo.Achievement) []modelMongo.FieldChange {
+	a, b := flattenAchievement(from), flattenAchievement(to)
+
+	changes := []modelMongo.FieldChange{}
+	for field, old := range a {
+		if nv, ok := b[field]; !ok || !reflect.DeepEqual(old, nv) {
+			changes = append(changes, modelMongo.FieldChange{Field: field, Old: old, New: b[field]})
+		}
+	}
+	for field, nv := range b {
+		if _, ok := a[field]; !ok {
+			changes = append(changes, modelMongo.FieldChange{Field: field, Old: nil, New: nv})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func flattenAchievement(a modelMongo.Achievement) map[string]interface{} {
+	raw, _ := json.Marshal(a)
+	var doc map[string]interface{}
+	_ = json.Unmarshal(raw, &doc)
+
+	out := make(map[string]interface{})
+	for k, v := range doc {
+		if !diffIgnored[k] {
+			flatten(k, v, out)
+		}
+	}
+	return out
+}
+
+func flatten(path string, v interface{}, out map[string]interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			flatten(path+"."+k, child, out)
+		}
+	case []interface{}:
+		for i, child := range val {
+			flatten(path+"."+strconv.Itoa(i), child, out)
+		}
+	case nil:
+		// absent and null are the same for a diff
+	default:
+		out[path] = val
+	}
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"strings"
+	"time"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+)
+
+const (
+	TypeCompetition   = "competition"
+	TypePublication   = "publication"
+	TypeOrganization  = "organization"
+	TypeCertification = "certification"
+	TypeOther         = "other"
+)
+
+var (
+	AchievementTypes  = []string{TypeCompetition, TypePublication, TypeOrganization, TypeCertification, TypeOther}
+	CompetitionLevels = []string{"international", "national", "regional", "local"}
+	MedalTypes        = []string{"gold", "silver", "bronze"}
+	PublicationTypes  = []string{"journal", "conference", "book"}
+	AttachmentKinds   = []string{"certificate", "photo", "assignment_letter", "other"}
+)
+
+// FieldError describes a single invalid field in a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type fieldErrors []FieldError
+
+func (e *fieldErrors) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+func (e *fieldErrors) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.add(field, "is required")
+	}
+}
+
+func (e *fieldErrors) oneOf(field, value string, allowed []string) {
+	if value != "" && !contains(allowed, value) {
+		e.add(field, "must be one of: "+strings.Join(allowed, ", "))
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAttachmentInfo checks the caption and kind of an attachment. prefix
+// is put before the field names, e.g. "files[1]."
+func ValidateAttachmentInfo(prefix, caption, kind string) []FieldError {
+	errs := fieldErrors{}
+	if len([]rune(caption)) > 200 {
+		errs.add(prefix+"caption", "must be at most 200 characters")
+	}
+	errs.oneOf(prefix+"kind", kind, AttachmentKinds)
+	return errs
+}
+
+// ValidateRequiredAttachments checks that an achievement has an attachment of
+// every kind its type requires. def is the registry entry for the type (nil if
+// none). Files removed by the malware scan or missing from storage do not count.
+func ValidateRequiredAttachments(a modelMongo.Achievement, def *modelMongo.AchievementTypeDefinition) []FieldError {
+	errs := fieldErrors{}
+	if def == nil {
+		return errs
+	}
+	for _, kind := range def.RequiredAttachmentKinds {
+		found := false
+		for _, att := range a.Attachments {
+			if strings.EqualFold(att.Kind, kind) && att.MissingAt == nil && (att.Scan == nil || att.Scan.Status != modelMongo.ScanInfected) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs.add("attachments", "needs an attachment of kind "+kind)
+		}
+	}
+	return errs
+}
+
+// IsBuiltInType reports whether code is one of the types with typed AchievementDetails
+func IsBuiltInType(code string) bool {
+	return contains(AchievementTypes, code)
+}
+
+// ValidateAchievement checks the common fields of an achievement and the
+// details required by its achievement type. def is the registry entry for the
+// type (nil if none); custom types must have an active entry, and when the
+// entry carries a schema the custom fields are validated against it.
+// An empty result means valid.
+func ValidateAchievement(a modelMongo.Achievement, def *modelMongo.AchievementTypeDefinition) []FieldError {
+	errs := fieldErrors{}
+
+	errs.required("title", a.Title)
+	errs.required("achievementType", a.AchievementType)
+	if a.AchievementType == "" {
+		return errs
+	}
+
+	if def != nil && !def.IsActive {
+		def = nil
+	}
+	if !IsBuiltInType(a.AchievementType) && def == nil {
+		errs.add("achievementType", "is not a registered achievement type")
+		return errs
+	}
+
+	if def != nil && def.CustomFieldSchema != nil {
+		fields := a.CustomFields
+		if fields == nil {
+			fields = map[string]interface{}{}
+		}
+		errs = append(errs, ValidateJSONSchema(def.CustomFieldSchema, fields, "customFields")...)
+	}
+
+	d := a.Details
+	switch strings.ToLower(a.AchievementType) {
+	case TypeCompetition:
+		errs.required("details.competitionName", d.CompetitionName)
+		errs.required("details.competitionLevel", d.CompetitionLevel)
+		errs.oneOf("details.competitionLevel", d.CompetitionLevel, CompetitionLevels)
+		errs.oneOf("details.medalType", d.MedalType, MedalTypes)
+		if d.Rank < 0 {
+			errs.add("details.rank", "must not be negative")
+		}
+		if d.ISSN != "" {
+			errs.add("details.issn", "is only allowed for publications")
+		}
+
+	case TypePublication:
+		errs.required("details.publicationTitle", d.PublicationTitle)
+		errs.required("details.publicationType", d.PublicationType)
+		errs.oneOf("details.publicationType", d.PublicationType, PublicationTypes)
+		if len(d.Authors) == 0 {
+			errs.add("details.authors", "at least one author is required")
+		}
+		if d.ISSN != "" && !ValidISSN(d.ISSN) {
+			errs.add("details.issn", "is not a valid ISSN")
+		}
+
+	case TypeOrganization:
+		errs.required("details.organizationName", d.OrganizationName)
+		errs.required("details.position", d.Position)
+		if d.StartDate.IsZero() {
+			errs.add("details.startDate", "is required")
+		}
+		if !d.StartDate.IsZero() && !d.EndDate.IsZero() && !d.StartDate.Before(d.EndDate) {
+			errs.add("details.endDate", "must be after startDate")
+		}
+
+	case TypeCertification:
+		errs.required("details.certificationName", d.CertificationName)
+		errs.required("details.issuedBy", d.IssuedBy)
+		if !d.ValidUntil.IsZero() && !d.ValidUntil.After(time.Now()) {
+			errs.add("details.validUntil", "must be in the future")
+		}
+	}
+
+	if d.Score < 0 {
+		errs.add("details.score", "must not be negative")
+	}
+
+	return errs
+}
+
+// ValidISSN checks the format and mod-11 check digit of an ISSN (e.g. 0317-8471)
+func ValidISSN(issn string) bool {
+	s := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(issn), "-", ""))
+	if len(s) != 8 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 7; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		sum += int(s[i]-'0') * (8 - i)
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return s[7] == 'X'
+	}
+	return s[7] == byte('0'+check)
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"strings"
+	"time"
+	"unicode"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+)
+
+const (
+	ReasonCertificationNumber = "same certification number"
+	ReasonAttachment          = "identical attachment"
+	ReasonTitleAndDate        = "same title and event date"
+	ReasonTitleAndOrganizer   = "same title and organizer"
+	ReasonCompetitionRank     = "same competition rank"
+)
+
+// NormalizeText lowercases s and collapses everything that is not a letter or
+// digit, so "Juara 1 - GEMASTIK  2024" and "juara 1 gemastik 2024" compare equal.
+func NormalizeText(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+func sameDay(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return false
+	}
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// DuplicateReasons compares a submitted achievement with an existing one and
+// returns why they look like the same claim. An empty result means no match.
+func DuplicateReasons(a, other modelMongo.Achievement) []string {
+	var reasons []string
+	ad, od := a.Details, other.Details
+
+	if cn := NormalizeText(ad.CertificationNumber); cn != "" && cn == NormalizeText(od.CertificationNumber) {
+		reasons = append(reasons, ReasonCertificationNumber)
+	}
+
+	checksums := make(map[string]bool)
+	for _, att := range other.Attachments {
+		if att.Checksum != "" {
+			checksums[att.Checksum] = true
+		}
+	}
+	for _, att := range a.Attachments {
+		if att.Checksum != "" && checksums[att.Checksum] {
+			reasons = append(reasons, ReasonAttachment)
+			break
+		}
+	}
+
+	title := NormalizeText(a.Title)
+	if title != "" && title == NormalizeText(other.Title) {
+		if sameDay(ad.EventDate, od.EventDate) {
+			reasons = append(reasons, ReasonTitleAndDate)
+		}
+		if org := NormalizeText(ad.Organizer); org != "" && org == NormalizeText(od.Organizer) {
+			reasons = append(reasons, ReasonTitleAndOrganizer)
+		}
+	}
+
+	// two students cannot hold the same individual rank in one competition
+	if a.StudentID != other.StudentID && ad.Rank > 0 && ad.Rank == od.Rank {
+		name := NormalizeText(ad.CompetitionName)
+		if name != "" && name == NormalizeText(od.CompetitionName) &&
+			(sameDay(ad.EventDate, od.EventDate) || ad.EventDate.IsZero() || od.EventDate.IsZero()) {
+			reasons = append(reasons, ReasonCompetitionRank)
+		}
+	}
+
+	return reasons
+}
+
+// FileChecksum returns the hex SHA-256 of an uploaded file
+func FileChecksum(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
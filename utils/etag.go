@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ETag formats a version counter as a strong entity tag
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// IfMatch reports whether an If-Match header value matches the given version.
+// The header may list several tags. Weak tags never match, as required for
+// If-Match, and neither does "*": a write has to name the version it replaces.
+func IfMatch(header string, version int) bool {
+	want := ETag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package utils
+
+import "strings"
+
+// SplitList parses a comma separated query value such as "title,status" into
+// its trimmed, non-empty and de-duplicated items, keeping their order
+func SplitList(value string) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		list = append(list, item)
+	}
+	return list
+}
+
+// CheckListValues returns a field error for every value that is not in allowed
+func CheckListValues(field string, values, allowed []string) []FieldError {
+	ok := make(map[string]bool, len(allowed))
+	for _, a := range allowed {
+		ok[a] = true
+	}
+
+	var errs []FieldError
+	for _, v := range values {
+		if !ok[v] {
+			errs = append(errs, FieldError{Field: field, Message: "unknown value " + v + "; allowed: " + strings.Join(allowed, ", ")})
+		}
+	}
+	return errs
+}
+
+// SelectFields keeps only the requested top-level keys of a response object.
+// The id is always kept so clients can still address the resource; an empty
+// selection returns the object unchanged.
+func SelectFields(obj map[string]interface{}, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		return obj
+	}
+
+	out := make(map[string]interface{}, len(fields)+1)
+	if id, ok := obj["id"]; ok {
+		out["id"] = id
+	}
+	for _, f := range fields {
+		if v, ok := obj[f]; ok {
+			out[f] = v
+		}
+	}
+	return out
+}
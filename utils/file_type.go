@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+)
+
+// MaxUploadSize is the most file data, or the largest resumable upload
+// chunk, the server accepts in one request. The files of a multi-file upload
+// count together.
+const MaxUploadSize = 10 * 1024 * 1024
+
+// MaxRequestSize is the request body limit: MaxUploadSize plus room for the
+// multipart envelope and form fields
+const MaxRequestSize = MaxUploadSize + 64*1024
+
+// MaxResumableUploadSize is the largest attachment the server accepts through
+// resumable uploads; per-type limits cannot exceed it
+const MaxResumableUploadSize = 512 * 1024 * 1024
+
+// SniffLength is how many leading bytes DetectFileType looks at
+const SniffLength = 512
+
+// FileType is an attachment format recognised from the content of a file
+type FileType struct {
+	Code       string   `json:"code"`
+	MimeType   string   `json:"mimeType"`
+	Extensions []string `json:"extensions"`
+	match      func(head []byte) bool
+}
+
+// FileTypes are the formats uploads can be detected as. Which of them may be
+// uploaded is configured per type in attachment_types.
+var FileTypes = []FileType{
+	{Code: "pdf", MimeType: "application/pdf", Extensions: []string{".pdf"}, match: prefix("%PDF-")},
+	{Code: "png", MimeType: "image/png", Extensions: []string{".png"}, match: prefix("\x89PNG\r\n\x1a\n")},
+	{Code: "jpeg", MimeType: "image/jpeg", Extensions: []string{".jpg", ".jpeg"}, match: prefix("\xff\xd8\xff")},
+	{Code: "gif", MimeType: "image/gif", Extensions: []string{".gif"}, match: func(head []byte) bool {
+		return prefix("GIF87a")(head) || prefix("GIF89a")(head)
+	}},
+	{Code: "webp", MimeType: "image/webp", Extensions: []string{".webp"}, match: func(head []byte) bool {
+		return len(head) >= 12 && prefix("RIFF")(head) && string(head[8:12]) == "WEBP"
+	}},
+	{Code: "mp4", MimeType: "video/mp4", Extensions: []string{".mp4", ".m4v"}, match: func(head []byte) bool {
+		return len(head) >= 12 && string(head[4:8]) == "ftyp" && mp4Brands[string(head[8:12])]
+	}},
+}
+
+// mp4Brands are the ISO media brands of MP4 video; other ftyp files such as
+// HEIC images or QuickTime movies are not MP4
+var mp4Brands = map[string]bool{
+	"isom": true, "iso2": true, "iso4": true, "iso5": true, "iso6": true,
+	"mp41": true, "mp42": true, "avc1": true, "M4V ": true, "dash": true,
+}
+
+func prefix(magic string) func([]byte) bool {
+	return func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte(magic))
+	}
+}
+
+// DetectFileType identifies a file by its leading bytes, ignoring its name and
+// the Content-Type sent by the client. It returns nil for unknown formats.
+func DetectFileType(head []byte) *FileType {
+	for i := range FileTypes {
+		if FileTypes[i].match(head) {
+			return &FileTypes[i]
+		}
+	}
+	return nil
+}
+
+// FindFileType returns the format with the given code, or nil
+func FindFileType(code string) *FileType {
+	for i := range FileTypes {
+		if FileTypes[i].Code == code {
+			return &FileTypes[i]
+		}
+	}
+	return nil
+}
+
+// HasExtension reports whether ext, with its dot, is used for the format
+func (t FileType) HasExtension(ext string) bool {
+	for _, e := range t.Extensions {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	modelMongo "StudenAchievementReportingSystem/app/models/mongodb"
+)
+
+// ValidateJSONSchema validates a decoded JSON value against the supported subset
+// of JSON Schema. Field names in the result are dotted paths rooted at path.
+func ValidateJSONSchema(schema *modelMongo.JSONSchema, value interface{}, path string) []FieldError {
+	errs := fieldErrors{}
+	validateSchema(schema, value, path, &errs)
+	return errs
+}
+
+func validateSchema(schema *modelMongo.JSONSchema, value interface{}, path string, errs *fieldErrors) {
+	if schema == nil {
+		return
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		errs.add(path, fmt.Sprintf("must be one of: %v", schema.Enum))
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			errs.add(path, "must be an object")
+			return
+		}
+		for _, name := range schema.Required {
+			if v, exists := obj[name]; !exists || v == nil {
+				errs.add(joinPath(path, name), "is required")
+			}
+		}
+
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			prop, known := schema.Properties[name]
+			if !known {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					errs.add(joinPath(path, name), "is not allowed")
+				}
+				continue
+			}
+			if obj[name] != nil {
+				validateSchema(prop, obj[name], joinPath(path, name), errs)
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			errs.add(path, "must be an array")
+			return
+		}
+		if schema.MinItems != nil && len(arr) < *schema.MinItems {
+			errs.add(path, fmt.Sprintf("must contain at least %d items", *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+			errs.add(path, fmt.Sprintf("must contain at most %d items", *schema.MaxItems))
+		}
+		for i, item := range arr {
+			validateSchema(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			errs.add(path, "must be a string")
+			return
+		}
+		if schema.MinLength != nil && len([]rune(str)) < *schema.MinLength {
+			errs.add(path, fmt.Sprintf("must be at least %d characters", *schema.MinLength))
+		}
+		if schema.MaxLength != nil && len([]rune(str)) > *schema.MaxLength {
+			errs.add(path, fmt.Sprintf("must be at most %d characters", *schema.MaxLength))
+		}
+		if schema.Pattern != "" {
+			re, err := regexp.Compile(schema.Pattern)
+			if err != nil || !re.MatchString(str) {
+				errs.add(path, "does not match the required pattern")
+			}
+		}
+		if msg := checkFormat(schema.Format, str); msg != "" {
+			errs.add(path, msg)
+		}
+
+	case "number", "integer":
+		num, ok := toFloat(value)
+		if !ok {
+			errs.add(path, "must be a "+schema.Type)
+			return
+		}
+		if schema.Type == "integer" && num != float64(int64(num)) {
+			errs.add(path, "must be an integer")
+		}
+		if schema.Minimum != nil && num < *schema.Minimum {
+			errs.add(path, fmt.Sprintf("must be >= %v", *schema.Minimum))
+		}
+		if schema.Maximum != nil && num > *schema.Maximum {
+			errs.add(path, fmt.Sprintf("must be <= %v", *schema.Maximum))
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs.add(path, "must be a boolean")
+		}
+	}
+}
+
+// CheckJSONSchema reports definition errors in a schema submitted by an admin
+func CheckJSONSchema(schema *modelMongo.JSONSchema, path string) []FieldError {
+	errs := fieldErrors{}
+	checkSchema(schema, path, &errs)
+	return errs
+}
+
+func checkSchema(schema *modelMongo.JSONSchema, path string, errs *fieldErrors) {
+	if schema == nil {
+		return
+	}
+
+	switch schema.Type {
+	case "object", "array", "string", "number", "integer", "boolean":
+	default:
+		errs.add(joinPath(path, "type"), "must be one of: object, array, string, number, integer, boolean")
+	}
+
+	if schema.Pattern != "" {
+		if _, err := regexp.Compile(schema.Pattern); err != nil {
+			errs.add(joinPath(path, "pattern"), "is not a valid regular expression")
+		}
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := schema.Properties[name]; !ok {
+			errs.add(joinPath(path, "required"), "references unknown property "+name)
+		}
+	}
+
+	for name, prop := range schema.Properties {
+		checkSchema(prop, joinPath(path, "properties."+name), errs)
+	}
+	if schema.Type == "array" {
+		checkSchema(schema.Items, joinPath(path, "items"), errs)
+	}
+}
+
+func checkFormat(format, value string) string {
+	switch format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return "must be an email address"
+		}
+	case "uri":
+		if u, err := url.ParseRequestURI(value); err != nil || u.Scheme == "" {
+			return "must be an absolute URI"
+		}
+	}
+	return ""
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if ef, ok := toFloat(e); ok {
+			if vf, ok := toFloat(value); ok && ef == vf {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return strings.TrimSuffix(path, ".") + "." + name
+}
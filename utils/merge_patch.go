@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// MergePatch applies an RFC 7396 JSON Merge Patch to a JSON document: objects
+// are merged recursively, null removes a member and any other value replaces
+// the target as a whole (arrays included).
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if len(doc) > 0 {
+		if err := json.Unmarshal(doc, &target); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergeValue(t[k], v)
+		}
+	}
+	return t
+}
+
+// CheckPatchFields rejects top-level members of a merge patch that are not in
+// allowed. Names are compared exactly, unlike encoding/json field matching.
+func CheckPatchFields(patch map[string]interface{}, allowed []string) []FieldError {
+	permitted := make(map[string]bool, len(allowed))
+	for _, f := range allowed {
+		permitted[f] = true
+	}
+
+	var fields []string
+	for field := range patch {
+		if !permitted[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	errs := fieldErrors{}
+	for _, field := range fields {
+		errs.add(field, "cannot be changed")
+	}
+	return errs
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+    "context"
+
+    "github.com/google/uuid"
+)
+
+type tenantKey struct{}
+
+// allTenants is the TenantKey value of callers working across tenants
+type allTenants struct{}
+
+// TenantKey is the fiber local holding the tenant a request is scoped to. Fiber
+// locals are request context values, so repositories read it back from
+// c.Context() with TenantFromContext.
+var TenantKey = tenantKey{}
+
+// AllTenants is stored under TenantKey for super admins working across
+// tenants, background jobs and signed links
+var AllTenants = allTenants{}
+
+// WithTenant scopes ctx to a tenant
+func WithTenant(ctx context.Context, id uuid.UUID) context.Context {
+    return context.WithValue(ctx, TenantKey, id)
+}
+
+// WithAllTenants lets ctx see every tenant
+func WithAllTenants(ctx context.Context) context.Context {
+    return context.WithValue(ctx, TenantKey, AllTenants)
+}
+
+// TenantFromContext returns the tenant ctx is scoped to. ok is false only when
+// ctx was marked with AllTenants. A ctx carrying no tenant at all is scoped to
+// uuid.Nil, which owns no rows, so a missed scope hides data instead of
+// exposing every tenant.
+func TenantFromContext(ctx context.Context) (uuid.UUID, bool) {
+    if ctx == nil {
+        return uuid.Nil, true
+    }
+    switch v := ctx.Value(TenantKey).(type) {
+    case allTenants:
+        return uuid.Nil, false
+    case uuid.UUID:
+        return v, true
+    }
+    return uuid.Nil, true
+}
@@ -16,6 +16,7 @@ func GenerateToken(user *models.User, roleName string, permissions []string) (st
         UserID:      user.ID,
         RoleID:      user.RoleID,
         RoleName:    roleName,
+        TenantID:    user.TenantID,
         Permissions: permissions,
         RegisteredClaims: jwt.RegisteredClaims{
             ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtCfg.TTLHours) * time.Hour)),